@@ -40,6 +40,7 @@ import (
 
 	cpmscontroller "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/controllers/controlplanemachineset"
 	cpmsgeneratorcontroller "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/controllers/controlplanemachinesetgenerator"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/etcdhealth"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
 	cpmswebhook "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/webhooks/controlplanemachineset"
 
@@ -131,6 +132,16 @@ func main() { //nolint:funlen,cyclop
 		setupLog.Error(err, "unable to set up uncached client")
 	}
 
+	// The etcd leader is only located when the NonLeaderFirst replacement order is configured.
+	// Without a leader locator, the indexes are replaced in ascending order.
+	var etcdLeaderLocator cpmscontroller.EtcdLeaderLocator
+
+	if leaderLocator, err := etcdhealth.NewLeaderLocator(mgr.GetAPIReader(), cfg); err != nil {
+		setupLog.Error(err, "unable to set up etcd leader locator")
+	} else {
+		etcdLeaderLocator = leaderLocator
+	}
+
 	if err := (&cpmscontroller.ControlPlaneMachineSetReconciler{
 		Client:         mgr.GetClient(),
 		UncachedClient: client.NewNamespacedClient(uncachedClient, managedNamespace),
@@ -138,6 +149,10 @@ func main() { //nolint:funlen,cyclop
 		Namespace:      managedNamespace,
 		OperatorName:   "control-plane-machine-set",
 		ReleaseVersion: getReleaseVersion(setupLog),
+		// The etcd members are read without a cache as they are only read when a replaced Machine is removed,
+		// and they are outside of the managed namespace.
+		EtcdHealthChecker: etcdhealth.NewMemberHealthChecker(mgr.GetAPIReader()),
+		EtcdLeaderLocator: etcdLeaderLocator,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ControlPlaneMachineSet")
 		os.Exit(1)
@@ -0,0 +1,949 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/maintenancewindow"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// annotationPrefix is the prefix used for all annotations that configure the behaviour
+	// of the ControlPlaneMachineSet.
+	annotationPrefix = "controlplanemachineset.machine.openshift.io/"
+
+	// MaxSurgeAnnotation is the annotation used to configure the maximum number of Machines
+	// that may be created above the desired number of replicas during a RollingUpdate.
+	MaxSurgeAnnotation = annotationPrefix + "max-surge"
+
+	// DefaultMaxSurge is the maximum surge used when the MaxSurgeAnnotation is not set.
+	DefaultMaxSurge = 1
+
+	// PausedAnnotation is the annotation used to pause the rollout of updates to the control plane Machines.
+	// While paused, replacements already in progress are completed, but no new replacements are started.
+	PausedAnnotation = annotationPrefix + "paused"
+
+	// PartitionAnnotation is the annotation used to partition the rollout of updates by index.
+	// Only outdated Machines in indexes lower than the partition are replaced. Machines in the remaining
+	// indexes are held back until the partition is raised or removed.
+	PartitionAnnotation = annotationPrefix + "partition"
+
+	// ReplacementOrderAnnotation is the annotation used to configure the order in which the indexes are considered
+	// for replacement. The value is either the name of an ordering policy, or a comma separated list of indexes.
+	ReplacementOrderAnnotation = annotationPrefix + "replacement-order"
+
+	// MaintenanceWindowScheduleAnnotation is the annotation used to configure when the maintenance window opens,
+	// as a five field cron schedule evaluated in UTC. Outside the maintenance window, no new replacements are started.
+	MaintenanceWindowScheduleAnnotation = annotationPrefix + "maintenance-window-schedule"
+
+	// MaintenanceWindowDurationAnnotation is the annotation used to configure how long the maintenance window
+	// remains open each time it opens, as a duration, for example 4h.
+	MaintenanceWindowDurationAnnotation = annotationPrefix + "maintenance-window-duration"
+
+	// OpenStackFailureDomainsAnnotation is the annotation used to configure the failure domains on the OpenStack
+	// platform, which the ControlPlaneMachineSet API does not yet support. The value is a JSON list of failure
+	// domains, and is used when the platform of the template failure domains is OpenStack.
+	OpenStackFailureDomainsAnnotation = annotationPrefix + "openstack-failure-domains"
+
+	// VSphereFailureDomainsAnnotation is the annotation used to configure the failure domains on the vSphere
+	// platform, which the ControlPlaneMachineSet API does not yet support. The value is a JSON list of failure
+	// domains, each naming a failure domain defined within the vSphere platform spec of the cluster Infrastructure,
+	// and is used when the platform of the template failure domains is VSphere.
+	VSphereFailureDomainsAnnotation = annotationPrefix + "vsphere-failure-domains"
+
+	// NutanixFailureDomainsAnnotation is the annotation used to configure the failure domains on the Nutanix
+	// platform, which the ControlPlaneMachineSet API does not yet support. The value is a JSON list of failure
+	// domains, each identifying a Prism Element (cluster) and its subnets, and is used when the platform of
+	// the template failure domains is Nutanix.
+	NutanixFailureDomainsAnnotation = annotationPrefix + "nutanix-failure-domains"
+
+	// FailureDomainWeightsAnnotation is the annotation used to configure how the Control Plane Machine indexes are
+	// spread across the failure domains. The value is a JSON list of failure domain weights, each identifying a
+	// failure domain by its string representation, as reported in the ControlPlaneMachineSet status.
+	FailureDomainWeightsAnnotation = annotationPrefix + "failure-domain-weights"
+
+	// DefaultFailureDomainWeight is the weight of failure domains that are not configured within the
+	// FailureDomainWeightsAnnotation.
+	DefaultFailureDomainWeight = 1
+
+	// UnavailableFailureDomainsAnnotation is the annotation used to mark failure domains as unavailable, for example
+	// during an outage. The value is a JSON list of failure domains, each identified by its string representation,
+	// as reported in the ControlPlaneMachineSet status. Control Plane Machines within an unavailable failure domain
+	// are evacuated, one at a time, to the available failure domains.
+	UnavailableFailureDomainsAnnotation = annotationPrefix + "unavailable-failure-domains"
+
+	// MachineSpecDiffAnnotation is set by the ControlPlaneMachineSet controller on each Control Plane Machine that
+	// needs an update. The value is a JSON list describing each field of the Machine that differs from the desired
+	// spec, with its current and desired value, so that users can see why the Machine will be replaced.
+	MachineSpecDiffAnnotation = annotationPrefix + "spec-diff"
+
+	// IgnoredProviderSpecFieldsAnnotation is the annotation used to configure the provider spec fields that are
+	// ignored when determining whether a Control Plane Machine needs an update. The value is a JSON object mapping
+	// each platform type to a list of field paths, in the format used by the MachineSpecDiffAnnotation,
+	// eg. `{"AWS":["spec.providerSpec.value.tags"]}`. Each path also ignores any field nested within it.
+	IgnoredProviderSpecFieldsAnnotation = annotationPrefix + "ignored-provider-spec-fields"
+
+	// MachineManagedMetadataAnnotation is set by the ControlPlaneMachineSet controller on each Control Plane Machine
+	// to record the keys of the labels and annotations that were last applied to the Machine from the template.
+	// The value is a JSON object, eg. `{"labels":["example.com/team"],"annotations":["example.com/owner"]}`.
+	// When a key is removed from the template, it is also removed from the Machine.
+	MachineManagedMetadataAnnotation = annotationPrefix + "managed-metadata"
+
+	// MachineTemplateRevisionAnnotation is set by the ControlPlaneMachineSet controller on each Control Plane Machine
+	// it creates, to record the revision of the template the Machine was created from. The revision is a hash of the
+	// template, excluding the labels, annotations and taints which are updated in place on the existing Machines.
+	MachineTemplateRevisionAnnotation = annotationPrefix + "template-revision"
+
+	// RollbackToRevisionAnnotation is used to restore a previously applied template into the ControlPlaneMachineSet
+	// spec. The value is the template revision to restore, as recorded on the Machines created from it, or
+	// PreviousTemplateRevision to restore the most recently applied template other than the current template.
+	// The annotation is removed once the template has been restored, after which the update strategy rolls out the
+	// restored template as it would any other change to the template.
+	RollbackToRevisionAnnotation = annotationPrefix + "rollback-to-revision"
+
+	// PreviousTemplateRevision is the value of the RollbackToRevisionAnnotation used to restore the most recently
+	// applied template other than the current template.
+	PreviousTemplateRevision = "previous"
+
+	// RolloutHistoryAnnotation is set by the ControlPlaneMachineSet controller to record the most recent rollouts of
+	// the ControlPlaneMachineSet. The value is a JSON list of RolloutRecords, oldest first, eg.
+	// `[{"templateHash":"5d8f7c9b4","startTime":"2023-06-01T10:00:00Z","endTime":"2023-06-01T11:30:00Z",...}]`.
+	RolloutHistoryAnnotation = annotationPrefix + "rollout-history"
+
+	// providerSpecFieldPrefix is the prefix of the path of each field within the provider spec of a Machine.
+	providerSpecFieldPrefix = "spec.providerSpec.value."
+)
+
+// ReplacementOrderPolicy is the policy used to order the indexes of the ControlPlaneMachineSet for replacement.
+type ReplacementOrderPolicy string
+
+const (
+	// AscendingReplacementOrder replaces the indexes in ascending order. This is the default policy.
+	AscendingReplacementOrder ReplacementOrderPolicy = "Ascending"
+
+	// NonLeaderFirstReplacementOrder replaces the indexes that do not host the etcd leader first,
+	// in ascending order, before replacing the index that hosts the etcd leader.
+	NonLeaderFirstReplacementOrder ReplacementOrderPolicy = "NonLeaderFirst"
+
+	// ExplicitReplacementOrder replaces the indexes in the order given by the user.
+	// Any indexes not given are replaced afterwards in ascending order.
+	ExplicitReplacementOrder ReplacementOrderPolicy = "Explicit"
+)
+
+// ReplacementOrder describes the order in which the indexes of the ControlPlaneMachineSet are replaced.
+type ReplacementOrder struct {
+	// Policy is the policy used to order the indexes.
+	Policy ReplacementOrderPolicy
+
+	// Indexes is the order given by the user when the Policy is Explicit.
+	Indexes []int32
+}
+
+// ManagedMetadata records the keys of the labels and annotations applied to a Machine from the template.
+type ManagedMetadata struct {
+	// Labels are the keys of the labels applied from the template.
+	Labels []string `json:"labels,omitempty"`
+
+	// Annotations are the keys of the annotations applied from the template.
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// RolloutOutcome describes how a rollout of the ControlPlaneMachineSet ended.
+type RolloutOutcome string
+
+const (
+	// RolloutInProgress denotes that the rollout has not yet ended.
+	RolloutInProgress RolloutOutcome = "InProgress"
+
+	// RolloutCompleted denotes that every outdated Control Plane Machine was replaced.
+	RolloutCompleted RolloutOutcome = "Completed"
+
+	// RolloutFailed denotes that a replacement Control Plane Machine failed, and that the rollout stopped.
+	RolloutFailed RolloutOutcome = "Failed"
+
+	// RolloutAborted denotes that the template changed before the rollout completed.
+	RolloutAborted RolloutOutcome = "Aborted"
+)
+
+// RolloutRecord describes a single rollout of the ControlPlaneMachineSet.
+type RolloutRecord struct {
+	// TemplateHash is the hash of the template that the Control Plane Machines were rolled out to.
+	TemplateHash string `json:"templateHash"`
+
+	// StartTime is the time at which the outdated Control Plane Machines were first observed.
+	StartTime metav1.Time `json:"startTime"`
+
+	// EndTime is the time at which the rollout ended. It is not set while the rollout is in progress.
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+
+	// Indexes are the Control Plane Machine indexes replaced by the rollout, in ascending order.
+	Indexes []int32 `json:"indexes,omitempty"`
+
+	// OldMachines are the names of the outdated Control Plane Machines replaced by the rollout.
+	OldMachines []string `json:"oldMachines,omitempty"`
+
+	// NewMachines are the names of the Control Plane Machines created by the rollout.
+	NewMachines []string `json:"newMachines,omitempty"`
+
+	// Outcome describes how the rollout ended.
+	Outcome RolloutOutcome `json:"outcome"`
+
+	// Message explains the outcome when the rollout failed or was aborted.
+	Message string `json:"message,omitempty"`
+}
+
+// FailureDomainWeight configures how the Control Plane Machine indexes are assigned to a failure domain.
+type FailureDomainWeight struct {
+	// FailureDomain identifies the failure domain by its string representation,
+	// as reported in the ControlPlaneMachineSet status.
+	FailureDomain string `json:"failureDomain"`
+
+	// Weight is the share of the indexes assigned to the failure domain, relative to the weights of the other
+	// failure domains. When omitted, the DefaultFailureDomainWeight is used.
+	Weight int32 `json:"weight,omitempty"`
+
+	// Avoid marks the failure domain to be used only when every failure domain is marked to be avoided.
+	// An avoided failure domain is dropped from the base mapping, and every index in it is remapped straight away,
+	// with the Rebalanced reason. The Machines in those indexes then need an update, so the update strategy starts
+	// replacing them immediately.
+	Avoid bool `json:"avoid,omitempty"`
+}
+
+var (
+	// ErrInvalidInteger is returned when an annotation value cannot be parsed as an integer.
+	ErrInvalidInteger = errors.New("value must be an integer")
+
+	// ErrInvalidBoolean is returned when an annotation value cannot be parsed as a boolean.
+	ErrInvalidBoolean = errors.New("value must be either true or false")
+
+	// ErrPartitionOutOfRange is returned when the partition is not a valid index boundary for the replicas.
+	ErrPartitionOutOfRange = errors.New("value must be between 0 and the number of replicas")
+
+	// ErrInvalidReplacementOrder is returned when the replacement order is neither a known policy nor a list of indexes.
+	ErrInvalidReplacementOrder = errors.New("value must be Ascending, NonLeaderFirst, or a comma separated list of indexes")
+
+	// ErrIndexOutOfRange is returned when an index is not valid for the number of replicas.
+	ErrIndexOutOfRange = errors.New("index must be between 0 and the number of replicas minus 1")
+
+	// ErrDuplicateIndex is returned when an index is given more than once.
+	ErrDuplicateIndex = errors.New("index must not be repeated")
+
+	// ErrMaxSurgeOutOfRange is returned when the maximum surge could put etcd quorum at risk.
+	ErrMaxSurgeOutOfRange = errors.New("value must be between 1 and the number of etcd members that may be lost while maintaining quorum")
+
+	// ErrIncompleteMaintenanceWindow is returned when only one of the maintenance window annotations is set.
+	ErrIncompleteMaintenanceWindow = errors.New("the maintenance window schedule and duration must be set together")
+
+	// ErrInvalidFailureDomains is returned when the failure domains annotation is not a valid list of failure domains.
+	ErrInvalidFailureDomains = errors.New("value must be a JSON list of failure domains")
+
+	// ErrEmptyFailureDomains is returned when the failure domains annotation contains no failure domains.
+	ErrEmptyFailureDomains = errors.New("at least one failure domain must be configured")
+
+	// ErrMissingFailureDomains is returned when the failure domains platform requires the failure domains
+	// annotation, but it is not set.
+	ErrMissingFailureDomains = errors.New("failure domains must be configured when the failure domains platform is set")
+
+	// ErrUnexpectedFailureDomains is returned when the failure domains annotation is set, but the failure domains
+	// platform does not match.
+	ErrUnexpectedFailureDomains = errors.New("failure domains may only be configured when the failure domains platform matches")
+
+	// ErrMissingFailureDomainName is returned when a failure domain in the failure domains annotation has no name.
+	ErrMissingFailureDomainName = errors.New("each failure domain must have a name")
+
+	// ErrMissingFailureDomainSubnets is returned when a failure domain in the failure domains annotation
+	// does not configure any subnets.
+	ErrMissingFailureDomainSubnets = errors.New("each failure domain must configure at least one subnet")
+
+	// ErrInvalidResourceIdentifier is returned when a Nutanix resource identifier does not set the value
+	// matching its type.
+	ErrInvalidResourceIdentifier = errors.New("resource identifiers must have type name or uuid, and set the matching value")
+
+	// ErrInvalidFailureDomainWeights is returned when the failure domain weights cannot be parsed.
+	ErrInvalidFailureDomainWeights = errors.New("value must be a JSON list of failure domain weights")
+
+	// ErrMissingFailureDomain is returned when an entry of the failure domain weights, or of the unavailable
+	// failure domains, does not identify a failure domain.
+	ErrMissingFailureDomain = errors.New("each entry must identify a failure domain")
+
+	// ErrNegativeWeight is returned when a failure domain weight is negative.
+	ErrNegativeWeight = errors.New("weight must not be negative")
+
+	// ErrDuplicateFailureDomain is returned when a failure domain is configured more than once.
+	ErrDuplicateFailureDomain = errors.New("failure domain must not be repeated")
+
+	// ErrUnexpectedFailureDomainWeights is returned when the failure domain weights annotation is set, but no
+	// failure domains are configured.
+	ErrUnexpectedFailureDomainWeights = errors.New("failure domain weights may only be configured when failure domains are configured")
+
+	// ErrUnknownFailureDomain is returned when a failure domain weight does not match any configured failure domain.
+	ErrUnknownFailureDomain = errors.New("failure domain is not configured on the control plane machine set")
+
+	// ErrAllFailureDomainsAvoided is returned when every configured failure domain is marked to be avoided.
+	ErrAllFailureDomainsAvoided = errors.New("at least one failure domain must not be avoided")
+
+	// ErrInvalidUnavailableFailureDomains is returned when the unavailable failure domains cannot be parsed.
+	ErrInvalidUnavailableFailureDomains = errors.New("value must be a JSON list of failure domains identified by their string representation")
+
+	// ErrUnexpectedUnavailableFailureDomains is returned when the unavailable failure domains annotation is set,
+	// but no failure domains are configured.
+	ErrUnexpectedUnavailableFailureDomains = errors.New("failure domains may only be marked unavailable when failure domains are configured")
+
+	// ErrAllFailureDomainsUnavailable is returned when every configured failure domain is marked as unavailable.
+	ErrAllFailureDomainsUnavailable = errors.New("at least one failure domain must remain available")
+
+	// ErrInvalidIgnoredProviderSpecFields is returned when the ignored provider spec fields cannot be parsed.
+	ErrInvalidIgnoredProviderSpecFields = errors.New("value must be a JSON object mapping platform types to lists of field paths")
+
+	// ErrMissingPlatformType is returned when the ignored provider spec fields are configured for an empty platform type.
+	ErrMissingPlatformType = errors.New("each entry must identify a platform type")
+
+	// ErrInvalidProviderSpecFieldPath is returned when an ignored field path is not a field within the provider spec.
+	ErrInvalidProviderSpecFieldPath = errors.New("field path must identify a field within spec.providerSpec.value")
+
+	// ErrDuplicateProviderSpecFieldPath is returned when an ignored field path is listed more than once for a platform.
+	ErrDuplicateProviderSpecFieldPath = errors.New("field path must not be repeated")
+
+	// ErrInvalidManagedMetadata is returned when the managed metadata annotation cannot be parsed.
+	ErrInvalidManagedMetadata = errors.New("value must be a JSON object listing the keys of the managed labels and annotations")
+
+	// ErrInvalidRolloutHistory is returned when the rollout history annotation cannot be parsed.
+	ErrInvalidRolloutHistory = errors.New("value must be a JSON list of rollout records")
+)
+
+// MaxSurge returns the maximum surge configured for the ControlPlaneMachineSet.
+// When the MaxSurgeAnnotation is not set, the DefaultMaxSurge is returned.
+// An error is returned when the value is not an integer, or when the value could break etcd quorum
+// for the replicas configured on the ControlPlaneMachineSet.
+func MaxSurge(cpms *machinev1.ControlPlaneMachineSet) (int, error) {
+	value, ok := cpms.Annotations[MaxSurgeAnnotation]
+	if !ok {
+		return DefaultMaxSurge, nil
+	}
+
+	maxSurge, err := ParseMaxSurge(cpms, value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", MaxSurgeAnnotation, err)
+	}
+
+	return maxSurge, nil
+}
+
+// ParseMaxSurge parses the value of the MaxSurgeAnnotation and checks that it is safe for the replicas
+// configured on the ControlPlaneMachineSet.
+func ParseMaxSurge(cpms *machinev1.ControlPlaneMachineSet, value string) (int, error) {
+	maxSurge, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidInteger, value)
+	}
+
+	if maxAllowed := MaxAllowedSurge(cpms); maxSurge < 1 || maxSurge > maxAllowed {
+		return 0, fmt.Errorf("%w: got %d, allowed range is 1-%d", ErrMaxSurgeOutOfRange, maxSurge, maxAllowed)
+	}
+
+	return maxSurge, nil
+}
+
+// MaxAllowedSurge returns the largest maximum surge that is safe for the replicas of the ControlPlaneMachineSet.
+// Each surged Machine replaces an existing etcd member, so at most this many members are removed at once.
+// This is limited to the number of members that may be lost while retaining quorum, with a minimum of 1.
+func MaxAllowedSurge(cpms *machinev1.ControlPlaneMachineSet) int {
+	if cpms.Spec.Replicas == nil {
+		return DefaultMaxSurge
+	}
+
+	faultTolerance := (int(*cpms.Spec.Replicas) - 1) / 2
+	if faultTolerance < DefaultMaxSurge {
+		return DefaultMaxSurge
+	}
+
+	return faultTolerance
+}
+
+// Paused returns whether the rollout of updates has been paused for the ControlPlaneMachineSet.
+// When the PausedAnnotation is not set, the rollout is not paused.
+func Paused(cpms *machinev1.ControlPlaneMachineSet) (bool, error) {
+	value, ok := cpms.Annotations[PausedAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	paused, err := ParsePaused(value)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", PausedAnnotation, err)
+	}
+
+	return paused, nil
+}
+
+// ParsePaused parses the value of the PausedAnnotation.
+func ParsePaused(value string) (bool, error) {
+	paused, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %q", ErrInvalidBoolean, value)
+	}
+
+	return paused, nil
+}
+
+// Partition returns the partition configured for the ControlPlaneMachineSet.
+// The boolean result reports whether the PartitionAnnotation is set. When it is not set, no indexes are held back.
+func Partition(cpms *machinev1.ControlPlaneMachineSet) (int32, bool, error) {
+	value, ok := cpms.Annotations[PartitionAnnotation]
+	if !ok {
+		return 0, false, nil
+	}
+
+	partition, err := ParsePartition(cpms, value)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", PartitionAnnotation, err)
+	}
+
+	return partition, true, nil
+}
+
+// ParsePartition parses the value of the PartitionAnnotation and checks that it is within the range of
+// indexes for the replicas configured on the ControlPlaneMachineSet.
+func ParsePartition(cpms *machinev1.ControlPlaneMachineSet, value string) (int32, error) {
+	partition, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidInteger, value)
+	}
+
+	replicas := int64(0)
+	if cpms.Spec.Replicas != nil {
+		replicas = int64(*cpms.Spec.Replicas)
+	}
+
+	if partition < 0 || partition > replicas {
+		return 0, fmt.Errorf("%w: got %d, allowed range is 0-%d", ErrPartitionOutOfRange, partition, replicas)
+	}
+
+	return int32(partition), nil
+}
+
+// GetReplacementOrder returns the replacement order configured for the ControlPlaneMachineSet.
+// When the ReplacementOrderAnnotation is not set, the Ascending policy is returned.
+func GetReplacementOrder(cpms *machinev1.ControlPlaneMachineSet) (ReplacementOrder, error) {
+	value, ok := cpms.Annotations[ReplacementOrderAnnotation]
+	if !ok {
+		return ReplacementOrder{Policy: AscendingReplacementOrder}, nil
+	}
+
+	order, err := ParseReplacementOrder(cpms, value)
+	if err != nil {
+		return ReplacementOrder{}, fmt.Errorf("%s: %w", ReplacementOrderAnnotation, err)
+	}
+
+	return order, nil
+}
+
+// ParseReplacementOrder parses the value of the ReplacementOrderAnnotation. The value is either the name of a
+// policy, or a comma separated list of unique indexes within the range of the replicas configured on the
+// ControlPlaneMachineSet.
+func ParseReplacementOrder(cpms *machinev1.ControlPlaneMachineSet, value string) (ReplacementOrder, error) {
+	switch ReplacementOrderPolicy(value) {
+	case AscendingReplacementOrder, NonLeaderFirstReplacementOrder:
+		return ReplacementOrder{Policy: ReplacementOrderPolicy(value)}, nil
+	case ExplicitReplacementOrder:
+		// The explicit policy is inferred from a list of indexes and cannot be named directly.
+		return ReplacementOrder{}, fmt.Errorf("%w: %q", ErrInvalidReplacementOrder, value)
+	}
+
+	replicas := int64(0)
+	if cpms.Spec.Replicas != nil {
+		replicas = int64(*cpms.Spec.Replicas)
+	}
+
+	indexes := []int32{}
+	seen := map[int64]struct{}{}
+
+	for _, item := range strings.Split(value, ",") {
+		idx, err := strconv.ParseInt(strings.TrimSpace(item), 10, 32)
+		if err != nil {
+			return ReplacementOrder{}, fmt.Errorf("%w: %q", ErrInvalidReplacementOrder, value)
+		}
+
+		if idx < 0 || idx >= replicas {
+			return ReplacementOrder{}, fmt.Errorf("%w: got %d, allowed range is 0-%d", ErrIndexOutOfRange, idx, replicas-1)
+		}
+
+		if _, ok := seen[idx]; ok {
+			return ReplacementOrder{}, fmt.Errorf("%w: %d", ErrDuplicateIndex, idx)
+		}
+
+		seen[idx] = struct{}{}
+		indexes = append(indexes, int32(idx))
+	}
+
+	return ReplacementOrder{Policy: ExplicitReplacementOrder, Indexes: indexes}, nil
+}
+
+// MaintenanceWindow returns the maintenance window configured for the ControlPlaneMachineSet.
+// The boolean result reports whether a maintenance window is configured. When it is not, replacements may
+// be started at any time.
+func MaintenanceWindow(cpms *machinev1.ControlPlaneMachineSet) (maintenancewindow.Window, bool, error) {
+	schedule, hasSchedule := cpms.Annotations[MaintenanceWindowScheduleAnnotation]
+	duration, hasDuration := cpms.Annotations[MaintenanceWindowDurationAnnotation]
+
+	switch {
+	case !hasSchedule && !hasDuration:
+		return maintenancewindow.Window{}, false, nil
+	case !hasSchedule:
+		return maintenancewindow.Window{}, false, fmt.Errorf("%s: %w", MaintenanceWindowScheduleAnnotation, ErrIncompleteMaintenanceWindow)
+	case !hasDuration:
+		return maintenancewindow.Window{}, false, fmt.Errorf("%s: %w", MaintenanceWindowDurationAnnotation, ErrIncompleteMaintenanceWindow)
+	}
+
+	s, err := ParseMaintenanceWindowSchedule(schedule)
+	if err != nil {
+		return maintenancewindow.Window{}, false, fmt.Errorf("%s: %w", MaintenanceWindowScheduleAnnotation, err)
+	}
+
+	d, err := ParseMaintenanceWindowDuration(duration)
+	if err != nil {
+		return maintenancewindow.Window{}, false, fmt.Errorf("%s: %w", MaintenanceWindowDurationAnnotation, err)
+	}
+
+	return maintenancewindow.Window{Schedule: s, Duration: d}, true, nil
+}
+
+// ParseMaintenanceWindowSchedule parses the value of the MaintenanceWindowScheduleAnnotation.
+func ParseMaintenanceWindowSchedule(value string) (maintenancewindow.Schedule, error) {
+	schedule, err := maintenancewindow.ParseSchedule(value)
+	if err != nil {
+		return maintenancewindow.Schedule{}, fmt.Errorf("could not parse schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// ParseMaintenanceWindowDuration parses the value of the MaintenanceWindowDurationAnnotation.
+func ParseMaintenanceWindowDuration(value string) (time.Duration, error) {
+	duration, err := maintenancewindow.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse duration: %w", err)
+	}
+
+	return duration, nil
+}
+
+// FailureDomains returns the failure domains configured on the template of the ControlPlaneMachineSet.
+// On platforms where the ControlPlaneMachineSet API does not yet support failure domains, the failure domains
+// are read from the annotation for the platform.
+func FailureDomains(cpms *machinev1.ControlPlaneMachineSet) ([]failuredomain.FailureDomain, error) {
+	template := cpms.Spec.Template.OpenShiftMachineV1Beta1Machine
+	if template == nil {
+		return nil, nil
+	}
+
+	platformAnnotation, hasPlatformAnnotation := FailureDomainsAnnotation(template.FailureDomains.Platform)
+
+	for _, annotation := range FailureDomainsAnnotations() {
+		if _, ok := cpms.Annotations[annotation]; ok && annotation != platformAnnotation {
+			return nil, fmt.Errorf("%s: %w", annotation, ErrUnexpectedFailureDomains)
+		}
+	}
+
+	if !hasPlatformAnnotation {
+		failureDomains, err := failuredomain.NewFailureDomains(template.FailureDomains)
+		if err != nil {
+			return nil, fmt.Errorf("could not construct failure domains: %w", err)
+		}
+
+		return failureDomains, nil
+	}
+
+	value, ok := cpms.Annotations[platformAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", platformAnnotation, ErrMissingFailureDomains)
+	}
+
+	switch template.FailureDomains.Platform {
+	case configv1.VSpherePlatformType:
+		vsphereFailureDomains, err := ParseVSphereFailureDomains(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", platformAnnotation, err)
+		}
+
+		return failuredomain.NewVSphereFailureDomains(vsphereFailureDomains), nil
+	case configv1.NutanixPlatformType:
+		nutanixFailureDomains, err := ParseNutanixFailureDomains(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", platformAnnotation, err)
+		}
+
+		return failuredomain.NewNutanixFailureDomains(nutanixFailureDomains), nil
+	default:
+		openStackFailureDomains, err := ParseOpenStackFailureDomains(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", platformAnnotation, err)
+		}
+
+		return failuredomain.NewOpenStackFailureDomains(openStackFailureDomains), nil
+	}
+}
+
+// FailureDomainWeights returns the failure domain weights configured for the ControlPlaneMachineSet.
+// When the FailureDomainWeightsAnnotation is not set, no weights are returned and every failure domain
+// has the DefaultFailureDomainWeight.
+func FailureDomainWeights(cpms *machinev1.ControlPlaneMachineSet) ([]FailureDomainWeight, error) {
+	value, ok := cpms.Annotations[FailureDomainWeightsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	weights, err := ParseFailureDomainWeights(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", FailureDomainWeightsAnnotation, err)
+	}
+
+	return weights, nil
+}
+
+// ParseFailureDomainWeights parses the value of the FailureDomainWeightsAnnotation.
+// The value must be a JSON list in which each failure domain is configured at most once.
+func ParseFailureDomainWeights(value string) ([]FailureDomainWeight, error) {
+	weights := []FailureDomainWeight{}
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&weights); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFailureDomainWeights, err.Error())
+	}
+
+	seen := map[string]struct{}{}
+
+	for _, weight := range weights {
+		if weight.FailureDomain == "" {
+			return nil, ErrMissingFailureDomain
+		}
+
+		if weight.Weight < 0 {
+			return nil, fmt.Errorf("%w: got %d for %s", ErrNegativeWeight, weight.Weight, weight.FailureDomain)
+		}
+
+		if _, ok := seen[weight.FailureDomain]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateFailureDomain, weight.FailureDomain)
+		}
+
+		seen[weight.FailureDomain] = struct{}{}
+	}
+
+	return weights, nil
+}
+
+// UnavailableFailureDomains returns the failure domains marked as unavailable on the ControlPlaneMachineSet.
+// When the UnavailableFailureDomainsAnnotation is not set, no failure domains are returned.
+func UnavailableFailureDomains(cpms *machinev1.ControlPlaneMachineSet) ([]string, error) {
+	value, ok := cpms.Annotations[UnavailableFailureDomainsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	failureDomains, err := ParseUnavailableFailureDomains(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", UnavailableFailureDomainsAnnotation, err)
+	}
+
+	return failureDomains, nil
+}
+
+// ParseUnavailableFailureDomains parses the value of the UnavailableFailureDomainsAnnotation.
+// The value must be a JSON list in which each failure domain is listed at most once.
+func ParseUnavailableFailureDomains(value string) ([]string, error) {
+	failureDomains := []string{}
+
+	if err := json.Unmarshal([]byte(value), &failureDomains); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidUnavailableFailureDomains, err.Error())
+	}
+
+	seen := map[string]struct{}{}
+
+	for _, failureDomain := range failureDomains {
+		if failureDomain == "" {
+			return nil, ErrMissingFailureDomain
+		}
+
+		if _, ok := seen[failureDomain]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateFailureDomain, failureDomain)
+		}
+
+		seen[failureDomain] = struct{}{}
+	}
+
+	return failureDomains, nil
+}
+
+// IgnoredProviderSpecFields returns the provider spec field paths that are ignored, for the given platform, when
+// determining whether a Control Plane Machine needs an update.
+// When the IgnoredProviderSpecFieldsAnnotation is not set, or does not configure the platform, no paths are returned.
+func IgnoredProviderSpecFields(cpms *machinev1.ControlPlaneMachineSet, platform configv1.PlatformType) ([]string, error) {
+	value, ok := cpms.Annotations[IgnoredProviderSpecFieldsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	ignoredFields, err := ParseIgnoredProviderSpecFields(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", IgnoredProviderSpecFieldsAnnotation, err)
+	}
+
+	return ignoredFields[platform], nil
+}
+
+// ParseIgnoredProviderSpecFields parses the value of the IgnoredProviderSpecFieldsAnnotation.
+// The value must be a JSON object in which each platform type maps to a list of paths of fields within the
+// provider spec, each listed at most once. Ignoring the provider spec as a whole is not allowed.
+func ParseIgnoredProviderSpecFields(value string) (map[configv1.PlatformType][]string, error) {
+	ignoredFields := map[configv1.PlatformType][]string{}
+
+	if err := json.Unmarshal([]byte(value), &ignoredFields); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIgnoredProviderSpecFields, err.Error())
+	}
+
+	for platform, paths := range ignoredFields {
+		if platform == "" {
+			return nil, ErrMissingPlatformType
+		}
+
+		seen := map[string]struct{}{}
+
+		for _, path := range paths {
+			if !strings.HasPrefix(path, providerSpecFieldPrefix) || len(path) == len(providerSpecFieldPrefix) {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidProviderSpecFieldPath, path)
+			}
+
+			if _, ok := seen[path]; ok {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateProviderSpecFieldPath, path)
+			}
+
+			seen[path] = struct{}{}
+		}
+	}
+
+	return ignoredFields, nil
+}
+
+// IsControlPlaneMachineSetAnnotation checks whether the key uses the prefix of the annotations that configure,
+// or are set by, the ControlPlaneMachineSet.
+func IsControlPlaneMachineSetAnnotation(key string) bool {
+	return strings.HasPrefix(key, annotationPrefix)
+}
+
+// MachineManagedMetadata returns the keys of the labels and annotations last applied to the Machine from the template.
+// When the MachineManagedMetadataAnnotation is not set, no keys are returned.
+func MachineManagedMetadata(machine metav1.Object) (ManagedMetadata, error) {
+	value, ok := machine.GetAnnotations()[MachineManagedMetadataAnnotation]
+	if !ok {
+		return ManagedMetadata{}, nil
+	}
+
+	managedMetadata, err := ParseManagedMetadata(value)
+	if err != nil {
+		return ManagedMetadata{}, fmt.Errorf("%s: %w", MachineManagedMetadataAnnotation, err)
+	}
+
+	return managedMetadata, nil
+}
+
+// ParseManagedMetadata parses the value of the MachineManagedMetadataAnnotation.
+func ParseManagedMetadata(value string) (ManagedMetadata, error) {
+	managedMetadata := ManagedMetadata{}
+
+	if err := json.Unmarshal([]byte(value), &managedMetadata); err != nil {
+		return ManagedMetadata{}, fmt.Errorf("%w: %s", ErrInvalidManagedMetadata, err.Error())
+	}
+
+	return managedMetadata, nil
+}
+
+// RolloutHistory returns the rollouts recorded on the ControlPlaneMachineSet, oldest first.
+// When the RolloutHistoryAnnotation is not set, no rollouts are returned.
+func RolloutHistory(cpms *machinev1.ControlPlaneMachineSet) ([]RolloutRecord, error) {
+	value, ok := cpms.Annotations[RolloutHistoryAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	history, err := ParseRolloutHistory(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", RolloutHistoryAnnotation, err)
+	}
+
+	return history, nil
+}
+
+// ParseRolloutHistory parses the value of the RolloutHistoryAnnotation.
+func ParseRolloutHistory(value string) ([]RolloutRecord, error) {
+	history := []RolloutRecord{}
+
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRolloutHistory, err.Error())
+	}
+
+	return history, nil
+}
+
+// FailureDomainsAnnotations returns the annotations used to configure the failure domains on the platforms
+// where the ControlPlaneMachineSet API does not yet support failure domains.
+func FailureDomainsAnnotations() []string {
+	return []string{
+		OpenStackFailureDomainsAnnotation,
+		VSphereFailureDomainsAnnotation,
+		NutanixFailureDomainsAnnotation,
+	}
+}
+
+// FailureDomainsAnnotationValues returns the failure domains annotations set on the ControlPlaneMachineSet, keyed by
+// annotation. When none are set, nil is returned.
+func FailureDomainsAnnotationValues(cpms *machinev1.ControlPlaneMachineSet) map[string]string {
+	var values map[string]string
+
+	for _, annotation := range FailureDomainsAnnotations() {
+		value, ok := cpms.Annotations[annotation]
+		if !ok {
+			continue
+		}
+
+		if values == nil {
+			values = map[string]string{}
+		}
+
+		values[annotation] = value
+	}
+
+	return values
+}
+
+// FailureDomainsAnnotation returns the annotation used to configure the failure domains for the platform,
+// and whether the failure domains of the platform are configured by annotation.
+func FailureDomainsAnnotation(platform configv1.PlatformType) (string, bool) {
+	switch platform {
+	case configv1.OpenStackPlatformType:
+		return OpenStackFailureDomainsAnnotation, true
+	case configv1.VSpherePlatformType:
+		return VSphereFailureDomainsAnnotation, true
+	case configv1.NutanixPlatformType:
+		return NutanixFailureDomainsAnnotation, true
+	default:
+		return "", false
+	}
+}
+
+// ParseOpenStackFailureDomains parses the value of the OpenStackFailureDomainsAnnotation.
+// The value must be a JSON list containing at least one failure domain.
+func ParseOpenStackFailureDomains(value string) ([]failuredomain.OpenStackFailureDomain, error) {
+	failureDomains := []failuredomain.OpenStackFailureDomain{}
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&failureDomains); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFailureDomains, err.Error())
+	}
+
+	if len(failureDomains) == 0 {
+		return nil, ErrEmptyFailureDomains
+	}
+
+	return failureDomains, nil
+}
+
+// ParseVSphereFailureDomains parses the value of the VSphereFailureDomainsAnnotation.
+// The value must be a JSON list containing at least one failure domain, and each failure domain must be named.
+func ParseVSphereFailureDomains(value string) ([]failuredomain.VSphereFailureDomain, error) {
+	failureDomains := []failuredomain.VSphereFailureDomain{}
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&failureDomains); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFailureDomains, err.Error())
+	}
+
+	if len(failureDomains) == 0 {
+		return nil, ErrEmptyFailureDomains
+	}
+
+	for _, failureDomain := range failureDomains {
+		if failureDomain.Name == "" {
+			return nil, ErrMissingFailureDomainName
+		}
+	}
+
+	return failureDomains, nil
+}
+
+// ParseNutanixFailureDomains parses the value of the NutanixFailureDomainsAnnotation.
+// The value must be a JSON list containing at least one failure domain, and each failure domain must identify
+// a Prism Element (cluster) and at least one subnet.
+func ParseNutanixFailureDomains(value string) ([]failuredomain.NutanixFailureDomain, error) {
+	failureDomains := []failuredomain.NutanixFailureDomain{}
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&failureDomains); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFailureDomains, err.Error())
+	}
+
+	if len(failureDomains) == 0 {
+		return nil, ErrEmptyFailureDomains
+	}
+
+	for _, failureDomain := range failureDomains {
+		if !validNutanixResourceIdentifier(failureDomain.Cluster) {
+			return nil, fmt.Errorf("cluster: %w", ErrInvalidResourceIdentifier)
+		}
+
+		if len(failureDomain.Subnets) == 0 {
+			return nil, ErrMissingFailureDomainSubnets
+		}
+
+		for _, subnet := range failureDomain.Subnets {
+			if !validNutanixResourceIdentifier(subnet) {
+				return nil, fmt.Errorf("subnets: %w", ErrInvalidResourceIdentifier)
+			}
+		}
+	}
+
+	return failureDomains, nil
+}
+
+// validNutanixResourceIdentifier returns whether the Nutanix resource identifier sets the value matching its type.
+func validNutanixResourceIdentifier(id machinev1.NutanixResourceIdentifier) bool {
+	switch id.Type {
+	case machinev1.NutanixIdentifierName:
+		return id.Name != nil && *id.Name != ""
+	case machinev1.NutanixIdentifierUUID:
+		return id.UUID != nil && *id.UUID != ""
+	default:
+		return false
+	}
+}
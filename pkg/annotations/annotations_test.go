@@ -0,0 +1,796 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotations
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1 "github.com/openshift/api/machine/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	machinev1beta1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/maintenancewindow"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("MaxSurge", func() {
+	type maxSurgeTableInput struct {
+		replicas         int32
+		annotations      map[string]string
+		expectedMaxSurge int
+		expectedError    error
+	}
+
+	DescribeTable("should parse the maximum surge from the ControlPlaneMachineSet", func(in maxSurgeTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithReplicas(in.replicas).Build()
+		cpms.Annotations = in.annotations
+
+		maxSurge, err := MaxSurge(cpms)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(maxSurge).To(Equal(in.expectedMaxSurge))
+	},
+		Entry("with no annotation", maxSurgeTableInput{
+			replicas:         3,
+			expectedMaxSurge: DefaultMaxSurge,
+		}),
+		Entry("with a maximum surge of 1 and 3 replicas", maxSurgeTableInput{
+			replicas:         3,
+			annotations:      map[string]string{MaxSurgeAnnotation: "1"},
+			expectedMaxSurge: 1,
+		}),
+		Entry("with a maximum surge of 2 and 5 replicas", maxSurgeTableInput{
+			replicas:         5,
+			annotations:      map[string]string{MaxSurgeAnnotation: "2"},
+			expectedMaxSurge: 2,
+		}),
+		Entry("with a maximum surge of 1 and 1 replica", maxSurgeTableInput{
+			replicas:         1,
+			annotations:      map[string]string{MaxSurgeAnnotation: "1"},
+			expectedMaxSurge: 1,
+		}),
+		Entry("with a maximum surge of 2 and 3 replicas", maxSurgeTableInput{
+			replicas:      3,
+			annotations:   map[string]string{MaxSurgeAnnotation: "2"},
+			expectedError: fmt.Errorf("%s: %w", MaxSurgeAnnotation, fmt.Errorf("%w: got 2, allowed range is 1-1", ErrMaxSurgeOutOfRange)),
+		}),
+		Entry("with a maximum surge of 3 and 5 replicas", maxSurgeTableInput{
+			replicas:      5,
+			annotations:   map[string]string{MaxSurgeAnnotation: "3"},
+			expectedError: fmt.Errorf("%s: %w", MaxSurgeAnnotation, fmt.Errorf("%w: got 3, allowed range is 1-2", ErrMaxSurgeOutOfRange)),
+		}),
+		Entry("with a maximum surge of 0", maxSurgeTableInput{
+			replicas:      3,
+			annotations:   map[string]string{MaxSurgeAnnotation: "0"},
+			expectedError: fmt.Errorf("%s: %w", MaxSurgeAnnotation, fmt.Errorf("%w: got 0, allowed range is 1-1", ErrMaxSurgeOutOfRange)),
+		}),
+		Entry("with a non-integer maximum surge", maxSurgeTableInput{
+			replicas:      3,
+			annotations:   map[string]string{MaxSurgeAnnotation: "two"},
+			expectedError: fmt.Errorf("%s: %w", MaxSurgeAnnotation, fmt.Errorf("%w: %q", ErrInvalidInteger, "two")),
+		}),
+	)
+})
+
+var _ = Describe("Paused", func() {
+	type pausedTableInput struct {
+		annotations    map[string]string
+		expectedPaused bool
+		expectedError  error
+	}
+
+	DescribeTable("should parse whether the rollout is paused from the ControlPlaneMachineSet", func(in pausedTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+		cpms.Annotations = in.annotations
+
+		paused, err := Paused(cpms)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paused).To(Equal(in.expectedPaused))
+	},
+		Entry("with no annotation", pausedTableInput{
+			expectedPaused: false,
+		}),
+		Entry("when paused", pausedTableInput{
+			annotations:    map[string]string{PausedAnnotation: "true"},
+			expectedPaused: true,
+		}),
+		Entry("when not paused", pausedTableInput{
+			annotations:    map[string]string{PausedAnnotation: "false"},
+			expectedPaused: false,
+		}),
+		Entry("with an invalid value", pausedTableInput{
+			annotations:   map[string]string{PausedAnnotation: "yes please"},
+			expectedError: fmt.Errorf("%s: %w", PausedAnnotation, fmt.Errorf("%w: %q", ErrInvalidBoolean, "yes please")),
+		}),
+	)
+})
+
+var _ = Describe("Partition", func() {
+	type partitionTableInput struct {
+		annotations       map[string]string
+		expectedPartition int32
+		expectedSet       bool
+		expectedError     error
+	}
+
+	DescribeTable("should parse the partition from the ControlPlaneMachineSet", func(in partitionTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithReplicas(3).Build()
+		cpms.Annotations = in.annotations
+
+		partition, set, err := Partition(cpms)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(set).To(Equal(in.expectedSet))
+		Expect(partition).To(Equal(in.expectedPartition))
+	},
+		Entry("with no annotation", partitionTableInput{
+			expectedPartition: 0,
+			expectedSet:       false,
+		}),
+		Entry("with a partition of 0", partitionTableInput{
+			annotations:       map[string]string{PartitionAnnotation: "0"},
+			expectedPartition: 0,
+			expectedSet:       true,
+		}),
+		Entry("with a partition of 1", partitionTableInput{
+			annotations:       map[string]string{PartitionAnnotation: "1"},
+			expectedPartition: 1,
+			expectedSet:       true,
+		}),
+		Entry("with a partition matching the replicas", partitionTableInput{
+			annotations:       map[string]string{PartitionAnnotation: "3"},
+			expectedPartition: 3,
+			expectedSet:       true,
+		}),
+		Entry("with a partition greater than the replicas", partitionTableInput{
+			annotations:   map[string]string{PartitionAnnotation: "4"},
+			expectedError: fmt.Errorf("%s: %w", PartitionAnnotation, fmt.Errorf("%w: got 4, allowed range is 0-3", ErrPartitionOutOfRange)),
+		}),
+		Entry("with a negative partition", partitionTableInput{
+			annotations:   map[string]string{PartitionAnnotation: "-1"},
+			expectedError: fmt.Errorf("%s: %w", PartitionAnnotation, fmt.Errorf("%w: got -1, allowed range is 0-3", ErrPartitionOutOfRange)),
+		}),
+		Entry("with a non-integer partition", partitionTableInput{
+			annotations:   map[string]string{PartitionAnnotation: "first"},
+			expectedError: fmt.Errorf("%s: %w", PartitionAnnotation, fmt.Errorf("%w: %q", ErrInvalidInteger, "first")),
+		}),
+	)
+})
+
+var _ = Describe("ReplacementOrder", func() {
+	type replacementOrderTableInput struct {
+		annotations   map[string]string
+		expectedOrder ReplacementOrder
+		expectedError error
+	}
+
+	DescribeTable("should parse the replacement order from the ControlPlaneMachineSet", func(in replacementOrderTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithReplicas(3).Build()
+		cpms.Annotations = in.annotations
+
+		order, err := GetReplacementOrder(cpms)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(order).To(Equal(in.expectedOrder))
+	},
+		Entry("with no annotation", replacementOrderTableInput{
+			expectedOrder: ReplacementOrder{Policy: AscendingReplacementOrder},
+		}),
+		Entry("with the Ascending policy", replacementOrderTableInput{
+			annotations:   map[string]string{ReplacementOrderAnnotation: "Ascending"},
+			expectedOrder: ReplacementOrder{Policy: AscendingReplacementOrder},
+		}),
+		Entry("with the NonLeaderFirst policy", replacementOrderTableInput{
+			annotations:   map[string]string{ReplacementOrderAnnotation: "NonLeaderFirst"},
+			expectedOrder: ReplacementOrder{Policy: NonLeaderFirstReplacementOrder},
+		}),
+		Entry("with a list of indexes", replacementOrderTableInput{
+			annotations:   map[string]string{ReplacementOrderAnnotation: "2, 0,1"},
+			expectedOrder: ReplacementOrder{Policy: ExplicitReplacementOrder, Indexes: []int32{2, 0, 1}},
+		}),
+		Entry("with a partial list of indexes", replacementOrderTableInput{
+			annotations:   map[string]string{ReplacementOrderAnnotation: "1"},
+			expectedOrder: ReplacementOrder{Policy: ExplicitReplacementOrder, Indexes: []int32{1}},
+		}),
+		Entry("with the Explicit policy named directly", replacementOrderTableInput{
+			annotations:   map[string]string{ReplacementOrderAnnotation: "Explicit"},
+			expectedError: fmt.Errorf("%s: %w", ReplacementOrderAnnotation, fmt.Errorf("%w: %q", ErrInvalidReplacementOrder, "Explicit")),
+		}),
+		Entry("with an unknown policy", replacementOrderTableInput{
+			annotations:   map[string]string{ReplacementOrderAnnotation: "Random"},
+			expectedError: fmt.Errorf("%s: %w", ReplacementOrderAnnotation, fmt.Errorf("%w: %q", ErrInvalidReplacementOrder, "Random")),
+		}),
+		Entry("with an index out of range", replacementOrderTableInput{
+			annotations:   map[string]string{ReplacementOrderAnnotation: "2,3"},
+			expectedError: fmt.Errorf("%s: %w", ReplacementOrderAnnotation, fmt.Errorf("%w: got 3, allowed range is 0-2", ErrIndexOutOfRange)),
+		}),
+		Entry("with a repeated index", replacementOrderTableInput{
+			annotations:   map[string]string{ReplacementOrderAnnotation: "2,0,2"},
+			expectedError: fmt.Errorf("%s: %w", ReplacementOrderAnnotation, fmt.Errorf("%w: 2", ErrDuplicateIndex)),
+		}),
+	)
+})
+
+var _ = Describe("MaintenanceWindow", func() {
+	type maintenanceWindowTableInput struct {
+		annotations        map[string]string
+		expectedConfigured bool
+		expectedDuration   time.Duration
+		expectedError      error
+	}
+
+	DescribeTable("should parse the maintenance window from the ControlPlaneMachineSet", func(in maintenanceWindowTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithReplicas(3).Build()
+		cpms.Annotations = in.annotations
+
+		window, configured, err := MaintenanceWindow(cpms)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configured).To(Equal(in.expectedConfigured))
+		Expect(window.Duration).To(Equal(in.expectedDuration))
+	},
+		Entry("with no annotations", maintenanceWindowTableInput{
+			expectedConfigured: false,
+		}),
+		Entry("with a valid schedule and duration", maintenanceWindowTableInput{
+			annotations: map[string]string{
+				MaintenanceWindowScheduleAnnotation: "0 22 * * 1-5",
+				MaintenanceWindowDurationAnnotation: "4h",
+			},
+			expectedConfigured: true,
+			expectedDuration:   4 * time.Hour,
+		}),
+		Entry("with only a schedule", maintenanceWindowTableInput{
+			annotations: map[string]string{
+				MaintenanceWindowScheduleAnnotation: "0 22 * * 1-5",
+			},
+			expectedError: fmt.Errorf("%s: %w", MaintenanceWindowDurationAnnotation, ErrIncompleteMaintenanceWindow),
+		}),
+		Entry("with only a duration", maintenanceWindowTableInput{
+			annotations: map[string]string{
+				MaintenanceWindowDurationAnnotation: "4h",
+			},
+			expectedError: fmt.Errorf("%s: %w", MaintenanceWindowScheduleAnnotation, ErrIncompleteMaintenanceWindow),
+		}),
+		Entry("with an invalid schedule", maintenanceWindowTableInput{
+			annotations: map[string]string{
+				MaintenanceWindowScheduleAnnotation: "0 22 * *",
+				MaintenanceWindowDurationAnnotation: "4h",
+			},
+			expectedError: fmt.Errorf("%s: %w", MaintenanceWindowScheduleAnnotation,
+				fmt.Errorf("could not parse schedule: %w", fmt.Errorf("%w: expected 5 fields, got 4", maintenancewindow.ErrInvalidSchedule)),
+			),
+		}),
+		Entry("with an invalid duration", maintenanceWindowTableInput{
+			annotations: map[string]string{
+				MaintenanceWindowScheduleAnnotation: "0 22 * * 1-5",
+				MaintenanceWindowDurationAnnotation: "0s",
+			},
+			expectedError: fmt.Errorf("%s: %w", MaintenanceWindowDurationAnnotation,
+				fmt.Errorf("could not parse duration: %w", fmt.Errorf("%w: %q", maintenancewindow.ErrInvalidDuration, "0s")),
+			),
+		}),
+	)
+})
+
+var _ = Describe("FailureDomains", func() {
+	type failureDomainsTableInput struct {
+		failureDomains         machinev1.FailureDomains
+		annotations            map[string]string
+		expectedFailureDomains []string
+		expectedError          error
+	}
+
+	DescribeTable("should construct the failure domains of the ControlPlaneMachineSet", func(in failureDomainsTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithReplicas(3).Build()
+		cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains = in.failureDomains
+		cpms.Annotations = in.annotations
+
+		failureDomains, err := FailureDomains(cpms)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+
+		failureDomainStrings := []string{}
+		for _, fd := range failureDomains {
+			failureDomainStrings = append(failureDomainStrings, fd.String())
+		}
+
+		Expect(failureDomainStrings).To(Equal(in.expectedFailureDomains))
+	},
+		Entry("with GCP failure domains", failureDomainsTableInput{
+			failureDomains: machinev1resourcebuilder.GCPFailureDomains().BuildFailureDomains(),
+			expectedFailureDomains: []string{
+				"GCPFailureDomain{Zone:us-central1-a}",
+				"GCPFailureDomain{Zone:us-central1-b}",
+				"GCPFailureDomain{Zone:us-central1-c}",
+			},
+		}),
+		Entry("with OpenStack failure domains", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.OpenStackPlatformType},
+			annotations: map[string]string{
+				OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1"},{"availabilityZone":"az2","rootVolume":{"availabilityZone":"volume-az2"}}]`,
+			},
+			expectedFailureDomains: []string{
+				"OpenStackFailureDomain{AvailabilityZone:az1}",
+				"OpenStackFailureDomain{AvailabilityZone:az2, RootVolume:{AvailabilityZone:volume-az2}}",
+			},
+		}),
+		Entry("with the OpenStack platform and no annotation", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.OpenStackPlatformType},
+			expectedError:  fmt.Errorf("%s: %w", OpenStackFailureDomainsAnnotation, ErrMissingFailureDomains),
+		}),
+		Entry("with the OpenStack annotation and GCP failure domains", failureDomainsTableInput{
+			failureDomains: machinev1resourcebuilder.GCPFailureDomains().BuildFailureDomains(),
+			annotations: map[string]string{
+				OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1"}]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", OpenStackFailureDomainsAnnotation, ErrUnexpectedFailureDomains),
+		}),
+		Entry("with an empty list of OpenStack failure domains", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.OpenStackPlatformType},
+			annotations: map[string]string{
+				OpenStackFailureDomainsAnnotation: `[]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", OpenStackFailureDomainsAnnotation, ErrEmptyFailureDomains),
+		}),
+		Entry("with an unknown OpenStack failure domain field", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.OpenStackPlatformType},
+			annotations: map[string]string{
+				OpenStackFailureDomainsAnnotation: `[{"zone":"az1"}]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", OpenStackFailureDomainsAnnotation,
+				fmt.Errorf("%w: %s", ErrInvalidFailureDomains, `json: unknown field "zone"`),
+			),
+		}),
+		Entry("with VSphere failure domains", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.VSpherePlatformType},
+			annotations: map[string]string{
+				VSphereFailureDomainsAnnotation: `[{"name":"fd-1"},{"name":"fd-2"}]`,
+			},
+			expectedFailureDomains: []string{
+				"VSphereFailureDomain{Name:fd-1}",
+				"VSphereFailureDomain{Name:fd-2}",
+			},
+		}),
+		Entry("with the VSphere platform and no annotation", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.VSpherePlatformType},
+			expectedError:  fmt.Errorf("%s: %w", VSphereFailureDomainsAnnotation, ErrMissingFailureDomains),
+		}),
+		Entry("with the VSphere annotation and the OpenStack platform", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.OpenStackPlatformType},
+			annotations: map[string]string{
+				OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1"}]`,
+				VSphereFailureDomainsAnnotation:   `[{"name":"fd-1"}]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", VSphereFailureDomainsAnnotation, ErrUnexpectedFailureDomains),
+		}),
+		Entry("with a VSphere failure domain without a name", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.VSpherePlatformType},
+			annotations: map[string]string{
+				VSphereFailureDomainsAnnotation: `[{"name":"fd-1"},{"name":""}]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", VSphereFailureDomainsAnnotation, ErrMissingFailureDomainName),
+		}),
+		Entry("with Nutanix failure domains", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.NutanixPlatformType},
+			annotations: map[string]string{
+				NutanixFailureDomainsAnnotation: `[{"cluster":{"type":"name","name":"pe-1"},"subnets":[{"type":"name","name":"subnet-1"}]},` +
+					`{"cluster":{"type":"uuid","uuid":"pe-uuid-2"},"subnets":[{"type":"uuid","uuid":"subnet-uuid-2"}]}]`,
+			},
+			expectedFailureDomains: []string{
+				"NutanixFailureDomain{Cluster:{Type:name, Value:pe-1}, Subnets:[{Type:name, Value:subnet-1}]}",
+				"NutanixFailureDomain{Cluster:{Type:uuid, Value:pe-uuid-2}, Subnets:[{Type:uuid, Value:subnet-uuid-2}]}",
+			},
+		}),
+		Entry("with the Nutanix platform and no annotation", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.NutanixPlatformType},
+			expectedError:  fmt.Errorf("%s: %w", NutanixFailureDomainsAnnotation, ErrMissingFailureDomains),
+		}),
+		Entry("with a Nutanix failure domain with a mismatched cluster identifier", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.NutanixPlatformType},
+			annotations: map[string]string{
+				NutanixFailureDomainsAnnotation: `[{"cluster":{"type":"uuid","name":"pe-1"},"subnets":[{"type":"name","name":"subnet-1"}]}]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", NutanixFailureDomainsAnnotation, fmt.Errorf("cluster: %w", ErrInvalidResourceIdentifier)),
+		}),
+		Entry("with a Nutanix failure domain without subnets", failureDomainsTableInput{
+			failureDomains: machinev1.FailureDomains{Platform: configv1.NutanixPlatformType},
+			annotations: map[string]string{
+				NutanixFailureDomainsAnnotation: `[{"cluster":{"type":"name","name":"pe-1"}}]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", NutanixFailureDomainsAnnotation, ErrMissingFailureDomainSubnets),
+		}),
+	)
+})
+
+var _ = Describe("FailureDomainWeights", func() {
+	type failureDomainWeightsTableInput struct {
+		annotations     map[string]string
+		expectedWeights []FailureDomainWeight
+		expectedError   error
+	}
+
+	DescribeTable("should parse the failure domain weights from the ControlPlaneMachineSet", func(in failureDomainWeightsTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+		cpms.Annotations = in.annotations
+
+		weights, err := FailureDomainWeights(cpms)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(weights).To(Equal(in.expectedWeights))
+	},
+		Entry("with no annotation", failureDomainWeightsTableInput{
+			expectedWeights: nil,
+		}),
+		Entry("with weighted and avoided failure domains", failureDomainWeightsTableInput{
+			annotations: map[string]string{
+				FailureDomainWeightsAnnotation: `[{"failureDomain":"us-east-1a","weight":2},{"failureDomain":"us-east-1c","avoid":true}]`,
+			},
+			expectedWeights: []FailureDomainWeight{
+				{FailureDomain: "us-east-1a", Weight: 2},
+				{FailureDomain: "us-east-1c", Avoid: true},
+			},
+		}),
+		Entry("with invalid JSON", failureDomainWeightsTableInput{
+			annotations: map[string]string{
+				FailureDomainWeightsAnnotation: `{"failureDomain":"us-east-1a"}`,
+			},
+			expectedError: ErrInvalidFailureDomainWeights,
+		}),
+		Entry("with an unknown field", failureDomainWeightsTableInput{
+			annotations: map[string]string{
+				FailureDomainWeightsAnnotation: `[{"failureDomain":"us-east-1a","priority":2}]`,
+			},
+			expectedError: ErrInvalidFailureDomainWeights,
+		}),
+		Entry("with a missing failure domain", failureDomainWeightsTableInput{
+			annotations: map[string]string{
+				FailureDomainWeightsAnnotation: `[{"weight":2}]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", FailureDomainWeightsAnnotation, ErrMissingFailureDomain),
+		}),
+		Entry("with a negative weight", failureDomainWeightsTableInput{
+			annotations: map[string]string{
+				FailureDomainWeightsAnnotation: `[{"failureDomain":"us-east-1a","weight":-2}]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", FailureDomainWeightsAnnotation, fmt.Errorf("%w: got -2 for us-east-1a", ErrNegativeWeight)),
+		}),
+		Entry("with a repeated failure domain", failureDomainWeightsTableInput{
+			annotations: map[string]string{
+				FailureDomainWeightsAnnotation: `[{"failureDomain":"us-east-1a","weight":2},{"failureDomain":"us-east-1a","weight":3}]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", FailureDomainWeightsAnnotation, fmt.Errorf("%w: us-east-1a", ErrDuplicateFailureDomain)),
+		}),
+	)
+})
+
+var _ = Describe("UnavailableFailureDomains", func() {
+	type unavailableFailureDomainsTableInput struct {
+		annotations            map[string]string
+		expectedFailureDomains []string
+		expectedError          error
+	}
+
+	DescribeTable("should parse the unavailable failure domains from the ControlPlaneMachineSet", func(in unavailableFailureDomainsTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+		cpms.Annotations = in.annotations
+
+		failureDomains, err := UnavailableFailureDomains(cpms)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failureDomains).To(Equal(in.expectedFailureDomains))
+	},
+		Entry("with no annotation", unavailableFailureDomainsTableInput{
+			expectedFailureDomains: nil,
+		}),
+		Entry("with unavailable failure domains", unavailableFailureDomainsTableInput{
+			annotations: map[string]string{
+				UnavailableFailureDomainsAnnotation: `["us-east-1a","us-east-1c"]`,
+			},
+			expectedFailureDomains: []string{"us-east-1a", "us-east-1c"},
+		}),
+		Entry("with an empty list", unavailableFailureDomainsTableInput{
+			annotations: map[string]string{
+				UnavailableFailureDomainsAnnotation: `[]`,
+			},
+			expectedFailureDomains: []string{},
+		}),
+		Entry("with invalid JSON", unavailableFailureDomainsTableInput{
+			annotations: map[string]string{
+				UnavailableFailureDomainsAnnotation: `us-east-1a`,
+			},
+			expectedError: ErrInvalidUnavailableFailureDomains,
+		}),
+		Entry("with an empty failure domain", unavailableFailureDomainsTableInput{
+			annotations: map[string]string{
+				UnavailableFailureDomainsAnnotation: `["us-east-1a",""]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", UnavailableFailureDomainsAnnotation, ErrMissingFailureDomain),
+		}),
+		Entry("with a repeated failure domain", unavailableFailureDomainsTableInput{
+			annotations: map[string]string{
+				UnavailableFailureDomainsAnnotation: `["us-east-1a","us-east-1a"]`,
+			},
+			expectedError: fmt.Errorf("%s: %w", UnavailableFailureDomainsAnnotation, fmt.Errorf("%w: us-east-1a", ErrDuplicateFailureDomain)),
+		}),
+	)
+})
+
+var _ = Describe("IgnoredProviderSpecFields", func() {
+	type ignoredProviderSpecFieldsTableInput struct {
+		annotations    map[string]string
+		platform       configv1.PlatformType
+		expectedFields []string
+		expectedError  error
+	}
+
+	DescribeTable("should parse the ignored provider spec fields from the ControlPlaneMachineSet", func(in ignoredProviderSpecFieldsTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+		cpms.Annotations = in.annotations
+
+		fields, err := IgnoredProviderSpecFields(cpms, in.platform)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fields).To(Equal(in.expectedFields))
+	},
+		Entry("with no annotation", ignoredProviderSpecFieldsTableInput{
+			platform:       configv1.AWSPlatformType,
+			expectedFields: nil,
+		}),
+		Entry("with ignored fields for the platform", ignoredProviderSpecFieldsTableInput{
+			annotations: map[string]string{
+				IgnoredProviderSpecFieldsAnnotation: `{"AWS":["spec.providerSpec.value.tags","spec.providerSpec.value.metadataServiceOptions"],"Azure":["spec.providerSpec.value.tags"]}`,
+			},
+			platform:       configv1.AWSPlatformType,
+			expectedFields: []string{"spec.providerSpec.value.tags", "spec.providerSpec.value.metadataServiceOptions"},
+		}),
+		Entry("with ignored fields for another platform", ignoredProviderSpecFieldsTableInput{
+			annotations: map[string]string{
+				IgnoredProviderSpecFieldsAnnotation: `{"Azure":["spec.providerSpec.value.tags"]}`,
+			},
+			platform:       configv1.AWSPlatformType,
+			expectedFields: nil,
+		}),
+		Entry("with invalid JSON", ignoredProviderSpecFieldsTableInput{
+			annotations: map[string]string{
+				IgnoredProviderSpecFieldsAnnotation: `["spec.providerSpec.value.tags"]`,
+			},
+			platform:      configv1.AWSPlatformType,
+			expectedError: ErrInvalidIgnoredProviderSpecFields,
+		}),
+		Entry("with an empty platform type", ignoredProviderSpecFieldsTableInput{
+			annotations: map[string]string{
+				IgnoredProviderSpecFieldsAnnotation: `{"":["spec.providerSpec.value.tags"]}`,
+			},
+			platform:      configv1.AWSPlatformType,
+			expectedError: fmt.Errorf("%s: %w", IgnoredProviderSpecFieldsAnnotation, ErrMissingPlatformType),
+		}),
+		Entry("with a field outside of the provider spec", ignoredProviderSpecFieldsTableInput{
+			annotations: map[string]string{
+				IgnoredProviderSpecFieldsAnnotation: `{"AWS":["spec.metadata.labels"]}`,
+			},
+			platform:      configv1.AWSPlatformType,
+			expectedError: fmt.Errorf("%s: %w", IgnoredProviderSpecFieldsAnnotation, fmt.Errorf("%w: spec.metadata.labels", ErrInvalidProviderSpecFieldPath)),
+		}),
+		Entry("with the whole provider spec", ignoredProviderSpecFieldsTableInput{
+			annotations: map[string]string{
+				IgnoredProviderSpecFieldsAnnotation: `{"AWS":["spec.providerSpec.value."]}`,
+			},
+			platform:      configv1.AWSPlatformType,
+			expectedError: fmt.Errorf("%s: %w", IgnoredProviderSpecFieldsAnnotation, fmt.Errorf("%w: spec.providerSpec.value.", ErrInvalidProviderSpecFieldPath)),
+		}),
+		Entry("with a repeated field", ignoredProviderSpecFieldsTableInput{
+			annotations: map[string]string{
+				IgnoredProviderSpecFieldsAnnotation: `{"AWS":["spec.providerSpec.value.tags","spec.providerSpec.value.tags"]}`,
+			},
+			platform:      configv1.AWSPlatformType,
+			expectedError: fmt.Errorf("%s: %w", IgnoredProviderSpecFieldsAnnotation, fmt.Errorf("%w: spec.providerSpec.value.tags", ErrDuplicateProviderSpecFieldPath)),
+		}),
+	)
+})
+
+var _ = Describe("MachineManagedMetadata", func() {
+	type managedMetadataTableInput struct {
+		annotations      map[string]string
+		expectedMetadata ManagedMetadata
+		expectedError    error
+	}
+
+	DescribeTable("should parse the managed metadata from the Machine", func(in managedMetadataTableInput) {
+		machine := machinev1beta1resourcebuilder.Machine().Build()
+		machine.Annotations = in.annotations
+
+		managedMetadata, err := MachineManagedMetadata(machine)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(managedMetadata).To(Equal(in.expectedMetadata))
+	},
+		Entry("with no annotation", managedMetadataTableInput{
+			expectedMetadata: ManagedMetadata{},
+		}),
+		Entry("with managed labels and annotations", managedMetadataTableInput{
+			annotations: map[string]string{
+				MachineManagedMetadataAnnotation: `{"labels":["team"],"annotations":["example.com/owner"]}`,
+			},
+			expectedMetadata: ManagedMetadata{
+				Labels:      []string{"team"},
+				Annotations: []string{"example.com/owner"},
+			},
+		}),
+		Entry("with only managed labels", managedMetadataTableInput{
+			annotations: map[string]string{
+				MachineManagedMetadataAnnotation: `{"labels":["team","tier"]}`,
+			},
+			expectedMetadata: ManagedMetadata{
+				Labels: []string{"team", "tier"},
+			},
+		}),
+		Entry("with invalid JSON", managedMetadataTableInput{
+			annotations: map[string]string{
+				MachineManagedMetadataAnnotation: `["team"]`,
+			},
+			expectedError: ErrInvalidManagedMetadata,
+		}),
+	)
+})
+
+var _ = Describe("FailureDomainsAnnotationValues", func() {
+	type failureDomainsAnnotationValuesTableInput struct {
+		annotations    map[string]string
+		expectedValues map[string]string
+	}
+
+	DescribeTable("should return the failure domains annotations set on the ControlPlaneMachineSet", func(in failureDomainsAnnotationValuesTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+		cpms.Annotations = in.annotations
+
+		Expect(FailureDomainsAnnotationValues(cpms)).To(Equal(in.expectedValues))
+	},
+		Entry("with no annotations", failureDomainsAnnotationValuesTableInput{
+			expectedValues: nil,
+		}),
+		Entry("with only other annotations", failureDomainsAnnotationValuesTableInput{
+			annotations: map[string]string{
+				PausedAnnotation: "true",
+			},
+			expectedValues: nil,
+		}),
+		Entry("with a failure domains annotation", failureDomainsAnnotationValuesTableInput{
+			annotations: map[string]string{
+				PausedAnnotation:                  "true",
+				OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1"}]`,
+			},
+			expectedValues: map[string]string{
+				OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1"}]`,
+			},
+		}),
+	)
+})
+
+var _ = Describe("RolloutHistory", func() {
+	type rolloutHistoryTableInput struct {
+		annotations     map[string]string
+		expectedHistory []RolloutRecord
+		expectedError   error
+	}
+
+	// Times are decoded in the local time zone.
+	startTime := metav1.NewTime(time.Date(2023, time.June, 1, 10, 0, 0, 0, time.UTC).Local())
+	endTime := metav1.NewTime(time.Date(2023, time.June, 1, 11, 30, 0, 0, time.UTC).Local())
+
+	DescribeTable("should parse the rollout history from the ControlPlaneMachineSet", func(in rolloutHistoryTableInput) {
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+		cpms.Annotations = in.annotations
+
+		history, err := RolloutHistory(cpms)
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(history).To(Equal(in.expectedHistory))
+	},
+		Entry("with no annotation", rolloutHistoryTableInput{
+			expectedHistory: nil,
+		}),
+		Entry("with an empty history", rolloutHistoryTableInput{
+			annotations: map[string]string{
+				RolloutHistoryAnnotation: `[]`,
+			},
+			expectedHistory: []RolloutRecord{},
+		}),
+		Entry("with a completed and an in progress rollout", rolloutHistoryTableInput{
+			annotations: map[string]string{
+				RolloutHistoryAnnotation: `[` +
+					`{"templateHash":"abc","startTime":"2023-06-01T10:00:00Z","endTime":"2023-06-01T11:30:00Z","indexes":[0,1],` +
+					`"oldMachines":["machine-0","machine-1"],"newMachines":["machine-a","machine-b"],"outcome":"Completed"},` +
+					`{"templateHash":"def","startTime":"2023-06-01T11:30:00Z","indexes":[2],"oldMachines":["machine-2"],"outcome":"InProgress"}` +
+					`]`,
+			},
+			expectedHistory: []RolloutRecord{
+				{
+					TemplateHash: "abc",
+					StartTime:    startTime,
+					EndTime:      &endTime,
+					Indexes:      []int32{0, 1},
+					OldMachines:  []string{"machine-0", "machine-1"},
+					NewMachines:  []string{"machine-a", "machine-b"},
+					Outcome:      RolloutCompleted,
+				},
+				{
+					TemplateHash: "def",
+					StartTime:    endTime,
+					Indexes:      []int32{2},
+					OldMachines:  []string{"machine-2"},
+					Outcome:      RolloutInProgress,
+				},
+			},
+		}),
+		Entry("with invalid JSON", rolloutHistoryTableInput{
+			annotations: map[string]string{
+				RolloutHistoryAnnotation: `{"templateHash":"abc"}`,
+			},
+			expectedError: ErrInvalidRolloutHistory,
+		}),
+	)
+})
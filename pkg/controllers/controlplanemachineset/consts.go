@@ -55,6 +55,43 @@ const (
 	// This condition may be false with a reason, such as when an update is needed
 	// but the rollout strategy is configured to OnDelete.
 	conditionProgressing = "Progressing"
+
+	// conditionEtcdReadyForRemoval is used to denote whether etcd is ready for the
+	// removal of the Machines that have been replaced by the ControlPlaneMachineSet.
+	// This condition is only reported when an etcd health checker is configured.
+	// It should be false, with a reason and message, while the removal of any replaced
+	// Machine is waiting for the replacement etcd member to join the cluster, or for
+	// the etcd cluster to become healthy.
+	conditionEtcdReadyForRemoval = "EtcdReadyForRemoval"
+
+	// conditionFailureDomainMapping is used to report the failure domain that each
+	// Control Plane Machine index is mapped to, as determined by the failure domains
+	// and failure domain weights configured on the ControlPlaneMachineSet, and the
+	// reason for each assignment (ExistingMachine, BaseMapping or Rebalanced).
+	// This condition is only reported when failure domains are configured.
+	conditionFailureDomainMapping = "FailureDomainMapping"
+
+	// conditionFailureDomainEvacuation is used to report the progress of moving the
+	// Control Plane Machines out of the failure domains marked as unavailable.
+	// This condition should be true while any index has a Machine within an unavailable
+	// failure domain, and false once every index has been evacuated.
+	// This condition is only reported when failure domains are marked as unavailable.
+	conditionFailureDomainEvacuation = "FailureDomainEvacuation"
+
+	// conditionMachineMetadataDrift is used to report the Control Plane Machines with
+	// labels or annotations that differ from the template. While the ControlPlaneMachineSet
+	// is active, the drift is corrected as it is detected.
+	// This condition is only reported while the metadata of a Machine differs from the template.
+	conditionMachineMetadataDrift = "MachineMetadataDrift"
+
+	// conditionTemplateRevision is used to report the current revision of the template,
+	// and how many of the Control Plane Machines were created from it.
+	// This condition should be true when every Control Plane Machine was created from the
+	// current revision, and false when any Machine was created from a previous revision,
+	// or has no recorded revision.
+	// Machines created from the current revision that need an update are listed, as they no
+	// longer match the template despite being created from it.
+	conditionTemplateRevision = "TemplateRevision"
 )
 
 // Condition reasons for use in the ControlPlaneMachineSet status.
@@ -85,7 +122,8 @@ const (
 	reasonFailedReplacement = "FailedReplacement"
 
 	// reasonInvalidStrategy denotes that the ControlPlaneMachineSet has identified an
-	// invalid value for the spec.strategy.type field.
+	// invalid value for the spec.strategy.type field, or an invalid configuration
+	// of the update strategy within the ControlPlaneMachineSet annotations.
 	// This must be resolved by the user before operation of the ControlPlaneMachineSet
 	// can continue.
 	reasonInvalidStrategy = "InvalidStrategy"
@@ -150,5 +188,69 @@ const (
 	// replicas under its management that are currently in need of an update.
 	reasonNeedsUpdateReplicas = "NeedsUpdateReplicas"
 
+	// reasonPaused denotes that the ControlPlaneMachineSet has identified replicas under
+	// its management that are in need of an update, but that the rollout has been paused
+	// by the user. No new replacements will be started until the rollout is resumed.
+	reasonPaused = "Paused"
+
+	// reasonOutsideMaintenanceWindow denotes that the ControlPlaneMachineSet has identified replicas
+	// under its management that are in need of an update, but that the maintenance window is closed.
+	// No new replacements will be started until the maintenance window next opens.
+	reasonOutsideMaintenanceWindow = "OutsideMaintenanceWindow"
+
 	// END: Progressing reasons.
+
+	// BEGIN: EtcdReadyForRemoval reasons.
+
+	// reasonWaitingForEtcd denotes that the ControlPlaneMachineSet is waiting for etcd
+	// before removing a replaced Machine. Either the etcd member on the replacement Machine
+	// has not yet joined the etcd cluster, or the etcd cluster is not healthy enough
+	// to maintain quorum once the replaced Machine is removed.
+	reasonWaitingForEtcd = "WaitingForEtcd"
+
+	// END: EtcdReadyForRemoval reasons.
+
+	// BEGIN: FailureDomainMapping reasons.
+
+	// reasonFailureDomainsMapped denotes that the ControlPlaneMachineSet has mapped
+	// each Control Plane Machine index to a failure domain.
+	reasonFailureDomainsMapped = "FailureDomainsMapped"
+
+	// END: FailureDomainMapping reasons.
+
+	// BEGIN: FailureDomainEvacuation reasons.
+
+	// reasonEvacuatingFailureDomains denotes that the ControlPlaneMachineSet is moving
+	// Control Plane Machines out of the failure domains marked as unavailable.
+	reasonEvacuatingFailureDomains = "EvacuatingFailureDomains"
+
+	// reasonEvacuationComplete denotes that no Control Plane Machines remain within
+	// the failure domains marked as unavailable.
+	reasonEvacuationComplete = "EvacuationComplete"
+
+	// END: FailureDomainEvacuation reasons.
+
+	// BEGIN: MachineMetadataDrift reasons.
+
+	// reasonMetadataDriftDetected denotes that the metadata of a Control Plane Machine differs
+	// from the template, and that the ControlPlaneMachineSet is inactive, so will not correct it.
+	reasonMetadataDriftDetected = "MetadataDriftDetected"
+
+	// reasonMetadataDriftCorrected denotes that the metadata of a Control Plane Machine differed
+	// from the template, and that the ControlPlaneMachineSet is updating the Machine to match.
+	reasonMetadataDriftCorrected = "MetadataDriftCorrected"
+
+	// END: MachineMetadataDrift reasons.
+
+	// BEGIN: TemplateRevision reasons.
+
+	// reasonAllMachinesAtCurrentRevision denotes that every Control Plane Machine was
+	// created from the current revision of the template.
+	reasonAllMachinesAtCurrentRevision = "AllMachinesAtCurrentRevision"
+
+	// reasonMachinesAtPreviousRevisions denotes that some Control Plane Machines were
+	// created from a previous revision of the template, or have no recorded revision.
+	reasonMachinesAtPreviousRevisions = "MachinesAtPreviousRevisions"
+
+	// END: TemplateRevision reasons.
 )
@@ -18,14 +18,17 @@ package controlplanemachineset
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
@@ -37,12 +40,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -97,8 +103,49 @@ type ControlPlaneMachineSetReconciler struct {
 	// ReleaseVersion is the version of current cluster operator release.
 	ReleaseVersion string
 
+	// EtcdLeaderLocator is used to locate the etcd leader when the NonLeaderFirst replacement order is configured.
+	// When it is not set, the etcd leader is treated as unknown and indexes are replaced in ascending order.
+	EtcdLeaderLocator EtcdLeaderLocator
+
+	// EtcdHealthChecker is used to check that etcd is ready before a replaced Machine is removed.
+	// When it is not set, replaced Machines are removed as soon as their replacement is ready.
+	EtcdHealthChecker EtcdHealthChecker
+
+	// Recorder is used to record Events for the decisions taken about the Control Plane Machines, on both the
+	// ControlPlaneMachineSet and the affected Machine.
+	// When it is not set, no Events are recorded.
+	Recorder record.EventRecorder
+
+	// Clock is used to determine whether the maintenance window is open.
+	// When it is not set, the real clock is used.
+	Clock clock.PassiveClock
+
+	// NewMachineProvider constructs the machine provider for the control plane machine set.
+	// When it is not set, the machine provider is constructed based on the machine type of the template.
+	NewMachineProvider MachineProviderFactory
+
+	// metrics reports the state of the ControlPlaneMachineSet and the progress of its rollouts.
+	// It is set up with the manager. When it is not set, no metrics are reported.
+	metrics *controlPlaneMachineSetMetrics
+
 	// lastError allows us to track the last error that occurred during reconciliation.
 	lastError *lastErrorTracker
+
+	// stateEvents tracks the states, such as waiting for a Machine, for which an Event has been recorded,
+	// so that the Event is not recorded again on every reconcile.
+	stateEvents stateEventTracker
+}
+
+// MachineProviderFactory constructs a machine provider for a control plane machine set.
+type MachineProviderFactory func(context.Context, logr.Logger, client.Client, *machinev1.ControlPlaneMachineSet) (machineproviders.MachineProvider, error)
+
+// now returns the current time from the reconciler's clock.
+func (r *ControlPlaneMachineSetReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+
+	return r.Clock.Now()
 }
 
 // lastErrorTracker tracks the last error that occurred during reconciliation.
@@ -142,6 +189,17 @@ func (r *ControlPlaneMachineSetReconciler) SetupWithManager(mgr ctrl.Manager) er
 	r.Scheme = mgr.GetScheme()
 	r.RESTMapper = mgr.GetRESTMapper()
 
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor(eventSourceName)
+	}
+
+	cpmsMetrics, err := newControlPlaneMachineSetMetrics(ctrlmetrics.Registry)
+	if err != nil {
+		return fmt.Errorf("could not set up metrics for control plane machine set: %w", err)
+	}
+
+	r.metrics = cpmsMetrics
+
 	return nil
 }
 
@@ -159,6 +217,8 @@ func (r *ControlPlaneMachineSetReconciler) Reconcile(ctx context.Context, req ct
 	if err := r.Get(ctx, cpmsKey, cpms); apierrors.IsNotFound(err) {
 		logger.V(1).Info("No control plane machine set found, setting operator status available")
 
+		r.metrics.forget(req.Namespace, req.Name)
+
 		if err := r.setClusterOperatorAvailable(ctx, logger); err != nil {
 			return ctrl.Result{}, fmt.Errorf("unable to reconcile cluster operator status: %w", err)
 		}
@@ -188,6 +248,8 @@ func (r *ControlPlaneMachineSetReconciler) Reconcile(ctx context.Context, req ct
 		errs = append(errs, fmt.Errorf("error updating control plane machine set status: %w", err))
 	}
 
+	r.metrics.observeStatus(cpms)
+
 	if isActive(cpms) {
 		if err := r.updateClusterOperatorStatus(ctx, logger, cpms); err != nil {
 			// Don't return an error here so we can aggregate the errors with previous updates.
@@ -225,7 +287,20 @@ func (r *ControlPlaneMachineSetReconciler) reconcile(ctx context.Context, logger
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	machineProvider, err := providers.NewMachineProvider(ctx, logger, r.Client, cpms)
+	// Restore the requested template before taking any other action, so that the restored template is rolled out
+	// from the next reconcile.
+	if rolledBack, err := r.ensureRollback(ctx, logger, cpms); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error rolling back template: %w", err)
+	} else if rolledBack {
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	newMachineProvider := r.NewMachineProvider
+	if newMachineProvider == nil {
+		newMachineProvider = providers.NewMachineProvider
+	}
+
+	machineProvider, err := newMachineProvider(ctx, logger, r.Client, cpms)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("error constructing machine provider: %w", err)
 	}
@@ -235,11 +310,19 @@ func (r *ControlPlaneMachineSetReconciler) reconcile(ctx context.Context, logger
 		return ctrl.Result{}, fmt.Errorf("error fetching machine info: %w", err)
 	}
 
+	setFailureDomainMappingCondition(cpms, machineProvider.GetFailureDomainMapping())
+
 	indexedMachineInfos, err := machineInfosByIndex(cpms, machineInfos)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("could not sort machine info by index: %w", err)
 	}
 
+	setEvacuationCondition(cpms, indexedMachineInfos)
+
+	if err := setTemplateRevisionCondition(cpms, indexedMachineInfos); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error setting template revision condition: %w", err)
+	}
+
 	result, err := r.reconcileMachines(ctx, logger, cpms, machineProvider, indexedMachineInfos)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("error reconciling machines: %w", err)
@@ -252,33 +335,77 @@ func (r *ControlPlaneMachineSetReconciler) reconcile(ctx context.Context, logger
 // after validating that the cluster state is as expected, uses the machine provider to take appropriate actions
 // to perform any requied roll outs.
 func (r *ControlPlaneMachineSetReconciler) reconcileMachines(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, machineInfos map[int32][]machineproviders.MachineInfo) (ctrl.Result, error) {
-	if err := reconcileStatusWithMachineInfo(logger, cpms, machineInfos); err != nil {
+	// Keep the Degraded condition from the previous reconcile, so that an Event is only recorded when it changes.
+	previousDegraded := meta.FindStatusCondition(cpms.Status.Conditions, conditionDegraded).DeepCopy()
+
+	r.stateEvents.begin()
+
+	if err := reconcileStatusWithMachineInfo(logger, cpms, machineInfos, r.now()); err != nil {
 		return ctrl.Result{}, fmt.Errorf("error reconciling machine info with status: %w", err)
 	}
 
+	r.metrics.observeMachineInfos(cpms, machineInfos, r.now())
+
 	if err := r.validateClusterState(ctx, logger, cpms, machineInfos); err != nil {
 		return ctrl.Result{}, fmt.Errorf("error validating cluster state: %w", err)
 	}
 
+	if isActive(cpms) {
+		// Failed replacements are recorded in the rollout history, so update it before checking the degraded state.
+		if err := updateRolloutHistory(logger, cpms, machineInfos, r.now()); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error updating rollout history: %w", err)
+		}
+	}
+
 	if isControlPlaneMachineSetDegraded(cpms) {
 		logger.V(1).Info(degradedClusterState)
+		r.recordDegradedEvents(cpms, previousDegraded, machineInfos)
+		r.stateEvents.end()
+
 		return ctrl.Result{}, nil
 	}
 
+	metadataUpdates, err := getMachineMetadataUpdates(cpms, machineInfos)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("error getting machine metadata updates: %w", err)
+	}
+
+	setMachineMetadataDriftCondition(cpms, metadataUpdates)
+
 	if !isActive(cpms) {
 		// When inactive, we don't want to modify the machines at all so stop processing here.
+		r.stateEvents.end()
+
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.ensureTemplateRevision(ctx, logger, cpms, machineInfos); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error recording template revision: %w", err)
+	}
+
 	if err := r.ensureOwnerReferences(ctx, logger, cpms, machineInfos); err != nil {
 		return ctrl.Result{}, fmt.Errorf("error ensuring owner references: %w", err)
 	}
 
+	if err := r.ensureMachineDiffAnnotations(ctx, logger, machineInfos); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error ensuring machine diff annotations: %w", err)
+	}
+
+	if err := r.ensureMachineMetadata(ctx, logger, metadataUpdates); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error ensuring machine metadata: %w", err)
+	}
+
+	if err := ensureInPlaceUpdates(ctx, logger, machineProvider, machineInfos); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error updating machines in place: %w", err)
+	}
+
 	result, err := r.reconcileMachineUpdates(ctx, logger, cpms, machineProvider, machineInfos)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("error reconciling machine updates: %w", err)
 	}
 
+	r.stateEvents.end()
+
 	return result, nil
 }
 
@@ -495,6 +622,92 @@ func (r *ControlPlaneMachineSetReconciler) ensureOwnerReferences(ctx context.Con
 	return nil
 }
 
+// ensureMachineDiffAnnotations records, on each Machine within the machineInfos, the fields that differ from the
+// desired spec of the Machine, so that users can see why a Machine will be replaced before it is replaced.
+// The annotation is removed from Machines that no longer need an update.
+// It uses PartialObjectMetadata to patch only the Machines whose annotation is out of date.
+func (r *ControlPlaneMachineSetReconciler) ensureMachineDiffAnnotations(ctx context.Context, logger logr.Logger, machineInfos map[int32][]machineproviders.MachineInfo) error {
+	for _, machineInfo := range machineInfos {
+		for _, mInfo := range machineInfo {
+			if mInfo.MachineRef == nil {
+				continue
+			}
+
+			mObjectMeta := mInfo.MachineRef.ObjectMeta
+			mLogger := logger.WithValues("machineNamespace", mObjectMeta.GetNamespace(), "machineName", mObjectMeta.GetName())
+
+			desiredDiff, needsDiff, err := machineDiffAnnotationValue(mInfo)
+			if err != nil {
+				return fmt.Errorf("error constructing diff annotation for machine %s: %w", mObjectMeta.GetName(), err)
+			}
+
+			currentDiff, hasDiff := mObjectMeta.GetAnnotations()[annotations.MachineSpecDiffAnnotation]
+			if needsDiff == hasDiff && desiredDiff == currentDiff {
+				continue
+			}
+
+			machineGVK, err := r.RESTMapper.KindFor(mInfo.MachineRef.GroupVersionResource)
+			if err != nil {
+				return fmt.Errorf("error getting GVK for machine: %w", err)
+			}
+
+			machine := &metav1.PartialObjectMetadata{}
+			machine.SetGroupVersionKind(machineGVK)
+			machine.ObjectMeta = *mObjectMeta.DeepCopy()
+
+			patchBase := client.MergeFrom(machine.DeepCopy())
+
+			if needsDiff {
+				metav1.SetMetaDataAnnotation(&machine.ObjectMeta, annotations.MachineSpecDiffAnnotation, desiredDiff)
+			} else {
+				delete(machine.Annotations, annotations.MachineSpecDiffAnnotation)
+			}
+
+			if err := r.Client.Patch(ctx, machine, patchBase); err != nil {
+				return fmt.Errorf("error patching machine: %w", err)
+			}
+
+			mLogger.V(2).Info("Updated spec diff annotation on machine", "diff", desiredDiff)
+		}
+	}
+
+	return nil
+}
+
+// ensureInPlaceUpdates instructs the machine provider to update, in place, each Machine with fields that differ from
+// the template but that can be updated without replacing the Machine.
+// Machines that are being deleted are not updated.
+func ensureInPlaceUpdates(ctx context.Context, logger logr.Logger, machineProvider machineproviders.MachineProvider, machineInfos map[int32][]machineproviders.MachineInfo) error {
+	for _, machineInfo := range machineInfos {
+		for _, mInfo := range machineInfo {
+			if mInfo.MachineRef == nil || !mInfo.NeedsInPlaceUpdate || mInfo.MachineRef.ObjectMeta.DeletionTimestamp != nil {
+				continue
+			}
+
+			if err := machineProvider.UpdateMachineInPlace(ctx, logger, mInfo.MachineRef); err != nil {
+				return fmt.Errorf("error updating machine %s in place: %w", mInfo.MachineRef.ObjectMeta.GetName(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// machineDiffAnnotationValue returns the JSON encoded Diff of the MachineInfo, and whether the Machine should
+// have the diff annotation at all. Only Machines that need an update, with a known diff, are annotated.
+func machineDiffAnnotationValue(machineInfo machineproviders.MachineInfo) (string, bool, error) {
+	if !machineInfo.NeedsUpdate || len(machineInfo.Diff) == 0 {
+		return "", false, nil
+	}
+
+	value, err := json.Marshal(machineInfo.Diff)
+	if err != nil {
+		return "", false, fmt.Errorf("could not marshal diff: %w", err)
+	}
+
+	return string(value), true, nil
+}
+
 // validateClusterState uses the machineInfos to validate that:
 //   - All Nodes in the cluster claiming to be control plane nodes have a valid machine.
 //   - At least 1 of the control plane machines is in the ready state (if there are no ready Machines then the cluster
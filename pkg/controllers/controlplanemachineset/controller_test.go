@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -34,7 +35,9 @@ import (
 	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
 	machinev1beta1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
 	metav1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/meta/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/mock"
 	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/test/e2e/framework"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/test/e2e/helpers"
@@ -1835,6 +1838,185 @@ var _ = Describe("ensureOwnerRefrences", func() {
 	})
 })
 
+var _ = Describe("ensureMachineDiffAnnotations", func() {
+	var namespaceName string
+	var reconciler *ControlPlaneMachineSetReconciler
+	var logger testutils.TestLogger
+
+	var machine *machinev1beta1.Machine
+	var machineInfoBuilder machineprovidersresourcebuilder.MachineInfoBuilder
+	machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+
+	diff := []machineproviders.FieldDiff{
+		{Path: "spec.providerSpec.value.instanceType", Old: `"m6i.xlarge"`, New: `"m6i.2xlarge"`},
+	}
+	diffAnnotation := `[{"path":"spec.providerSpec.value.instanceType","old":"\"m6i.xlarge\"","new":"\"m6i.2xlarge\""}]`
+
+	ensureMachineDiffAnnotations := func(machineInfo machineproviders.MachineInfo) {
+		machineInfo.MachineRef.ObjectMeta = machine.ObjectMeta
+
+		Expect(reconciler.ensureMachineDiffAnnotations(ctx, logger.Logger(), map[int32][]machineproviders.MachineInfo{
+			0: {machineInfo},
+		})).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		By("Setting up a namespace for the test")
+		ns := corev1resourcebuilder.Namespace().WithGenerateName("control-plane-machine-set-ensure-machine-diff-annotations-").Build()
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		namespaceName = ns.GetName()
+
+		reconciler = &ControlPlaneMachineSetReconciler{
+			Client:         k8sClient,
+			UncachedClient: k8sClient,
+			Scheme:         testScheme,
+			RESTMapper:     testRESTMapper,
+			Namespace:      namespaceName,
+		}
+
+		logger = testutils.NewTestLogger()
+
+		By("Creating a machine to annotate")
+		machine = machinev1beta1resourcebuilder.Machine().WithNamespace(namespaceName).WithGenerateName("ensure-machine-diff-annotations-test-").Build()
+		Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+		machineInfoBuilder = machineprovidersresourcebuilder.MachineInfo().WithMachineGVR(machineGVR).WithMachineName(machine.GetName()).WithMachineNamespace(namespaceName)
+	})
+
+	AfterEach(func() {
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
+			&machinev1beta1.Machine{},
+		)
+	})
+
+	Context("when the machine needs an update", func() {
+		BeforeEach(func() {
+			ensureMachineDiffAnnotations(machineInfoBuilder.WithNeedsUpdate(true).WithDiff(diff...).Build())
+		})
+
+		It("should add the diff annotation", func() {
+			Eventually(komega.Object(machine)).Should(HaveField("ObjectMeta.Annotations", HaveKeyWithValue(annotations.MachineSpecDiffAnnotation, diffAnnotation)))
+		})
+
+		It("should log that it has updated the annotation", func() {
+			Expect(logger.Entries()).To(ConsistOf(testutils.LogEntry{
+				KeysAndValues: []interface{}{"machineNamespace", namespaceName, "machineName", machine.GetName(), "diff", diffAnnotation},
+				Level:         2,
+				Message:       "Updated spec diff annotation on machine",
+			}))
+		})
+	})
+
+	Context("when the machine needs an update without a known diff", func() {
+		BeforeEach(func() {
+			ensureMachineDiffAnnotations(machineInfoBuilder.WithNeedsUpdate(true).Build())
+		})
+
+		It("should not add the diff annotation", func() {
+			Consistently(komega.Object(machine)).ShouldNot(HaveField("ObjectMeta.Annotations", HaveKey(annotations.MachineSpecDiffAnnotation)))
+		})
+
+		It("should not log", func() {
+			Expect(logger.Entries()).To(BeEmpty())
+		})
+	})
+
+	Context("when the machine already has an up to date diff annotation", func() {
+		BeforeEach(func() {
+			patchBase := client.MergeFrom(machine.DeepCopy())
+			metav1.SetMetaDataAnnotation(&machine.ObjectMeta, annotations.MachineSpecDiffAnnotation, diffAnnotation)
+			Expect(k8sClient.Patch(ctx, machine, patchBase)).To(Succeed())
+
+			ensureMachineDiffAnnotations(machineInfoBuilder.WithNeedsUpdate(true).WithDiff(diff...).Build())
+		})
+
+		It("should keep the diff annotation", func() {
+			Consistently(komega.Object(machine)).Should(HaveField("ObjectMeta.Annotations", HaveKeyWithValue(annotations.MachineSpecDiffAnnotation, diffAnnotation)))
+		})
+
+		It("should not log", func() {
+			Expect(logger.Entries()).To(BeEmpty())
+		})
+	})
+
+	Context("when the machine no longer needs an update", func() {
+		BeforeEach(func() {
+			patchBase := client.MergeFrom(machine.DeepCopy())
+			metav1.SetMetaDataAnnotation(&machine.ObjectMeta, annotations.MachineSpecDiffAnnotation, diffAnnotation)
+			Expect(k8sClient.Patch(ctx, machine, patchBase)).To(Succeed())
+
+			ensureMachineDiffAnnotations(machineInfoBuilder.WithNeedsUpdate(false).Build())
+		})
+
+		It("should remove the diff annotation", func() {
+			Eventually(komega.Object(machine)).ShouldNot(HaveField("ObjectMeta.Annotations", HaveKey(annotations.MachineSpecDiffAnnotation)))
+		})
+
+		It("should log that it has updated the annotation", func() {
+			Expect(logger.Entries()).To(ConsistOf(testutils.LogEntry{
+				KeysAndValues: []interface{}{"machineNamespace", namespaceName, "machineName", machine.GetName(), "diff", ""},
+				Level:         2,
+				Message:       "Updated spec diff annotation on machine",
+			}))
+		})
+	})
+})
+
+var _ = Describe("ensureInPlaceUpdates", func() {
+	var logger testutils.TestLogger
+
+	var mockCtrl *gomock.Controller
+	var mockMachineProvider *mock.MockMachineProvider
+
+	machineInfoBuilder := machineprovidersresourcebuilder.MachineInfo().
+		WithMachineGVR(machinev1beta1.GroupVersion.WithResource("machines")).
+		WithMachineNamespace("openshift-machine-api")
+
+	inPlaceDiff := machineproviders.FieldDiff{Path: "spec.taints", New: `[{"key":"node-role.kubernetes.io/master","effect":"NoSchedule"}]`}
+
+	BeforeEach(func() {
+		logger = testutils.NewTestLogger()
+
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockMachineProvider = mock.NewMockMachineProvider(mockCtrl)
+	})
+
+	It("updates each machine that needs an in place update", func() {
+		needsInPlaceUpdate := machineInfoBuilder.WithIndex(0).WithMachineName("machine-0").WithNeedsInPlaceUpdate(true).WithInPlaceDiff(inPlaceDiff).Build()
+		needsBothUpdates := machineInfoBuilder.WithIndex(1).WithMachineName("machine-1").WithNeedsUpdate(true).WithNeedsInPlaceUpdate(true).WithInPlaceDiff(inPlaceDiff).Build()
+
+		mockMachineProvider.EXPECT().UpdateMachineInPlace(gomock.Any(), gomock.Any(), needsInPlaceUpdate.MachineRef).Return(nil).Times(1)
+		mockMachineProvider.EXPECT().UpdateMachineInPlace(gomock.Any(), gomock.Any(), needsBothUpdates.MachineRef).Return(nil).Times(1)
+
+		Expect(ensureInPlaceUpdates(ctx, logger.Logger(), mockMachineProvider, map[int32][]machineproviders.MachineInfo{
+			0: {needsInPlaceUpdate},
+			1: {needsBothUpdates},
+			2: {machineInfoBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+		})).To(Succeed())
+	})
+
+	It("does not update machines that are being deleted", func() {
+		deleting := machineInfoBuilder.WithIndex(0).WithMachineName("machine-0").WithNeedsInPlaceUpdate(true).WithInPlaceDiff(inPlaceDiff).
+			WithMachineDeletionTimestamp(metav1.Now()).Build()
+
+		mockMachineProvider.EXPECT().UpdateMachineInPlace(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		Expect(ensureInPlaceUpdates(ctx, logger.Logger(), mockMachineProvider, map[int32][]machineproviders.MachineInfo{
+			0: {deleting},
+		})).To(Succeed())
+	})
+
+	It("returns an error when the update fails", func() {
+		needsInPlaceUpdate := machineInfoBuilder.WithIndex(0).WithMachineName("machine-0").WithNeedsInPlaceUpdate(true).WithInPlaceDiff(inPlaceDiff).Build()
+
+		mockMachineProvider.EXPECT().UpdateMachineInPlace(gomock.Any(), gomock.Any(), needsInPlaceUpdate.MachineRef).Return(errors.New("fake error")).Times(1)
+
+		Expect(ensureInPlaceUpdates(ctx, logger.Logger(), mockMachineProvider, map[int32][]machineproviders.MachineInfo{
+			0: {needsInPlaceUpdate},
+		})).To(MatchError("error updating machine machine-0 in place: fake error"))
+	})
+})
+
 var _ = Describe("machineInfosByIndex", func() {
 	i0m0 := machineprovidersresourcebuilder.MachineInfo().WithIndex(0).WithMachineName("machine-0-0").Build()
 	i0m1 := machineprovidersresourcebuilder.MachineInfo().WithIndex(0).WithMachineName("machine-1-0").Build()
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// etcdNotReadyForRemoval is a log message used to inform the user that a replaced Machine is not being removed
+	// because etcd is not yet ready for its removal.
+	etcdNotReadyForRemoval = "Etcd is not ready for the removal of the replaced Machine. Waiting for etcd before removing the Machine."
+
+	// etcdReadyForRemoval is a log message used to inform the user that etcd is ready for the removal of a
+	// replaced Machine.
+	etcdReadyForRemoval = "Etcd is ready for the removal of the replaced Machine."
+
+	// etcdHealthRecheckInterval is the interval after which the etcd health is checked again when it blocked the
+	// removal of a replaced Machine. The etcd health is not otherwise watched, so this ensures the removal continues
+	// once etcd is ready.
+	etcdHealthRecheckInterval = 30 * time.Second
+)
+
+// EtcdHealthChecker checks whether etcd is ready for the removal of an outdated control plane Machine.
+type EtcdHealthChecker interface {
+	// CheckRemoval checks that the etcd member on the replacement Machine has joined the etcd cluster and is healthy,
+	// and that etcd quorum will be maintained when the member on the outdated Machine is removed.
+	// It returns an empty message when the outdated Machine may be removed, or otherwise, a message explaining why it
+	// must not yet be removed.
+	CheckRemoval(ctx context.Context, outdated, replacement machineproviders.MachineInfo) (string, error)
+
+	// CheckRemovalWithoutReplacement checks that etcd quorum will be maintained when the member on the outdated
+	// Machine is removed before any replacement for it has been created.
+	// It returns an empty message when the outdated Machine may be removed, or otherwise, a message explaining why it
+	// must not yet be removed.
+	CheckRemovalWithoutReplacement(ctx context.Context, outdated machineproviders.MachineInfo) (string, error)
+}
+
+// etcdHealthGate gates the removal of replaced Machines on the health of etcd, and records the outcome so that it
+// can be reported on the ControlPlaneMachineSet.
+type etcdHealthGate struct {
+	// checker is the EtcdHealthChecker used to check the health of etcd.
+	// When it is not set, the gate is disabled and all removals are allowed.
+	checker EtcdHealthChecker
+
+	// blocked holds the reasons for each removal that was blocked by the gate.
+	blocked []string
+}
+
+// newEtcdHealthGate creates a new etcdHealthGate using the given EtcdHealthChecker.
+func newEtcdHealthGate(checker EtcdHealthChecker) *etcdHealthGate {
+	return &etcdHealthGate{
+		checker: checker,
+	}
+}
+
+// allowRemoval checks whether the outdated Machine may be removed now that its replacement is ready.
+func (g *etcdHealthGate) allowRemoval(ctx context.Context, logger logr.Logger, outdated, replacement machineproviders.MachineInfo) (bool, error) {
+	if g.checker == nil {
+		return true, nil
+	}
+
+	message, err := g.checker.CheckRemoval(ctx, outdated, replacement)
+	if err != nil {
+		return false, fmt.Errorf("error checking etcd health: %w", err)
+	}
+
+	return g.observe(logger, outdated, message), nil
+}
+
+// allowRemovalWithoutReplacement checks whether the outdated Machine may be removed before its replacement has been
+// created.
+func (g *etcdHealthGate) allowRemovalWithoutReplacement(ctx context.Context, logger logr.Logger, outdated machineproviders.MachineInfo) (bool, error) {
+	if g.checker == nil {
+		return true, nil
+	}
+
+	message, err := g.checker.CheckRemovalWithoutReplacement(ctx, outdated)
+	if err != nil {
+		return false, fmt.Errorf("error checking etcd health: %w", err)
+	}
+
+	return g.observe(logger, outdated, message), nil
+}
+
+// observe records the outcome of a check on the removal of the outdated Machine, and returns whether the removal is
+// allowed.
+func (g *etcdHealthGate) observe(logger logr.Logger, outdated machineproviders.MachineInfo, message string) bool {
+	if message != "" {
+		logger.V(2).Info(etcdNotReadyForRemoval, "reason", message)
+		g.blocked = append(g.blocked, fmt.Sprintf("index %d: %s", outdated.Index, message))
+
+		return false
+	}
+
+	logger.V(2).Info(etcdReadyForRemoval)
+
+	return true
+}
+
+// result returns a result that requeues the ControlPlaneMachineSet to check the etcd health again when any removal
+// was blocked by the gate.
+func (g *etcdHealthGate) result() ctrl.Result {
+	if len(g.blocked) == 0 {
+		return ctrl.Result{}
+	}
+
+	return ctrl.Result{RequeueAfter: etcdHealthRecheckInterval}
+}
+
+// setCondition reports the outcome of the gate on the ControlPlaneMachineSet.
+// The condition is only reported when an EtcdHealthChecker is configured.
+func (g *etcdHealthGate) setCondition(cpms *machinev1.ControlPlaneMachineSet) {
+	if g.checker == nil {
+		return
+	}
+
+	if len(g.blocked) > 0 {
+		meta.SetStatusCondition(&cpms.Status.Conditions, metav1.Condition{
+			Type:               conditionEtcdReadyForRemoval,
+			Status:             metav1.ConditionFalse,
+			Reason:             reasonWaitingForEtcd,
+			Message:            fmt.Sprintf("Waiting for etcd before removing replaced Machine(s): %s", strings.Join(g.blocked, ", ")),
+			ObservedGeneration: cpms.Generation,
+		})
+
+		return
+	}
+
+	meta.SetStatusCondition(&cpms.Status.Conditions, metav1.Condition{
+		Type:               conditionEtcdReadyForRemoval,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonAsExpected,
+		ObservedGeneration: cpms.Generation,
+	})
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// staticEtcdHealthChecker is an EtcdHealthChecker that always returns the same result.
+type staticEtcdHealthChecker struct {
+	message string
+	err     error
+}
+
+// CheckRemoval returns the configured message and error.
+func (c staticEtcdHealthChecker) CheckRemoval(_ context.Context, _, _ machineproviders.MachineInfo) (string, error) {
+	return c.message, c.err
+}
+
+// CheckRemovalWithoutReplacement returns the configured message and error.
+func (c staticEtcdHealthChecker) CheckRemovalWithoutReplacement(_ context.Context, _ machineproviders.MachineInfo) (string, error) {
+	return c.message, c.err
+}
+
+// machineEtcdHealthChecker is an EtcdHealthChecker that returns the configured message for the outdated Machine.
+// Machines without a message may be removed.
+type machineEtcdHealthChecker map[string]string
+
+// CheckRemoval returns the message configured for the outdated Machine.
+func (c machineEtcdHealthChecker) CheckRemoval(_ context.Context, outdated, _ machineproviders.MachineInfo) (string, error) {
+	return c[outdated.MachineRef.ObjectMeta.Name], nil
+}
+
+// CheckRemovalWithoutReplacement returns the message configured for the outdated Machine.
+func (c machineEtcdHealthChecker) CheckRemovalWithoutReplacement(_ context.Context, outdated machineproviders.MachineInfo) (string, error) {
+	return c[outdated.MachineRef.ObjectMeta.Name], nil
+}
+
+var _ = Describe("Etcd health gate", func() {
+	machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+	nodeGVR := corev1.SchemeGroupVersion.WithResource("nodes")
+
+	machineBuilder := machineprovidersresourcebuilder.MachineInfo().
+		WithMachineGVR(machineGVR).
+		WithNodeGVR(nodeGVR).
+		WithReady(true)
+
+	outdated := machineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()
+	replacement := machineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithNodeName("node-replacement-1").Build()
+
+	checkerError := errors.New("checker error")
+
+	type etcdHealthGateTableInput struct {
+		checker            EtcdHealthChecker
+		withoutReplacement bool
+		expectedAllowed    bool
+		expectedError      error
+		expectedResult     ctrl.Result
+		expectedLogs       []testutils.LogEntry
+		expectedConditions []metav1.Condition
+	}
+
+	DescribeTable("should gate the removal of replaced Machines on the etcd health", func(in etcdHealthGateTableInput) {
+		logger := testutils.NewTestLogger()
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2).Build()
+
+		gate := newEtcdHealthGate(in.checker)
+
+		var allowed bool
+		var err error
+
+		if in.withoutReplacement {
+			allowed, err = gate.allowRemovalWithoutReplacement(ctx, logger.Logger(), outdated)
+		} else {
+			allowed, err = gate.allowRemoval(ctx, logger.Logger(), outdated, replacement)
+		}
+
+		if in.expectedError != nil {
+			Expect(err).To(MatchError(in.expectedError))
+		} else {
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(allowed).To(Equal(in.expectedAllowed))
+		Expect(gate.result()).To(Equal(in.expectedResult))
+		Expect(logger.Entries()).To(ConsistOf(in.expectedLogs))
+
+		gate.setCondition(cpms)
+		Expect(cpms.Status.Conditions).To(testutils.MatchConditions(in.expectedConditions))
+	},
+		Entry("with no checker", etcdHealthGateTableInput{
+			checker:         nil,
+			expectedAllowed: true,
+		}),
+		Entry("when etcd is ready for the removal", etcdHealthGateTableInput{
+			checker:         staticEtcdHealthChecker{},
+			expectedAllowed: true,
+			expectedLogs: []testutils.LogEntry{
+				{
+					Level:   2,
+					Message: etcdReadyForRemoval,
+				},
+			},
+			expectedConditions: []metav1.Condition{
+				{
+					Type:               conditionEtcdReadyForRemoval,
+					Status:             metav1.ConditionTrue,
+					Reason:             reasonAsExpected,
+					ObservedGeneration: 2,
+				},
+			},
+		}),
+		Entry("when etcd is not ready for the removal", etcdHealthGateTableInput{
+			checker:         staticEtcdHealthChecker{message: "etcd member on Node node-replacement-1 has not yet joined the cluster"},
+			expectedAllowed: false,
+			expectedResult:  ctrl.Result{RequeueAfter: 30 * time.Second},
+			expectedLogs: []testutils.LogEntry{
+				{
+					Level: 2,
+					KeysAndValues: []interface{}{
+						"reason", "etcd member on Node node-replacement-1 has not yet joined the cluster",
+					},
+					Message: etcdNotReadyForRemoval,
+				},
+			},
+			expectedConditions: []metav1.Condition{
+				{
+					Type:               conditionEtcdReadyForRemoval,
+					Status:             metav1.ConditionFalse,
+					Reason:             reasonWaitingForEtcd,
+					Message:            "Waiting for etcd before removing replaced Machine(s): index 1: etcd member on Node node-replacement-1 has not yet joined the cluster",
+					ObservedGeneration: 2,
+				},
+			},
+		}),
+		Entry("when etcd is ready for the removal without a replacement", etcdHealthGateTableInput{
+			checker:            staticEtcdHealthChecker{},
+			withoutReplacement: true,
+			expectedAllowed:    true,
+			expectedLogs: []testutils.LogEntry{
+				{
+					Level:   2,
+					Message: etcdReadyForRemoval,
+				},
+			},
+			expectedConditions: []metav1.Condition{
+				{
+					Type:               conditionEtcdReadyForRemoval,
+					Status:             metav1.ConditionTrue,
+					Reason:             reasonAsExpected,
+					ObservedGeneration: 2,
+				},
+			},
+		}),
+		Entry("when etcd is not ready for the removal without a replacement", etcdHealthGateTableInput{
+			checker:            staticEtcdHealthChecker{message: "removal would leave 1 of 3 etcd voting members healthy, below the quorum of 2"},
+			withoutReplacement: true,
+			expectedAllowed:    false,
+			expectedResult:     ctrl.Result{RequeueAfter: 30 * time.Second},
+			expectedLogs: []testutils.LogEntry{
+				{
+					Level: 2,
+					KeysAndValues: []interface{}{
+						"reason", "removal would leave 1 of 3 etcd voting members healthy, below the quorum of 2",
+					},
+					Message: etcdNotReadyForRemoval,
+				},
+			},
+			expectedConditions: []metav1.Condition{
+				{
+					Type:               conditionEtcdReadyForRemoval,
+					Status:             metav1.ConditionFalse,
+					Reason:             reasonWaitingForEtcd,
+					Message:            "Waiting for etcd before removing replaced Machine(s): index 1: removal would leave 1 of 3 etcd voting members healthy, below the quorum of 2",
+					ObservedGeneration: 2,
+				},
+			},
+		}),
+		Entry("when the checker returns an error", etcdHealthGateTableInput{
+			checker:         staticEtcdHealthChecker{err: checkerError},
+			expectedAllowed: false,
+			expectedError:   fmt.Errorf("error checking etcd health: %w", checkerError),
+			expectedConditions: []metav1.Condition{
+				{
+					Type:               conditionEtcdReadyForRemoval,
+					Status:             metav1.ConditionTrue,
+					Reason:             reasonAsExpected,
+					ObservedGeneration: 2,
+				},
+			},
+		}),
+	)
+
+	DescribeTable("should return the result that requeues soonest", func(results []ctrl.Result, expected ctrl.Result) {
+		Expect(lowestNonZeroResult(results...)).To(Equal(expected))
+	},
+		Entry("with no results", []ctrl.Result{}, ctrl.Result{}),
+		Entry("with no requeues", []ctrl.Result{{}, {}}, ctrl.Result{}),
+		Entry("with a single requeue", []ctrl.Result{{}, {RequeueAfter: time.Minute}}, ctrl.Result{RequeueAfter: time.Minute}),
+		Entry("with multiple requeues", []ctrl.Result{{RequeueAfter: time.Hour}, {RequeueAfter: time.Minute}}, ctrl.Result{RequeueAfter: time.Minute}),
+		Entry("with an immediate requeue", []ctrl.Result{{RequeueAfter: time.Minute}, {Requeue: true}}, ctrl.Result{Requeue: true}),
+	)
+})
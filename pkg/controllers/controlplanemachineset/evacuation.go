@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// maxConcurrentEvacuations is the maximum number of indexes that may be evacuated from unavailable failure
+	// domains at any one time.
+	maxConcurrentEvacuations = 1
+
+	// evacuationInProgress is a log message used to inform the user that a Machine within an unavailable failure
+	// domain is not being replaced because the evacuation of another index is already in progress.
+	evacuationInProgress = "Evacuation of another index is in progress. Cannot start the evacuation of this Machine at this time."
+)
+
+// evacuationFirst returns the indexes with Machines in unavailable failure domains, followed by the remaining
+// indexes. The relative order of the indexes within each group is preserved.
+func evacuationFirst(indexes []indexToMachineInfos) []indexToMachineInfos {
+	ordered := make([]indexToMachineInfos, len(indexes))
+	copy(ordered, indexes)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return isEvacuatingIndex(ordered[i]) && !isEvacuatingIndex(ordered[j])
+	})
+
+	return ordered
+}
+
+// isEvacuatingIndex checks whether any Machine in the index is within an unavailable failure domain.
+func isEvacuatingIndex(mi indexToMachineInfos) bool {
+	for _, machineInfo := range mi.machineInfos {
+		if machineInfo.Evacuating {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deviseExistingEvacuations computes the number of indexes that are part way through being evacuated.
+// An index is being evacuated when its Machine within an unavailable failure domain has either been deleted,
+// or a replacement for it has been created.
+func deviseExistingEvacuations(mis []indexToMachineInfos) int {
+	evacuations := 0
+
+	for _, mi := range mis {
+		if !isEvacuatingIndex(mi) {
+			continue
+		}
+
+		if len(mi.machineInfos) > 1 || len(deletingMachines(mi.machineInfos)) > 0 {
+			evacuations++
+		}
+	}
+
+	return evacuations
+}
+
+// setEvacuationCondition reports the progress of the evacuation of the unavailable failure domains on the
+// ControlPlaneMachineSet. The condition is only reported while failure domains are marked as unavailable.
+func setEvacuationCondition(cpms *machinev1.ControlPlaneMachineSet, machineInfosByIndex map[int32][]machineproviders.MachineInfo) {
+	unavailable, err := annotations.UnavailableFailureDomains(cpms)
+	if err != nil || len(unavailable) == 0 {
+		// An invalid annotation prevents the machine provider from being constructed, so is reported elsewhere.
+		meta.RemoveStatusCondition(&cpms.Status.Conditions, conditionFailureDomainEvacuation)
+
+		return
+	}
+
+	remaining := []int32{}
+
+	for idx, machineInfos := range machineInfosByIndex {
+		if isEvacuatingIndex(indexToMachineInfos{index: idx, machineInfos: machineInfos}) {
+			remaining = append(remaining, idx)
+		}
+	}
+
+	if len(remaining) == 0 {
+		meta.SetStatusCondition(&cpms.Status.Conditions, metav1.Condition{
+			Type:               conditionFailureDomainEvacuation,
+			Status:             metav1.ConditionFalse,
+			Reason:             reasonEvacuationComplete,
+			Message:            "No Machines remain within unavailable failure domains",
+			ObservedGeneration: cpms.Generation,
+		})
+
+		return
+	}
+
+	sort.Slice(remaining, func(i, j int) bool { return remaining[i] < remaining[j] })
+
+	indexes := []string{}
+	for _, idx := range remaining {
+		indexes = append(indexes, strconv.Itoa(int(idx)))
+	}
+
+	meta.SetStatusCondition(&cpms.Status.Conditions, metav1.Condition{
+		Type:               conditionFailureDomainEvacuation,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonEvacuatingFailureDomains,
+		Message:            fmt.Sprintf("Evacuating %d index(es) from unavailable failure domains: %s", len(remaining), strings.Join(indexes, ", ")),
+		ObservedGeneration: cpms.Generation,
+	})
+}
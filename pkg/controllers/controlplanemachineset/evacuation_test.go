@@ -0,0 +1,175 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Failure domain evacuation", func() {
+	machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+	nodeGVR := corev1.SchemeGroupVersion.WithResource("nodes")
+
+	updatedMachineBuilder := machineprovidersresourcebuilder.MachineInfo().
+		WithMachineGVR(machineGVR).
+		WithNodeGVR(nodeGVR).
+		WithReady(true).
+		WithNeedsUpdate(false)
+
+	evacuatingMachineBuilder := updatedMachineBuilder.WithNeedsUpdate(true).WithEvacuating(true)
+
+	pendingMachineBuilder := machineprovidersresourcebuilder.MachineInfo().
+		WithMachineGVR(machineGVR).
+		WithReady(false).
+		WithNeedsUpdate(false)
+
+	Context("evacuationFirst", func() {
+		It("should order the evacuating indexes first, preserving the order within each group", func() {
+			indexes := sortMachineInfosByIndex(map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {evacuatingMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+				3: {evacuatingMachineBuilder.WithIndex(3).WithMachineName("machine-3").Build()},
+			})
+
+			ordered := evacuationFirst(indexes)
+
+			orderedIndexes := []int32{}
+			for _, mi := range ordered {
+				orderedIndexes = append(orderedIndexes, mi.index)
+			}
+
+			Expect(orderedIndexes).To(Equal([]int32{1, 3, 0, 2}))
+		})
+	})
+
+	Context("deviseExistingEvacuations", func() {
+		type existingEvacuationsTableInput struct {
+			machineInfos        map[int32][]machineproviders.MachineInfo
+			expectedEvacuations int
+		}
+
+		DescribeTable("should count the indexes part way through an evacuation", func(in existingEvacuationsTableInput) {
+			Expect(deviseExistingEvacuations(sortMachineInfosByIndex(in.machineInfos))).To(Equal(in.expectedEvacuations))
+		},
+			Entry("with no evacuating Machines", existingEvacuationsTableInput{
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				},
+				expectedEvacuations: 0,
+			}),
+			Entry("with an evacuating Machine that has not been replaced", existingEvacuationsTableInput{
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {evacuatingMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				},
+				expectedEvacuations: 0,
+			}),
+			Entry("with an evacuating Machine that has a pending replacement", existingEvacuationsTableInput{
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						evacuatingMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build(),
+						pendingMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build(),
+					},
+					1: {evacuatingMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()},
+				},
+				expectedEvacuations: 1,
+			}),
+			Entry("with an evacuating Machine that has been deleted", existingEvacuationsTableInput{
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {evacuatingMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithMachineDeletionTimestamp(metav1.Now()).Build()},
+				},
+				expectedEvacuations: 1,
+			}),
+		)
+	})
+
+	Context("setEvacuationCondition", func() {
+		type evacuationConditionTableInput struct {
+			annotations       map[string]string
+			machineInfos      map[int32][]machineproviders.MachineInfo
+			expectedCondition *metav1.Condition
+		}
+
+		DescribeTable("should report the progress of the evacuation", func(in evacuationConditionTableInput) {
+			cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2).Build()
+			cpms.Annotations = in.annotations
+
+			setEvacuationCondition(cpms, in.machineInfos)
+
+			if in.expectedCondition == nil {
+				Expect(cpms.Status.Conditions).To(BeEmpty())
+				return
+			}
+
+			Expect(cpms.Status.Conditions).To(ConsistOf(testutils.MatchCondition(*in.expectedCondition)))
+		},
+			Entry("with no unavailable failure domains", evacuationConditionTableInput{
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				},
+			}),
+			Entry("with an invalid annotation", evacuationConditionTableInput{
+				annotations: map[string]string{annotations.UnavailableFailureDomainsAnnotation: "us-east-1a"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				},
+			}),
+			Entry("with indexes remaining to be evacuated", evacuationConditionTableInput{
+				annotations: map[string]string{annotations.UnavailableFailureDomainsAnnotation: `["us-east-1c"]`},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+					1: {
+						evacuatingMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build(),
+						pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+					},
+					2: {evacuatingMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+				},
+				expectedCondition: &metav1.Condition{
+					Type:               conditionFailureDomainEvacuation,
+					Status:             metav1.ConditionTrue,
+					Reason:             reasonEvacuatingFailureDomains,
+					Message:            "Evacuating 2 index(es) from unavailable failure domains: 1, 2",
+					ObservedGeneration: 2,
+				},
+			}),
+			Entry("with every index evacuated", evacuationConditionTableInput{
+				annotations: map[string]string{annotations.UnavailableFailureDomainsAnnotation: `["us-east-1c"]`},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()},
+				},
+				expectedCondition: &metav1.Condition{
+					Type:               conditionFailureDomainEvacuation,
+					Status:             metav1.ConditionFalse,
+					Reason:             reasonEvacuationComplete,
+					Message:            "No Machines remain within unavailable failure domains",
+					ObservedGeneration: 2,
+				},
+			}),
+		)
+	})
+})
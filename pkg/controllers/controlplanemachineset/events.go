@@ -0,0 +1,198 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"fmt"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// eventSourceName is the name of the component reported as the source of the Events recorded by the
+	// ControlPlaneMachineSet controller.
+	eventSourceName = "control-plane-machine-set-controller"
+
+	// eventReasonCreatedMachine is used when a Machine has been created, either to replace an outdated Machine,
+	// or to fill a missing index.
+	eventReasonCreatedMachine = "CreatedMachine"
+
+	// eventReasonFailedCreate is used when an error occurred while attempting to create a Machine.
+	eventReasonFailedCreate = "FailedCreate"
+
+	// eventReasonDeletedMachine is used when a Machine has been deleted as a part of the rollout.
+	eventReasonDeletedMachine = "DeletedMachine"
+
+	// eventReasonFailedDelete is used when an error occurred while attempting to delete a Machine.
+	eventReasonFailedDelete = "FailedDelete"
+
+	// eventReasonWaitingForReady is used when the rollout is waiting for a new Machine to become ready.
+	eventReasonWaitingForReady = "WaitingForReady"
+
+	// eventReasonWaitingForReplacement is used when the rollout is waiting for a replacement Machine to become ready.
+	eventReasonWaitingForReplacement = "WaitingForReplacement"
+
+	// eventReasonWaitingForRemoval is used when the rollout is waiting for a deleted Machine to be removed.
+	eventReasonWaitingForRemoval = "WaitingForRemoval"
+
+	// eventReasonMaxSurgeReached is used when a replacement Machine cannot be created as the maximum surge
+	// has been reached.
+	eventReasonMaxSurgeReached = "MaxSurgeReached"
+
+	// eventReasonMaxUnavailableReached is used when an outdated Machine cannot be removed as the maximum
+	// unavailable has been reached.
+	eventReasonMaxUnavailableReached = "MaxUnavailableReached"
+
+	// eventReasonRolledBack is used when a previously applied template has been restored into the spec of
+	// the ControlPlaneMachineSet.
+	eventReasonRolledBack = "RolledBack"
+
+	// eventReasonFailedRollback is used when the template revision requested for a rollback could not be restored.
+	eventReasonFailedRollback = "FailedRollback"
+
+	// eventReasonDegraded is used when the ControlPlaneMachineSet has detected a degraded cluster and
+	// will not take any action until the issues have been resolved.
+	eventReasonDegraded = "Degraded"
+)
+
+// recordEventf records an Event on the ControlPlaneMachineSet and, when the Machine is known, on the affected Machine.
+// When the reconciler has no event recorder, no Events are recorded.
+func (r *ControlPlaneMachineSetReconciler) recordEventf(cpms *machinev1.ControlPlaneMachineSet, machineRef *machineproviders.ObjectRef, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+
+	message := fmt.Sprintf(messageFmt, args...)
+
+	r.Recorder.Event(cpms, eventType, reason, message)
+
+	if machineRef == nil || r.RESTMapper == nil {
+		return
+	}
+
+	machineGVK, err := r.RESTMapper.KindFor(machineRef.GroupVersionResource)
+	if err != nil {
+		// Without the kind, the Event cannot reference the Machine.
+		// The Event has already been recorded on the ControlPlaneMachineSet, so skip the Machine.
+		return
+	}
+
+	machine := &metav1.PartialObjectMetadata{}
+	machine.SetGroupVersionKind(machineGVK)
+	machine.ObjectMeta = machineRef.ObjectMeta
+
+	r.Recorder.Event(machine, eventType, reason, message)
+}
+
+// recordStateEventf records an Event describing a state the rollout is in, such as waiting for a Machine, in the same
+// way as recordEventf. The Event is only recorded when the state is entered, and not on every reconcile while the
+// rollout remains in that state.
+func (r *ControlPlaneMachineSetReconciler) recordStateEventf(cpms *machinev1.ControlPlaneMachineSet, machineRef *machineproviders.ObjectRef, eventType, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+
+	if !r.stateEvents.enter(fmt.Sprintf("%s/%s: %s", eventType, reason, message)) {
+		return
+	}
+
+	r.recordEventf(cpms, machineRef, eventType, reason, "%s", message)
+}
+
+// recordDegradedEvents records a Warning Event on the ControlPlaneMachineSet with the reason the cluster state is
+// degraded, and on each Machine reporting an error.
+// The Event on the ControlPlaneMachineSet is only recorded when it becomes degraded, or the reason it is degraded
+// changes, compared to the previous Degraded condition. The Event on each Machine is only recorded when the Machine
+// starts reporting the error.
+func (r *ControlPlaneMachineSetReconciler) recordDegradedEvents(cpms *machinev1.ControlPlaneMachineSet, previousDegraded *metav1.Condition, machineInfos map[int32][]machineproviders.MachineInfo) {
+	degraded := meta.FindStatusCondition(cpms.Status.Conditions, conditionDegraded)
+
+	if previousDegraded == nil || previousDegraded.Status != metav1.ConditionTrue || degraded == nil || previousDegraded.Message != degraded.Message {
+		message := degradedClusterState
+		if degraded != nil && degraded.Message != "" {
+			message = fmt.Sprintf("%s: %s", degraded.Message, degradedClusterState)
+		}
+
+		r.recordEventf(cpms, nil, corev1.EventTypeWarning, eventReasonDegraded, "%s", message)
+	}
+
+	for _, indexToMachines := range sortMachineInfosByIndex(machineInfos) {
+		for _, machineInfo := range indexToMachines.machineInfos {
+			if machineInfo.MachineRef == nil || machineInfo.ErrorMessage == "" {
+				continue
+			}
+
+			r.recordStateEventf(cpms, machineInfo.MachineRef, corev1.EventTypeWarning, eventReasonDegraded,
+				"Machine %s in index %d is reporting an error: %s", machineInfo.MachineRef.ObjectMeta.Name, machineInfo.Index, machineInfo.ErrorMessage)
+		}
+	}
+}
+
+// stateEventTracker tracks the states for which an Event has been recorded, so that an Event is only recorded when a
+// state is entered. The states observed during a reconcile replace those of the previous reconcile once it completes,
+// so that an Event is recorded again when a state is left and later entered again.
+type stateEventTracker struct {
+	// previous holds the states observed during the last completed reconcile, and those entered since.
+	previous map[string]struct{}
+
+	// current holds the states observed during the reconcile in progress.
+	current map[string]struct{}
+}
+
+// begin starts tracking the states observed during a reconcile.
+func (t *stateEventTracker) begin() {
+	t.current = map[string]struct{}{}
+}
+
+// end completes the reconcile. States that were not observed during the reconcile have been left.
+func (t *stateEventTracker) end() {
+	t.previous = t.current
+	t.current = nil
+}
+
+// enter observes the state, and returns whether the state has been entered since the previous reconcile.
+func (t *stateEventTracker) enter(state string) bool {
+	if t.current == nil {
+		t.current = map[string]struct{}{}
+	}
+
+	t.current[state] = struct{}{}
+
+	if _, ok := t.previous[state]; ok {
+		return false
+	}
+
+	// Remember the state straight away, so that it is not recorded again should the reconcile not complete.
+	if t.previous == nil {
+		t.previous = map[string]struct{}{}
+	}
+
+	t.previous[state] = struct{}{}
+
+	return true
+}
+
+// describeNewMachine describes the Machine about to be created for the index, for use within Event messages.
+func describeNewMachine(idx int32, replacedMachineRef *machineproviders.ObjectRef) string {
+	if replacedMachineRef == nil {
+		return fmt.Sprintf("machine for index %d", idx)
+	}
+
+	return fmt.Sprintf("replacement for machine %s in index %d", replacedMachineRef.ObjectMeta.Name, idx)
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/mock"
+	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("Events", func() {
+	const (
+		cpmsObject    = " involvedObject{kind=ControlPlaneMachineSet,apiVersion=machine.openshift.io/v1}"
+		machineObject = " involvedObject{kind=Machine,apiVersion=machine.openshift.io/v1beta1}"
+	)
+
+	var logger testutils.TestLogger
+	var recorder *record.FakeRecorder
+	var reconciler *ControlPlaneMachineSetReconciler
+	var cpms *machinev1.ControlPlaneMachineSet
+
+	var mockCtrl *gomock.Controller
+	var mockMachineProvider *mock.MockMachineProvider
+
+	machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+
+	updatedMachineBuilder := machineprovidersresourcebuilder.MachineInfo().
+		WithMachineGVR(machineGVR).
+		WithReady(true).
+		WithNeedsUpdate(false)
+
+	pendingMachineBuilder := updatedMachineBuilder.WithReady(false)
+
+	outdatedMachineBuilder := updatedMachineBuilder.WithNeedsUpdate(true)
+
+	// recordedEvents drains the Events recorded so far.
+	recordedEvents := func() []string {
+		events := []string{}
+
+		for {
+			select {
+			case event := <-recorder.Events:
+				events = append(events, event)
+			default:
+				return events
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		logger = testutils.NewTestLogger()
+		recorder = record.NewFakeRecorder(10)
+		recorder.IncludeObject = true
+
+		reconciler = &ControlPlaneMachineSetReconciler{
+			Namespace:  "openshift-machine-api",
+			Scheme:     testScheme,
+			RESTMapper: testRESTMapper,
+			Recorder:   recorder,
+		}
+
+		cpms = machinev1resourcebuilder.ControlPlaneMachineSet().WithReplicas(3).WithStrategyType(machinev1.RollingUpdate).Build()
+		cpms.SetGroupVersionKind(machinev1.GroupVersion.WithKind("ControlPlaneMachineSet"))
+
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockMachineProvider = mock.NewMockMachineProvider(mockCtrl)
+	})
+
+	type eventsTableInput struct {
+		machineInfos   map[int32][]machineproviders.MachineInfo
+		createError    error
+		expectedEvents []string
+	}
+
+	DescribeTable("should record an Event on the ControlPlaneMachineSet and the affected Machine", func(in eventsTableInput) {
+		mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+		mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(in.machineInfos), nil).AnyTimes()
+		mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Return(in.createError).AnyTimes()
+		mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		_, err := reconciler.reconcileMachineUpdates(ctx, logger.Logger(), cpms, mockMachineProvider, in.machineInfos)
+		if in.createError != nil {
+			Expect(err).To(MatchError(in.createError))
+		} else {
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(recordedEvents()).To(Equal(in.expectedEvents))
+	},
+		Entry("when no updates are required", eventsTableInput{
+			machineInfos: map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			},
+			expectedEvents: []string{},
+		}),
+		Entry("when creating a replacement machine", eventsTableInput{
+			machineInfos: map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			},
+			expectedEvents: []string{
+				"Normal CreatedMachine Created replacement for machine machine-1 in index 1" + cpmsObject,
+				"Normal CreatedMachine Created replacement for machine machine-1 in index 1" + machineObject,
+			},
+		}),
+		Entry("when creating a machine for an empty index", eventsTableInput{
+			machineInfos: map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			},
+			expectedEvents: []string{
+				"Normal CreatedMachine Created machine for index 1" + cpmsObject,
+			},
+		}),
+		Entry("when creating a replacement machine fails", eventsTableInput{
+			machineInfos: map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			},
+			createError: errors.New("transient error"),
+			expectedEvents: []string{
+				"Warning FailedCreate Error creating replacement for machine machine-1 in index 1: transient error" + cpmsObject,
+				"Warning FailedCreate Error creating replacement for machine machine-1 in index 1: transient error" + machineObject,
+			},
+		}),
+		Entry("when the maximum surge has been reached", eventsTableInput{
+			machineInfos: map[int32][]machineproviders.MachineInfo{
+				0: {outdatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			},
+			expectedEvents: []string{
+				"Normal CreatedMachine Created replacement for machine machine-0 in index 0" + cpmsObject,
+				"Normal CreatedMachine Created replacement for machine machine-0 in index 0" + machineObject,
+				"Normal MaxSurgeReached Cannot create replacement for machine machine-1 in index 1, the maximum surge of 1 has been reached" + cpmsObject,
+				"Normal MaxSurgeReached Cannot create replacement for machine machine-1 in index 1, the maximum surge of 1 has been reached" + machineObject,
+			},
+		}),
+		Entry("when waiting for a replacement machine", eventsTableInput{
+			machineInfos: map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {
+					outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build(),
+					pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+				},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			},
+			expectedEvents: []string{
+				"Normal WaitingForReplacement Waiting for replacement machine machine-replacement-1 to become ready before removing machine machine-1 from index 1" + cpmsObject,
+				"Normal WaitingForReplacement Waiting for replacement machine machine-replacement-1 to become ready before removing machine machine-1 from index 1" + machineObject,
+			},
+		}),
+		Entry("when deleting a replaced machine", eventsTableInput{
+			machineInfos: map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {
+					outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build(),
+					updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+				},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			},
+			expectedEvents: []string{
+				"Normal DeletedMachine Removing machine machine-1 from index 1" + cpmsObject,
+				"Normal DeletedMachine Removing machine machine-1 from index 1" + machineObject,
+			},
+		}),
+		Entry("when waiting for a deleted machine to be removed", eventsTableInput{
+			machineInfos: map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {
+					outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithMachineDeletionTimestamp(metav1.Now()).Build(),
+					updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+				},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			},
+			expectedEvents: []string{
+				"Normal WaitingForRemoval Waiting for machine machine-1 to be removed from index 1" + cpmsObject,
+				"Normal WaitingForRemoval Waiting for machine machine-1 to be removed from index 1" + machineObject,
+			},
+		}),
+	)
+
+	Context("when the same state is observed on consecutive reconciles", func() {
+		waitingMachineInfos := map[int32][]machineproviders.MachineInfo{
+			0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+			1: {
+				outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build(),
+				pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+			},
+			2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+		}
+
+		updatedMachineInfos := map[int32][]machineproviders.MachineInfo{
+			0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+			1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build()},
+			2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+		}
+
+		waitingEvents := []string{
+			"Normal WaitingForReplacement Waiting for replacement machine machine-replacement-1 to become ready before removing machine machine-1 from index 1" + cpmsObject,
+			"Normal WaitingForReplacement Waiting for replacement machine machine-replacement-1 to become ready before removing machine machine-1 from index 1" + machineObject,
+		}
+
+		reconcile := func(machineInfos map[int32][]machineproviders.MachineInfo) {
+			reconciler.stateEvents.begin()
+
+			_, err := reconciler.reconcileMachineUpdates(ctx, logger.Logger(), cpms, mockMachineProvider, machineInfos)
+			Expect(err).ToNot(HaveOccurred())
+
+			reconciler.stateEvents.end()
+		}
+
+		BeforeEach(func() {
+			reconcile(waitingMachineInfos)
+
+			Expect(recordedEvents()).To(Equal(waitingEvents))
+		})
+
+		It("should not record the Event again while the state persists", func() {
+			reconcile(waitingMachineInfos)
+			reconcile(waitingMachineInfos)
+
+			Expect(recordedEvents()).To(BeEmpty())
+		})
+
+		It("should record the Event again when the state is entered again", func() {
+			reconcile(updatedMachineInfos)
+			Expect(recordedEvents()).To(BeEmpty())
+
+			reconcile(waitingMachineInfos)
+			Expect(recordedEvents()).To(Equal(waitingEvents))
+		})
+	})
+
+	Context("when the cluster state is degraded", func() {
+		degradedMachineInfos := map[int32][]machineproviders.MachineInfo{
+			0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+			1: {
+				outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build(),
+				pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithErrorMessage("InsufficientCapacity").Build(),
+			},
+		}
+
+		degradedCondition := metav1.Condition{
+			Type:    conditionDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonFailedReplacement,
+			Message: "Observed 1 replacement machine(s) in error state",
+		}
+
+		BeforeEach(func() {
+			cpms.Status.Conditions = []metav1.Condition{degradedCondition}
+
+			reconciler.recordDegradedEvents(cpms, &metav1.Condition{
+				Type:   conditionDegraded,
+				Status: metav1.ConditionFalse,
+				Reason: reasonAsExpected,
+			}, degradedMachineInfos)
+		})
+
+		It("should record a Warning Event on the ControlPlaneMachineSet and the failed Machine", func() {
+			Expect(recordedEvents()).To(Equal([]string{
+				"Warning Degraded Observed 1 replacement machine(s) in error state: " + degradedClusterState + cpmsObject,
+				"Warning Degraded Machine machine-replacement-1 in index 1 is reporting an error: InsufficientCapacity" + cpmsObject,
+				"Warning Degraded Machine machine-replacement-1 in index 1 is reporting an error: InsufficientCapacity" + machineObject,
+			}))
+		})
+
+		Context("and was already degraded for the same reason", func() {
+			BeforeEach(func() {
+				recordedEvents()
+
+				reconciler.recordDegradedEvents(cpms, degradedCondition.DeepCopy(), degradedMachineInfos)
+			})
+
+			It("should not record any Events", func() {
+				Expect(recordedEvents()).To(BeEmpty())
+			})
+		})
+
+		Context("and was already degraded for a different reason", func() {
+			BeforeEach(func() {
+				recordedEvents()
+
+				previousDegraded := degradedCondition.DeepCopy()
+				previousDegraded.Message = "Observed 2 replacement machine(s) in error state"
+
+				reconciler.recordDegradedEvents(cpms, previousDegraded, degradedMachineInfos)
+			})
+
+			It("should only record the Warning Event on the ControlPlaneMachineSet", func() {
+				Expect(recordedEvents()).To(Equal([]string{
+					"Warning Degraded Observed 1 replacement machine(s) in error state: " + degradedClusterState + cpmsObject,
+				}))
+			})
+		})
+	})
+
+	Context("without an event recorder", func() {
+		BeforeEach(func() {
+			reconciler.Recorder = nil
+		})
+
+		It("should not record any Events", func() {
+			reconciler.recordEventf(cpms, nil, corev1.EventTypeNormal, eventReasonCreatedMachine, "Created machine for index %d", 0)
+
+			Expect(recordedEvents()).To(BeEmpty())
+		})
+	})
+})
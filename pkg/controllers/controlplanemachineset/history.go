@@ -0,0 +1,219 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// maxRolloutHistory is the maximum number of rollouts recorded on the ControlPlaneMachineSet.
+	// Once the limit is reached, the oldest rollouts are dropped from the history.
+	maxRolloutHistory = 5
+
+	// rolloutAbortedMessage is the message recorded when the template changes before a rollout completes.
+	rolloutAbortedMessage = "The template changed before the rollout completed"
+)
+
+// updateRolloutHistory records the progress of the current rollout within the rollout history of the
+// ControlPlaneMachineSet. The history is set on the ControlPlaneMachineSet annotations, and is persisted
+// by updateControlPlaneMachineSetStatus at the end of the reconcile.
+func updateRolloutHistory(logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineInfos map[int32][]machineproviders.MachineInfo, now time.Time) error {
+	templateHash, err := util.TemplateHash(cpms.Spec.Template, annotations.FailureDomainsAnnotationValues(cpms))
+	if err != nil {
+		return fmt.Errorf("error calculating template hash: %w", err)
+	}
+
+	history, err := annotations.RolloutHistory(cpms)
+	if err != nil {
+		// The history is informational only, so start over rather than blocking the reconcile.
+		logger.Error(err, "Ignoring invalid rollout history")
+
+		history = nil
+	}
+
+	history = observeRollout(history, cpms, machineInfos, templateHash, metav1.NewTime(now))
+	if len(history) == 0 {
+		return nil
+	}
+
+	if len(history) > maxRolloutHistory {
+		history = history[len(history)-maxRolloutHistory:]
+	}
+
+	value, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("could not marshal rollout history: %w", err)
+	}
+
+	metav1.SetMetaDataAnnotation(&cpms.ObjectMeta, annotations.RolloutHistoryAnnotation, string(value))
+
+	return nil
+}
+
+// observeRollout updates the most recent rollout in the history based on the current state of the Control Plane
+// Machines. A new rollout is started once outdated Machines are observed. The rollout then ends as:
+// - Aborted, when the template changes before the rollout completes.
+// - Failed, when the ControlPlaneMachineSet is degraded because a replacement Machine failed.
+// - Completed, once no outdated Machines remain and every index has a single, ready and up to date Machine.
+func observeRollout(history []annotations.RolloutRecord, cpms *machinev1.ControlPlaneMachineSet, machineInfos map[int32][]machineproviders.MachineInfo, templateHash string, now metav1.Time) []annotations.RolloutRecord {
+	var current *annotations.RolloutRecord
+	if len(history) > 0 && history[len(history)-1].Outcome == annotations.RolloutInProgress {
+		current = &history[len(history)-1]
+	}
+
+	if current != nil && current.TemplateHash != templateHash {
+		endRollout(current, annotations.RolloutAborted, now, rolloutAbortedMessage)
+		current = nil
+	}
+
+	failedReplacement, failedReplacementMessage := hasFailedReplacement(cpms)
+
+	if current == nil {
+		// Do not start a new rollout while a failed replacement blocks any progress.
+		if !hasOutdatedMachines(machineInfos) || failedReplacement {
+			return history
+		}
+
+		history = append(history, annotations.RolloutRecord{
+			TemplateHash: templateHash,
+			StartTime:    now,
+			Outcome:      annotations.RolloutInProgress,
+		})
+		current = &history[len(history)-1]
+	}
+
+	trackRolloutMachines(current, machineInfos)
+
+	switch {
+	case failedReplacement:
+		endRollout(current, annotations.RolloutFailed, now, failedReplacementMessage)
+	case !hasOutdatedMachines(machineInfos) && isRolloutComplete(cpms):
+		endRollout(current, annotations.RolloutCompleted, now, "")
+	}
+
+	return history
+}
+
+// trackRolloutMachines adds the outdated Machines, and their replacements, to the rollout.
+// Any other Machine within an index replaced by the rollout is a replacement created by the rollout.
+func trackRolloutMachines(rollout *annotations.RolloutRecord, machineInfos map[int32][]machineproviders.MachineInfo) {
+	indexes := sets.New(rollout.Indexes...)
+	oldMachines := sets.New(rollout.OldMachines...)
+	newMachines := sets.New(rollout.NewMachines...)
+
+	for idx, machines := range machineInfos {
+		for _, machine := range needReplacementMachines(machines) {
+			indexes.Insert(idx)
+			oldMachines.Insert(machine.MachineRef.ObjectMeta.Name)
+		}
+	}
+
+	for idx, machines := range machineInfos {
+		if !indexes.Has(idx) {
+			continue
+		}
+
+		for _, machine := range machines {
+			if machine.NeedsUpdate || isDeletedMachine(machine) || oldMachines.Has(machine.MachineRef.ObjectMeta.Name) {
+				continue
+			}
+
+			newMachines.Insert(machine.MachineRef.ObjectMeta.Name)
+		}
+	}
+
+	rollout.Indexes = sets.List(indexes)
+	rollout.OldMachines = sets.List(oldMachines)
+
+	if newMachines.Len() > 0 {
+		rollout.NewMachines = sets.List(newMachines)
+	}
+}
+
+// endRollout records the outcome of the rollout.
+func endRollout(rollout *annotations.RolloutRecord, outcome annotations.RolloutOutcome, now metav1.Time, message string) {
+	rollout.Outcome = outcome
+	rollout.EndTime = &now
+	rollout.Message = message
+}
+
+// hasOutdatedMachines checks whether any Machine needs to be replaced.
+func hasOutdatedMachines(machineInfos map[int32][]machineproviders.MachineInfo) bool {
+	for _, machines := range machineInfos {
+		if hasAny(needReplacementMachines(machines)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasFailedReplacement checks whether the ControlPlaneMachineSet is degraded because a replacement Machine failed,
+// and returns the message of the Degraded condition when it is.
+func hasFailedReplacement(cpms *machinev1.ControlPlaneMachineSet) (bool, string) {
+	degraded := meta.FindStatusCondition(cpms.Status.Conditions, conditionDegraded)
+	if degraded == nil || degraded.Status != metav1.ConditionTrue || degraded.Reason != reasonFailedReplacement {
+		return false, ""
+	}
+
+	return true, degraded.Message
+}
+
+// isRolloutComplete checks whether the status reports exactly the desired number of replicas,
+// all of which are ready and up to date.
+func isRolloutComplete(cpms *machinev1.ControlPlaneMachineSet) bool {
+	if cpms.Spec.Replicas == nil {
+		return false
+	}
+
+	desired := *cpms.Spec.Replicas
+
+	return cpms.Status.Replicas == desired && cpms.Status.ReadyReplicas == desired && cpms.Status.UpdatedReplicas == desired
+}
+
+// ensureRolloutHistory persists the rollout history on the ControlPlaneMachineSet.
+// The history is recorded within the annotations, which are not updated alongside the status.
+func (r *ControlPlaneMachineSetReconciler) ensureRolloutHistory(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, value string) error {
+	if cpms.Annotations[annotations.RolloutHistoryAnnotation] == value {
+		return nil
+	}
+
+	patchBase := client.MergeFrom(cpms.DeepCopy())
+	metav1.SetMetaDataAnnotation(&cpms.ObjectMeta, annotations.RolloutHistoryAnnotation, value)
+
+	if err := r.Patch(ctx, cpms, patchBase); err != nil {
+		return fmt.Errorf("failed to record rollout history: %w", err)
+	}
+
+	logger.V(3).Info("Updated rollout history", "rolloutHistory", value)
+
+	return nil
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Rollout history", func() {
+	machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+	machineBuilder := machineprovidersresourcebuilder.MachineInfo().WithMachineGVR(machineGVR)
+
+	startTime := metav1.NewTime(time.Date(2023, time.June, 1, 10, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(time.Date(2023, time.June, 1, 11, 30, 0, 0, time.UTC))
+
+	// templateHash is the hash of the current template, as passed to observeRollout.
+	templateHash := "current"
+
+	failedReplacementCondition := metav1.Condition{
+		Type:    conditionDegraded,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonFailedReplacement,
+		Message: "Observed 1 replacement machine(s) in error state",
+	}
+
+	// outdatedMachineInfos are the Machines observed when a rollout of indexes 0 and 1 starts.
+	outdatedMachineInfos := map[int32][]machineproviders.MachineInfo{
+		0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").WithReady(true).WithNeedsUpdate(true).Build()},
+		1: {machineBuilder.WithIndex(1).WithMachineName("machine-1").WithReady(true).WithNeedsUpdate(true).Build()},
+		2: {machineBuilder.WithIndex(2).WithMachineName("machine-2").WithReady(true).Build()},
+	}
+
+	// inProgressRollout is the record of the rollout of indexes 0 and 1, before any replacement was created.
+	inProgressRollout := annotations.RolloutRecord{
+		TemplateHash: templateHash,
+		StartTime:    startTime,
+		Indexes:      []int32{0, 1},
+		OldMachines:  []string{"machine-0", "machine-1"},
+		Outcome:      annotations.RolloutInProgress,
+	}
+
+	Context("observeRollout", func() {
+		type observeRolloutTableInput struct {
+			conditions      []metav1.Condition
+			status          machinev1.ControlPlaneMachineSetStatus
+			history         []annotations.RolloutRecord
+			machineInfos    map[int32][]machineproviders.MachineInfo
+			expectedHistory []annotations.RolloutRecord
+		}
+
+		DescribeTable("should record the progress of the rollout", func(in observeRolloutTableInput) {
+			cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithConditions(in.conditions).Build()
+			cpms.Status.Replicas = in.status.Replicas
+			cpms.Status.ReadyReplicas = in.status.ReadyReplicas
+			cpms.Status.UpdatedReplicas = in.status.UpdatedReplicas
+
+			history := observeRollout(in.history, cpms, in.machineInfos, templateHash, now)
+			Expect(history).To(Equal(in.expectedHistory))
+		},
+			Entry("with no outdated machines and no history", observeRolloutTableInput{
+				status: machinev1.ControlPlaneMachineSetStatus{Replicas: 3, ReadyReplicas: 3, UpdatedReplicas: 3},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").WithReady(true).Build()},
+					1: {machineBuilder.WithIndex(1).WithMachineName("machine-1").WithReady(true).Build()},
+					2: {machineBuilder.WithIndex(2).WithMachineName("machine-2").WithReady(true).Build()},
+				},
+				expectedHistory: nil,
+			}),
+			Entry("when outdated machines are first observed", observeRolloutTableInput{
+				status:       machinev1.ControlPlaneMachineSetStatus{Replicas: 3, ReadyReplicas: 3, UpdatedReplicas: 1},
+				machineInfos: outdatedMachineInfos,
+				expectedHistory: []annotations.RolloutRecord{
+					{
+						TemplateHash: templateHash,
+						StartTime:    now,
+						Indexes:      []int32{0, 1},
+						OldMachines:  []string{"machine-0", "machine-1"},
+						Outcome:      annotations.RolloutInProgress,
+					},
+				},
+			}),
+			Entry("when a replacement machine is created", observeRolloutTableInput{
+				status:  machinev1.ControlPlaneMachineSetStatus{Replicas: 4, ReadyReplicas: 3, UpdatedReplicas: 1},
+				history: []annotations.RolloutRecord{inProgressRollout},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						machineBuilder.WithIndex(0).WithMachineName("machine-0").WithReady(true).WithNeedsUpdate(true).Build(),
+						machineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build(),
+					},
+					1: {machineBuilder.WithIndex(1).WithMachineName("machine-1").WithReady(true).WithNeedsUpdate(true).Build()},
+					2: {machineBuilder.WithIndex(2).WithMachineName("machine-2").WithReady(true).Build()},
+				},
+				expectedHistory: []annotations.RolloutRecord{
+					{
+						TemplateHash: templateHash,
+						StartTime:    startTime,
+						Indexes:      []int32{0, 1},
+						OldMachines:  []string{"machine-0", "machine-1"},
+						NewMachines:  []string{"machine-replacement-0"},
+						Outcome:      annotations.RolloutInProgress,
+					},
+				},
+			}),
+			Entry("when the outdated machines are replaced but a replacement is not yet ready", observeRolloutTableInput{
+				status:  machinev1.ControlPlaneMachineSetStatus{Replicas: 3, ReadyReplicas: 2, UpdatedReplicas: 2},
+				history: []annotations.RolloutRecord{inProgressRollout},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithReady(true).Build()},
+					1: {machineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build()},
+					2: {machineBuilder.WithIndex(2).WithMachineName("machine-2").WithReady(true).Build()},
+				},
+				expectedHistory: []annotations.RolloutRecord{
+					{
+						TemplateHash: templateHash,
+						StartTime:    startTime,
+						Indexes:      []int32{0, 1},
+						OldMachines:  []string{"machine-0", "machine-1"},
+						NewMachines:  []string{"machine-replacement-0", "machine-replacement-1"},
+						Outcome:      annotations.RolloutInProgress,
+					},
+				},
+			}),
+			Entry("when the outdated machines are replaced and all replicas are ready", observeRolloutTableInput{
+				status:  machinev1.ControlPlaneMachineSetStatus{Replicas: 3, ReadyReplicas: 3, UpdatedReplicas: 3},
+				history: []annotations.RolloutRecord{inProgressRollout},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithReady(true).Build()},
+					1: {machineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithReady(true).Build()},
+					2: {machineBuilder.WithIndex(2).WithMachineName("machine-2").WithReady(true).Build()},
+				},
+				expectedHistory: []annotations.RolloutRecord{
+					{
+						TemplateHash: templateHash,
+						StartTime:    startTime,
+						EndTime:      &now,
+						Indexes:      []int32{0, 1},
+						OldMachines:  []string{"machine-0", "machine-1"},
+						NewMachines:  []string{"machine-replacement-0", "machine-replacement-1"},
+						Outcome:      annotations.RolloutCompleted,
+					},
+				},
+			}),
+			Entry("when a replacement machine fails", observeRolloutTableInput{
+				conditions: []metav1.Condition{failedReplacementCondition},
+				status:     machinev1.ControlPlaneMachineSetStatus{Replicas: 4, ReadyReplicas: 3, UpdatedReplicas: 1},
+				history:    []annotations.RolloutRecord{inProgressRollout},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						machineBuilder.WithIndex(0).WithMachineName("machine-0").WithReady(true).WithNeedsUpdate(true).Build(),
+						machineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithErrorMessage("InsufficientCapacity").Build(),
+					},
+					1: {machineBuilder.WithIndex(1).WithMachineName("machine-1").WithReady(true).WithNeedsUpdate(true).Build()},
+					2: {machineBuilder.WithIndex(2).WithMachineName("machine-2").WithReady(true).Build()},
+				},
+				expectedHistory: []annotations.RolloutRecord{
+					{
+						TemplateHash: templateHash,
+						StartTime:    startTime,
+						EndTime:      &now,
+						Indexes:      []int32{0, 1},
+						OldMachines:  []string{"machine-0", "machine-1"},
+						NewMachines:  []string{"machine-replacement-0"},
+						Outcome:      annotations.RolloutFailed,
+						Message:      "Observed 1 replacement machine(s) in error state",
+					},
+				},
+			}),
+			Entry("when a replacement machine has failed and no rollout is in progress", observeRolloutTableInput{
+				conditions: []metav1.Condition{failedReplacementCondition},
+				status:     machinev1.ControlPlaneMachineSetStatus{Replicas: 3, ReadyReplicas: 3, UpdatedReplicas: 1},
+				history: []annotations.RolloutRecord{
+					{
+						TemplateHash: templateHash,
+						StartTime:    startTime,
+						EndTime:      &startTime,
+						Indexes:      []int32{0, 1},
+						OldMachines:  []string{"machine-0", "machine-1"},
+						Outcome:      annotations.RolloutFailed,
+						Message:      "Observed 1 replacement machine(s) in error state",
+					},
+				},
+				machineInfos: outdatedMachineInfos,
+				expectedHistory: []annotations.RolloutRecord{
+					{
+						TemplateHash: templateHash,
+						StartTime:    startTime,
+						EndTime:      &startTime,
+						Indexes:      []int32{0, 1},
+						OldMachines:  []string{"machine-0", "machine-1"},
+						Outcome:      annotations.RolloutFailed,
+						Message:      "Observed 1 replacement machine(s) in error state",
+					},
+				},
+			}),
+			Entry("when the template changes before the rollout completes", observeRolloutTableInput{
+				status: machinev1.ControlPlaneMachineSetStatus{Replicas: 3, ReadyReplicas: 3, UpdatedReplicas: 1},
+				history: []annotations.RolloutRecord{
+					{
+						TemplateHash: "previous",
+						StartTime:    startTime,
+						Indexes:      []int32{0},
+						OldMachines:  []string{"machine-0"},
+						Outcome:      annotations.RolloutInProgress,
+					},
+				},
+				machineInfos: outdatedMachineInfos,
+				expectedHistory: []annotations.RolloutRecord{
+					{
+						TemplateHash: "previous",
+						StartTime:    startTime,
+						EndTime:      &now,
+						Indexes:      []int32{0},
+						OldMachines:  []string{"machine-0"},
+						Outcome:      annotations.RolloutAborted,
+						Message:      rolloutAbortedMessage,
+					},
+					{
+						TemplateHash: templateHash,
+						StartTime:    now,
+						Indexes:      []int32{0, 1},
+						OldMachines:  []string{"machine-0", "machine-1"},
+						Outcome:      annotations.RolloutInProgress,
+					},
+				},
+			}),
+		)
+	})
+
+	Context("updateRolloutHistory", func() {
+		var logger testutils.TestLogger
+
+		BeforeEach(func() {
+			logger = testutils.NewTestLogger()
+		})
+
+		It("should only keep the most recent rollouts", func() {
+			history := []annotations.RolloutRecord{}
+
+			for i := 0; i < maxRolloutHistory; i++ {
+				history = append(history, annotations.RolloutRecord{
+					TemplateHash: fmt.Sprintf("template-%d", i),
+					StartTime:    startTime,
+					EndTime:      &startTime,
+					Outcome:      annotations.RolloutCompleted,
+				})
+			}
+
+			value, err := json.Marshal(history)
+			Expect(err).ToNot(HaveOccurred())
+
+			cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+			cpms.Annotations = map[string]string{annotations.RolloutHistoryAnnotation: string(value)}
+
+			Expect(updateRolloutHistory(logger.Logger(), cpms, outdatedMachineInfos, now.Time)).To(Succeed())
+
+			updatedHistory, err := annotations.RolloutHistory(cpms)
+			Expect(err).ToNot(HaveOccurred())
+
+			currentTemplateHash, err := util.TemplateHash(cpms.Spec.Template, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(updatedHistory).To(HaveLen(maxRolloutHistory))
+			Expect(updatedHistory[0]).To(HaveField("TemplateHash", "template-1"))
+			Expect(updatedHistory[maxRolloutHistory-1]).To(SatisfyAll(
+				HaveField("TemplateHash", currentTemplateHash),
+				HaveField("Outcome", annotations.RolloutInProgress),
+			))
+		})
+
+		It("should not set the annotation when no rollout has been observed", func() {
+			cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+
+			machineInfos := map[int32][]machineproviders.MachineInfo{
+				0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").WithReady(true).Build()},
+			}
+
+			Expect(updateRolloutHistory(logger.Logger(), cpms, machineInfos, now.Time)).To(Succeed())
+			Expect(cpms.Annotations).ToNot(HaveKey(annotations.RolloutHistoryAnnotation))
+		})
+
+		It("should start over when the history is invalid", func() {
+			cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+			cpms.Annotations = map[string]string{annotations.RolloutHistoryAnnotation: "invalid"}
+
+			Expect(updateRolloutHistory(logger.Logger(), cpms, outdatedMachineInfos, now.Time)).To(Succeed())
+
+			updatedHistory, err := annotations.RolloutHistory(cpms)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updatedHistory).To(ConsistOf(HaveField("Outcome", annotations.RolloutInProgress)))
+		})
+	})
+})
@@ -0,0 +1,282 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// machineMetadataUpdate describes the labels and annotations a Machine should have for its metadata
+// to be in line with the template.
+type machineMetadataUpdate struct {
+	// machineRef is the reference to the Machine to update.
+	machineRef *machineproviders.ObjectRef
+
+	// labels are the labels the Machine should have.
+	labels map[string]string
+
+	// annotations are the annotations the Machine should have, including the managed metadata annotation.
+	annotations map[string]string
+
+	// driftedLabels are the keys of the labels that differ from the template.
+	driftedLabels []string
+
+	// driftedAnnotations are the keys of the annotations that differ from the template.
+	driftedAnnotations []string
+}
+
+// hasDrifted checks whether the metadata of the Machine differs from the template.
+// Otherwise, only the managed metadata annotation needs to be updated.
+func (u machineMetadataUpdate) hasDrifted() bool {
+	return len(u.driftedLabels) > 0 || len(u.driftedAnnotations) > 0
+}
+
+// getMachineMetadataUpdates works out, for each Machine, the labels and annotations needed to bring the Machine
+// metadata in line with the template. Only Machines that need to be updated are returned, sorted by name.
+// The labels used by the selector are never updated, as changing them could cause the ControlPlaneMachineSet to lose
+// track of its Machines. Neither are labels and annotations that use the ControlPlaneMachineSet prefix.
+// Machines that are being deleted are not updated.
+func getMachineMetadataUpdates(cpms *machinev1.ControlPlaneMachineSet, machineInfos map[int32][]machineproviders.MachineInfo) ([]machineMetadataUpdate, error) {
+	if cpms.Spec.Template.OpenShiftMachineV1Beta1Machine == nil {
+		return nil, nil
+	}
+
+	selectorKeys := getSelectorKeys(cpms.Spec.Selector)
+	templateMetadata := cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.ObjectMeta
+
+	desiredLabels := filterMetadata(templateMetadata.Labels, selectorKeys)
+	desiredAnnotations := filterMetadata(templateMetadata.Annotations, sets.NewString())
+
+	managedValue, err := getManagedMetadataValue(desiredLabels, desiredAnnotations)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := []machineMetadataUpdate{}
+
+	for _, machineInfo := range machineInfos {
+		for _, mInfo := range machineInfo {
+			if mInfo.MachineRef == nil || mInfo.MachineRef.ObjectMeta.DeletionTimestamp != nil {
+				continue
+			}
+
+			mObjectMeta := mInfo.MachineRef.ObjectMeta
+
+			// An invalid managed metadata annotation is replaced, so that it records the keys applied from now on.
+			managed, err := annotations.MachineManagedMetadata(&mObjectMeta)
+			if err != nil {
+				managed = annotations.ManagedMetadata{}
+			}
+
+			update := machineMetadataUpdate{
+				machineRef: mInfo.MachineRef,
+			}
+
+			update.labels, update.driftedLabels = syncMetadata(mObjectMeta.Labels, desiredLabels, sets.NewString(managed.Labels...).Difference(selectorKeys))
+			update.annotations, update.driftedAnnotations = syncMetadata(mObjectMeta.Annotations, desiredAnnotations, sets.NewString(managed.Annotations...))
+
+			if !update.hasDrifted() && mObjectMeta.Annotations[annotations.MachineManagedMetadataAnnotation] == managedValue {
+				continue
+			}
+
+			if managedValue == "" {
+				delete(update.annotations, annotations.MachineManagedMetadataAnnotation)
+			} else {
+				update.annotations[annotations.MachineManagedMetadataAnnotation] = managedValue
+			}
+
+			updates = append(updates, update)
+		}
+	}
+
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].machineRef.ObjectMeta.GetName() < updates[j].machineRef.ObjectMeta.GetName()
+	})
+
+	return updates, nil
+}
+
+// getManagedMetadataValue returns the value of the managed metadata annotation for the desired metadata.
+// When there is no desired metadata, the value is empty and the annotation should not be present.
+func getManagedMetadataValue(desiredLabels, desiredAnnotations map[string]string) (string, error) {
+	if len(desiredLabels) == 0 && len(desiredAnnotations) == 0 {
+		return "", nil
+	}
+
+	managedValue, err := json.Marshal(annotations.ManagedMetadata{
+		Labels:      sets.StringKeySet(desiredLabels).List(),
+		Annotations: sets.StringKeySet(desiredAnnotations).List(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not marshal managed metadata: %w", err)
+	}
+
+	return string(managedValue), nil
+}
+
+// getSelectorKeys returns the keys of the labels used by the selector.
+func getSelectorKeys(selector metav1.LabelSelector) sets.String {
+	keys := sets.StringKeySet(selector.MatchLabels)
+
+	for _, requirement := range selector.MatchExpressions {
+		keys.Insert(requirement.Key)
+	}
+
+	return keys
+}
+
+// filterMetadata returns the metadata without the excluded keys, and without the keys that use the
+// ControlPlaneMachineSet prefix.
+func filterMetadata(metadata map[string]string, excluded sets.String) map[string]string {
+	out := map[string]string{}
+
+	for key, value := range metadata {
+		if excluded.Has(key) || annotations.IsControlPlaneMachineSetAnnotation(key) {
+			continue
+		}
+
+		out[key] = value
+	}
+
+	return out
+}
+
+// syncMetadata returns a copy of the current metadata, updated to include the desired metadata.
+// Keys that were previously managed, but are no longer desired, are removed.
+// It also returns the sorted keys that were changed.
+func syncMetadata(current, desired map[string]string, previouslyManaged sets.String) (map[string]string, []string) {
+	out := map[string]string{}
+	for key, value := range current {
+		out[key] = value
+	}
+
+	drifted := sets.NewString()
+
+	for key, value := range desired {
+		if currentValue, ok := current[key]; !ok || currentValue != value {
+			out[key] = value
+
+			drifted.Insert(key)
+		}
+	}
+
+	for key := range previouslyManaged {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+
+		if _, ok := current[key]; ok {
+			delete(out, key)
+
+			drifted.Insert(key)
+		}
+	}
+
+	return out, drifted.List()
+}
+
+// ensureMachineMetadata updates the labels and annotations of each Machine that differs from the template.
+func (r *ControlPlaneMachineSetReconciler) ensureMachineMetadata(ctx context.Context, logger logr.Logger, updates []machineMetadataUpdate) error {
+	for _, update := range updates {
+		mObjectMeta := update.machineRef.ObjectMeta
+		mLogger := logger.WithValues("machineNamespace", mObjectMeta.GetNamespace(), "machineName", mObjectMeta.GetName())
+
+		machineGVK, err := r.RESTMapper.KindFor(update.machineRef.GroupVersionResource)
+		if err != nil {
+			return fmt.Errorf("error getting GVK for machine: %w", err)
+		}
+
+		machine := &metav1.PartialObjectMetadata{}
+		machine.SetGroupVersionKind(machineGVK)
+		machine.ObjectMeta = *mObjectMeta.DeepCopy()
+
+		patchBase := client.MergeFrom(machine.DeepCopy())
+
+		machine.SetLabels(update.labels)
+		machine.SetAnnotations(update.annotations)
+
+		if err := r.Client.Patch(ctx, machine, patchBase); err != nil {
+			return fmt.Errorf("error patching machine: %w", err)
+		}
+
+		if update.hasDrifted() {
+			mLogger.V(1).Info("Updated machine metadata to match the template", "labels", update.driftedLabels, "annotations", update.driftedAnnotations)
+		}
+	}
+
+	return nil
+}
+
+// setMachineMetadataDriftCondition reports the Machines with labels or annotations that differ from the template.
+// While the ControlPlaneMachineSet is active, the drift is corrected as it is detected.
+// The condition is only reported while the metadata of a Machine differs from the template.
+func setMachineMetadataDriftCondition(cpms *machinev1.ControlPlaneMachineSet, updates []machineMetadataUpdate) {
+	drifted := []string{}
+
+	for _, update := range updates {
+		if !update.hasDrifted() {
+			continue
+		}
+
+		keys := []string{}
+
+		if len(update.driftedLabels) > 0 {
+			keys = append(keys, fmt.Sprintf("labels: %s", strings.Join(update.driftedLabels, ", ")))
+		}
+
+		if len(update.driftedAnnotations) > 0 {
+			keys = append(keys, fmt.Sprintf("annotations: %s", strings.Join(update.driftedAnnotations, ", ")))
+		}
+
+		drifted = append(drifted, fmt.Sprintf("%s (%s)", update.machineRef.ObjectMeta.GetName(), strings.Join(keys, "; ")))
+	}
+
+	if len(drifted) == 0 {
+		meta.RemoveStatusCondition(&cpms.Status.Conditions, conditionMachineMetadataDrift)
+
+		return
+	}
+
+	reason := reasonMetadataDriftDetected
+	message := "Machine metadata differs from the template"
+
+	if isActive(cpms) {
+		reason = reasonMetadataDriftCorrected
+		message = "Updating Machine metadata to match the template"
+	}
+
+	meta.SetStatusCondition(&cpms.Status.Conditions, metav1.Condition{
+		Type:               conditionMachineMetadataDrift,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            fmt.Sprintf("%s: %s", message, strings.Join(drifted, ", ")),
+		ObservedGeneration: cpms.Generation,
+	})
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	machinev1beta1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+)
+
+var _ = Describe("Machine metadata", func() {
+	machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+
+	// selectorLabels are the labels used by the default ControlPlaneMachineSet selector.
+	selectorLabels := machinev1resourcebuilder.ControlPlaneMachineSet().Build().Spec.Selector.MatchLabels
+
+	withSelectorLabels := func(labels map[string]string) map[string]string {
+		out := map[string]string{}
+
+		for key, value := range selectorLabels {
+			out[key] = value
+		}
+
+		for key, value := range labels {
+			out[key] = value
+		}
+
+		return out
+	}
+
+	machineBuilder := machineprovidersresourcebuilder.MachineInfo().
+		WithMachineGVR(machineGVR).
+		WithMachineLabels(selectorLabels)
+
+	Context("getMachineMetadataUpdates", func() {
+		type machineMetadataUpdatesTableInput struct {
+			templateLabels      map[string]string
+			templateAnnotations map[string]string
+			machineInfos        map[int32][]machineproviders.MachineInfo
+			state               machinev1.ControlPlaneMachineSetState
+			expectedUpdates     []machineMetadataUpdate
+			expectedCondition   *metav1.Condition
+		}
+
+		DescribeTable("should work out the metadata updates for the machines", func(in machineMetadataUpdatesTableInput) {
+			cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2).Build()
+			cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.ObjectMeta.Labels = withSelectorLabels(in.templateLabels)
+			cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.ObjectMeta.Annotations = in.templateAnnotations
+
+			if in.state != "" {
+				cpms.Spec.State = in.state
+			}
+
+			updates, err := getMachineMetadataUpdates(cpms, in.machineInfos)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(updates).To(HaveLen(len(in.expectedUpdates)))
+
+			for i, expected := range in.expectedUpdates {
+				Expect(updates[i].machineRef.ObjectMeta.Name).To(Equal(expected.machineRef.ObjectMeta.Name))
+				Expect(updates[i].labels).To(Equal(expected.labels))
+				Expect(updates[i].annotations).To(Equal(expected.annotations))
+				Expect(updates[i].driftedLabels).To(Equal(expected.driftedLabels))
+				Expect(updates[i].driftedAnnotations).To(Equal(expected.driftedAnnotations))
+			}
+
+			setMachineMetadataDriftCondition(cpms, updates)
+
+			if in.expectedCondition == nil {
+				Expect(cpms.Status.Conditions).To(BeEmpty())
+				return
+			}
+
+			Expect(cpms.Status.Conditions).To(ConsistOf(testutils.MatchCondition(*in.expectedCondition)))
+		},
+			Entry("with only selector labels on the template", machineMetadataUpdatesTableInput{
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				},
+				expectedUpdates: []machineMetadataUpdate{},
+			}),
+			Entry("with machines missing the template metadata", machineMetadataUpdatesTableInput{
+				templateLabels:      map[string]string{"team": "infra"},
+				templateAnnotations: map[string]string{"example.com/owner": "infra"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+					1: {machineBuilder.WithIndex(1).WithMachineName("machine-1").WithMachineLabels(withSelectorLabels(map[string]string{"team": "infra"})).Build()},
+				},
+				expectedUpdates: []machineMetadataUpdate{
+					{
+						machineRef:         &machineproviders.ObjectRef{ObjectMeta: metav1.ObjectMeta{Name: "machine-0"}},
+						labels:             withSelectorLabels(map[string]string{"team": "infra"}),
+						annotations:        map[string]string{"example.com/owner": "infra", annotations.MachineManagedMetadataAnnotation: `{"labels":["team"],"annotations":["example.com/owner"]}`},
+						driftedLabels:      []string{"team"},
+						driftedAnnotations: []string{"example.com/owner"},
+					},
+					{
+						machineRef:         &machineproviders.ObjectRef{ObjectMeta: metav1.ObjectMeta{Name: "machine-1"}},
+						labels:             withSelectorLabels(map[string]string{"team": "infra"}),
+						annotations:        map[string]string{"example.com/owner": "infra", annotations.MachineManagedMetadataAnnotation: `{"labels":["team"],"annotations":["example.com/owner"]}`},
+						driftedLabels:      []string{},
+						driftedAnnotations: []string{"example.com/owner"},
+					},
+				},
+				expectedCondition: &metav1.Condition{
+					Type:               conditionMachineMetadataDrift,
+					Status:             metav1.ConditionTrue,
+					Reason:             reasonMetadataDriftCorrected,
+					Message:            "Updating Machine metadata to match the template: machine-0 (labels: team; annotations: example.com/owner), machine-1 (annotations: example.com/owner)",
+					ObservedGeneration: 2,
+				},
+			}),
+			Entry("with an inactive control plane machine set", machineMetadataUpdatesTableInput{
+				templateLabels: map[string]string{"team": "infra"},
+				state:          machinev1.ControlPlaneMachineSetStateInactive,
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").WithMachineLabels(withSelectorLabels(map[string]string{"team": "apps"})).Build()},
+				},
+				expectedUpdates: []machineMetadataUpdate{
+					{
+						machineRef:         &machineproviders.ObjectRef{ObjectMeta: metav1.ObjectMeta{Name: "machine-0"}},
+						labels:             withSelectorLabels(map[string]string{"team": "infra"}),
+						annotations:        map[string]string{annotations.MachineManagedMetadataAnnotation: `{"labels":["team"]}`},
+						driftedLabels:      []string{"team"},
+						driftedAnnotations: []string{},
+					},
+				},
+				expectedCondition: &metav1.Condition{
+					Type:               conditionMachineMetadataDrift,
+					Status:             metav1.ConditionTrue,
+					Reason:             reasonMetadataDriftDetected,
+					Message:            "Machine metadata differs from the template: machine-0 (labels: team)",
+					ObservedGeneration: 2,
+				},
+			}),
+			Entry("with metadata removed from the template", machineMetadataUpdatesTableInput{
+				templateLabels: map[string]string{"team": "infra"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").
+						WithMachineLabels(withSelectorLabels(map[string]string{"team": "infra", "tier": "gold", "user-label": "kept"})).
+						WithMachineAnnotations(map[string]string{"example.com/owner": "infra", annotations.MachineManagedMetadataAnnotation: `{"labels":["team","tier"],"annotations":["example.com/owner"]}`}).
+						Build()},
+				},
+				expectedUpdates: []machineMetadataUpdate{
+					{
+						machineRef:         &machineproviders.ObjectRef{ObjectMeta: metav1.ObjectMeta{Name: "machine-0"}},
+						labels:             withSelectorLabels(map[string]string{"team": "infra", "user-label": "kept"}),
+						annotations:        map[string]string{annotations.MachineManagedMetadataAnnotation: `{"labels":["team"]}`},
+						driftedLabels:      []string{"tier"},
+						driftedAnnotations: []string{"example.com/owner"},
+					},
+				},
+				expectedCondition: &metav1.Condition{
+					Type:               conditionMachineMetadataDrift,
+					Status:             metav1.ConditionTrue,
+					Reason:             reasonMetadataDriftCorrected,
+					Message:            "Updating Machine metadata to match the template: machine-0 (labels: tier; annotations: example.com/owner)",
+					ObservedGeneration: 2,
+				},
+			}),
+			Entry("with machines already in line with the template", machineMetadataUpdatesTableInput{
+				templateLabels: map[string]string{"team": "infra"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").
+						WithMachineLabels(withSelectorLabels(map[string]string{"team": "infra"})).
+						WithMachineAnnotations(map[string]string{annotations.MachineManagedMetadataAnnotation: `{"labels":["team"]}`}).
+						Build()},
+				},
+				expectedUpdates: []machineMetadataUpdate{},
+			}),
+			Entry("with machines in line with the template, missing the managed metadata annotation", machineMetadataUpdatesTableInput{
+				templateLabels: map[string]string{"team": "infra"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").WithMachineLabels(withSelectorLabels(map[string]string{"team": "infra"})).Build()},
+				},
+				expectedUpdates: []machineMetadataUpdate{
+					{
+						machineRef:         &machineproviders.ObjectRef{ObjectMeta: metav1.ObjectMeta{Name: "machine-0"}},
+						labels:             withSelectorLabels(map[string]string{"team": "infra"}),
+						annotations:        map[string]string{annotations.MachineManagedMetadataAnnotation: `{"labels":["team"]}`},
+						driftedLabels:      []string{},
+						driftedAnnotations: []string{},
+					},
+				},
+			}),
+			Entry("with a machine being deleted", machineMetadataUpdatesTableInput{
+				templateLabels: map[string]string{"team": "infra"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").WithMachineDeletionTimestamp(metav1.Now()).Build()},
+				},
+				expectedUpdates: []machineMetadataUpdate{},
+			}),
+			Entry("with control plane machine set labels and annotations on the template", machineMetadataUpdatesTableInput{
+				templateLabels:      map[string]string{annotations.MachineSpecDiffAnnotation: "true"},
+				templateAnnotations: map[string]string{annotations.MachineSpecDiffAnnotation: "[]"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				},
+				expectedUpdates: []machineMetadataUpdate{},
+			}),
+		)
+	})
+
+	Context("ensureMachineMetadata", func() {
+		var namespaceName string
+		var reconciler *ControlPlaneMachineSetReconciler
+		var logger testutils.TestLogger
+
+		var machine *machinev1beta1.Machine
+
+		BeforeEach(func() {
+			By("Setting up a namespace for the test")
+			ns := corev1resourcebuilder.Namespace().WithGenerateName("control-plane-machine-set-ensure-machine-metadata-").Build()
+			Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+			namespaceName = ns.GetName()
+
+			reconciler = &ControlPlaneMachineSetReconciler{
+				Client:         k8sClient,
+				UncachedClient: k8sClient,
+				Scheme:         testScheme,
+				RESTMapper:     testRESTMapper,
+				Namespace:      namespaceName,
+			}
+
+			logger = testutils.NewTestLogger()
+
+			By("Creating a machine to update")
+			machine = machinev1beta1resourcebuilder.Machine().AsMaster().WithNamespace(namespaceName).WithGenerateName("ensure-machine-metadata-test-").
+				WithLabel("tier", "gold").Build()
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+			labels := map[string]string{}
+			for key, value := range machine.GetLabels() {
+				labels[key] = value
+			}
+
+			delete(labels, "tier")
+			labels["team"] = "infra"
+
+			update := machineMetadataUpdate{
+				machineRef: &machineproviders.ObjectRef{
+					GroupVersionResource: machineGVR,
+					ObjectMeta:           machine.ObjectMeta,
+				},
+				labels:             labels,
+				annotations:        map[string]string{annotations.MachineManagedMetadataAnnotation: `{"labels":["team"]}`},
+				driftedLabels:      []string{"team", "tier"},
+				driftedAnnotations: []string{},
+			}
+
+			Expect(reconciler.ensureMachineMetadata(ctx, logger.Logger(), []machineMetadataUpdate{update})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
+				&machinev1beta1.Machine{},
+			)
+		})
+
+		It("should add the template labels", func() {
+			Eventually(komega.Object(machine)).Should(HaveField("ObjectMeta.Labels", HaveKeyWithValue("team", "infra")))
+		})
+
+		It("should remove the labels no longer on the template", func() {
+			Eventually(komega.Object(machine)).Should(HaveField("ObjectMeta.Labels", Not(HaveKey("tier"))))
+		})
+
+		It("should record the managed metadata", func() {
+			Eventually(komega.Object(machine)).Should(HaveField("ObjectMeta.Annotations", HaveKeyWithValue(annotations.MachineManagedMetadataAnnotation, `{"labels":["team"]}`)))
+		})
+
+		It("should log that it has updated the machine metadata", func() {
+			Expect(logger.Entries()).To(ConsistOf(testutils.LogEntry{
+				KeysAndValues: []interface{}{"machineNamespace", namespaceName, "machineName", machine.GetName(), "labels", []string{"team", "tier"}, "annotations", []string{}},
+				Level:         1,
+				Message:       "Updated machine metadata to match the template",
+			}))
+		})
+	})
+})
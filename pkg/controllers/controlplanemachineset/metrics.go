@@ -0,0 +1,346 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// metricsNamespace is the namespace of the metrics reported by the ControlPlaneMachineSet controller.
+	// This matches the metrics reported for the other Machine API resources.
+	metricsNamespace = "mapi"
+
+	// metricsSubsystem is the subsystem of the metrics reported by the ControlPlaneMachineSet controller.
+	metricsSubsystem = "control_plane_machine_set"
+
+	// namespaceLabel is the metric label for the namespace of the ControlPlaneMachineSet.
+	namespaceLabel = "namespace"
+
+	// nameLabel is the metric label for the name of the ControlPlaneMachineSet.
+	nameLabel = "name"
+
+	// indexLabel is the metric label for the index of the Control Plane Machines.
+	indexLabel = "index"
+
+	// strategyLabel is the metric label for the update strategy of the ControlPlaneMachineSet.
+	strategyLabel = "strategy"
+
+	// typeLabel is the metric label for the type of a condition.
+	typeLabel = "type"
+
+	// statusLabel is the metric label for the status of a condition.
+	statusLabel = "status"
+
+	// reasonLabel is the metric label for the reason of a condition.
+	reasonLabel = "reason"
+)
+
+// errUnexpectedCollector is used when a collector has already been registered with the same name,
+// but is not of the expected type.
+var errUnexpectedCollector = errors.New("a collector of an unexpected type is already registered")
+
+// controlPlaneMachineSetMetrics reports the state of the ControlPlaneMachineSet, and the progress of its rollouts.
+type controlPlaneMachineSetMetrics struct {
+	desiredReplicas     *prometheus.GaugeVec
+	currentReplicas     *prometheus.GaugeVec
+	readyReplicas       *prometheus.GaugeVec
+	updatedReplicas     *prometheus.GaugeVec
+	unavailableReplicas *prometheus.GaugeVec
+
+	indexNeedsUpdate *prometheus.GaugeVec
+	indexReady       *prometheus.GaugeVec
+
+	machinesCreated     *prometheus.CounterVec
+	machinesDeleted     *prometheus.CounterVec
+	replacementDuration *prometheus.HistogramVec
+
+	condition *prometheus.GaugeVec
+
+	// replacementStarts records the time at which the replacement of each index started, so that the duration of
+	// the replacement can be observed once the index is up to date.
+	// This is kept in memory, so replacements in progress when the operator restarts are not observed.
+	replacementStarts map[replacementKey]time.Time
+	lock              sync.Mutex
+}
+
+// replacementKey identifies an index of a ControlPlaneMachineSet being replaced.
+type replacementKey struct {
+	namespace string
+	name      string
+	index     int32
+}
+
+// newControlPlaneMachineSetMetrics creates the ControlPlaneMachineSet metrics and registers them with the registerer.
+// When the metrics have already been registered, for example, when the controller is set up more than once within
+// the same process, the existing metrics are reused.
+func newControlPlaneMachineSetMetrics(registerer prometheus.Registerer) (*controlPlaneMachineSetMetrics, error) {
+	m := &controlPlaneMachineSetMetrics{
+		replacementStarts: map[replacementKey]time.Time{},
+	}
+
+	var err error
+
+	cpmsLabels := []string{namespaceLabel, nameLabel}
+	indexLabels := []string{namespaceLabel, nameLabel, indexLabel}
+	strategyLabels := []string{namespaceLabel, nameLabel, strategyLabel}
+
+	if m.desiredReplicas, err = registerCollector(registerer, newGaugeVec("spec_replicas",
+		"The number of desired Control Plane Machines.", cpmsLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.currentReplicas, err = registerCollector(registerer, newGaugeVec("status_replicas",
+		"The number of Control Plane Machines that exist.", cpmsLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.readyReplicas, err = registerCollector(registerer, newGaugeVec("status_replicas_ready",
+		"The number of Control Plane Machines that are ready.", cpmsLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.updatedReplicas, err = registerCollector(registerer, newGaugeVec("status_replicas_updated",
+		"The number of ready Control Plane Machines that match the template.", cpmsLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.unavailableReplicas, err = registerCollector(registerer, newGaugeVec("status_replicas_unavailable",
+		"The number of indexes without a ready Control Plane Machine.", cpmsLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.indexNeedsUpdate, err = registerCollector(registerer, newGaugeVec("index_needs_update",
+		"Whether a Control Plane Machine within the index needs an update.", indexLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.indexReady, err = registerCollector(registerer, newGaugeVec("index_ready",
+		"Whether a Control Plane Machine within the index is ready.", indexLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.machinesCreated, err = registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "machines_created_total",
+		Help:      "The number of Control Plane Machines created, by update strategy.",
+	}, strategyLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.machinesDeleted, err = registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "machines_deleted_total",
+		Help:      "The number of Control Plane Machines deleted, by update strategy.",
+	}, strategyLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.replacementDuration, err = registerCollector(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "index_replacement_duration_seconds",
+		Help:      "The time taken to replace the Control Plane Machine within an index, by update strategy.",
+		// From 1 minute up to a little over 2 hours.
+		Buckets: prometheus.ExponentialBuckets(60, 2, 8),
+	}, strategyLabels)); err != nil {
+		return nil, err
+	}
+
+	if m.condition, err = registerCollector(registerer, newGaugeVec("condition",
+		"The status and reason of each condition of the ControlPlaneMachineSet.",
+		[]string{namespaceLabel, nameLabel, typeLabel, statusLabel, reasonLabel})); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// newGaugeVec creates a gauge vector within the ControlPlaneMachineSet metrics subsystem.
+func newGaugeVec(name, help string, labels []string) *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      name,
+		Help:      help,
+	}, labels)
+}
+
+// registerCollector registers the collector with the registerer.
+// When an equivalent collector has already been registered, the existing collector is returned instead.
+func registerCollector[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	err := registerer.Register(collector)
+	if err == nil {
+		return collector, nil
+	}
+
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if !errors.As(err, &alreadyRegistered) {
+		return collector, fmt.Errorf("could not register metrics collector: %w", err)
+	}
+
+	existing, ok := alreadyRegistered.ExistingCollector.(T)
+	if !ok {
+		return collector, fmt.Errorf("%w: %T", errUnexpectedCollector, alreadyRegistered.ExistingCollector)
+	}
+
+	return existing, nil
+}
+
+// observeStatus reports the replicas and the conditions from the status of the ControlPlaneMachineSet.
+func (m *controlPlaneMachineSetMetrics) observeStatus(cpms *machinev1.ControlPlaneMachineSet) {
+	if m == nil {
+		return
+	}
+
+	labels := prometheus.Labels{namespaceLabel: cpms.Namespace, nameLabel: cpms.Name}
+
+	desiredReplicas := 0.0
+	if cpms.Spec.Replicas != nil {
+		desiredReplicas = float64(*cpms.Spec.Replicas)
+	}
+
+	m.desiredReplicas.With(labels).Set(desiredReplicas)
+	m.currentReplicas.With(labels).Set(float64(cpms.Status.Replicas))
+	m.readyReplicas.With(labels).Set(float64(cpms.Status.ReadyReplicas))
+	m.updatedReplicas.With(labels).Set(float64(cpms.Status.UpdatedReplicas))
+	m.unavailableReplicas.With(labels).Set(float64(cpms.Status.UnavailableReplicas))
+
+	// Remove the previous status and reason of each condition, so that only the current ones are reported.
+	m.condition.DeletePartialMatch(labels)
+
+	for _, condition := range cpms.Status.Conditions {
+		m.condition.WithLabelValues(cpms.Namespace, cpms.Name, condition.Type, string(condition.Status), condition.Reason).Set(1)
+	}
+}
+
+// observeMachineInfos reports whether each index needs an update and is ready.
+// It also observes the duration of the replacements that have completed, where an index is considered to be
+// replaced once its only Machine is ready and up to date.
+func (m *controlPlaneMachineSetMetrics) observeMachineInfos(cpms *machinev1.ControlPlaneMachineSet, machineInfosByIndex map[int32][]machineproviders.MachineInfo, now time.Time) {
+	if m == nil {
+		return
+	}
+
+	// Remove the indexes previously reported, so that indexes that no longer exist are not reported.
+	labels := prometheus.Labels{namespaceLabel: cpms.Namespace, nameLabel: cpms.Name}
+	m.indexNeedsUpdate.DeletePartialMatch(labels)
+	m.indexReady.DeletePartialMatch(labels)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for idx, machineInfos := range machineInfosByIndex {
+		index := strconv.Itoa(int(idx))
+
+		m.indexNeedsUpdate.WithLabelValues(cpms.Namespace, cpms.Name, index).Set(boolToFloat(hasAny(needReplacementMachines(machineInfos))))
+		m.indexReady.WithLabelValues(cpms.Namespace, cpms.Name, index).Set(boolToFloat(hasAny(readyMachines(machineInfos))))
+
+		key := replacementKey{namespace: cpms.Namespace, name: cpms.Name, index: idx}
+
+		started, ok := m.replacementStarts[key]
+		if !ok || len(machineInfos) != 1 || len(updatedNonDeletedMachines(machineInfos)) != 1 {
+			continue
+		}
+
+		m.replacementDuration.WithLabelValues(cpms.Namespace, cpms.Name, string(cpms.Spec.Strategy.Type)).Observe(now.Sub(started).Seconds())
+		delete(m.replacementStarts, key)
+	}
+}
+
+// machineCreated counts a Machine created for the index. When the Machine replaces an outdated Machine,
+// this starts the replacement of the index, unless it has already been started.
+func (m *controlPlaneMachineSetMetrics) machineCreated(cpms *machinev1.ControlPlaneMachineSet, idx int32, replacing bool, now time.Time) {
+	if m == nil {
+		return
+	}
+
+	m.machinesCreated.WithLabelValues(cpms.Namespace, cpms.Name, string(cpms.Spec.Strategy.Type)).Inc()
+
+	if replacing {
+		m.startReplacement(cpms, idx, now)
+	}
+}
+
+// machineDeleted counts a Machine deleted from the index. When the Machine is outdated, this starts the replacement
+// of the index, unless it has already been started.
+func (m *controlPlaneMachineSetMetrics) machineDeleted(cpms *machinev1.ControlPlaneMachineSet, machineInfo machineproviders.MachineInfo, now time.Time) {
+	if m == nil {
+		return
+	}
+
+	m.machinesDeleted.WithLabelValues(cpms.Namespace, cpms.Name, string(cpms.Spec.Strategy.Type)).Inc()
+
+	if machineInfo.NeedsUpdate {
+		m.startReplacement(cpms, machineInfo.Index, now)
+	}
+}
+
+// startReplacement records the time at which the replacement of the index started.
+func (m *controlPlaneMachineSetMetrics) startReplacement(cpms *machinev1.ControlPlaneMachineSet, idx int32, now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := replacementKey{namespace: cpms.Namespace, name: cpms.Name, index: idx}
+	if _, ok := m.replacementStarts[key]; !ok {
+		m.replacementStarts[key] = now
+	}
+}
+
+// forget removes the metrics reported for a ControlPlaneMachineSet that no longer exists.
+func (m *controlPlaneMachineSetMetrics) forget(namespace, name string) {
+	if m == nil {
+		return
+	}
+
+	labels := prometheus.Labels{namespaceLabel: namespace, nameLabel: name}
+
+	for _, vec := range []interface{ DeletePartialMatch(prometheus.Labels) int }{
+		m.desiredReplicas, m.currentReplicas, m.readyReplicas, m.updatedReplicas, m.unavailableReplicas,
+		m.indexNeedsUpdate, m.indexReady, m.condition,
+	} {
+		vec.DeletePartialMatch(labels)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for key := range m.replacementStarts {
+		if key.namespace == namespace && key.name == name {
+			delete(m.replacementStarts, key)
+		}
+	}
+}
+
+// boolToFloat converts a boolean into the value of a metric.
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
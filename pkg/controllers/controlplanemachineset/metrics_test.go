@@ -0,0 +1,244 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Metrics", func() {
+	var registry *prometheus.Registry
+	var cpmsMetrics *controlPlaneMachineSetMetrics
+	var cpms *machinev1.ControlPlaneMachineSet
+
+	machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+
+	updatedMachineBuilder := machineprovidersresourcebuilder.MachineInfo().
+		WithMachineGVR(machineGVR).
+		WithReady(true).
+		WithNeedsUpdate(false)
+
+	pendingMachineBuilder := updatedMachineBuilder.WithReady(false)
+
+	outdatedMachineBuilder := updatedMachineBuilder.WithNeedsUpdate(true)
+
+	// getMetrics returns the metrics with the given name, keyed by their label values.
+	getMetrics := func(name string) map[string]*dto.Metric {
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+
+		out := map[string]*dto.Metric{}
+
+		for _, family := range families {
+			if family.GetName() != name {
+				continue
+			}
+
+			for _, metric := range family.GetMetric() {
+				key := ""
+				for _, label := range metric.GetLabel() {
+					key += label.GetName() + "=" + label.GetValue() + ","
+				}
+
+				out[key] = metric
+			}
+		}
+
+		return out
+	}
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+
+		var err error
+		cpmsMetrics, err = newControlPlaneMachineSetMetrics(registry)
+		Expect(err).ToNot(HaveOccurred())
+
+		cpms = machinev1resourcebuilder.ControlPlaneMachineSet().WithReplicas(3).WithStrategyType(machinev1.RollingUpdate).Build()
+	})
+
+	Context("when registering the metrics more than once", func() {
+		It("should reuse the existing metrics", func() {
+			again, err := newControlPlaneMachineSetMetrics(registry)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(again.desiredReplicas).To(BeIdenticalTo(cpmsMetrics.desiredReplicas))
+			Expect(again.replacementDuration).To(BeIdenticalTo(cpmsMetrics.replacementDuration))
+		})
+	})
+
+	Context("observeStatus", func() {
+		BeforeEach(func() {
+			cpms.Status = machinev1.ControlPlaneMachineSetStatus{
+				Replicas:            4,
+				ReadyReplicas:       3,
+				UpdatedReplicas:     2,
+				UnavailableReplicas: 0,
+				Conditions: []metav1.Condition{
+					{Type: conditionAvailable, Status: metav1.ConditionTrue, Reason: reasonAllReplicasAvailable},
+					{Type: conditionProgressing, Status: metav1.ConditionTrue, Reason: reasonNeedsUpdateReplicas},
+				},
+			}
+
+			cpmsMetrics.observeStatus(cpms)
+		})
+
+		It("should report the replicas", func() {
+			labels := "name=cluster,namespace=openshift-machine-api,"
+
+			Expect(getMetrics("mapi_control_plane_machine_set_spec_replicas")[labels].GetGauge().GetValue()).To(Equal(3.0))
+			Expect(getMetrics("mapi_control_plane_machine_set_status_replicas")[labels].GetGauge().GetValue()).To(Equal(4.0))
+			Expect(getMetrics("mapi_control_plane_machine_set_status_replicas_ready")[labels].GetGauge().GetValue()).To(Equal(3.0))
+			Expect(getMetrics("mapi_control_plane_machine_set_status_replicas_updated")[labels].GetGauge().GetValue()).To(Equal(2.0))
+			Expect(getMetrics("mapi_control_plane_machine_set_status_replicas_unavailable")[labels].GetGauge().GetValue()).To(Equal(0.0))
+		})
+
+		It("should report the conditions", func() {
+			Expect(getMetrics("mapi_control_plane_machine_set_condition")).To(SatisfyAll(
+				HaveLen(2),
+				HaveKey("name=cluster,namespace=openshift-machine-api,reason=AllReplicasAvailable,status=True,type=Available,"),
+				HaveKey("name=cluster,namespace=openshift-machine-api,reason=NeedsUpdateReplicas,status=True,type=Progressing,"),
+			))
+		})
+
+		It("should only report the current status and reason of each condition", func() {
+			cpms.Status.Conditions[1] = metav1.Condition{Type: conditionProgressing, Status: metav1.ConditionFalse, Reason: reasonAllReplicasUpdated}
+			cpmsMetrics.observeStatus(cpms)
+
+			Expect(getMetrics("mapi_control_plane_machine_set_condition")).To(SatisfyAll(
+				HaveLen(2),
+				HaveKey("name=cluster,namespace=openshift-machine-api,reason=AllReplicasAvailable,status=True,type=Available,"),
+				HaveKey("name=cluster,namespace=openshift-machine-api,reason=AllReplicasUpdated,status=False,type=Progressing,"),
+			))
+		})
+
+		It("should remove the metrics once the control plane machine set is forgotten", func() {
+			cpmsMetrics.forget(cpms.Namespace, cpms.Name)
+
+			Expect(getMetrics("mapi_control_plane_machine_set_spec_replicas")).To(BeEmpty())
+			Expect(getMetrics("mapi_control_plane_machine_set_condition")).To(BeEmpty())
+		})
+	})
+
+	Context("observeMachineInfos", func() {
+		BeforeEach(func() {
+			cpmsMetrics.observeMachineInfos(cpms, map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()},
+				2: {pendingMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			}, time.Now())
+		})
+
+		It("should report whether each index needs an update", func() {
+			needsUpdate := getMetrics("mapi_control_plane_machine_set_index_needs_update")
+
+			Expect(needsUpdate).To(HaveLen(3))
+			Expect(needsUpdate["index=0,name=cluster,namespace=openshift-machine-api,"].GetGauge().GetValue()).To(Equal(0.0))
+			Expect(needsUpdate["index=1,name=cluster,namespace=openshift-machine-api,"].GetGauge().GetValue()).To(Equal(1.0))
+			Expect(needsUpdate["index=2,name=cluster,namespace=openshift-machine-api,"].GetGauge().GetValue()).To(Equal(0.0))
+		})
+
+		It("should report whether each index is ready", func() {
+			ready := getMetrics("mapi_control_plane_machine_set_index_ready")
+
+			Expect(ready).To(HaveLen(3))
+			Expect(ready["index=0,name=cluster,namespace=openshift-machine-api,"].GetGauge().GetValue()).To(Equal(1.0))
+			Expect(ready["index=1,name=cluster,namespace=openshift-machine-api,"].GetGauge().GetValue()).To(Equal(1.0))
+			Expect(ready["index=2,name=cluster,namespace=openshift-machine-api,"].GetGauge().GetValue()).To(Equal(0.0))
+		})
+	})
+
+	Context("when replacing a machine", func() {
+		start := time.Date(2023, time.March, 1, 10, 0, 0, 0, time.UTC)
+		durationLabels := "name=cluster,namespace=openshift-machine-api,strategy=RollingUpdate,"
+
+		BeforeEach(func() {
+			cpmsMetrics.machineCreated(cpms, 1, true, start)
+		})
+
+		It("should count the created machine", func() {
+			Expect(getMetrics("mapi_control_plane_machine_set_machines_created_total")[durationLabels].GetCounter().GetValue()).To(Equal(1.0))
+		})
+
+		It("should not observe the duration while the replacement is in progress", func() {
+			cpmsMetrics.observeMachineInfos(cpms, map[int32][]machineproviders.MachineInfo{
+				1: {
+					outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build(),
+					updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+				},
+			}, start.Add(10*time.Minute))
+
+			Expect(getMetrics("mapi_control_plane_machine_set_index_replacement_duration_seconds")).To(BeEmpty())
+		})
+
+		Context("once the outdated machine has been removed", func() {
+			BeforeEach(func() {
+				cpmsMetrics.machineDeleted(cpms, outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build(), start.Add(10*time.Minute))
+
+				cpmsMetrics.observeMachineInfos(cpms, map[int32][]machineproviders.MachineInfo{
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build()},
+				}, start.Add(15*time.Minute))
+			})
+
+			It("should count the deleted machine", func() {
+				Expect(getMetrics("mapi_control_plane_machine_set_machines_deleted_total")[durationLabels].GetCounter().GetValue()).To(Equal(1.0))
+			})
+
+			It("should observe the duration of the replacement from when it started", func() {
+				duration := getMetrics("mapi_control_plane_machine_set_index_replacement_duration_seconds")[durationLabels].GetHistogram()
+
+				Expect(duration.GetSampleCount()).To(Equal(uint64(1)))
+				Expect(duration.GetSampleSum()).To(Equal((15 * time.Minute).Seconds()))
+			})
+
+			It("should only observe the duration once", func() {
+				cpmsMetrics.observeMachineInfos(cpms, map[int32][]machineproviders.MachineInfo{
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build()},
+				}, start.Add(20*time.Minute))
+
+				duration := getMetrics("mapi_control_plane_machine_set_index_replacement_duration_seconds")[durationLabels].GetHistogram()
+				Expect(duration.GetSampleCount()).To(Equal(uint64(1)))
+			})
+		})
+	})
+
+	Context("without metrics", func() {
+		It("should not panic", func() {
+			var noMetrics *controlPlaneMachineSetMetrics
+
+			Expect(func() {
+				noMetrics.observeStatus(cpms)
+				noMetrics.observeMachineInfos(cpms, map[int32][]machineproviders.MachineInfo{}, time.Now())
+				noMetrics.machineCreated(cpms, 0, true, time.Now())
+				noMetrics.machineDeleted(cpms, machineproviders.MachineInfo{}, time.Now())
+				noMetrics.forget(cpms.Namespace, cpms.Name)
+			}).ToNot(Panic())
+		})
+	})
+})
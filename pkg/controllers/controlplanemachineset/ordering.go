@@ -0,0 +1,157 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+)
+
+const (
+	// etcdLeaderUnknown is a log message used to inform the user that the etcd leader could not be located,
+	// and so the indexes will be replaced in ascending order.
+	etcdLeaderUnknown = "Could not locate the etcd leader, indexes will be replaced in ascending order"
+)
+
+// EtcdLeaderLocator locates the Node hosting the current etcd leader.
+type EtcdLeaderLocator interface {
+	// LeaderNodeName returns the name of the Node hosting the current etcd leader.
+	// An empty name is returned when the leader is not currently known.
+	LeaderNodeName(ctx context.Context) (string, error)
+}
+
+// indexOrderPolicy determines the order in which the automated update strategies consider the indexes of the
+// ControlPlaneMachineSet for replacement.
+type indexOrderPolicy interface {
+	// Order returns the indexes in the order in which they should be considered for replacement.
+	// The input is sorted by index in ascending order and must not be modified.
+	Order(ctx context.Context, logger logr.Logger, indexes []indexToMachineInfos) ([]indexToMachineInfos, error)
+}
+
+// newIndexOrderPolicy returns the index ordering policy configured by the annotations on the ControlPlaneMachineSet.
+func newIndexOrderPolicy(cpms *machinev1.ControlPlaneMachineSet, leaderLocator EtcdLeaderLocator) (indexOrderPolicy, error) {
+	order, err := annotations.GetReplacementOrder(cpms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rollout controls: %w", err)
+	}
+
+	switch order.Policy {
+	case annotations.ExplicitReplacementOrder:
+		return explicitOrderPolicy{indexes: order.Indexes}, nil
+	case annotations.NonLeaderFirstReplacementOrder:
+		return nonLeaderFirstOrderPolicy{leaderLocator: leaderLocator}, nil
+	case annotations.AscendingReplacementOrder:
+		return ascendingOrderPolicy{}, nil
+	default:
+		return ascendingOrderPolicy{}, nil
+	}
+}
+
+// ascendingOrderPolicy considers the indexes in ascending order.
+type ascendingOrderPolicy struct{}
+
+// Order returns the indexes in ascending order.
+func (ascendingOrderPolicy) Order(_ context.Context, _ logr.Logger, indexes []indexToMachineInfos) ([]indexToMachineInfos, error) {
+	return indexes, nil
+}
+
+// explicitOrderPolicy considers the indexes in the order given by the user.
+// Any indexes not given are considered afterwards in ascending order.
+type explicitOrderPolicy struct {
+	indexes []int32
+}
+
+// Order returns the indexes in the order given by the user, followed by the remaining indexes.
+func (p explicitOrderPolicy) Order(_ context.Context, _ logr.Logger, indexes []indexToMachineInfos) ([]indexToMachineInfos, error) {
+	position := map[int32]int{}
+	for i, idx := range p.indexes {
+		position[idx] = i
+	}
+
+	// Indexes not given by the user sort after all given indexes.
+	rank := func(idx int32) int {
+		if pos, ok := position[idx]; ok {
+			return pos
+		}
+
+		return len(p.indexes)
+	}
+
+	ordered := make([]indexToMachineInfos, len(indexes))
+	copy(ordered, indexes)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i].index) < rank(ordered[j].index)
+	})
+
+	return ordered, nil
+}
+
+// nonLeaderFirstOrderPolicy considers the indexes that do not host the etcd leader first, in ascending order,
+// followed by the index hosting the etcd leader. This minimises the number of etcd leader elections during a rollout.
+// When the etcd leader cannot be located, the indexes are considered in ascending order.
+type nonLeaderFirstOrderPolicy struct {
+	leaderLocator EtcdLeaderLocator
+}
+
+// Order returns the indexes that do not host the etcd leader, followed by the index that does.
+func (p nonLeaderFirstOrderPolicy) Order(ctx context.Context, logger logr.Logger, indexes []indexToMachineInfos) ([]indexToMachineInfos, error) {
+	if p.leaderLocator == nil {
+		logger.V(4).Info(etcdLeaderUnknown)
+
+		return indexes, nil
+	}
+
+	leaderNodeName, err := p.leaderLocator.LeaderNodeName(ctx)
+	if err != nil {
+		// The order only limits the number of etcd leader elections, so an unavailable leader location
+		// must not block the rollout.
+		logger.Error(err, etcdLeaderUnknown)
+
+		return indexes, nil
+	}
+
+	if leaderNodeName == "" {
+		logger.V(4).Info(etcdLeaderUnknown)
+
+		return indexes, nil
+	}
+
+	hostsLeader := func(mi indexToMachineInfos) bool {
+		for _, machineInfo := range mi.machineInfos {
+			if machineInfo.NodeRef != nil && machineInfo.NodeRef.ObjectMeta.Name == leaderNodeName {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ordered := make([]indexToMachineInfos, len(indexes))
+	copy(ordered, indexes)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return !hostsLeader(ordered[i]) && hostsLeader(ordered[j])
+	})
+
+	return ordered, nil
+}
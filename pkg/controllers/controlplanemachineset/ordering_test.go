@@ -0,0 +1,171 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// staticEtcdLeaderLocator is an EtcdLeaderLocator that always returns the same result.
+type staticEtcdLeaderLocator struct {
+	nodeName string
+	err      error
+}
+
+// LeaderNodeName returns the configured node name and error.
+func (l staticEtcdLeaderLocator) LeaderNodeName(_ context.Context) (string, error) {
+	return l.nodeName, l.err
+}
+
+var _ = Describe("Index ordering", func() {
+	machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+	nodeGVR := corev1.SchemeGroupVersion.WithResource("nodes")
+
+	updatedMachineBuilder := machineprovidersresourcebuilder.MachineInfo().
+		WithMachineGVR(machineGVR).
+		WithNodeGVR(nodeGVR).
+		WithReady(true).
+		WithNeedsUpdate(false)
+
+	indexedMachineInfos := map[int32][]machineproviders.MachineInfo{
+		0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+		1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+		2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+	}
+
+	leaderLocatorError := errors.New("leader locator error")
+
+	type indexOrderTableInput struct {
+		annotations        map[string]string
+		leaderLocator      EtcdLeaderLocator
+		expectedOrder      []int32
+		expectedParseError error
+		expectedLogs       []testutils.LogEntry
+	}
+
+	DescribeTable("should order the indexes based on the replacement order policy", func(in indexOrderTableInput) {
+		logger := testutils.NewTestLogger()
+
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithReplicas(3).Build()
+		cpms.Annotations = in.annotations
+
+		policy, err := newIndexOrderPolicy(cpms, in.leaderLocator)
+		if in.expectedParseError != nil {
+			Expect(err).To(MatchError(in.expectedParseError))
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+
+		sorted := sortMachineInfosByIndex(indexedMachineInfos)
+		original := make([]indexToMachineInfos, len(sorted))
+		copy(original, sorted)
+
+		ordered, err := policy.Order(context.Background(), logger.Logger(), sorted)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sorted).To(Equal(original), "The ordering policy should not modify the input")
+
+		orderedIndexes := []int32{}
+		for _, mi := range ordered {
+			orderedIndexes = append(orderedIndexes, mi.index)
+		}
+
+		Expect(orderedIndexes).To(Equal(in.expectedOrder))
+		Expect(logger.Entries()).To(ConsistOf(in.expectedLogs))
+	},
+		Entry("with no annotation", indexOrderTableInput{
+			expectedOrder: []int32{0, 1, 2},
+		}),
+		Entry("with the Ascending policy", indexOrderTableInput{
+			annotations:   map[string]string{annotations.ReplacementOrderAnnotation: "Ascending"},
+			expectedOrder: []int32{0, 1, 2},
+		}),
+		Entry("with an explicit list of all indexes", indexOrderTableInput{
+			annotations:   map[string]string{annotations.ReplacementOrderAnnotation: "2,0,1"},
+			expectedOrder: []int32{2, 0, 1},
+		}),
+		Entry("with an explicit list of some indexes", indexOrderTableInput{
+			annotations:   map[string]string{annotations.ReplacementOrderAnnotation: "1"},
+			expectedOrder: []int32{1, 0, 2},
+		}),
+		Entry("with the NonLeaderFirst policy and the leader on the first index", indexOrderTableInput{
+			annotations:   map[string]string{annotations.ReplacementOrderAnnotation: "NonLeaderFirst"},
+			leaderLocator: staticEtcdLeaderLocator{nodeName: "node-0"},
+			expectedOrder: []int32{1, 2, 0},
+		}),
+		Entry("with the NonLeaderFirst policy and the leader on the middle index", indexOrderTableInput{
+			annotations:   map[string]string{annotations.ReplacementOrderAnnotation: "NonLeaderFirst"},
+			leaderLocator: staticEtcdLeaderLocator{nodeName: "node-1"},
+			expectedOrder: []int32{0, 2, 1},
+		}),
+		Entry("with the NonLeaderFirst policy and the leader not on a control plane machine", indexOrderTableInput{
+			annotations:   map[string]string{annotations.ReplacementOrderAnnotation: "NonLeaderFirst"},
+			leaderLocator: staticEtcdLeaderLocator{nodeName: "node-other"},
+			expectedOrder: []int32{0, 1, 2},
+		}),
+		Entry("with the NonLeaderFirst policy and an unknown leader", indexOrderTableInput{
+			annotations:   map[string]string{annotations.ReplacementOrderAnnotation: "NonLeaderFirst"},
+			leaderLocator: staticEtcdLeaderLocator{},
+			expectedOrder: []int32{0, 1, 2},
+			expectedLogs: []testutils.LogEntry{
+				{
+					Level:   4,
+					Message: etcdLeaderUnknown,
+				},
+			},
+		}),
+		Entry("with the NonLeaderFirst policy and no leader locator", indexOrderTableInput{
+			annotations:   map[string]string{annotations.ReplacementOrderAnnotation: "NonLeaderFirst"},
+			expectedOrder: []int32{0, 1, 2},
+			expectedLogs: []testutils.LogEntry{
+				{
+					Level:   4,
+					Message: etcdLeaderUnknown,
+				},
+			},
+		}),
+		Entry("with the NonLeaderFirst policy and an error locating the leader", indexOrderTableInput{
+			annotations:   map[string]string{annotations.ReplacementOrderAnnotation: "NonLeaderFirst"},
+			leaderLocator: staticEtcdLeaderLocator{err: leaderLocatorError},
+			expectedOrder: []int32{0, 1, 2},
+			expectedLogs: []testutils.LogEntry{
+				{
+					Error:   leaderLocatorError,
+					Message: etcdLeaderUnknown,
+				},
+			},
+		}),
+		Entry("with an invalid policy", indexOrderTableInput{
+			annotations: map[string]string{annotations.ReplacementOrderAnnotation: "Random"},
+			expectedParseError: fmt.Errorf("failed to parse rollout controls: %w", fmt.Errorf("%s: %w", annotations.ReplacementOrderAnnotation,
+				fmt.Errorf("%w: %q", annotations.ErrInvalidReplacementOrder, "Random"))),
+		}),
+	)
+})
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setTemplateRevisionCondition reports how many of the Control Plane Machines were created from the current revision
+// of the template. This allows Machines created from a previous template to be told apart from Machines that no longer
+// match the template they were created from.
+func setTemplateRevisionCondition(cpms *machinev1.ControlPlaneMachineSet, machineInfosByIndex map[int32][]machineproviders.MachineInfo) error {
+	revision, err := util.TemplateHash(cpms.Spec.Template, annotations.FailureDomainsAnnotationValues(cpms))
+	if err != nil {
+		return fmt.Errorf("error calculating template revision: %w", err)
+	}
+
+	var total, current, previous, unknown int
+
+	drifted := []string{}
+
+	for _, machineInfos := range machineInfosByIndex {
+		for _, machineInfo := range machineInfos {
+			total++
+
+			switch machineInfo.TemplateRevision {
+			case revision:
+				current++
+
+				if machineInfo.NeedsUpdate {
+					drifted = append(drifted, machineInfo.MachineRef.ObjectMeta.Name)
+				}
+			case "":
+				unknown++
+			default:
+				previous++
+			}
+		}
+	}
+
+	sort.Strings(drifted)
+
+	message := fmt.Sprintf("%d of %d Machines were created from the current template revision %s", current, total, revision)
+
+	if previous > 0 || unknown > 0 {
+		message += fmt.Sprintf(", %d from a previous revision and %d with no recorded revision", previous, unknown)
+	}
+
+	if len(drifted) > 0 {
+		message += fmt.Sprintf("; Machines created from the current revision that no longer match the template: %s", strings.Join(drifted, ", "))
+	}
+
+	condition := metav1.Condition{
+		Type:               conditionTemplateRevision,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonAllMachinesAtCurrentRevision,
+		Message:            message,
+		ObservedGeneration: cpms.Generation,
+	}
+
+	if current < total {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonMachinesAtPreviousRevisions
+	}
+
+	meta.SetStatusCondition(&cpms.Status.Conditions, condition)
+
+	return nil
+}
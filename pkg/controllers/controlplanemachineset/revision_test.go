@@ -0,0 +1,114 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Template revision", func() {
+	machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+	machineBuilder := machineprovidersresourcebuilder.MachineInfo().WithMachineGVR(machineGVR).WithReady(true)
+
+	Context("setTemplateRevisionCondition", func() {
+		type templateRevisionTableInput struct {
+			// machineInfos builds the Machines given the current revision of the template.
+			machineInfos      func(revision string) map[int32][]machineproviders.MachineInfo
+			expectedCondition func(revision string) metav1.Condition
+		}
+
+		DescribeTable("should report the revisions of the Machines", func(in templateRevisionTableInput) {
+			cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2).Build()
+
+			revision, err := util.TemplateHash(cpms.Spec.Template, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(setTemplateRevisionCondition(cpms, in.machineInfos(revision))).To(Succeed())
+
+			Expect(cpms.Status.Conditions).To(ConsistOf(testutils.MatchCondition(in.expectedCondition(revision))))
+		},
+			Entry("when all Machines were created from the current revision", templateRevisionTableInput{
+				machineInfos: func(revision string) map[int32][]machineproviders.MachineInfo {
+					return map[int32][]machineproviders.MachineInfo{
+						0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").WithTemplateRevision(revision).Build()},
+						1: {machineBuilder.WithIndex(1).WithMachineName("machine-1").WithTemplateRevision(revision).Build()},
+						2: {machineBuilder.WithIndex(2).WithMachineName("machine-2").WithTemplateRevision(revision).Build()},
+					}
+				},
+				expectedCondition: func(revision string) metav1.Condition {
+					return metav1.Condition{
+						Type:               conditionTemplateRevision,
+						Status:             metav1.ConditionTrue,
+						Reason:             reasonAllMachinesAtCurrentRevision,
+						Message:            fmt.Sprintf("3 of 3 Machines were created from the current template revision %s", revision),
+						ObservedGeneration: 2,
+					}
+				},
+			}),
+			Entry("when Machines were created from a previous revision, or have no recorded revision", templateRevisionTableInput{
+				machineInfos: func(revision string) map[int32][]machineproviders.MachineInfo {
+					return map[int32][]machineproviders.MachineInfo{
+						0: {
+							machineBuilder.WithIndex(0).WithMachineName("machine-0").WithTemplateRevision("previous").WithNeedsUpdate(true).Build(),
+							machineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithTemplateRevision(revision).WithReady(false).Build(),
+						},
+						1: {machineBuilder.WithIndex(1).WithMachineName("machine-1").WithTemplateRevision("previous").WithNeedsUpdate(true).Build()},
+						2: {machineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+					}
+				},
+				expectedCondition: func(revision string) metav1.Condition {
+					return metav1.Condition{
+						Type:               conditionTemplateRevision,
+						Status:             metav1.ConditionFalse,
+						Reason:             reasonMachinesAtPreviousRevisions,
+						Message:            fmt.Sprintf("1 of 4 Machines were created from the current template revision %s, 2 from a previous revision and 1 with no recorded revision", revision),
+						ObservedGeneration: 2,
+					}
+				},
+			}),
+			Entry("when Machines created from the current revision no longer match the template", templateRevisionTableInput{
+				machineInfos: func(revision string) map[int32][]machineproviders.MachineInfo {
+					return map[int32][]machineproviders.MachineInfo{
+						0: {machineBuilder.WithIndex(0).WithMachineName("machine-0").WithTemplateRevision(revision).Build()},
+						1: {machineBuilder.WithIndex(1).WithMachineName("machine-1").WithTemplateRevision(revision).WithNeedsUpdate(true).Build()},
+						2: {machineBuilder.WithIndex(2).WithMachineName("machine-2").WithTemplateRevision(revision).WithNeedsUpdate(true).Build()},
+					}
+				},
+				expectedCondition: func(revision string) metav1.Condition {
+					return metav1.Condition{
+						Type:               conditionTemplateRevision,
+						Status:             metav1.ConditionTrue,
+						Reason:             reasonAllMachinesAtCurrentRevision,
+						Message:            fmt.Sprintf("3 of 3 Machines were created from the current template revision %s; Machines created from the current revision that no longer match the template: machine-1, machine-2", revision),
+						ObservedGeneration: 2,
+					}
+				},
+			}),
+		)
+	})
+})
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/templaterevisions"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ensureTemplateRevision records the template of the ControlPlaneMachineSet within its template revisions once the
+// template has been applied, that is, once every Control Plane Machine is ready and up to date with the template.
+// The template revisions are kept in a ConfigMap owned by the ControlPlaneMachineSet, so that an applied template
+// can later be restored using the RollbackToRevisionAnnotation.
+func (r *ControlPlaneMachineSetReconciler) ensureTemplateRevision(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineInfos map[int32][]machineproviders.MachineInfo) error {
+	if hasOutdatedMachines(machineInfos) || !isRolloutComplete(cpms) {
+		return nil
+	}
+
+	revision, err := util.TemplateHash(cpms.Spec.Template, annotations.FailureDomainsAnnotationValues(cpms))
+	if err != nil {
+		return fmt.Errorf("error calculating template revision: %w", err)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	configMapKey := client.ObjectKey{Namespace: cpms.Namespace, Name: templaterevisions.ConfigMapName(cpms)}
+
+	configMapNotFound := false
+	if err := r.Get(ctx, configMapKey, configMap); apierrors.IsNotFound(err) {
+		configMapNotFound = true
+	} else if err != nil {
+		return fmt.Errorf("error fetching template revisions: %w", err)
+	}
+
+	revisions, err := templaterevisions.Parse(configMap)
+	if err != nil {
+		// Start over rather than blocking the reconcile, the invalid revisions could not be restored anyway.
+		logger.Error(err, "Ignoring invalid template revisions")
+
+		revisions = nil
+	}
+
+	revisions, changed := templaterevisions.Record(revisions, templaterevisions.TemplateRevision{
+		Revision:    revision,
+		AppliedTime: metav1.NewTime(r.now()),
+		Template:    *cpms.Spec.Template.DeepCopy(),
+
+		FailureDomainsAnnotations: annotations.FailureDomainsAnnotationValues(cpms),
+	})
+	if !changed {
+		return nil
+	}
+
+	value, err := json.Marshal(revisions)
+	if err != nil {
+		return fmt.Errorf("could not marshal template revisions: %w", err)
+	}
+
+	if configMapNotFound {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      configMapKey.Name,
+				Namespace: configMapKey.Namespace,
+			},
+		}
+
+		if err := controllerutil.SetControllerReference(cpms, configMap, r.Scheme); err != nil {
+			return fmt.Errorf("could not set owner reference: %w", err)
+		}
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+
+	configMap.Data[templaterevisions.RevisionsKey] = string(value)
+
+	if configMapNotFound {
+		err = r.Create(ctx, configMap)
+	} else {
+		err = r.Update(ctx, configMap)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error storing template revisions: %w", err)
+	}
+
+	logger.V(2).Info("Recorded applied template revision", "revision", revision)
+
+	return nil
+}
+
+// ensureRollback restores the template revision requested by the RollbackToRevisionAnnotation into the spec of the
+// ControlPlaneMachineSet, and removes the annotation. It returns true when the template has been restored, after
+// which the update strategy rolls out the restored template on subsequent reconciles.
+// When the revision cannot be restored, either because it is unknown, or because the restored template is rejected,
+// a Warning Event is recorded and the annotation is removed, so that the request is not retried.
+func (r *ControlPlaneMachineSetReconciler) ensureRollback(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet) (bool, error) {
+	value, ok := cpms.Annotations[annotations.RollbackToRevisionAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	currentRevision, err := util.TemplateHash(cpms.Spec.Template, annotations.FailureDomainsAnnotationValues(cpms))
+	if err != nil {
+		return false, fmt.Errorf("error calculating template revision: %w", err)
+	}
+
+	revisions, err := templaterevisions.Get(ctx, r.Client, cpms)
+	if err != nil {
+		return false, fmt.Errorf("error fetching template revisions: %w", err)
+	}
+
+	target, err := templaterevisions.Find(revisions, value, currentRevision)
+	if err != nil {
+		return false, r.abandonRollback(ctx, logger, cpms, value, err)
+	}
+
+	// Update a copy so that, should the restored template be rejected, the reconcile carries on with the current template.
+	restored := cpms.DeepCopy()
+	delete(restored.Annotations, annotations.RollbackToRevisionAnnotation)
+	restoreTemplateRevision(restored, target)
+
+	if err := r.Update(ctx, restored); apierrors.IsForbidden(err) || apierrors.IsInvalid(err) {
+		return false, r.abandonRollback(ctx, logger, cpms, value, err)
+	} else if err != nil {
+		return false, fmt.Errorf("error restoring template revision %s: %w", target.Revision, err)
+	}
+
+	restored.DeepCopyInto(cpms)
+
+	logger.V(1).Info("Restored template revision", "revision", target.Revision, "previousRevision", currentRevision)
+	r.recordEventf(cpms, nil, corev1.EventTypeNormal, eventReasonRolledBack,
+		"Restored template revision %s, replacing template revision %s", target.Revision, currentRevision)
+
+	return true, nil
+}
+
+// restoreTemplateRevision sets the template, and the failure domains annotations, of the template revision on the
+// ControlPlaneMachineSet. Failure domains annotations that were not set alongside the template are removed.
+func restoreTemplateRevision(cpms *machinev1.ControlPlaneMachineSet, revision templaterevisions.TemplateRevision) {
+	cpms.Spec.Template = *revision.Template.DeepCopy()
+
+	for _, annotation := range annotations.FailureDomainsAnnotations() {
+		delete(cpms.Annotations, annotation)
+	}
+
+	for annotation, value := range revision.FailureDomainsAnnotations {
+		if cpms.Annotations == nil {
+			cpms.Annotations = map[string]string{}
+		}
+
+		cpms.Annotations[annotation] = value
+	}
+}
+
+// abandonRollback records why the template revision requested by the RollbackToRevisionAnnotation could not be
+// restored, and removes the annotation.
+func (r *ControlPlaneMachineSetReconciler) abandonRollback(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, value string, reason error) error {
+	logger.Error(reason, "Could not restore template revision", "revision", value)
+	r.recordEventf(cpms, nil, corev1.EventTypeWarning, eventReasonFailedRollback,
+		"Could not restore template revision %s: %s", value, reason.Error())
+
+	patchBase := client.MergeFrom(cpms.DeepCopy())
+	delete(cpms.Annotations, annotations.RollbackToRevisionAnnotation)
+
+	if err := r.Patch(ctx, cpms, patchBase); err != nil {
+		return fmt.Errorf("error removing rollback annotation: %w", err)
+	}
+
+	return nil
+}
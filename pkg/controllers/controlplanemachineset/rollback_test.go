@@ -0,0 +1,369 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	machinev1beta1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/templaterevisions"
+	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+)
+
+var _ = Describe("Rollback", func() {
+	var namespaceName string
+	var logger testutils.TestLogger
+	var recorder *record.FakeRecorder
+	var reconciler *ControlPlaneMachineSetReconciler
+	var cpms *machinev1.ControlPlaneMachineSet
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// revisionsConfigMap returns a reference to the ConfigMap holding the template revisions of the cpms.
+	revisionsConfigMap := func() *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      templaterevisions.ConfigMapName(cpms),
+				Namespace: namespaceName,
+			},
+		}
+	}
+
+	// storedRevisions fetches the template revisions kept for the cpms.
+	storedRevisions := func() ([]templaterevisions.TemplateRevision, error) {
+		return templaterevisions.Get(ctx, k8sClient, cpms)
+	}
+
+	// templateRevision builds a template revision for the template.
+	templateRevision := func(template machinev1.ControlPlaneMachineSetTemplate) templaterevisions.TemplateRevision {
+		revision, err := util.TemplateHash(template, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		return templaterevisions.TemplateRevision{
+			Revision:    revision,
+			AppliedTime: metav1.NewTime(now),
+			Template:    template,
+		}
+	}
+
+	// storeRevisions creates the ConfigMap holding the template revisions of the cpms.
+	storeRevisions := func(revisions ...templaterevisions.TemplateRevision) {
+		value, err := json.Marshal(revisions)
+		Expect(err).ToNot(HaveOccurred())
+
+		configMap := revisionsConfigMap()
+		configMap.Data = map[string]string{templaterevisions.RevisionsKey: string(value)}
+		Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+	}
+
+	// previousTemplate returns a copy of the template of the cpms with a different availability zone.
+	previousTemplate := func() machinev1.ControlPlaneMachineSetTemplate {
+		template := *cpms.Spec.Template.DeepCopy()
+		template.OpenShiftMachineV1Beta1Machine.Spec.ProviderSpec.Value = machinev1beta1resourcebuilder.AWSProviderSpec().WithAvailabilityZone("us-east-2").BuildRawExtension()
+
+		return template
+	}
+
+	// recordedEvents drains the Events recorded so far.
+	recordedEvents := func() []string {
+		events := []string{}
+
+		for {
+			select {
+			case event := <-recorder.Events:
+				events = append(events, event)
+			default:
+				return events
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		By("Setting up a namespace for the test")
+		ns := corev1resourcebuilder.Namespace().WithGenerateName("control-plane-machine-set-controller-").Build()
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		namespaceName = ns.GetName()
+
+		By("Setting up the reconciler")
+		logger = testutils.NewTestLogger()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ControlPlaneMachineSetReconciler{
+			Namespace:      namespaceName,
+			Scheme:         testScheme,
+			Client:         k8sClient,
+			UncachedClient: k8sClient,
+			Recorder:       recorder,
+			Clock:          clocktesting.NewFakePassiveClock(now),
+		}
+
+		By("Setting up supporting resources")
+		cpms = machinev1resourcebuilder.ControlPlaneMachineSet().WithNamespace(namespaceName).WithReplicas(3).Build()
+		Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
+			&machinev1.ControlPlaneMachineSet{},
+			&corev1.ConfigMap{},
+		)
+	})
+
+	Context("ensureTemplateRevision", func() {
+		machineGVR := machinev1beta1.GroupVersion.WithResource("machines")
+
+		updatedMachineBuilder := machineprovidersresourcebuilder.MachineInfo().
+			WithMachineGVR(machineGVR).
+			WithReady(true).
+			WithNeedsUpdate(false)
+
+		outdatedMachineBuilder := updatedMachineBuilder.WithNeedsUpdate(true)
+
+		var machineInfos map[int32][]machineproviders.MachineInfo
+
+		BeforeEach(func() {
+			machineInfos = map[int32][]machineproviders.MachineInfo{
+				0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()},
+				1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()},
+				2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").Build()},
+			}
+
+			cpms.Status.Replicas = 3
+			cpms.Status.ReadyReplicas = 3
+			cpms.Status.UpdatedReplicas = 3
+		})
+
+		It("should record the template once it has been applied", func() {
+			Expect(reconciler.ensureTemplateRevision(ctx, logger.Logger(), cpms, machineInfos)).To(Succeed())
+
+			Expect(storedRevisions()).To(ConsistOf(SatisfyAll(
+				HaveField("Revision", templateRevision(cpms.Spec.Template).Revision),
+				// Times are decoded into the Local timezone.
+				HaveField("AppliedTime", Equal(metav1.NewTime(now.Local()))),
+			)))
+		})
+
+		It("should record the failure domains annotations alongside the template", func() {
+			failureDomains := map[string]string{annotations.OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1"}]`}
+			cpms.Annotations = failureDomains
+
+			revision, err := util.TemplateHash(cpms.Spec.Template, failureDomains)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revision).ToNot(Equal(templateRevision(cpms.Spec.Template).Revision), "the failure domains should be part of the revision")
+
+			Expect(reconciler.ensureTemplateRevision(ctx, logger.Logger(), cpms, machineInfos)).To(Succeed())
+
+			Expect(storedRevisions()).To(ConsistOf(SatisfyAll(
+				HaveField("Revision", revision),
+				HaveField("FailureDomainsAnnotations", Equal(failureDomains)),
+			)))
+		})
+
+		It("should make the ControlPlaneMachineSet the owner of the template revisions", func() {
+			Expect(reconciler.ensureTemplateRevision(ctx, logger.Logger(), cpms, machineInfos)).To(Succeed())
+
+			Eventually(komega.Object(revisionsConfigMap())).Should(HaveField("ObjectMeta.OwnerReferences", ConsistOf(SatisfyAll(
+				HaveField("Kind", "ControlPlaneMachineSet"),
+				HaveField("Name", cpms.Name),
+				HaveField("Controller", Equal(pointer.Bool(true))),
+			))))
+		})
+
+		It("should append the template to the existing revisions", func() {
+			previous := templateRevision(previousTemplate())
+			storeRevisions(previous)
+
+			Expect(reconciler.ensureTemplateRevision(ctx, logger.Logger(), cpms, machineInfos)).To(Succeed())
+
+			revisions, err := storedRevisions()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revisions).To(HaveLen(2))
+			Expect(revisions[0].Revision).To(Equal(previous.Revision))
+			Expect(revisions[1].Revision).To(Equal(templateRevision(cpms.Spec.Template).Revision))
+		})
+
+		It("should not record the template while Machines need updating", func() {
+			machineInfos[1] = []machineproviders.MachineInfo{outdatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").Build()}
+
+			Expect(reconciler.ensureTemplateRevision(ctx, logger.Logger(), cpms, machineInfos)).To(Succeed())
+
+			Expect(storedRevisions()).To(BeEmpty())
+		})
+
+		It("should not record the template while Machines are not ready", func() {
+			cpms.Status.ReadyReplicas = 2
+
+			Expect(reconciler.ensureTemplateRevision(ctx, logger.Logger(), cpms, machineInfos)).To(Succeed())
+
+			Expect(storedRevisions()).To(BeEmpty())
+		})
+	})
+
+	Context("ensureRollback", func() {
+		It("should do nothing without the rollback annotation", func() {
+			rolledBack, err := reconciler.ensureRollback(ctx, logger.Logger(), cpms)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rolledBack).To(BeFalse())
+
+			Expect(recordedEvents()).To(BeEmpty())
+		})
+
+		Context("with a known revision", func() {
+			var previous templaterevisions.TemplateRevision
+			var currentRevision string
+
+			BeforeEach(func() {
+				previous = templateRevision(previousTemplate())
+				currentRevision = templateRevision(cpms.Spec.Template).Revision
+				storeRevisions(previous, templateRevision(cpms.Spec.Template))
+
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.RollbackToRevisionAnnotation: annotations.PreviousTemplateRevision}
+				})()).To(Succeed())
+			})
+
+			It("should restore the template and remove the annotation", func() {
+				rolledBack, err := reconciler.ensureRollback(ctx, logger.Logger(), cpms)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rolledBack).To(BeTrue())
+
+				Eventually(komega.Object(cpms)).Should(SatisfyAll(
+					HaveField("ObjectMeta.Annotations", Not(HaveKey(annotations.RollbackToRevisionAnnotation))),
+					HaveField("Spec.Template.OpenShiftMachineV1Beta1Machine.Spec.ProviderSpec.Value.Raw", ContainSubstring(`"availabilityZone":"us-east-2"`)),
+				))
+			})
+
+			It("should record an Event", func() {
+				_, err := reconciler.ensureRollback(ctx, logger.Logger(), cpms)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(recordedEvents()).To(ConsistOf(
+					"Normal RolledBack Restored template revision " + previous.Revision + ", replacing template revision " + currentRevision,
+				))
+			})
+		})
+
+		Context("with a revision applied alongside failure domains annotations", func() {
+			previousFailureDomains := map[string]string{annotations.OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1"}]`}
+			currentFailureDomains := map[string]string{annotations.OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az2"}]`}
+
+			BeforeEach(func() {
+				previous := templateRevision(previousTemplate())
+				previous.FailureDomainsAnnotations = previousFailureDomains
+
+				var err error
+				previous.Revision, err = util.TemplateHash(previous.Template, previousFailureDomains)
+				Expect(err).ToNot(HaveOccurred())
+
+				storeRevisions(previous)
+
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{
+						annotations.RollbackToRevisionAnnotation: previous.Revision,
+						annotations.PausedAnnotation:             "false",
+					}
+
+					for annotation, value := range currentFailureDomains {
+						cpms.Annotations[annotation] = value
+					}
+				})()).To(Succeed())
+			})
+
+			It("should restore the failure domains annotations with the template", func() {
+				rolledBack, err := reconciler.ensureRollback(ctx, logger.Logger(), cpms)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rolledBack).To(BeTrue())
+
+				Eventually(komega.Object(cpms)).Should(SatisfyAll(
+					HaveField("ObjectMeta.Annotations", Equal(map[string]string{
+						annotations.OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1"}]`,
+						annotations.PausedAnnotation:                  "false",
+					})),
+					HaveField("Spec.Template.OpenShiftMachineV1Beta1Machine.Spec.ProviderSpec.Value.Raw", ContainSubstring(`"availabilityZone":"us-east-2"`)),
+				))
+			})
+		})
+
+		Context("with a revision applied without failure domains annotations", func() {
+			BeforeEach(func() {
+				storeRevisions(templateRevision(previousTemplate()))
+
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{
+						annotations.RollbackToRevisionAnnotation:      annotations.PreviousTemplateRevision,
+						annotations.OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az2"}]`,
+					}
+				})()).To(Succeed())
+			})
+
+			It("should remove the failure domains annotations", func() {
+				rolledBack, err := reconciler.ensureRollback(ctx, logger.Logger(), cpms)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rolledBack).To(BeTrue())
+
+				Eventually(komega.Object(cpms)).Should(HaveField("ObjectMeta.Annotations", BeEmpty()))
+			})
+		})
+
+		Context("with an unknown revision", func() {
+			BeforeEach(func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.RollbackToRevisionAnnotation: "abc"}
+				})()).To(Succeed())
+			})
+
+			It("should remove the annotation without changing the template", func() {
+				template := *cpms.Spec.Template.DeepCopy()
+
+				rolledBack, err := reconciler.ensureRollback(ctx, logger.Logger(), cpms)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rolledBack).To(BeFalse())
+
+				Eventually(komega.Object(cpms)).Should(SatisfyAll(
+					HaveField("ObjectMeta.Annotations", Not(HaveKey(annotations.RollbackToRevisionAnnotation))),
+					HaveField("Spec.Template", Equal(template)),
+				))
+			})
+
+			It("should record a Warning Event", func() {
+				_, err := reconciler.ensureRollback(ctx, logger.Logger(), cpms)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(recordedEvents()).To(ConsistOf(
+					"Warning FailedRollback Could not restore template revision abc: unknown template revision: abc",
+				))
+			})
+		})
+	})
+})
@@ -0,0 +1,208 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"fmt"
+	"time"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/maintenancewindow"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// rolloutPaused is a log message used to inform the user that an outdated Machine is not being replaced
+	// because the rollout has been paused.
+	rolloutPaused = "Rollout is paused. Cannot start the replacement of an outdated Machine at this time."
+
+	// rolloutHeldByPartition is a log message used to inform the user that an outdated Machine is not being
+	// replaced because its index is not lower than the rollout partition.
+	rolloutHeldByPartition = "Machine is held by the rollout partition. Cannot start the replacement of an outdated Machine at this time."
+
+	// rolloutOutsideMaintenanceWindow is a log message used to inform the user that an outdated Machine is not being
+	// replaced because the maintenance window is closed.
+	rolloutOutsideMaintenanceWindow = "Maintenance window is closed. Cannot start the replacement of an outdated Machine at this time."
+)
+
+// rolloutControls holds the user provided controls that restrict when the automated update strategies may start
+// the replacement of an outdated Machine.
+// These controls never prevent a replacement that is already in progress from completing, and never prevent the
+// replacement of a Machine that has been deleted, or of a missing Machine, so that control plane capacity is restored.
+// Only pausing the rollout prevents the evacuation of a Machine within an unavailable failure domain.
+type rolloutControls struct {
+	// paused prevents the replacement of any outdated Machine from being started.
+	paused bool
+
+	// partition, when set, prevents the replacement of outdated Machines in any index equal to or greater than
+	// the partition.
+	partition *int32
+
+	// window, when set, prevents the replacement of any outdated Machine from being started while the maintenance
+	// window is closed.
+	window *maintenancewindow.Window
+
+	// now is the time at which the maintenance window is evaluated.
+	now time.Time
+
+	// order determines the order in which the indexes are considered for replacement.
+	order indexOrderPolicy
+}
+
+// newRolloutControls parses the rollout controls from the annotations on the ControlPlaneMachineSet.
+// The leaderLocator is used by replacement order policies that depend on the location of the etcd leader, and now is
+// the time at which the maintenance window, if configured, is evaluated.
+func newRolloutControls(cpms *machinev1.ControlPlaneMachineSet, leaderLocator EtcdLeaderLocator, now time.Time) (rolloutControls, error) {
+	paused, err := annotations.Paused(cpms)
+	if err != nil {
+		return rolloutControls{}, fmt.Errorf("failed to parse rollout controls: %w", err)
+	}
+
+	controls := rolloutControls{
+		paused: paused,
+		now:    now,
+	}
+
+	partition, partitioned, err := annotations.Partition(cpms)
+	if err != nil {
+		return rolloutControls{}, fmt.Errorf("failed to parse rollout controls: %w", err)
+	}
+
+	if partitioned {
+		controls.partition = &partition
+	}
+
+	window, hasWindow, err := annotations.MaintenanceWindow(cpms)
+	if err != nil {
+		return rolloutControls{}, fmt.Errorf("failed to parse rollout controls: %w", err)
+	}
+
+	if hasWindow {
+		controls.window = &window
+	}
+
+	controls.order, err = newIndexOrderPolicy(cpms, leaderLocator)
+	if err != nil {
+		return rolloutControls{}, err
+	}
+
+	return controls, nil
+}
+
+// holdReplacement returns a message explaining why the replacement of the Machine must not be started, or an empty
+// string when the replacement may be started.
+func (c rolloutControls) holdReplacement(machine machineproviders.MachineInfo) string {
+	if isDeletedMachine(machine) {
+		// Deleted Machines must always be replaced to restore the control plane capacity.
+		return ""
+	}
+
+	if c.paused {
+		return rolloutPaused
+	}
+
+	if machine.Evacuating {
+		// Machines within unavailable failure domains are evacuated as soon as possible, but only while the
+		// rollout is not paused.
+		return ""
+	}
+
+	if c.window != nil && !c.window.IsOpen(c.now) {
+		return rolloutOutsideMaintenanceWindow
+	}
+
+	if c.partition != nil && machine.Index >= *c.partition {
+		return rolloutHeldByPartition
+	}
+
+	return ""
+}
+
+// maintenanceWindowResult returns a result that requeues the ControlPlaneMachineSet for the time at which the
+// maintenance window next opens, when any outdated Machine is held because the maintenance window is closed.
+func (c rolloutControls) maintenanceWindowResult(mis []indexToMachineInfos) ctrl.Result {
+	for _, indexToMachines := range mis {
+		for _, machineInfo := range indexToMachines.machineInfos {
+			if machineInfo.NeedsUpdate && c.holdReplacement(machineInfo) == rolloutOutsideMaintenanceWindow {
+				return ctrl.Result{RequeueAfter: c.window.NextOpening(c.now).Sub(c.now)}
+			}
+		}
+	}
+
+	return ctrl.Result{}
+}
+
+// rolloutHolds summarises the outdated Machines that the automated update strategy of the ControlPlaneMachineSet
+// will not start replacing because of the rollout controls.
+type rolloutHolds struct {
+	// paused reports whether the rollout has been paused. When paused, no other holds are counted.
+	paused bool
+
+	// heldByMaintenanceWindow is the number of outdated Machines held because the maintenance window is closed.
+	heldByMaintenanceWindow int32
+
+	// nextWindowOpening is the time at which the maintenance window next opens.
+	// It is only set when Machines are held by the maintenance window.
+	nextWindowOpening time.Time
+
+	// heldByPartition is the number of outdated Machines held by the rollout partition.
+	heldByPartition int32
+}
+
+// getRolloutHolds determines which outdated Machines the automated update strategy of the ControlPlaneMachineSet
+// will not start replacing at the given time.
+// The OnDelete strategy is driven by the user, so the rollout controls do not apply to it.
+// An invalid configuration is reported as degraded by the update strategy, so no Machines are considered held here.
+func getRolloutHolds(cpms *machinev1.ControlPlaneMachineSet, machineInfosByIndex map[int32][]machineproviders.MachineInfo, now time.Time) rolloutHolds {
+	if cpms.Spec.Strategy.Type == machinev1.OnDelete {
+		return rolloutHolds{}
+	}
+
+	controls, err := newRolloutControls(cpms, nil, now)
+	if err != nil {
+		return rolloutHolds{}
+	}
+
+	if controls.paused {
+		return rolloutHolds{paused: true}
+	}
+
+	holds := rolloutHolds{}
+
+	for _, machineInfos := range machineInfosByIndex {
+		for _, machineInfo := range machineInfos {
+			if !machineInfo.NeedsUpdate {
+				continue
+			}
+
+			switch controls.holdReplacement(machineInfo) {
+			case rolloutOutsideMaintenanceWindow:
+				holds.heldByMaintenanceWindow++
+			case rolloutHeldByPartition:
+				holds.heldByPartition++
+			}
+		}
+	}
+
+	if holds.heldByMaintenanceWindow > 0 {
+		holds.nextWindowOpening = controls.window.NextOpening(now)
+	}
+
+	return holds
+}
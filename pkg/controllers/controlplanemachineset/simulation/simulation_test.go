@@ -0,0 +1,316 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	machinev1 "github.com/openshift/api/machine/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	configv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/controllers/controlplanemachineset"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	fakeprovider "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/fake"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Rollout simulation", func() {
+	const operatorName = "control-plane-machine-set"
+
+	const (
+		conditionDegraded    = "Degraded"
+		conditionProgressing = "Progressing"
+	)
+
+	// maxSimulationSteps bounds each simulation so that a stalled rollout fails the test rather than hanging.
+	const maxSimulationSteps = 50
+
+	var cl client.Client
+	var reconciler *controlplanemachineset.ControlPlaneMachineSetReconciler
+	var provider *fakeprovider.MachineProvider
+	var request ctrl.Request
+
+	// setupSimulation creates the control plane machine set, with an existing machine in each index,
+	// and a reconciler that uses the simulated machine provider.
+	setupSimulation := func(cpmsBuilder machinev1resourcebuilder.ControlPlaneMachineSetBuilder) {
+		cpms := cpmsBuilder.Build()
+		cpms.UID = "cpms-uid"
+
+		provider = fakeprovider.NewMachineProvider(cpms)
+		for i := int32(0); i < *cpms.Spec.Replicas; i++ {
+			provider.AddMachine(i)
+		}
+
+		cl = fakeclient.NewClientBuilder().
+			WithScheme(testScheme).
+			WithObjects(cpms, configv1resourcebuilder.ClusterOperator().WithName(operatorName).Build()).
+			Build()
+
+		restMapper := meta.NewDefaultRESTMapper(nil)
+		restMapper.Add(machinev1beta1.GroupVersion.WithKind("Machine"), meta.RESTScopeNamespace)
+
+		reconciler = &controlplanemachineset.ControlPlaneMachineSetReconciler{
+			Client:         cl,
+			UncachedClient: cl,
+			Scheme:         testScheme,
+			RESTMapper:     restMapper,
+			Namespace:      cpms.Namespace,
+			OperatorName:   operatorName,
+			NewMachineProvider: func(context.Context, logr.Logger, client.Client, *machinev1.ControlPlaneMachineSet) (machineproviders.MachineProvider, error) {
+				return provider, nil
+			},
+		}
+
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cpms)}
+	}
+
+	// simulate alternates between reconciling the control plane machine set and stepping the simulation
+	// until the condition is met. Before each step, the hook, when set, is called to act on the simulated machines,
+	// as another component in the cluster would. Reconcile errors are returned for the caller to inspect.
+	simulate := func(condition func() bool, hook func()) []error {
+		var errs []error
+
+		for step := 0; step < maxSimulationSteps; step++ {
+			if _, err := reconciler.Reconcile(ctx, request); err != nil {
+				errs = append(errs, err)
+			}
+
+			if condition() {
+				return errs
+			}
+
+			if hook != nil {
+				hook()
+			}
+
+			provider.Step()
+		}
+
+		Fail("simulation did not reach the expected state")
+
+		return errs
+	}
+
+	// getCPMS fetches the latest version of the control plane machine set.
+	getCPMS := func() *machinev1.ControlPlaneMachineSet {
+		cpms := &machinev1.ControlPlaneMachineSet{}
+		Expect(cl.Get(ctx, request.NamespacedName, cpms)).To(Succeed())
+
+		return cpms
+	}
+
+	// rolloutComplete is true when each index has a single, Ready machine created from the latest template revision.
+	rolloutComplete := func(revision string) func() bool {
+		return func() bool {
+			machines := provider.Machines()
+			indexes := map[int32]bool{}
+
+			for _, machine := range machines {
+				if machine.TemplateRevision != revision || machine.Phase != fakeprovider.PhaseRunning || machine.DeletionTimestamp != nil {
+					return false
+				}
+
+				indexes[machine.Index] = true
+			}
+
+			return len(machines) == len(indexes) && int32(len(indexes)) == *getCPMS().Spec.Replicas
+		}
+	}
+
+	// releaseEtcdHooks simulates the etcd operator, which releases the deletion hook on a deleted machine
+	// once there is a Ready replacement in the same index.
+	releaseEtcdHooks := func() {
+		machines := provider.Machines()
+
+		for _, deleted := range machines {
+			if deleted.DeletionTimestamp == nil {
+				continue
+			}
+
+			for _, replacement := range machines {
+				if replacement.Index == deleted.Index && replacement.DeletionTimestamp == nil && replacement.Phase == fakeprovider.PhaseRunning {
+					Expect(provider.RemoveFinalizers(deleted.Name)).To(Succeed())
+				}
+			}
+		}
+	}
+
+	// maxMachines returns a hook that records the highest number of machines observed during the simulation.
+	maxMachines := func(observed *int) func() {
+		return func() {
+			if count := len(provider.Machines()); count > *observed {
+				*observed = count
+			}
+		}
+	}
+
+	Context("with the RollingUpdate strategy", func() {
+		BeforeEach(func() {
+			setupSimulation(machinev1resourcebuilder.ControlPlaneMachineSet().WithStrategyType(machinev1.RollingUpdate))
+			provider.SetFinalizers("etcd-quorum-operator")
+
+			By("Reconciling the existing machines")
+			Expect(simulate(func() bool {
+				return getCPMS().Status.ReadyReplicas == 3
+			}, nil)).To(BeEmpty())
+
+			provider.SetTemplateRevision("2")
+		})
+
+		It("replaces each index in turn, waiting for each replacement to become ready", func() {
+			observedMachines := 0
+
+			Expect(simulate(rolloutComplete("2"), func() {
+				maxMachines(&observedMachines)()
+				releaseEtcdHooks()
+			})).To(BeEmpty())
+
+			Expect(observedMachines).To(Equal(4), "only a single index should be surged at any one time")
+			Expect(provider.Actions()).To(Equal([]fakeprovider.Action{
+				{Type: fakeprovider.ActionCreate, Index: 0, Name: "machine-0-3"},
+				{Type: fakeprovider.ActionDelete, Index: 0, Name: "machine-0-0"},
+				{Type: fakeprovider.ActionCreate, Index: 1, Name: "machine-1-4"},
+				{Type: fakeprovider.ActionDelete, Index: 1, Name: "machine-1-1"},
+				{Type: fakeprovider.ActionCreate, Index: 2, Name: "machine-2-5"},
+				{Type: fakeprovider.ActionDelete, Index: 2, Name: "machine-2-2"},
+			}))
+
+			Expect(simulate(func() bool {
+				return getCPMS().Status.UpdatedReplicas == 3
+			}, nil)).To(BeEmpty())
+		})
+
+		It("does not remove an outdated machine until its replacement has provisioned", func() {
+			provider.SetProvisioningSteps(5)
+
+			Expect(simulate(func() bool {
+				return len(provider.Actions()) > 0
+			}, nil)).To(BeEmpty())
+
+			for i := 0; i < 4; i++ {
+				_, err := reconciler.Reconcile(ctx, request)
+				Expect(err).ToNot(HaveOccurred())
+
+				provider.Step()
+			}
+
+			Expect(provider.Actions()).To(ConsistOf(
+				fakeprovider.Action{Type: fakeprovider.ActionCreate, Index: 0, Name: "machine-0-3"},
+			))
+
+			Expect(simulate(rolloutComplete("2"), releaseEtcdHooks)).To(BeEmpty())
+		})
+
+		It("stops the rollout when a replacement fails to provision, and resumes once the failed machine is removed", func() {
+			provider.FailNextProvisioning(0, "insufficient capacity in availability zone")
+
+			By("Waiting for the replacement machine to fail")
+			Expect(simulate(func() bool {
+				return meta.IsStatusConditionTrue(getCPMS().Status.Conditions, conditionDegraded)
+			}, nil)).To(BeEmpty())
+
+			Expect(provider.Actions()).To(ConsistOf(
+				fakeprovider.Action{Type: fakeprovider.ActionCreate, Index: 0, Name: "machine-0-3"},
+			))
+
+			By("Removing the failed machine")
+			Expect(provider.DeleteMachineByName("machine-0-3")).To(Succeed())
+			Expect(provider.RemoveFinalizers("machine-0-3")).To(Succeed())
+
+			Expect(simulate(rolloutComplete("2"), releaseEtcdHooks)).To(BeEmpty())
+			Expect(meta.IsStatusConditionTrue(getCPMS().Status.Conditions, conditionDegraded)).To(BeFalse())
+		})
+
+		It("retries when creating a replacement fails", func() {
+			errQuota := errors.New("quota exceeded")
+			provider.FailNextCreate(1, errQuota)
+
+			errs := simulate(rolloutComplete("2"), releaseEtcdHooks)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0]).To(MatchError(errQuota))
+		})
+	})
+
+	Context("with the Recreate strategy", func() {
+		BeforeEach(func() {
+			setupSimulation(machinev1resourcebuilder.ControlPlaneMachineSet().WithStrategyType(machinev1.Recreate))
+
+			Expect(simulate(func() bool {
+				return getCPMS().Status.ReadyReplicas == 3
+			}, nil)).To(BeEmpty())
+
+			provider.SetTemplateRevision("2")
+		})
+
+		It("removes each outdated machine before creating its replacement", func() {
+			observedMachines := 0
+
+			Expect(simulate(rolloutComplete("2"), maxMachines(&observedMachines))).To(BeEmpty())
+
+			Expect(observedMachines).To(Equal(3), "no index should be surged")
+			Expect(provider.Actions()).To(Equal([]fakeprovider.Action{
+				{Type: fakeprovider.ActionDelete, Index: 0, Name: "machine-0-0"},
+				{Type: fakeprovider.ActionCreate, Index: 0, Name: "machine-0-3"},
+				{Type: fakeprovider.ActionDelete, Index: 1, Name: "machine-1-1"},
+				{Type: fakeprovider.ActionCreate, Index: 1, Name: "machine-1-4"},
+				{Type: fakeprovider.ActionDelete, Index: 2, Name: "machine-2-2"},
+				{Type: fakeprovider.ActionCreate, Index: 2, Name: "machine-2-5"},
+			}))
+		})
+	})
+
+	Context("with the OnDelete strategy", func() {
+		BeforeEach(func() {
+			setupSimulation(machinev1resourcebuilder.ControlPlaneMachineSet().WithStrategyType(machinev1.OnDelete))
+			provider.SetFinalizers("etcd-quorum-operator")
+
+			Expect(simulate(func() bool {
+				return getCPMS().Status.ReadyReplicas == 3
+			}, nil)).To(BeEmpty())
+
+			provider.SetTemplateRevision("2")
+		})
+
+		It("only replaces the machines that are deleted", func() {
+			Expect(provider.DeleteMachineByName("machine-1-1")).To(Succeed())
+
+			Expect(simulate(func() bool {
+				return len(provider.Machines()) == 3 && getCPMS().Status.UpdatedReplicas == 1
+			}, releaseEtcdHooks)).To(BeEmpty())
+
+			Expect(provider.Actions()).To(ConsistOf(
+				fakeprovider.Action{Type: fakeprovider.ActionCreate, Index: 1, Name: "machine-1-3"},
+			))
+
+			Expect(getCPMS().Status.Conditions).To(ContainElement(SatisfyAll(
+				HaveField("Type", conditionProgressing),
+				HaveField("Status", metav1.ConditionTrue),
+			)))
+		})
+	})
+})
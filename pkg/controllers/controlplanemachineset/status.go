@@ -19,12 +19,17 @@ package controlplanemachineset
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -42,11 +47,18 @@ const (
 
 	// notUpdatingStatus is a log message used to inform users that the ControlPlaneMachineSet status is not being updated.
 	notUpdatingStatus = "No update to control plane machine set status required"
+
+	// maxSummarizedFields is the maximum number of changed fields listed within the Progressing condition.
+	// The full list of changes for each Machine is recorded on the Machine itself.
+	maxSummarizedFields = 5
 )
 
-// updateControlPlaneMachineSetStatus ensures that the status of the ControlPlaneMachineSet is up to date after
-// the resource has been reconciled.
+// updateControlPlaneMachineSetStatus ensures that the status, and the rollout history, of the ControlPlaneMachineSet
+// are up to date after the resource has been reconciled.
 func (r *ControlPlaneMachineSetReconciler) updateControlPlaneMachineSetStatus(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, patchBase client.Patch) error {
+	// Take the rollout history before the status update, which resets the annotations to their stored values.
+	rolloutHistory, hasRolloutHistory := cpms.Annotations[annotations.RolloutHistoryAnnotation]
+
 	data, err := patchBase.Data(cpms)
 	if err != nil {
 		return fmt.Errorf("cannot calculate patch data from control plane machine set object: %w", err)
@@ -65,6 +77,12 @@ func (r *ControlPlaneMachineSetReconciler) updateControlPlaneMachineSetStatus(ct
 
 	logger.V(3).Info(updatingStatus, "data", string(data))
 
+	if hasRolloutHistory {
+		if err := r.ensureRolloutHistory(ctx, logger, cpms, rolloutHistory); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -79,7 +97,7 @@ func (r *ControlPlaneMachineSetReconciler) updateControlPlaneMachineSetStatus(ct
 //   - UnavailableReplicas is the number of Machines required to satisfy the requirement of at least 1 Ready Replica per
 //     index. Eg. if one index has no ready replicas, this is 1, if an index has 2 ready replicas, this does not count as
 //     2 available replicas.
-func reconcileStatusWithMachineInfo(logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineInfosByIndex map[int32][]machineproviders.MachineInfo) error {
+func reconcileStatusWithMachineInfo(logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineInfosByIndex map[int32][]machineproviders.MachineInfo, now time.Time) error {
 	replicas := int32(0)
 	readyReplicas := int32(0)
 	updatedReplicas := int32(0)
@@ -124,7 +142,7 @@ func reconcileStatusWithMachineInfo(logger logr.Logger, cpms *machinev1.ControlP
 		"unavailableReplicas", cpms.Status.UnavailableReplicas,
 	)
 
-	if err := setConditions(cpms); err != nil {
+	if err := setConditions(cpms, getRolloutHolds(cpms, machineInfosByIndex, now), summarizeChangedFields(machineInfosByIndex)); err != nil {
 		return fmt.Errorf("could not set control plane machine set conditions: %w", err)
 	}
 
@@ -132,14 +150,16 @@ func reconcileStatusWithMachineInfo(logger logr.Logger, cpms *machinev1.ControlP
 }
 
 // setConditions sets Available, Degraded and Progressing conditions on the ControlPlaneMachineSet.
-func setConditions(cpms *machinev1.ControlPlaneMachineSet) error {
+// The holds describe the replicas in need of update that are held back by the rollout controls,
+// and the changedFields summarise the fields that caused the replicas to need an update.
+func setConditions(cpms *machinev1.ControlPlaneMachineSet, holds rolloutHolds, changedFields string) error {
 	availableCondition := getAvailableCondition(cpms)
 	meta.SetStatusCondition(&cpms.Status.Conditions, availableCondition)
 
 	degradedCondition := getDegradedCondition(cpms)
 	meta.SetStatusCondition(&cpms.Status.Conditions, degradedCondition)
 
-	progressingCondition, err := getProgressingCondition(cpms)
+	progressingCondition, err := getProgressingCondition(cpms, holds, changedFields)
 	if err != nil {
 		return fmt.Errorf("could not set progressing condition: %w", err)
 	}
@@ -209,19 +229,45 @@ func getErrorCondition(cpms *machinev1.ControlPlaneMachineSet, lastError *lastEr
 }
 
 // getProgressingCondition computes Progressing condition based on the current ControlPlaneMachineSet status.
-func getProgressingCondition(cpms *machinev1.ControlPlaneMachineSet) (metav1.Condition, error) {
+// When replicas need an update, the summary of the changed fields is appended to the message.
+func getProgressingCondition(cpms *machinev1.ControlPlaneMachineSet, holds rolloutHolds, changedFields string) (metav1.Condition, error) {
 	if cpms.Spec.Replicas == nil {
 		return metav1.Condition{}, errReplicasRequired
 	}
 
 	desiredReplicas := *cpms.Spec.Replicas
 
+	if holds.paused && desiredReplicas > cpms.Status.UpdatedReplicas {
+		return metav1.Condition{
+			Type:               conditionProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             reasonPaused,
+			Message:            withChangedFields(fmt.Sprintf("Rollout is paused with %d replica(s) in need of update", desiredReplicas-cpms.Status.UpdatedReplicas), changedFields),
+			ObservedGeneration: cpms.Generation,
+		}, nil
+	}
+
+	if holds.heldByMaintenanceWindow > 0 && desiredReplicas > cpms.Status.UpdatedReplicas {
+		return metav1.Condition{
+			Type:               conditionProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             reasonOutsideMaintenanceWindow,
+			Message:            withChangedFields(fmt.Sprintf("Observed %d replica(s) in need of update, the maintenance window next opens at %s", desiredReplicas-cpms.Status.UpdatedReplicas, holds.nextWindowOpening.Format(time.RFC3339)), changedFields),
+			ObservedGeneration: cpms.Generation,
+		}, nil
+	}
+
 	if desiredReplicas > cpms.Status.UpdatedReplicas {
+		message := fmt.Sprintf("Observed %d replica(s) in need of update", desiredReplicas-cpms.Status.UpdatedReplicas)
+		if holds.heldByPartition > 0 {
+			message = fmt.Sprintf("%s, %d replica(s) held by the rollout partition", message, holds.heldByPartition)
+		}
+
 		return metav1.Condition{
 			Type:               conditionProgressing,
 			Status:             metav1.ConditionTrue,
 			Reason:             reasonNeedsUpdateReplicas,
-			Message:            fmt.Sprintf("Observed %d replica(s) in need of update", desiredReplicas-cpms.Status.UpdatedReplicas),
+			Message:            withChangedFields(message, changedFields),
 			ObservedGeneration: cpms.Generation,
 		}, nil
 	}
@@ -243,3 +289,72 @@ func getProgressingCondition(cpms *machinev1.ControlPlaneMachineSet) (metav1.Con
 		ObservedGeneration: cpms.Generation,
 	}, nil
 }
+
+// summarizeChangedFields lists the paths of the fields that differ from the desired spec across all Machines
+// that need an update. The list is sorted, and is truncated once it reaches maxSummarizedFields entries.
+// An empty string is returned when no changed fields are known.
+func summarizeChangedFields(machineInfosByIndex map[int32][]machineproviders.MachineInfo) string {
+	paths := sets.New[string]()
+
+	for _, machineInfos := range machineInfosByIndex {
+		for _, machineInfo := range machineInfos {
+			if !machineInfo.NeedsUpdate {
+				continue
+			}
+
+			for _, fieldDiff := range machineInfo.Diff {
+				paths.Insert(fieldDiff.Path)
+			}
+		}
+	}
+
+	if paths.Len() == 0 {
+		return ""
+	}
+
+	sortedPaths := sets.List(paths)
+	if len(sortedPaths) <= maxSummarizedFields {
+		return strings.Join(sortedPaths, ", ")
+	}
+
+	return fmt.Sprintf("%s and %d more", strings.Join(sortedPaths[:maxSummarizedFields], ", "), len(sortedPaths)-maxSummarizedFields)
+}
+
+// withChangedFields appends the summary of the changed fields to a condition message.
+func withChangedFields(message, changedFields string) string {
+	if changedFields == "" {
+		return message
+	}
+
+	return fmt.Sprintf("%s; changed fields: %s", message, changedFields)
+}
+
+// setFailureDomainMappingCondition reports the failure domain that each index is mapped to on the ControlPlaneMachineSet,
+// along with the reason for each assignment. The condition is removed when no failure domains are mapped.
+func setFailureDomainMappingCondition(cpms *machinev1.ControlPlaneMachineSet, mapping map[int32]machineproviders.FailureDomainMapping) {
+	if len(mapping) == 0 {
+		meta.RemoveStatusCondition(&cpms.Status.Conditions, conditionFailureDomainMapping)
+
+		return
+	}
+
+	indexes := []int32{}
+	for idx := range mapping {
+		indexes = append(indexes, idx)
+	}
+
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	entries := []string{}
+	for _, idx := range indexes {
+		entries = append(entries, fmt.Sprintf("%d: %s (%s)", idx, mapping[idx].FailureDomain, mapping[idx].Reason))
+	}
+
+	meta.SetStatusCondition(&cpms.Status.Conditions, metav1.Condition{
+		Type:               conditionFailureDomainMapping,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonFailureDomainsMapped,
+		Message:            fmt.Sprintf("Indexes are mapped to failure domains: %s", strings.Join(entries, "; ")),
+		ObservedGeneration: cpms.Generation,
+	})
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package controlplanemachineset
 
 import (
+	"fmt"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -25,6 +28,7 @@ import (
 	"github.com/openshift/cluster-api-actuator-pkg/testutils"
 	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
 	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
 	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
 	corev1 "k8s.io/api/core/v1"
@@ -113,6 +117,26 @@ var _ = Describe("Status", func() {
 			})
 		})
 
+		Context("when the rollout history has changed", func() {
+			rolloutHistory := `[{"templateHash":"abc","startTime":"2023-06-01T10:00:00Z","indexes":[0],"oldMachines":["machine-0"],"outcome":"InProgress"}]`
+
+			BeforeEach(func() {
+				cpms.Status.ObservedGeneration = 2
+				cpms.Annotations = map[string]string{annotations.RolloutHistoryAnnotation: rolloutHistory}
+
+				// Use a DeepCopy of the CPMS to avoid any reflection from the update affecting the test cases.
+				Expect(reconciler.updateControlPlaneMachineSetStatus(ctx, logger.Logger(), cpms.DeepCopy(), patchBase)).To(Succeed())
+			})
+
+			It("updates the status on the API", func() {
+				Eventually(komega.Object(cpms)).Should(HaveField("Status.ObservedGeneration", int64(2)))
+			})
+
+			It("records the rollout history on the API", func() {
+				Eventually(komega.Object(cpms)).Should(HaveField("ObjectMeta.Annotations", HaveKeyWithValue(annotations.RolloutHistoryAnnotation, rolloutHistory)))
+			})
+		})
+
 		Context("when the status has not changed", func() {
 			BeforeEach(func() {
 				// Use different values to what is set on the API, but a different patch base to prove
@@ -149,6 +173,7 @@ var _ = Describe("Status", func() {
 	Context("reconcileStatusWithMachineInfo", func() {
 		type reconcileStatusTableInput struct {
 			cpmsBuilder    machinev1resourcebuilder.ControlPlaneMachineSetInterface
+			annotations    map[string]string
 			machineInfos   map[int32][]machineproviders.MachineInfo
 			expectedError  error
 			expectedStatus machinev1.ControlPlaneMachineSetStatus
@@ -169,6 +194,9 @@ var _ = Describe("Status", func() {
 			WithReady(false).
 			WithNeedsUpdate(false)
 
+		// now is a Wednesday, used to evaluate maintenance windows.
+		now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+
 		// If a node is removed from the cloud provider, the machine should report an error
 		// and the error should be propogated to the MachineInfo so that the controller
 		// can handle this error state.
@@ -178,8 +206,11 @@ var _ = Describe("Status", func() {
 		DescribeTable("correctly sets the status based on the machine info", func(in *reconcileStatusTableInput) {
 			logger := testutils.NewTestLogger()
 			cpms := in.cpmsBuilder.Build()
+			if in.annotations != nil {
+				cpms.Annotations = in.annotations
+			}
 
-			err := reconcileStatusWithMachineInfo(logger.Logger(), cpms, in.machineInfos)
+			err := reconcileStatusWithMachineInfo(logger.Logger(), cpms, in.machineInfos, now)
 			if in.expectedError != nil {
 				Expect(err).To(MatchError(ContainSubstring(in.expectedError.Error())))
 				return
@@ -293,6 +324,322 @@ var _ = Describe("Status", func() {
 					},
 				},
 			}),
+			Entry("when Machines need updates with known changes", &reconcileStatusTableInput{
+				cpmsBuilder: machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithDiff(
+						machineproviders.FieldDiff{Path: "spec.providerSpec.value.instanceType", Old: `"m6i.xlarge"`, New: `"m6i.2xlarge"`},
+					).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).WithDiff(
+						machineproviders.FieldDiff{Path: "spec.providerSpec.value.instanceType", Old: `"m6i.xlarge"`, New: `"m6i.2xlarge"`},
+						machineproviders.FieldDiff{Path: "spec.providerSpec.value.placement.availabilityZone", Old: `"us-east-1a"`, New: `"us-east-1b"`},
+					).Build()},
+				},
+				expectedError: nil,
+				expectedStatus: machinev1.ControlPlaneMachineSetStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               conditionAvailable,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonAllReplicasAvailable,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionDegraded,
+							Status:             metav1.ConditionFalse,
+							Reason:             reasonAsExpected,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionProgressing,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonNeedsUpdateReplicas,
+							ObservedGeneration: 2,
+							Message:            "Observed 2 replica(s) in need of update; changed fields: spec.providerSpec.value.instanceType, spec.providerSpec.value.placement.availabilityZone",
+						},
+					},
+					ObservedGeneration:  2,
+					Replicas:            3,
+					ReadyReplicas:       3,
+					UpdatedReplicas:     1,
+					UnavailableReplicas: 0,
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"observedGeneration", "2",
+							"replicas", "3",
+							"readyReplicas", "3",
+							"updatedReplicas", "1",
+							"unavailableReplicas", "0",
+						},
+						Message: "Observed Machine Configuration",
+					},
+				},
+			}),
+			Entry("when Machines need updates and the rollout is paused", &reconcileStatusTableInput{
+				cpmsBuilder: machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2),
+				annotations: map[string]string{annotations.PausedAnnotation: "true"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+				},
+				expectedError: nil,
+				expectedStatus: machinev1.ControlPlaneMachineSetStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               conditionAvailable,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonAllReplicasAvailable,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionDegraded,
+							Status:             metav1.ConditionFalse,
+							Reason:             reasonAsExpected,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionProgressing,
+							Status:             metav1.ConditionFalse,
+							Reason:             reasonPaused,
+							ObservedGeneration: 2,
+							Message:            "Rollout is paused with 2 replica(s) in need of update",
+						},
+					},
+					ObservedGeneration:  2,
+					Replicas:            3,
+					ReadyReplicas:       3,
+					UpdatedReplicas:     1,
+					UnavailableReplicas: 0,
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"observedGeneration", "2",
+							"replicas", "3",
+							"readyReplicas", "3",
+							"updatedReplicas", "1",
+							"unavailableReplicas", "0",
+						},
+						Message: "Observed Machine Configuration",
+					},
+				},
+			}),
+			Entry("when Machines need updates and are held by the rollout partition", &reconcileStatusTableInput{
+				cpmsBuilder: machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2),
+				annotations: map[string]string{annotations.PartitionAnnotation: "1"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+				},
+				expectedError: nil,
+				expectedStatus: machinev1.ControlPlaneMachineSetStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               conditionAvailable,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonAllReplicasAvailable,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionDegraded,
+							Status:             metav1.ConditionFalse,
+							Reason:             reasonAsExpected,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionProgressing,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonNeedsUpdateReplicas,
+							ObservedGeneration: 2,
+							Message:            "Observed 2 replica(s) in need of update, 2 replica(s) held by the rollout partition",
+						},
+					},
+					ObservedGeneration:  2,
+					Replicas:            3,
+					ReadyReplicas:       3,
+					UpdatedReplicas:     1,
+					UnavailableReplicas: 0,
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"observedGeneration", "2",
+							"replicas", "3",
+							"readyReplicas", "3",
+							"updatedReplicas", "1",
+							"unavailableReplicas", "0",
+						},
+						Message: "Observed Machine Configuration",
+					},
+				},
+			}),
+			Entry("when Machines need updates and the maintenance window is closed", &reconcileStatusTableInput{
+				cpmsBuilder: machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2),
+				annotations: map[string]string{
+					annotations.MaintenanceWindowScheduleAnnotation: "0 22 * * *",
+					annotations.MaintenanceWindowDurationAnnotation: "4h",
+				},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+				},
+				expectedError: nil,
+				expectedStatus: machinev1.ControlPlaneMachineSetStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               conditionAvailable,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonAllReplicasAvailable,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionDegraded,
+							Status:             metav1.ConditionFalse,
+							Reason:             reasonAsExpected,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionProgressing,
+							Status:             metav1.ConditionFalse,
+							Reason:             reasonOutsideMaintenanceWindow,
+							ObservedGeneration: 2,
+							Message:            "Observed 2 replica(s) in need of update, the maintenance window next opens at 2023-03-01T22:00:00Z",
+						},
+					},
+					ObservedGeneration:  2,
+					Replicas:            3,
+					ReadyReplicas:       3,
+					UpdatedReplicas:     1,
+					UnavailableReplicas: 0,
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"observedGeneration", "2",
+							"replicas", "3",
+							"readyReplicas", "3",
+							"updatedReplicas", "1",
+							"unavailableReplicas", "0",
+						},
+						Message: "Observed Machine Configuration",
+					},
+				},
+			}),
+			Entry("when Machines need updates and the maintenance window is open", &reconcileStatusTableInput{
+				cpmsBuilder: machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2),
+				annotations: map[string]string{
+					annotations.MaintenanceWindowScheduleAnnotation: "0 10 * * *",
+					annotations.MaintenanceWindowDurationAnnotation: "4h",
+				},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+				},
+				expectedError: nil,
+				expectedStatus: machinev1.ControlPlaneMachineSetStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               conditionAvailable,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonAllReplicasAvailable,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionDegraded,
+							Status:             metav1.ConditionFalse,
+							Reason:             reasonAsExpected,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionProgressing,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonNeedsUpdateReplicas,
+							ObservedGeneration: 2,
+							Message:            "Observed 2 replica(s) in need of update",
+						},
+					},
+					ObservedGeneration:  2,
+					Replicas:            3,
+					ReadyReplicas:       3,
+					UpdatedReplicas:     1,
+					UnavailableReplicas: 0,
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"observedGeneration", "2",
+							"replicas", "3",
+							"readyReplicas", "3",
+							"updatedReplicas", "1",
+							"unavailableReplicas", "0",
+						},
+						Message: "Observed Machine Configuration",
+					},
+				},
+			}),
+			Entry("when Machines need updates and the OnDelete rollout is paused", &reconcileStatusTableInput{
+				cpmsBuilder: machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(2).WithStrategyType(machinev1.OnDelete),
+				annotations: map[string]string{annotations.PausedAnnotation: "true"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+				},
+				expectedError: nil,
+				expectedStatus: machinev1.ControlPlaneMachineSetStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               conditionAvailable,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonAllReplicasAvailable,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionDegraded,
+							Status:             metav1.ConditionFalse,
+							Reason:             reasonAsExpected,
+							ObservedGeneration: 2,
+						},
+						{
+							Type:               conditionProgressing,
+							Status:             metav1.ConditionTrue,
+							Reason:             reasonNeedsUpdateReplicas,
+							ObservedGeneration: 2,
+							Message:            "Observed 2 replica(s) in need of update",
+						},
+					},
+					ObservedGeneration:  2,
+					Replicas:            3,
+					ReadyReplicas:       3,
+					UpdatedReplicas:     1,
+					UnavailableReplicas: 0,
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"observedGeneration", "2",
+							"replicas", "3",
+							"readyReplicas", "3",
+							"updatedReplicas", "1",
+							"unavailableReplicas", "0",
+						},
+						Message: "Observed Machine Configuration",
+					},
+				},
+			}),
 			Entry("with pending replacement replicas", &reconcileStatusTableInput{
 				cpmsBuilder: machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
@@ -617,4 +964,65 @@ var _ = Describe("Status", func() {
 			}),
 		)
 	})
+
+	Context("summarizeChangedFields", func() {
+		machineInfoBuilder := machineprovidersresourcebuilder.MachineInfo().WithNeedsUpdate(true)
+
+		fieldDiff := func(field string) machineproviders.FieldDiff {
+			return machineproviders.FieldDiff{Path: fmt.Sprintf("spec.providerSpec.value.%s", field)}
+		}
+
+		It("should ignore Machines that do not need an update", func() {
+			Expect(summarizeChangedFields(map[int32][]machineproviders.MachineInfo{
+				0: {machineInfoBuilder.WithNeedsUpdate(false).WithDiff(fieldDiff("instanceType")).Build()},
+			})).To(BeEmpty())
+		})
+
+		It("should list each changed field once, sorted by path", func() {
+			Expect(summarizeChangedFields(map[int32][]machineproviders.MachineInfo{
+				0: {machineInfoBuilder.WithDiff(fieldDiff("placement.availabilityZone"), fieldDiff("instanceType")).Build()},
+				1: {machineInfoBuilder.WithDiff(fieldDiff("instanceType")).Build()},
+			})).To(Equal("spec.providerSpec.value.instanceType, spec.providerSpec.value.placement.availabilityZone"))
+		})
+
+		It("should truncate the list of changed fields", func() {
+			Expect(summarizeChangedFields(map[int32][]machineproviders.MachineInfo{
+				0: {machineInfoBuilder.WithDiff(fieldDiff("a"), fieldDiff("b"), fieldDiff("c"), fieldDiff("d"), fieldDiff("e"), fieldDiff("f"), fieldDiff("g")).Build()},
+			})).To(Equal("spec.providerSpec.value.a, spec.providerSpec.value.b, spec.providerSpec.value.c, spec.providerSpec.value.d, spec.providerSpec.value.e and 2 more"))
+		})
+	})
+
+	Context("setFailureDomainMappingCondition", func() {
+		var cpms *machinev1.ControlPlaneMachineSet
+
+		BeforeEach(func() {
+			cpms = machinev1resourcebuilder.ControlPlaneMachineSet().WithGeneration(3).Build()
+		})
+
+		It("should report the mapping and reasons sorted by index", func() {
+			setFailureDomainMappingCondition(cpms, map[int32]machineproviders.FailureDomainMapping{
+				2: {FailureDomain: "us-east-1c", Reason: machineproviders.FailureDomainMappingReasonRebalanced},
+				0: {FailureDomain: "us-east-1a", Reason: machineproviders.FailureDomainMappingReasonExistingMachine},
+				1: {FailureDomain: "us-east-1b", Reason: machineproviders.FailureDomainMappingReasonBaseMapping},
+			})
+
+			Expect(cpms.Status.Conditions).To(ConsistOf(testutils.MatchCondition(metav1.Condition{
+				Type:               conditionFailureDomainMapping,
+				Status:             metav1.ConditionTrue,
+				Reason:             reasonFailureDomainsMapped,
+				ObservedGeneration: 3,
+				Message:            "Indexes are mapped to failure domains: 0: us-east-1a (ExistingMachine); 1: us-east-1b (BaseMapping); 2: us-east-1c (Rebalanced)",
+			})))
+		})
+
+		It("should remove the condition when there is no mapping", func() {
+			setFailureDomainMappingCondition(cpms, map[int32]machineproviders.FailureDomainMapping{
+				0: {FailureDomain: "us-east-1a", Reason: machineproviders.FailureDomainMappingReasonExistingMachine},
+			})
+			Expect(cpms.Status.Conditions).To(HaveLen(1))
+
+			setFailureDomainMappingCondition(cpms, map[int32]machineproviders.FailureDomainMapping{})
+			Expect(cpms.Status.Conditions).To(BeEmpty())
+		})
+	})
 })
@@ -24,7 +24,9 @@ import (
 
 	"github.com/go-logr/logr"
 	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -51,6 +53,10 @@ const (
 	// for the update strategy.
 	invalidStrategyMessage = "invalid value for spec.strategy.type"
 
+	// invalidStrategyConfigurationMessage is used to inform the user that they have provided an invalid
+	// configuration for the update strategy, using annotations on the ControlPlaneMachineSet.
+	invalidStrategyConfigurationMessage = "invalid update strategy configuration"
+
 	// machineRequiresUpdate is a log message used to inform the user that a Machine requires an update,
 	// but that they must first delete the Machine to trigger a replacement.
 	// This is used with the OnDelete replacement strategy.
@@ -65,6 +71,12 @@ const (
 	noCapacityForExpansion = "Insufficient capacity for expansion, maximum surge has been reached." +
 		" Cannot create a replacement Machine at this time."
 
+	// noCapacityForRemoval is a log message used to inform the user that no capacity for reducing the number of
+	// available indexes by removing an outdated machine is left as the maximum unavailable has been reached.
+	// This is used with the Recreate replacement strategy.
+	noCapacityForRemoval = "Insufficient capacity for removal, maximum unavailable has been reached." +
+		" Cannot remove an outdated Machine at this time."
+
 	// removingOldMachine is a log message used to inform the user that an old Machine has been
 	// deleted as a part of the rollout operation.
 	removingOldMachine = "Removing old machine"
@@ -78,6 +90,11 @@ const (
 	// place because the rollout is waiting for a Machine to be removed.
 	waitingForRemoved = "Waiting for machine to be removed"
 
+	// waitingForRemovedBeforeReplacement is a log message used to inform the user that no operations are taking
+	// place because the rollout is waiting for an outdated Machine to be removed before its replacement is created.
+	// This is used with the Recreate replacement strategy.
+	waitingForRemovedBeforeReplacement = "Waiting for machine to be removed before creating a replacement"
+
 	// waitingForReplacement is a log message used to inform the user that no operations are taking
 	// place because the rollout is waiting for a replacement Machine to become ready.
 	// This is used when replacing a Machine within an index.
@@ -90,10 +107,6 @@ const (
 )
 
 var (
-	// errRecreateStrategyNotSupported is used to inform users that the Recreate update strategy is not yet supported.
-	// It may be supported in a future version.
-	errRecreateStrategyNotSupported = fmt.Errorf("update strategy %q is not supported", machinev1.Recreate)
-
 	// errReplicasRequired is used to inform users that the replicas field is currently unset, and
 	// must be set to continue operation.
 	errReplicasRequired = errors.New("spec.replicas is unset: replicas is required")
@@ -112,14 +125,7 @@ func (r *ControlPlaneMachineSetReconciler) reconcileMachineUpdates(ctx context.C
 	case machinev1.OnDelete:
 		return r.reconcileMachineOnDeleteUpdate(ctx, logger, cpms, machineProvider, machineInfos)
 	case machinev1.Recreate:
-		meta.SetStatusCondition(&cpms.Status.Conditions, metav1.Condition{
-			Type:    conditionDegraded,
-			Status:  metav1.ConditionTrue,
-			Reason:  reasonInvalidStrategy,
-			Message: fmt.Sprintf("%s: %s", invalidStrategyMessage, errRecreateStrategyNotSupported),
-		})
-
-		logger.Error(errRecreateStrategyNotSupported, invalidStrategyMessage)
+		return r.reconcileMachineRecreateUpdate(ctx, logger, cpms, machineProvider, machineInfos)
 	default:
 		meta.SetStatusCondition(&cpms.Status.Conditions,
 			metav1.Condition{
@@ -137,6 +143,20 @@ func (r *ControlPlaneMachineSetReconciler) reconcileMachineUpdates(ctx context.C
 	return ctrl.Result{}, nil
 }
 
+// setInvalidStrategyConfiguration marks the ControlPlaneMachineSet as degraded when the configuration of the update
+// strategy, provided by annotations on the ControlPlaneMachineSet, is invalid.
+func setInvalidStrategyConfiguration(logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, err error) {
+	meta.SetStatusCondition(&cpms.Status.Conditions,
+		metav1.Condition{
+			Type:    conditionDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonInvalidStrategy,
+			Message: fmt.Sprintf("%s: %s", invalidStrategyConfigurationMessage, err),
+		})
+
+	logger.Error(err, invalidStrategyConfigurationMessage)
+}
+
 // reconcileMachineRollingUpdate implements the rolling update strategy for the ControlPlaneMachineSet. It uses the
 // indexed machine information to determine when a new Machine is required to be created. When a new Machine is required,
 // it uses the machine provider to create the new Machine.
@@ -144,50 +164,83 @@ func (r *ControlPlaneMachineSetReconciler) reconcileMachineUpdates(ctx context.C
 // For rolling updates, a new Machine is required when a machine index has a Machine, which needs an update, but does
 // not yet have replacement created. It must also observe the surge semantics of a rolling update, so, if an existing
 // index is already going through the process of a rolling update, it should not start the update of any other index.
-// At present, the surge is limited to a single Machine instance.
+// By default, the surge is limited to a single Machine instance. This can be raised, using the max surge annotation, to
+// update multiple indexes at once, up to the number of etcd members that may be lost without losing quorum.
 //
 // Once a replacement Machine is ready, the strategy should also delete the old Machine to allow it to be removed from
 // the cluster.
 //
+// Indexes with a Machine in a failure domain marked as unavailable are considered first, and are evacuated one index
+// at a time. Evacuation is not held by the maintenance window or the partition, only by pausing the rollout.
+//
+// When the rollout is paused, or the maintenance window is closed, replacements already in progress are completed, but
+// no new replacement of an outdated Machine is started. While the maintenance window is closed, the
+// ControlPlaneMachineSet is requeued for the time at which it next opens.
+//
 // In certain scenarios, there may be indexes with missing Machines. In these circumstances, the update should attempt
 // to create a new Machine to fulfil the requirement of that index.
 func (r *ControlPlaneMachineSetReconciler) reconcileMachineRollingUpdate(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, indexedMachineInfos map[int32][]machineproviders.MachineInfo) (ctrl.Result, error) {
 	logger = logger.WithValues("updateStrategy", cpms.Spec.Strategy.Type)
 
+	// The maximum number of machines that
+	// can be scheduled above the original number of desired machines.
+	// This defaults to a single Machine instance, but may be raised,
+	// up to the etcd fault tolerance for the number of replicas, by annotation.
+	maxSurge, err := annotations.MaxSurge(cpms)
+	if err != nil {
+		setInvalidStrategyConfiguration(logger, cpms, err)
+
+		// Do not return an error here as the configuration is invalid.
+		// This will need user intervention to resolve.
+		return ctrl.Result{}, nil
+	}
+
+	controls, err := newRolloutControls(cpms, r.EtcdLeaderLocator, r.now())
+	if err != nil {
+		setInvalidStrategyConfiguration(logger, cpms, err)
+
+		return ctrl.Result{}, nil
+	}
+
 	// To ensure an ordered and safe reconciliation,
 	// one index at a time is considered.
-	// Indexes are sorted in ascending order, so that all the operations of the same importance,
-	// are executed prioritizing the lower indexes first.
-	sortedIndexedMs := sortMachineInfosByIndex(indexedMachineInfos)
+	// Indexes are ordered by the replacement order policy, ascending by default,
+	// so that all the operations of the same importance are executed prioritizing the earlier indexes first.
+	sortedIndexedMs, err := controls.order.Order(ctx, logger, sortMachineInfosByIndex(indexedMachineInfos))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("error ordering indexes for replacement: %w", err)
+	}
+
+	// Indexes with Machines in unavailable failure domains are evacuated first,
+	// and only one index is evacuated at a time.
+	sortedIndexedMs = evacuationFirst(sortedIndexedMs)
+	evacuationCount := deviseExistingEvacuations(sortedIndexedMs)
 
-	// The maximum number of machines that
-	// can be scheduled above the original number of desired machines.
-	// At present, the surge is limited to a single Machine instance.
-	// NOTE: If this gets changed or parametrized,
-	// the tests will need to be updated accordingly.
-	maxSurge := 1
 	// Devise the existing surge and keep track of the current surge count.
 	// No check for early stoppage is done here,
 	// as deletions can continue even if the maxSurge has been already reached.
 	surgeCount := deviseExistingSurge(cpms, sortedIndexedMs)
 
+	// The removal of replaced Machines is gated on the health of etcd.
+	gate := newEtcdHealthGate(r.EtcdHealthChecker)
+
 	var updated bool
 
 	for _, indexToMachines := range sortedIndexedMs {
 		idx := indexToMachines.index
 		machines := indexToMachines.machineInfos
 
-		if done, result, err := r.deleteReplacedMachines(ctx, logger, machineProvider, machines); err != nil {
+		if done, result, err := r.deleteReplacedMachines(ctx, logger, cpms, machineProvider, machines, gate); err != nil {
 			return result, err
 		} else if done {
 			updated = true
 		}
 
-		if done := r.waitForPendingMachines(logger, machines); done {
+		if done := r.waitForPendingMachines(logger, cpms, machines); done {
 			updated = true
 		}
 
-		if done, result, err := r.createRollingUpdateReplacementMachines(ctx, logger, machineProvider, machines, idx, maxSurge, &surgeCount); err != nil {
+		if done, result, err := r.createRollingUpdateReplacementMachines(ctx, logger, cpms, machineProvider, machines, idx, controls, maxSurge, &surgeCount, &evacuationCount); err != nil {
 			return result, err
 		} else if done {
 			updated = true
@@ -198,7 +251,100 @@ func (r *ControlPlaneMachineSetReconciler) reconcileMachineRollingUpdate(ctx con
 		logger.V(4).Info(noUpdatesRequired)
 	}
 
-	return ctrl.Result{}, nil
+	gate.setCondition(cpms)
+
+	return lowestNonZeroResult(controls.maintenanceWindowResult(sortedIndexedMs), gate.result()), nil
+}
+
+// reconcileMachineRecreateUpdate implements the recreate update strategy for the ControlPlaneMachineSet. It uses the
+// indexed machine information to determine when an outdated Machine should be removed and when a new Machine is
+// required to be created. When a new Machine is required, it uses the machine provider to create the new Machine.
+//
+// For recreate updates, the outdated Machine in an index is removed before its replacement is created. This means the
+// number of Machines never exceeds the desired number of replicas, which allows updates in environments where there
+// is no spare capacity to surge an additional Machine.
+// The removal of the outdated Machine is blocked by the etcd operator until the etcd member hosted on the Machine has
+// been removed from the cluster, so the replacement is only created once the Machine has been removed entirely.
+//
+// To protect etcd quorum, the recreate strategy observes a maximum unavailable, so if an existing index is already
+// going through the process of being recreated, it will not start the update of any other index.
+// At present, the maximum unavailable is limited to a single index.
+//
+// Indexes with a Machine in a failure domain marked as unavailable are considered first, and are evacuated one index
+// at a time.
+//
+// In certain scenarios, there may be indexes with missing Machines. In these circumstances, the update should attempt
+// to create a new Machine to fulfil the requirement of that index.
+func (r *ControlPlaneMachineSetReconciler) reconcileMachineRecreateUpdate(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, indexedMachineInfos map[int32][]machineproviders.MachineInfo) (ctrl.Result, error) {
+	logger = logger.WithValues("updateStrategy", cpms.Spec.Strategy.Type)
+
+	controls, err := newRolloutControls(cpms, r.EtcdLeaderLocator, r.now())
+	if err != nil {
+		setInvalidStrategyConfiguration(logger, cpms, err)
+
+		// Do not return an error here as the configuration is invalid.
+		// This will need user intervention to resolve.
+		return ctrl.Result{}, nil
+	}
+
+	// To ensure an ordered and safe reconciliation,
+	// one index at a time is considered.
+	// Indexes are ordered by the replacement order policy, ascending by default,
+	// so that all the operations of the same importance are executed prioritizing the earlier indexes first.
+	sortedIndexedMs, err := controls.order.Order(ctx, logger, sortMachineInfosByIndex(indexedMachineInfos))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("error ordering indexes for replacement: %w", err)
+	}
+
+	// Indexes with Machines in unavailable failure domains are evacuated first,
+	// and only one index is evacuated at a time.
+	sortedIndexedMs = evacuationFirst(sortedIndexedMs)
+	evacuationCount := deviseExistingEvacuations(sortedIndexedMs)
+
+	// The maximum number of indexes that
+	// can be without an available Machine at any one time.
+	// At present, this is limited to a single index so that
+	// etcd quorum is maintained throughout the update.
+	maxUnavailable := 1
+	// Devise the existing number of unavailable indexes and keep track of it.
+	// No check for early stoppage is done here,
+	// as creations and deletions of replaced Machines can continue
+	// even if the maxUnavailable has been already reached.
+	unavailableCount := deviseExistingUnavailable(sortedIndexedMs)
+
+	// The removal of replaced Machines is gated on the health of etcd.
+	gate := newEtcdHealthGate(r.EtcdHealthChecker)
+
+	var updated bool
+
+	for _, indexToMachines := range sortedIndexedMs {
+		idx := indexToMachines.index
+		machines := indexToMachines.machineInfos
+
+		if done, result, err := r.deleteReplacedMachines(ctx, logger, cpms, machineProvider, machines, gate); err != nil {
+			return result, err
+		} else if done {
+			updated = true
+		}
+
+		if done := r.waitForPendingMachines(logger, cpms, machines); done {
+			updated = true
+		}
+
+		if done, result, err := r.createRecreateReplacementMachines(ctx, logger, cpms, machineProvider, machines, idx, controls, gate, maxUnavailable, &unavailableCount, &evacuationCount); err != nil {
+			return result, err
+		} else if done {
+			updated = true
+		}
+	}
+
+	if !updated {
+		logger.V(4).Info(noUpdatesRequired)
+	}
+
+	gate.setCondition(cpms)
+
+	return lowestNonZeroResult(controls.maintenanceWindowResult(sortedIndexedMs), gate.result()), nil
 }
 
 // reconcileMachineOnDeleteUpdate implements the rolling update strategy for the ControlPlaneMachineSet. It uses the
@@ -233,11 +379,11 @@ func (r *ControlPlaneMachineSetReconciler) reconcileMachineOnDeleteUpdate(ctx co
 			continue
 		}
 
-		if done := r.waitForPendingMachines(logger, machines); done {
+		if done := r.waitForPendingMachines(logger, cpms, machines); done {
 			updated = true
 		}
 
-		if done, result, err := r.createOnDeleteReplacementMachines(ctx, logger, machineProvider, machines, idx); err != nil {
+		if done, result, err := r.createOnDeleteReplacementMachines(ctx, logger, cpms, machineProvider, machines, idx); err != nil {
 			return result, err
 		} else if done {
 			updated = true
@@ -256,7 +402,7 @@ func (r *ControlPlaneMachineSetReconciler) reconcileMachineOnDeleteUpdate(ctx co
 // are in a pending or deleting state based on the presence, and state, of other machines
 // in the same array. this function does not block, but gives a signal to the caller about
 // whether there are machines that are still transitioning towards a final state.
-func (r *ControlPlaneMachineSetReconciler) waitForPendingMachines(logger logr.Logger, machines []machineproviders.MachineInfo) bool {
+func (r *ControlPlaneMachineSetReconciler) waitForPendingMachines(logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machines []machineproviders.MachineInfo) bool {
 	machinesPending := pendingMachines(machines)
 	machinesNeedingReplacement := needReplacementMachines(machines)
 	machinesReady := readyMachines(machines)
@@ -271,6 +417,8 @@ func (r *ControlPlaneMachineSetReconciler) waitForPendingMachines(logger logr.Lo
 		replacementMachine := machinesPending[0]
 		logger := logger.WithValues("index", replacementMachine.Index, "namespace", r.Namespace, "name", replacementMachine.MachineRef.ObjectMeta.Name)
 		logger.V(2).Info(waitingForReady)
+		r.recordStateEventf(cpms, replacementMachine.MachineRef, corev1.EventTypeNormal, eventReasonWaitingForReady,
+			"Waiting for machine %s in index %d to become ready", replacementMachine.MachineRef.ObjectMeta.Name, replacementMachine.Index)
 
 		return true
 	}
@@ -285,6 +433,9 @@ func (r *ControlPlaneMachineSetReconciler) waitForPendingMachines(logger logr.Lo
 
 		logger := logger.WithValues("index", outdatedMachine.Index, "namespace", r.Namespace, "name", outdatedMachine.MachineRef.ObjectMeta.Name)
 		logger.V(2).WithValues("replacementName", replacementMachine.MachineRef.ObjectMeta.Name).Info(waitingForReplacement)
+		r.recordStateEventf(cpms, outdatedMachine.MachineRef, corev1.EventTypeNormal, eventReasonWaitingForReplacement,
+			"Waiting for replacement machine %s to become ready before removing machine %s from index %d",
+			replacementMachine.MachineRef.ObjectMeta.Name, outdatedMachine.MachineRef.ObjectMeta.Name, outdatedMachine.Index)
 
 		return true
 	}
@@ -297,6 +448,8 @@ func (r *ControlPlaneMachineSetReconciler) waitForPendingMachines(logger logr.Lo
 
 		logger := logger.WithValues("index", deletedMachine.Index, "namespace", r.Namespace, "name", deletedMachine.MachineRef.ObjectMeta.Name)
 		logger.V(2).Info(waitingForRemoved)
+		r.recordStateEventf(cpms, deletedMachine.MachineRef, corev1.EventTypeNormal, eventReasonWaitingForRemoval,
+			"Waiting for machine %s to be removed from index %d", deletedMachine.MachineRef.ObjectMeta.Name, deletedMachine.Index)
 
 		return true
 	}
@@ -304,7 +457,9 @@ func (r *ControlPlaneMachineSetReconciler) waitForPendingMachines(logger logr.Lo
 	return false
 }
 
-func (r *ControlPlaneMachineSetReconciler) deleteReplacedMachines(ctx context.Context, logger logr.Logger, machineProvider machineproviders.MachineProvider, machines []machineproviders.MachineInfo) (bool, ctrl.Result, error) {
+// deleteReplacedMachines removes the Machines in an index that are no longer required, once their replacement is ready.
+// Before a Ready Machine is removed, the etcd health gate must allow its removal.
+func (r *ControlPlaneMachineSetReconciler) deleteReplacedMachines(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, machines []machineproviders.MachineInfo, gate *etcdHealthGate) (bool, ctrl.Result, error) {
 	machinesNeedingReplacement := needReplacementMachines(machines)
 	machinesUpdated := updatedMachines(machines)
 	machinesOutdatedNonReady := nonReadyMachines(machinesNeedingReplacement)
@@ -339,7 +494,21 @@ func (r *ControlPlaneMachineSetReconciler) deleteReplacedMachines(ctx context.Co
 		logger := logger.WithValues("index", toDeleteMachine.Index, "namespace", r.Namespace, "name", toDeleteMachine.MachineRef.ObjectMeta.Name)
 
 		if !isDeletedMachine(toDeleteMachine) {
-			result, err := deleteMachine(ctx, logger, machineProvider, toDeleteMachine, r.Namespace)
+			if toDeleteMachine.Ready {
+				// A Ready Machine hosts an etcd member, so etcd must be ready for its removal.
+				// Ready Machines are only removed when an updated replacement exists, the newest of which
+				// hosts the replacement etcd member.
+				sortedUpdated := sortMachineInfoByCreationTimestamp(machinesUpdated)
+				replacement := sortedUpdated[len(sortedUpdated)-1]
+
+				if allowed, err := gate.allowRemoval(ctx, logger, toDeleteMachine, replacement); err != nil {
+					return false, ctrl.Result{}, err
+				} else if !allowed {
+					return true, ctrl.Result{}, nil
+				}
+			}
+
+			result, err := r.deleteMachine(ctx, logger, cpms, machineProvider, toDeleteMachine)
 			if err != nil {
 				return false, result, err
 			}
@@ -358,13 +527,13 @@ func (r *ControlPlaneMachineSetReconciler) deleteReplacedMachines(ctx context.Co
 // this function will attempt to create new machines when none are available
 // in the machine info, or when there is a machine that needs an update for
 // which no replacement has been created.
-func (r *ControlPlaneMachineSetReconciler) createOnDeleteReplacementMachines(ctx context.Context, logger logr.Logger, machineProvider machineproviders.MachineProvider, machines []machineproviders.MachineInfo, idx int32) (bool, ctrl.Result, error) {
+func (r *ControlPlaneMachineSetReconciler) createOnDeleteReplacementMachines(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, machines []machineproviders.MachineInfo, idx int32) (bool, ctrl.Result, error) {
 	if isEmpty(machines) {
 		// No Machines exist for this index.
 		// Trigger a Machine creation.
 		logger := logger.WithValues("index", idx, "namespace", r.Namespace, "name", unknownMachineName)
 
-		result, err := r.createMachine(ctx, logger, machineProvider, idx)
+		result, err := r.createMachine(ctx, logger, cpms, machineProvider, idx, nil)
 		if err != nil {
 			return false, result, err
 		}
@@ -379,7 +548,7 @@ func (r *ControlPlaneMachineSetReconciler) createOnDeleteReplacementMachines(ctx
 
 		if isDeletedMachine(machines[0]) {
 			// if deleted create the replacement
-			result, err := r.createMachine(ctx, logger, machineProvider, idx)
+			result, err := r.createMachine(ctx, logger, cpms, machineProvider, idx, machines[0].MachineRef)
 			if err != nil {
 				return false, result, err
 			}
@@ -400,7 +569,7 @@ func (r *ControlPlaneMachineSetReconciler) createOnDeleteReplacementMachines(ctx
 // in the machine info, or when there is a machine that needs an update for
 // which no replacement has been created. in all cases it will observe the
 // surge parameters when creating new machines.
-func (r *ControlPlaneMachineSetReconciler) createRollingUpdateReplacementMachines(ctx context.Context, logger logr.Logger, machineProvider machineproviders.MachineProvider, machines []machineproviders.MachineInfo, idx int32, maxSurge int, surgeCount *int) (bool, ctrl.Result, error) {
+func (r *ControlPlaneMachineSetReconciler) createRollingUpdateReplacementMachines(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, machines []machineproviders.MachineInfo, idx int32, controls rolloutControls, maxSurge int, surgeCount *int, evacuationCount *int) (bool, ctrl.Result, error) {
 	machinesNeedingReplacement := needReplacementMachines(machines)
 	machinesPending := pendingMachines(machines)
 	machinesUpdatedNonDeleted := updatedNonDeletedMachines(machines)
@@ -410,7 +579,7 @@ func (r *ControlPlaneMachineSetReconciler) createRollingUpdateReplacementMachine
 		// Trigger a Machine creation.
 		logger := logger.WithValues("index", idx, "namespace", r.Namespace, "name", unknownMachineName)
 
-		result, err := r.createMachineWithSurge(ctx, logger, machineProvider, idx, maxSurge, surgeCount)
+		_, result, err := r.createMachineWithSurge(ctx, logger, cpms, machineProvider, idx, nil, maxSurge, surgeCount)
 		if err != nil {
 			return false, result, err
 		}
@@ -426,11 +595,102 @@ func (r *ControlPlaneMachineSetReconciler) createRollingUpdateReplacementMachine
 		outdatedMachine := machinesNeedingReplacement[0]
 		logger := logger.WithValues("index", outdatedMachine.Index, "namespace", r.Namespace, "name", outdatedMachine.MachineRef.ObjectMeta.Name)
 
-		result, err := r.createMachineWithSurge(ctx, logger, machineProvider, outdatedMachine.Index, maxSurge, surgeCount)
+		if reason := controls.holdReplacement(outdatedMachine); reason != "" {
+			// The rollout controls do not allow the replacement to be started at this time.
+			logger.V(2).Info(reason)
+
+			return true, ctrl.Result{}, nil
+		}
+
+		if outdatedMachine.Evacuating && *evacuationCount >= maxConcurrentEvacuations {
+			// Another index is already being evacuated from an unavailable failure domain.
+			logger.V(2).Info(evacuationInProgress)
+
+			return true, ctrl.Result{}, nil
+		}
+
+		created, result, err := r.createMachineWithSurge(ctx, logger, cpms, machineProvider, outdatedMachine.Index, outdatedMachine.MachineRef, maxSurge, surgeCount)
+		if err != nil {
+			return false, result, err
+		}
+
+		if created && outdatedMachine.Evacuating {
+			// The evacuation of this index has only started once its replacement has been created.
+			*evacuationCount++
+		}
+
+		return true, result, nil
+	}
+
+	return false, ctrl.Result{}, nil
+}
+
+// create replacement machines for the Recreate method.
+// this function will attempt to create new machines when none are available
+// in the machine info. when there is a machine that needs an update for
+// which no replacement has been created, it will remove the outdated machine
+// first, observing the maximum unavailable parameters and the etcd health gate,
+// and wait for it to be removed before creating the replacement.
+func (r *ControlPlaneMachineSetReconciler) createRecreateReplacementMachines(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, machines []machineproviders.MachineInfo, idx int32, controls rolloutControls, gate *etcdHealthGate, maxUnavailable int, unavailableCount *int, evacuationCount *int) (bool, ctrl.Result, error) {
+	machinesNeedingReplacement := needReplacementMachines(machines)
+	machinesPending := pendingMachines(machines)
+	machinesUpdatedNonDeleted := updatedNonDeletedMachines(machines)
+
+	if isEmpty(machines) {
+		// No Machines exist for this index.
+		// Either the outdated Machine has been removed, or the index was missing.
+		// This index is already unavailable, so trigger a Machine creation.
+		logger := logger.WithValues("index", idx, "namespace", r.Namespace, "name", unknownMachineName)
+
+		result, err := r.createMachine(ctx, logger, cpms, machineProvider, idx, nil)
+		if err != nil {
+			return false, result, err
+		}
+
+		return true, result, nil
+	}
+
+	if hasAny(machinesNeedingReplacement) && isEmpty(machinesUpdatedNonDeleted) && isEmpty(machinesPending) {
+		// A Machine for this index needs updating (or has been deleted).
+		// No Updated (non-terminated) or Pending (Updated, Non-Ready) Replacement Machine exist for it.
+		// Consider the first found outdated machine for this index to be the one in need of update.
+		outdatedMachine := machinesNeedingReplacement[0]
+		logger := logger.WithValues("index", outdatedMachine.Index, "namespace", r.Namespace, "name", outdatedMachine.MachineRef.ObjectMeta.Name)
+
+		if isDeletedMachine(outdatedMachine) {
+			// The outdated Machine is already being removed.
+			// Wait for the etcd member to be removed and the Machine to go away before creating the replacement.
+			logger.V(2).Info(waitingForRemovedBeforeReplacement)
+			r.recordStateEventf(cpms, outdatedMachine.MachineRef, corev1.EventTypeNormal, eventReasonWaitingForRemoval,
+				"Waiting for machine %s to be removed from index %d before creating a replacement", outdatedMachine.MachineRef.ObjectMeta.Name, outdatedMachine.Index)
+
+			return true, ctrl.Result{}, nil
+		}
+
+		if reason := controls.holdReplacement(outdatedMachine); reason != "" {
+			// The rollout controls do not allow the replacement to be started at this time.
+			logger.V(2).Info(reason)
+
+			return true, ctrl.Result{}, nil
+		}
+
+		if outdatedMachine.Evacuating && *evacuationCount >= maxConcurrentEvacuations {
+			// Another index is already being evacuated from an unavailable failure domain.
+			logger.V(2).Info(evacuationInProgress)
+
+			return true, ctrl.Result{}, nil
+		}
+
+		deleted, result, err := r.deleteMachineWithUnavailable(ctx, logger, cpms, machineProvider, outdatedMachine, gate, maxUnavailable, unavailableCount)
 		if err != nil {
 			return false, result, err
 		}
 
+		if deleted && outdatedMachine.Evacuating {
+			// The evacuation of this index has only started once its outdated Machine has been removed.
+			*evacuationCount++
+		}
+
 		return true, result, nil
 	}
 
@@ -438,21 +698,26 @@ func (r *ControlPlaneMachineSetReconciler) createRollingUpdateReplacementMachine
 }
 
 // deleteMachine deletes the Machine provided.
-func deleteMachine(ctx context.Context, logger logr.Logger, machineProvider machineproviders.MachineProvider, outdatedMachine machineproviders.MachineInfo, namespace string) (ctrl.Result, error) {
+func (r *ControlPlaneMachineSetReconciler) deleteMachine(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, outdatedMachine machineproviders.MachineInfo) (ctrl.Result, error) {
 	if err := machineProvider.DeleteMachine(ctx, logger, outdatedMachine.MachineRef); err != nil {
-		werr := fmt.Errorf("error deleting Machine %s/%s: %w", namespace, outdatedMachine.MachineRef.ObjectMeta.Name, err)
+		werr := fmt.Errorf("error deleting Machine %s/%s: %w", r.Namespace, outdatedMachine.MachineRef.ObjectMeta.Name, err)
 		logger.Error(werr, errorDeletingMachine)
+		r.recordEventf(cpms, outdatedMachine.MachineRef, corev1.EventTypeWarning, eventReasonFailedDelete,
+			"Error deleting machine %s in index %d: %s", outdatedMachine.MachineRef.ObjectMeta.Name, outdatedMachine.Index, err.Error())
 
 		return ctrl.Result{}, werr
 	}
 
 	logger.V(2).Info(removingOldMachine)
+	r.metrics.machineDeleted(cpms, outdatedMachine, r.now())
+	r.recordEventf(cpms, outdatedMachine.MachineRef, corev1.EventTypeNormal, eventReasonDeletedMachine,
+		"Removing machine %s from index %d", outdatedMachine.MachineRef.ObjectMeta.Name, outdatedMachine.Index)
 
 	return ctrl.Result{}, nil
 }
 
 // createMachine creates the Machine provided.
-func (r *ControlPlaneMachineSetReconciler) createMachine(ctx context.Context, logger logr.Logger, machineProvider machineproviders.MachineProvider, idx int32) (ctrl.Result, error) {
+func (r *ControlPlaneMachineSetReconciler) createMachine(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, idx int32, replacedMachineRef *machineproviders.ObjectRef) (ctrl.Result, error) {
 	// Check if a replacement machine already exists and
 	// was not previously detected due to potential stale cache.
 	exists, err := r.checkForExistingReplacement(ctx, logger, machineProvider, idx)
@@ -471,11 +736,16 @@ func (r *ControlPlaneMachineSetReconciler) createMachine(ctx context.Context, lo
 	if err := machineProvider.CreateMachine(ctx, logger, idx); err != nil {
 		werr := fmt.Errorf("error creating new Machine for index %d: %w", idx, err)
 		logger.Error(werr, errorCreatingMachine)
+		r.recordEventf(cpms, replacedMachineRef, corev1.EventTypeWarning, eventReasonFailedCreate,
+			"Error creating %s: %s", describeNewMachine(idx, replacedMachineRef), err.Error())
 
 		return ctrl.Result{}, werr
 	}
 
 	logger.V(2).Info(createdReplacement)
+	r.metrics.machineCreated(cpms, idx, replacedMachineRef != nil, r.now())
+	r.recordEventf(cpms, replacedMachineRef, corev1.EventTypeNormal, eventReasonCreatedMachine,
+		"Created %s", describeNewMachine(idx, replacedMachineRef))
 
 	return ctrl.Result{}, nil
 }
@@ -483,25 +753,66 @@ func (r *ControlPlaneMachineSetReconciler) createMachine(ctx context.Context, lo
 // createMachineWithSurge creates the Machine provided while observing the surge count.
 // This function will not create machines if the current surgeCount is greater
 // than the maxSurge. If it does create a machine, it will increase the surgeCount.
-func (r *ControlPlaneMachineSetReconciler) createMachineWithSurge(ctx context.Context, logger logr.Logger, machineProvider machineproviders.MachineProvider, idx int32, maxSurge int, surgeCount *int) (ctrl.Result, error) {
+// It returns whether a machine was created.
+func (r *ControlPlaneMachineSetReconciler) createMachineWithSurge(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, idx int32, replacedMachineRef *machineproviders.ObjectRef, maxSurge int, surgeCount *int) (bool, ctrl.Result, error) {
 	// Check if a surge in Machines is allowed.
 	if *surgeCount >= maxSurge {
 		// No more room to surge
 		logger.V(2).Info(noCapacityForExpansion)
+		r.recordStateEventf(cpms, replacedMachineRef, corev1.EventTypeNormal, eventReasonMaxSurgeReached,
+			"Cannot create %s, the maximum surge of %d has been reached", describeNewMachine(idx, replacedMachineRef), maxSurge)
 
-		return ctrl.Result{}, nil
+		return false, ctrl.Result{}, nil
 	}
 
 	// There is still room to surge,
 	// trigger a Replacement Machine creation.
-	result, err := r.createMachine(ctx, logger, machineProvider, idx)
+	result, err := r.createMachine(ctx, logger, cpms, machineProvider, idx, replacedMachineRef)
 	if err != nil {
-		return result, err
+		return false, result, err
 	}
 
 	*surgeCount++
 
-	return result, nil
+	return true, result, nil
+}
+
+// deleteMachineWithUnavailable deletes the Machine provided while observing the unavailable count.
+// This function will not delete machines if the current unavailableCount is greater
+// than the maxUnavailable. If it does delete a machine, it will increase the unavailableCount.
+// As no replacement exists yet, a Ready Machine is only deleted once the etcd health gate allows
+// the removal of its etcd member without a replacement.
+// It returns whether the machine was deleted.
+func (r *ControlPlaneMachineSetReconciler) deleteMachineWithUnavailable(ctx context.Context, logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, machineProvider machineproviders.MachineProvider, outdatedMachine machineproviders.MachineInfo, gate *etcdHealthGate, maxUnavailable int, unavailableCount *int) (bool, ctrl.Result, error) {
+	// Check if removing another index is allowed.
+	if *unavailableCount >= maxUnavailable {
+		// No more room to remove
+		logger.V(2).Info(noCapacityForRemoval)
+		r.recordStateEventf(cpms, outdatedMachine.MachineRef, corev1.EventTypeNormal, eventReasonMaxUnavailableReached,
+			"Cannot remove machine %s from index %d, the maximum unavailable of %d has been reached", outdatedMachine.MachineRef.ObjectMeta.Name, outdatedMachine.Index, maxUnavailable)
+
+		return false, ctrl.Result{}, nil
+	}
+
+	if outdatedMachine.Ready {
+		// A Ready Machine hosts an etcd member, so etcd quorum must survive the loss of that member.
+		if allowed, err := gate.allowRemovalWithoutReplacement(ctx, logger, outdatedMachine); err != nil {
+			return false, ctrl.Result{}, err
+		} else if !allowed {
+			return false, ctrl.Result{}, nil
+		}
+	}
+
+	// There is still room to remove,
+	// trigger the Outdated Machine deletion.
+	result, err := r.deleteMachine(ctx, logger, cpms, machineProvider, outdatedMachine)
+	if err != nil {
+		return false, result, err
+	}
+
+	*unavailableCount++
+
+	return true, result, nil
 }
 
 // checkForExistingReplacement checks with an uncached API client if a specific index,
@@ -599,6 +910,19 @@ func updatedNonDeletedMachines(machinesInfo []machineproviders.MachineInfo) []ma
 	return result
 }
 
+// readyNonDeletedMachines returns the list of MachineInfo which have a Ready Machine and are not pending deletion.
+func readyNonDeletedMachines(machinesInfo []machineproviders.MachineInfo) []machineproviders.MachineInfo {
+	result := []machineproviders.MachineInfo{}
+
+	for i := range machinesInfo {
+		if machinesInfo[i].Ready && !isDeletedMachine(machinesInfo[i]) {
+			result = append(result, machinesInfo[i])
+		}
+	}
+
+	return result
+}
+
 // readyMachines returns the list of MachineInfo which have a Ready Machine.
 func readyMachines(machinesInfo []machineproviders.MachineInfo) []machineproviders.MachineInfo {
 	result := []machineproviders.MachineInfo{}
@@ -661,6 +985,25 @@ func sortMachineInfoByCreationTimestamp(machineInfos []machineproviders.MachineI
 	return machineInfos
 }
 
+// lowestNonZeroResult returns the result that requeues soonest, preferring an immediate requeue.
+// A result with no requeue is only returned when none of the results request a requeue.
+func lowestNonZeroResult(results ...ctrl.Result) ctrl.Result {
+	lowest := ctrl.Result{}
+
+	for _, result := range results {
+		switch {
+		case result.Requeue && result.RequeueAfter == 0:
+			return result
+		case result.RequeueAfter == 0:
+			continue
+		case lowest.RequeueAfter == 0 || result.RequeueAfter < lowest.RequeueAfter:
+			lowest = result
+		}
+	}
+
+	return lowest
+}
+
 // machineInfosMaptoSlice returns a slice of MachineInfos from a map of MachineInfos slices.
 func machineInfosMaptoSlice(indexedMachineInfos map[int32][]machineproviders.MachineInfo) []machineproviders.MachineInfo {
 	slice := []machineproviders.MachineInfo{}
@@ -684,6 +1027,22 @@ func deviseExistingSurge(cpms *machinev1.ControlPlaneMachineSet, mis []indexToMa
 	return currentReplicas - desiredReplicas
 }
 
+// deviseExistingUnavailable computes the current number of disrupted indexes for the ControlPlaneMachineSet.
+// An index is considered settled only when it contains a single Ready Machine that is not pending deletion.
+// Any other index is either missing its Machine, or is part way through a replacement, and so is counted as
+// unavailable until it settles.
+func deviseExistingUnavailable(mis []indexToMachineInfos) int {
+	unavailable := 0
+
+	for _, mi := range mis {
+		if len(mi.machineInfos) != 1 || len(readyNonDeletedMachines(mi.machineInfos)) != 1 {
+			unavailable++
+		}
+	}
+
+	return unavailable
+}
+
 // hasAny checks if a MachineInfo slice contains at least 1 element.
 func hasAny(machinesInfo []machineproviders.MachineInfo) bool {
 	return len(machinesInfo) > 0
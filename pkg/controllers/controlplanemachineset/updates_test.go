@@ -24,17 +24,20 @@ import (
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
 
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	"github.com/openshift/cluster-api-actuator-pkg/testutils"
 	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
 	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/mock"
 	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -1047,6 +1050,1387 @@ var _ = Describe("reconcileMachineUpdates", func() {
 		)
 	})
 
+	Context("When the update strategy is RollingUpdate with a configured maximum surge", func() {
+		type maxSurgeTableInput struct {
+			replicas            int32
+			maxSurge            string
+			machineInfos        map[int32][]machineproviders.MachineInfo
+			setupMock           func(machineInfos map[int32][]machineproviders.MachineInfo)
+			expectedLogsBuilder func() []testutils.LogEntry
+			expectedConditions  []metav1.Condition
+		}
+
+		DescribeTable("should observe the maximum surge when creating replacement Machines", func(in maxSurgeTableInput) {
+			// We setup the mock machine provider on each test with the expected assertions.
+			in.setupMock(in.machineInfos)
+
+			cpms := cpmsBuilder.WithStrategyType(machinev1.RollingUpdate).WithReplicas(in.replicas).Build()
+			cpms.Annotations = map[string]string{annotations.MaxSurgeAnnotation: in.maxSurge}
+
+			result, err := reconciler.reconcileMachineUpdates(ctx, logger.Logger(), cpms, mockMachineProvider, in.machineInfos)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+			Expect(logger.Entries()).To(ConsistOf(in.expectedLogsBuilder()))
+
+			conditionMatchers := []types.GomegaMatcher{}
+			for _, condition := range in.expectedConditions {
+				conditionMatchers = append(conditionMatchers, testutils.MatchCondition(condition))
+			}
+			Expect(cpms.Status.Conditions).To(ConsistOf(conditionMatchers))
+		},
+			Entry("with a maximum surge of 2, and updates required in multiple indexes", maxSurgeTableInput{
+				replicas: 5,
+				maxSurge: "2",
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+					3: {updatedMachineBuilder.WithIndex(3).WithMachineName("machine-3").WithNodeName("node-3").Build()},
+					4: {updatedMachineBuilder.WithIndex(4).WithMachineName("machine-4").WithNodeName("node-4").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					// Note, in this case it should create two machines.
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(0)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+							},
+							Message: createdReplacement,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: createdReplacement,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: noCapacityForExpansion,
+						},
+					}
+				},
+			}),
+			Entry("with a maximum surge of 2, and a pending replacement in the first index", maxSurgeTableInput{
+				replicas: 5,
+				maxSurge: "2",
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build(),
+						pendingMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build(),
+					},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+					3: {updatedMachineBuilder.WithIndex(3).WithMachineName("machine-3").WithNodeName("node-3").Build()},
+					4: {updatedMachineBuilder.WithIndex(4).WithMachineName("machine-4").WithNodeName("node-4").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					// Note, in this case it should create a single machine as one surge is already in progress.
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+								"replacementName", "machine-replacement-0",
+							},
+							Message: waitingForReplacement,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: createdReplacement,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: noCapacityForExpansion,
+						},
+					}
+				},
+			}),
+			Entry("with a maximum surge that could break etcd quorum", maxSurgeTableInput{
+				replicas: 3,
+				maxSurge: "2",
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Error: fmt.Errorf("%s: %w", annotations.MaxSurgeAnnotation,
+								fmt.Errorf("%w: got 2, allowed range is 1-1", annotations.ErrMaxSurgeOutOfRange)),
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+							},
+							Message: invalidStrategyConfigurationMessage,
+						},
+					}
+				},
+				expectedConditions: []metav1.Condition{
+					{
+						Type:   conditionDegraded,
+						Status: metav1.ConditionTrue,
+						Reason: reasonInvalidStrategy,
+						Message: fmt.Sprintf("%s: %s: %s: got 2, allowed range is 1-1",
+							invalidStrategyConfigurationMessage, annotations.MaxSurgeAnnotation, annotations.ErrMaxSurgeOutOfRange),
+					},
+				},
+			}),
+		)
+	})
+
+	Context("When rollout controls are configured", func() {
+		type rolloutControlsTableInput struct {
+			strategy            machinev1.ControlPlaneMachineSetStrategyType
+			annotations         map[string]string
+			machineInfos        map[int32][]machineproviders.MachineInfo
+			setupMock           func(machineInfos map[int32][]machineproviders.MachineInfo)
+			expectedResult      ctrl.Result
+			expectedLogsBuilder func() []testutils.LogEntry
+			expectedConditions  []metav1.Condition
+		}
+
+		// now is used to evaluate maintenance windows.
+		now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+		closedMaintenanceWindow := map[string]string{
+			annotations.MaintenanceWindowScheduleAnnotation: "0 22 * * *",
+			annotations.MaintenanceWindowDurationAnnotation: "4h",
+		}
+
+		openMaintenanceWindow := map[string]string{
+			annotations.MaintenanceWindowScheduleAnnotation: "0 10 * * *",
+			annotations.MaintenanceWindowDurationAnnotation: "4h",
+		}
+
+		DescribeTable("should complete in progress replacements without starting held replacements", func(in rolloutControlsTableInput) {
+			// We setup the mock machine provider on each test with the expected assertions.
+			in.setupMock(in.machineInfos)
+
+			cpms := cpmsBuilder.WithStrategyType(in.strategy).Build()
+			cpms.Annotations = in.annotations
+
+			reconciler.Clock = clocktesting.NewFakePassiveClock(now)
+
+			result, err := reconciler.reconcileMachineUpdates(ctx, logger.Logger(), cpms, mockMachineProvider, in.machineInfos)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(in.expectedResult))
+			Expect(logger.Entries()).To(ConsistOf(in.expectedLogsBuilder()))
+
+			conditionMatchers := []types.GomegaMatcher{}
+			for _, condition := range in.expectedConditions {
+				conditionMatchers = append(conditionMatchers, testutils.MatchCondition(condition))
+			}
+			Expect(cpms.Status.Conditions).To(ConsistOf(conditionMatchers))
+		},
+			Entry("with a paused RollingUpdate, and updates required in a single index", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PausedAnnotation: "true"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutPaused,
+						},
+					}
+				},
+			}),
+			Entry("with a RollingUpdate that is explicitly not paused", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PausedAnnotation: "false"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: createdReplacement,
+						},
+					}
+				},
+			}),
+			Entry("with a paused RollingUpdate, and a replacement in progress", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PausedAnnotation: "true"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build(),
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithNodeName("node-replacement-0").Build(),
+					},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					// The replacement already in progress should be completed.
+					machineInfo := updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+							},
+							Message: removingOldMachine,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutPaused,
+						},
+					}
+				},
+			}),
+			Entry("with a paused RollingUpdate, and a deleted machine", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PausedAnnotation: "true"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithMachineDeletionTimestamp(metav1.Now()).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					// Deleted machines are always replaced to restore the control plane capacity.
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: createdReplacement,
+						},
+					}
+				},
+			}),
+			Entry("with a paused Recreate, and updates required in a single index", rolloutControlsTableInput{
+				strategy:    machinev1.Recreate,
+				annotations: map[string]string{annotations.PausedAnnotation: "true"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutPaused,
+						},
+					}
+				},
+			}),
+			Entry("with a partitioned RollingUpdate, and updates required in multiple indexes", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PartitionAnnotation: "1"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(0)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+							},
+							Message: createdReplacement,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutHeldByPartition,
+						},
+					}
+				},
+			}),
+			Entry("with a partitioned RollingUpdate, and the partitioned indexes are updated", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PartitionAnnotation: "1"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutHeldByPartition,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: rolloutHeldByPartition,
+						},
+					}
+				},
+			}),
+			Entry("with a partitioned RollingUpdate, and a deleted machine beyond the partition", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PartitionAnnotation: "0"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					// Deleted machines are always replaced to restore the control plane capacity.
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: createdReplacement,
+						},
+					}
+				},
+			}),
+			Entry("with a partitioned Recreate, and updates required in multiple indexes", rolloutControlsTableInput{
+				strategy:    machinev1.Recreate,
+				annotations: map[string]string{annotations.PartitionAnnotation: "1"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutHeldByPartition,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: rolloutHeldByPartition,
+						},
+					}
+				},
+			}),
+			Entry("with an explicit replacement order, and updates required in multiple indexes", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.ReplacementOrderAnnotation: "2,1,0"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithNeedsUpdate(true).Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					// The highest index should be replaced first as it is first in the replacement order.
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(2)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: createdReplacement,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: noCapacityForExpansion,
+						},
+					}
+				},
+			}),
+			Entry("with a RollingUpdate outside the maintenance window, and updates required in a single index", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: closedMaintenanceWindow,
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedResult: ctrl.Result{RequeueAfter: 10 * time.Hour},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutOutsideMaintenanceWindow,
+						},
+					}
+				},
+			}),
+			Entry("with a RollingUpdate outside the maintenance window, and a replacement in progress", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: closedMaintenanceWindow,
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build(),
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithNodeName("node-replacement-0").Build(),
+					},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					// The replacement already in progress should be completed.
+					machineInfo := updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
+				},
+				expectedResult: ctrl.Result{RequeueAfter: 10 * time.Hour},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+							},
+							Message: removingOldMachine,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutOutsideMaintenanceWindow,
+						},
+					}
+				},
+			}),
+			Entry("with a RollingUpdate inside the maintenance window, and updates required in a single index", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: openMaintenanceWindow,
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: createdReplacement,
+						},
+					}
+				},
+			}),
+			Entry("with a Recreate outside the maintenance window, and updates required in a single index", rolloutControlsTableInput{
+				strategy:    machinev1.Recreate,
+				annotations: closedMaintenanceWindow,
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedResult: ctrl.Result{RequeueAfter: 10 * time.Hour},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutOutsideMaintenanceWindow,
+						},
+					}
+				},
+			}),
+			Entry("with an incomplete maintenance window", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.MaintenanceWindowScheduleAnnotation: "0 22 * * *"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Error: fmt.Errorf("failed to parse rollout controls: %w", fmt.Errorf("%s: %w", annotations.MaintenanceWindowDurationAnnotation,
+								annotations.ErrIncompleteMaintenanceWindow)),
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+							},
+							Message: invalidStrategyConfigurationMessage,
+						},
+					}
+				},
+				expectedConditions: []metav1.Condition{
+					{
+						Type:   conditionDegraded,
+						Status: metav1.ConditionTrue,
+						Reason: reasonInvalidStrategy,
+						Message: fmt.Sprintf("%s: failed to parse rollout controls: %s: %s",
+							invalidStrategyConfigurationMessage, annotations.MaintenanceWindowDurationAnnotation, annotations.ErrIncompleteMaintenanceWindow),
+					},
+				},
+			}),
+			Entry("with an invalid paused value", rolloutControlsTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PausedAnnotation: "maybe"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Error: fmt.Errorf("failed to parse rollout controls: %w", fmt.Errorf("%s: %w", annotations.PausedAnnotation,
+								fmt.Errorf("%w: %q", annotations.ErrInvalidBoolean, "maybe"))),
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+							},
+							Message: invalidStrategyConfigurationMessage,
+						},
+					}
+				},
+				expectedConditions: []metav1.Condition{
+					{
+						Type:   conditionDegraded,
+						Status: metav1.ConditionTrue,
+						Reason: reasonInvalidStrategy,
+						Message: fmt.Sprintf("%s: failed to parse rollout controls: %s: %s: %q",
+							invalidStrategyConfigurationMessage, annotations.PausedAnnotation, annotations.ErrInvalidBoolean, "maybe"),
+					},
+				},
+			}),
+		)
+	})
+
+	Context("When Machines are in unavailable failure domains", func() {
+		type evacuationTableInput struct {
+			strategy            machinev1.ControlPlaneMachineSetStrategyType
+			annotations         map[string]string
+			checker             EtcdHealthChecker
+			machineInfos        map[int32][]machineproviders.MachineInfo
+			setupMock           func(machineInfos map[int32][]machineproviders.MachineInfo)
+			expectedResult      ctrl.Result
+			expectedLogsBuilder func() []testutils.LogEntry
+		}
+
+		evacuatingMachineBuilder := updatedMachineBuilder.WithNeedsUpdate(true).WithEvacuating(true)
+
+		DescribeTable("should evacuate the indexes first, one index at a time", func(in evacuationTableInput) {
+			// We setup the mock machine provider on each test with the expected assertions.
+			in.setupMock(in.machineInfos)
+
+			cpms := cpmsBuilder.WithStrategyType(in.strategy).Build()
+			cpms.Annotations = in.annotations
+
+			reconciler.EtcdHealthChecker = in.checker
+
+			result, err := reconciler.reconcileMachineUpdates(ctx, logger.Logger(), cpms, mockMachineProvider, in.machineInfos)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(in.expectedResult))
+			Expect(logger.Entries()).To(ConsistOf(in.expectedLogsBuilder()))
+		},
+			Entry("with a RollingUpdate, and multiple indexes to evacuate", evacuationTableInput{
+				strategy: machinev1.RollingUpdate,
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
+					1: {evacuatingMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+					2: {evacuatingMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: createdReplacement,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: evacuationInProgress,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+							},
+							Message: noCapacityForExpansion,
+						},
+					}
+				},
+			}),
+			Entry("with a RollingUpdate, and an evacuation in progress", evacuationTableInput{
+				strategy: machinev1.RollingUpdate,
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						evacuatingMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build(),
+						pendingMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build(),
+					},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+					2: {evacuatingMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+								"replacementName", "machine-replacement-0",
+							},
+							Message: waitingForReplacement,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: evacuationInProgress,
+						},
+					}
+				},
+			}),
+			Entry("with a RollingUpdate, and no capacity to surge", evacuationTableInput{
+				strategy: machinev1.RollingUpdate,
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build(),
+						pendingMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build(),
+					},
+					1: {evacuatingMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+					2: {evacuatingMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: noCapacityForExpansion,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: noCapacityForExpansion,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+								"replacementName", "machine-replacement-0",
+							},
+							Message: waitingForReplacement,
+						},
+					}
+				},
+			}),
+			Entry("with a partitioned RollingUpdate, evacuates indexes beyond the partition", evacuationTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PartitionAnnotation: "0"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {evacuatingMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(2)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: createdReplacement,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: rolloutHeldByPartition,
+						},
+					}
+				},
+			}),
+			Entry("with a paused RollingUpdate, does not evacuate", evacuationTableInput{
+				strategy:    machinev1.RollingUpdate,
+				annotations: map[string]string{annotations.PausedAnnotation: "true"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+					2: {evacuatingMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: rolloutPaused,
+						},
+					}
+				},
+			}),
+			Entry("with a Recreate, and multiple indexes to evacuate", evacuationTableInput{
+				strategy: machinev1.Recreate,
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {evacuatingMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+					2: {evacuatingMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					machineInfo := evacuatingMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: removingOldMachine,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: evacuationInProgress,
+						},
+					}
+				},
+			}),
+			Entry("with a Recreate, and etcd blocking the removal of the first index to evacuate", evacuationTableInput{
+				strategy: machinev1.Recreate,
+				checker:  machineEtcdHealthChecker{"machine-1": "etcd member on Node node-1 is not healthy"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {evacuatingMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+					2: {evacuatingMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					machineInfo := evacuatingMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
+				},
+				expectedResult: ctrl.Result{RequeueAfter: etcdHealthRecheckInterval},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+								"reason", "etcd member on Node node-1 is not healthy",
+							},
+							Message: etcdNotReadyForRemoval,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: etcdReadyForRemoval,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(2),
+								"namespace", namespaceName,
+								"name", "machine-2",
+							},
+							Message: removingOldMachine,
+						},
+					}
+				},
+			}),
+		)
+	})
+
+	Context("When an etcd health checker is configured", func() {
+		type etcdHealthTableInput struct {
+			strategy             machinev1.ControlPlaneMachineSetStrategyType
+			checker              EtcdHealthChecker
+			machineInfos         map[int32][]machineproviders.MachineInfo
+			setupMock            func(machineInfos map[int32][]machineproviders.MachineInfo)
+			expectedErrorBuilder func() error
+			expectedResult       ctrl.Result
+			expectedLogsBuilder  func() []testutils.LogEntry
+			expectedConditions   []metav1.Condition
+		}
+
+		checkerError := errors.New("checker error")
+
+		DescribeTable("should gate the removal of replaced Machines on the etcd health", func(in etcdHealthTableInput) {
+			// We setup the mock machine provider on each test with the expected assertions.
+			in.setupMock(in.machineInfos)
+
+			cpms := cpmsBuilder.WithStrategyType(in.strategy).Build()
+
+			reconciler.EtcdHealthChecker = in.checker
+
+			result, err := reconciler.reconcileMachineUpdates(ctx, logger.Logger(), cpms, mockMachineProvider, in.machineInfos)
+			if in.expectedErrorBuilder != nil {
+				Expect(err).To(MatchError(in.expectedErrorBuilder()))
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			Expect(result).To(Equal(in.expectedResult))
+			Expect(logger.Entries()).To(ConsistOf(in.expectedLogsBuilder()))
+			Expect(cpms.Status.Conditions).To(testutils.MatchConditions(in.expectedConditions))
+		},
+			Entry("when etcd is ready for the removal of the replaced Machine", etcdHealthTableInput{
+				strategy: machinev1.RollingUpdate,
+				checker:  staticEtcdHealthChecker{},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build(),
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithNodeName("node-replacement-0").Build(),
+					},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					machineInfo := updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+							},
+							Message: etcdReadyForRemoval,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+							},
+							Message: removingOldMachine,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: noCapacityForExpansion,
+						},
+					}
+				},
+				expectedConditions: []metav1.Condition{
+					{
+						Type:   conditionEtcdReadyForRemoval,
+						Status: metav1.ConditionTrue,
+						Reason: reasonAsExpected,
+					},
+				},
+			}),
+			Entry("when etcd is not ready for the removal of the replaced Machine", etcdHealthTableInput{
+				strategy: machinev1.RollingUpdate,
+				checker:  staticEtcdHealthChecker{message: "etcd member on Node node-replacement-0 has not yet joined the cluster"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build(),
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithNodeName("node-replacement-0").Build(),
+					},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedResult: ctrl.Result{RequeueAfter: etcdHealthRecheckInterval},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+								"reason", "etcd member on Node node-replacement-0 has not yet joined the cluster",
+							},
+							Message: etcdNotReadyForRemoval,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: noCapacityForExpansion,
+						},
+					}
+				},
+				expectedConditions: []metav1.Condition{
+					{
+						Type:    conditionEtcdReadyForRemoval,
+						Status:  metav1.ConditionFalse,
+						Reason:  reasonWaitingForEtcd,
+						Message: "Waiting for etcd before removing replaced Machine(s): index 0: etcd member on Node node-replacement-0 has not yet joined the cluster",
+					},
+				},
+			}),
+			Entry("when the replaced Machine is not ready, and etcd is not ready", etcdHealthTableInput{
+				strategy: machinev1.RollingUpdate,
+				checker:  staticEtcdHealthChecker{message: "etcd member on Node node-replacement-0 has not yet joined the cluster"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						outdatedNonReadyMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build(),
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithNodeName("node-replacement-0").Build(),
+					},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					// A Machine that is not ready does not host a healthy etcd member, so it is removed without
+					// consulting the etcd health.
+					machineInfo := outdatedNonReadyMachineBuilder.WithIndex(0).WithMachineName("machine-0").Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+							},
+							Message: removingOldMachine,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.RollingUpdate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: noCapacityForExpansion,
+						},
+					}
+				},
+				expectedConditions: []metav1.Condition{
+					{
+						Type:   conditionEtcdReadyForRemoval,
+						Status: metav1.ConditionTrue,
+						Reason: reasonAsExpected,
+					},
+				},
+			}),
+			Entry("when the etcd health cannot be checked", etcdHealthTableInput{
+				strategy: machinev1.RollingUpdate,
+				checker:  staticEtcdHealthChecker{err: checkerError},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build(),
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithNodeName("node-replacement-0").Build(),
+					},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedErrorBuilder: func() error {
+					return fmt.Errorf("error checking etcd health: %w", checkerError)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{}
+				},
+			}),
+			Entry("with a Recreate, when etcd quorum is maintained without the outdated Machine", etcdHealthTableInput{
+				strategy: machinev1.Recreate,
+				checker:  staticEtcdHealthChecker{},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					machineInfo := updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: etcdReadyForRemoval,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: removingOldMachine,
+						},
+					}
+				},
+				expectedConditions: []metav1.Condition{
+					{
+						Type:   conditionEtcdReadyForRemoval,
+						Status: metav1.ConditionTrue,
+						Reason: reasonAsExpected,
+					},
+				},
+			}),
+			Entry("with a Recreate, when etcd quorum would be lost without the outdated Machine", etcdHealthTableInput{
+				strategy: machinev1.Recreate,
+				checker:  staticEtcdHealthChecker{message: "removal would leave 1 of 3 etcd voting members healthy, below the quorum of 2"},
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedResult: ctrl.Result{RequeueAfter: etcdHealthRecheckInterval},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+								"reason", "removal would leave 1 of 3 etcd voting members healthy, below the quorum of 2",
+							},
+							Message: etcdNotReadyForRemoval,
+						},
+					}
+				},
+				expectedConditions: []metav1.Condition{
+					{
+						Type:    conditionEtcdReadyForRemoval,
+						Status:  metav1.ConditionFalse,
+						Reason:  reasonWaitingForEtcd,
+						Message: "Waiting for etcd before removing replaced Machine(s): index 1: removal would leave 1 of 3 etcd voting members healthy, below the quorum of 2",
+					},
+				},
+			}),
+		)
+	})
+
 	Context("When the update strategy is OnDelete", func() {
 		BeforeEach(func() {
 			cpmsBuilder = cpmsBuilder.WithStrategyType(machinev1.OnDelete)
@@ -1091,26 +2475,228 @@ var _ = Describe("reconcileMachineUpdates", func() {
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{
+					return []testutils.LogEntry{
+						{
+							Level: 4,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.OnDelete,
+							},
+							Message: noUpdatesRequired,
+						},
+					}
+				},
+			}),
+			Entry("with updates required in a single index, and the machine is not yet deleted", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(1),
+							"namespace", namespaceName,
+							"name", "machine-1",
+						},
+						Message: machineRequiresUpdate,
+					},
+					}
+				},
+			}),
+			Entry("with updates required in a single index, and the machine has been deleted", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(1),
+							"namespace", namespaceName,
+							"name", "machine-1",
+						},
+						Message: createdReplacement,
+					},
+					}
+				},
+			}),
+			Entry("with updates required in a single index, and the machine has been deleted (with no standard machine indexes)", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					3: {updatedMachineBuilder.WithIndex(3).WithMachineName("machine-3").WithNodeName("node-3").Build()},
+					4: {updatedMachineBuilder.WithIndex(4).WithMachineName("machine-4").WithNodeName("node-4").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
+					5: {updatedMachineBuilder.WithIndex(5).WithMachineName("machine-5").WithNodeName("node-5").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(4)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(4),
+							"namespace", namespaceName,
+							"name", "machine-4",
+						},
+						Message: createdReplacement,
+					},
+					}
+				},
+			}),
+			Entry("with updates required in a single index, and the machine has been deleted, and an error occurrs", onDeleteUpdateTableInput{
+				cpmsBuilder:          cpmsBuilder.WithReplicas(3),
+				expectedErrorBuilder: func() error { return fmt.Errorf("error creating new Machine for index %d: %w", 1, transientError) },
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(transientError).Times(1)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Error: fmt.Errorf("error creating new Machine for index %d: %w", 1, transientError),
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(1),
+							"namespace", namespaceName,
+							"name", "machine-1",
+						},
+						Message: errorCreatingMachine,
+					},
+					}
+				},
+			}),
+			Entry("with updates required in a single index, and replacement machine is pending", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
+						pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+					},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(0)).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(1),
+							"namespace", namespaceName,
+							"name", "machine-1",
+							"replacementName", "machine-replacement-1",
+						},
+						Message: waitingForReplacement,
+					},
+					}
+				},
+			}),
+			Entry("with updates required in a single index, and replacement machine is ready", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithNodeName("node-replacement-1").Build(),
+					},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(0)).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(1),
+							"namespace", namespaceName,
+							"name", "machine-1",
+						},
+						Message: waitingForRemoved,
+					},
+					}
+				},
+			}),
+			Entry("with updates required in multiple indexes, and the machines are not yet deleted", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(0),
+							"namespace", namespaceName,
+							"name", "machine-0",
+						},
+						Message: machineRequiresUpdate,
+					},
 						{
-							Level: 4,
+							Level: 2,
 							KeysAndValues: []interface{}{
 								"updateStrategy", machinev1.OnDelete,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
 							},
-							Message: noUpdatesRequired,
+							Message: machineRequiresUpdate,
 						},
 					}
 				},
 			}),
-			Entry("with updates required in a single index, and the machine is not yet deleted", onDeleteUpdateTableInput{
-				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+			Entry("with updates required in a multiple indexes, and machine has been deleted, and an error occurrs", onDeleteUpdateTableInput{
+				cpmsBuilder:          cpmsBuilder.WithReplicas(3),
+				expectedErrorBuilder: func() error { return fmt.Errorf("error creating new Machine for index %d: %w", 1, transientError) },
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
-					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
 					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(transientError).Times(1)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
@@ -1118,19 +2704,29 @@ var _ = Describe("reconcileMachineUpdates", func() {
 						Level: 2,
 						KeysAndValues: []interface{}{
 							"updateStrategy", machinev1.OnDelete,
-							"index", int32(1),
+							"index", int32(0),
 							"namespace", namespaceName,
-							"name", "machine-1",
+							"name", "machine-0",
 						},
 						Message: machineRequiresUpdate,
 					},
+						{
+							Error: fmt.Errorf("error creating new Machine for index %d: %w", 1, transientError),
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.OnDelete,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: errorCreatingMachine,
+						},
 					}
 				},
 			}),
-			Entry("with updates required in a single index, and the machine has been deleted", onDeleteUpdateTableInput{
+			Entry("with updates required in multiple indexes, and a machine has been deleted", onDeleteUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
 					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
 					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
@@ -1145,26 +2741,37 @@ var _ = Describe("reconcileMachineUpdates", func() {
 						Level: 2,
 						KeysAndValues: []interface{}{
 							"updateStrategy", machinev1.OnDelete,
-							"index", int32(1),
+							"index", int32(0),
 							"namespace", namespaceName,
-							"name", "machine-1",
+							"name", "machine-0",
 						},
-						Message: createdReplacement,
+						Message: machineRequiresUpdate,
 					},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.OnDelete,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: createdReplacement,
+						},
 					}
 				},
 			}),
-			Entry("with updates required in a single index, and the machine has been deleted (with no standard machine indexes)", onDeleteUpdateTableInput{
+			Entry("with updates required in multiple indexes, and multiple machines have been deleted", onDeleteUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					3: {updatedMachineBuilder.WithIndex(3).WithMachineName("machine-3").WithNodeName("node-3").Build()},
-					4: {updatedMachineBuilder.WithIndex(4).WithMachineName("machine-4").WithNodeName("node-4").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
-					5: {updatedMachineBuilder.WithIndex(5).WithMachineName("machine-5").WithNodeName("node-5").Build()},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
 					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
 					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(4)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(0)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
@@ -1172,55 +2779,202 @@ var _ = Describe("reconcileMachineUpdates", func() {
 						Level: 2,
 						KeysAndValues: []interface{}{
 							"updateStrategy", machinev1.OnDelete,
-							"index", int32(4),
+							"index", int32(0),
 							"namespace", namespaceName,
-							"name", "machine-4",
+							"name", "machine-0",
 						},
 						Message: createdReplacement,
 					},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.OnDelete,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: createdReplacement,
+						},
 					}
 				},
 			}),
-			Entry("with updates required in a single index, and the machine has been deleted, and an error occurrs", onDeleteUpdateTableInput{
-				cpmsBuilder:          cpmsBuilder.WithReplicas(3),
-				expectedErrorBuilder: func() error { return fmt.Errorf("error creating new Machine for index %d: %w", 1, transientError) },
+			Entry("with updates required in multiple indexes, and a single machine has been deleted, and the replacement machine is pending", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
-					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
+					1: {
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
+						pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+					},
 					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
-					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
-					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(transientError).Times(1)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
 					return []testutils.LogEntry{{
-						Error: fmt.Errorf("error creating new Machine for index %d: %w", 1, transientError),
+						Level: 2,
 						KeysAndValues: []interface{}{
 							"updateStrategy", machinev1.OnDelete,
-							"index", int32(1),
+							"index", int32(0),
 							"namespace", namespaceName,
-							"name", "machine-1",
+							"name", "machine-0",
 						},
-						Message: errorCreatingMachine,
+						Message: machineRequiresUpdate,
 					},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.OnDelete,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+								"replacementName", "machine-replacement-1",
+							},
+							Message: waitingForReplacement,
+						},
 					}
 				},
 			}),
-			Entry("with updates required in a single index, and replacement machine is pending", onDeleteUpdateTableInput{
+			Entry("with updates required in multiple indexes, and multiple machines have been deleted, and the replacement machines are pending", onDeleteUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
+						pendingMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build(),
+					},
+					1: {
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
+						pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+					},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(0),
+							"namespace", namespaceName,
+							"name", "machine-0",
+							"replacementName", "machine-replacement-0",
+						},
+						Message: waitingForReplacement,
+					},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.OnDelete,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+								"replacementName", "machine-replacement-1",
+							},
+							Message: waitingForReplacement,
+						},
+					}
+				},
+			}),
+			Entry("with updates required in multiple indexes, and a single machine has been deleted, and the replacement machine is ready", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
+					1: {
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithNodeName("node-replacement-1").Build(),
+					},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(0),
+							"namespace", namespaceName,
+							"name", "machine-0",
+						},
+						Message: machineRequiresUpdate,
+					},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.OnDelete,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: waitingForRemoved,
+						},
+					}
+				},
+			}),
+			Entry("with updates required in multiple indexes, and multiple machines have been deleted, and a replacement machine is ready, and a replacement machine is pending", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
+						pendingMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build(),
+					},
+					1: {
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithNodeName("node-replacement-1").Build(),
+					},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"updateStrategy", machinev1.OnDelete,
+							"index", int32(0),
+							"namespace", namespaceName,
+							"name", "machine-0",
+							"replacementName", "machine-replacement-0",
+						},
+						Message: waitingForReplacement,
+					},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.OnDelete,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: waitingForRemoved,
+						},
+					}
+				},
+			}),
+			Entry("with updates required in multiple indexes, and multiple machines have been deleted, and all replacement machines are ready", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				machineInfos: map[int32][]machineproviders.MachineInfo{
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithNodeName("node-replacement-0").Build(),
+					},
 					1: {
 						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
+						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithNodeName("node-replacement-1").Build(),
 					},
 					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(0)).Times(0)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
@@ -1228,28 +2982,36 @@ var _ = Describe("reconcileMachineUpdates", func() {
 						Level: 2,
 						KeysAndValues: []interface{}{
 							"updateStrategy", machinev1.OnDelete,
-							"index", int32(1),
+							"index", int32(0),
 							"namespace", namespaceName,
-							"name", "machine-1",
-							"replacementName", "machine-replacement-1",
+							"name", "machine-0",
 						},
-						Message: waitingForReplacement,
+						Message: waitingForRemoved,
 					},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.OnDelete,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: waitingForRemoved,
+						},
 					}
 				},
 			}),
-			Entry("with updates required in a single index, and replacement machine is ready", onDeleteUpdateTableInput{
+			Entry("with an empty index", onDeleteUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
 					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
-					1: {
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithNodeName("node-replacement-1").Build(),
-					},
-					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+					2: {},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(0)).Times(0)
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(2)).Return(nil).Times(1)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
@@ -1257,21 +3019,21 @@ var _ = Describe("reconcileMachineUpdates", func() {
 						Level: 2,
 						KeysAndValues: []interface{}{
 							"updateStrategy", machinev1.OnDelete,
-							"index", int32(1),
+							"index", int32(2),
 							"namespace", namespaceName,
-							"name", "machine-1",
+							"name", "<Unknown>",
 						},
-						Message: waitingForRemoved,
+						Message: createdReplacement,
 					},
 					}
 				},
 			}),
-			Entry("with updates required in multiple indexes, and the machines are not yet deleted", onDeleteUpdateTableInput{
+			Entry("with a pending machine in an index", onDeleteUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
-					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
-					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+					2: {pendingMachineBuilder.WithIndex(2).WithMachineName("machine-replacement-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
 					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
@@ -1282,37 +3044,26 @@ var _ = Describe("reconcileMachineUpdates", func() {
 						Level: 2,
 						KeysAndValues: []interface{}{
 							"updateStrategy", machinev1.OnDelete,
-							"index", int32(0),
+							"index", int32(2),
 							"namespace", namespaceName,
-							"name", "machine-0",
+							"name", "machine-replacement-2",
 						},
-						Message: machineRequiresUpdate,
+						Message: waitingForReady,
 					},
-						{
-							Level: 2,
-							KeysAndValues: []interface{}{
-								"updateStrategy", machinev1.OnDelete,
-								"index", int32(1),
-								"namespace", namespaceName,
-								"name", "machine-1",
-							},
-							Message: machineRequiresUpdate,
-						},
 					}
 				},
 			}),
-			Entry("with updates required in a multiple indexes, and machine has been deleted, and an error occurrs", onDeleteUpdateTableInput{
-				cpmsBuilder:          cpmsBuilder.WithReplicas(3),
-				expectedErrorBuilder: func() error { return fmt.Errorf("error creating new Machine for index %d: %w", 1, transientError) },
+			Entry("with a missing index, and other indexes need updating", onDeleteUpdateTableInput{
+				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
-					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
-					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
 					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
 					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(transientError).Times(1)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(2)).Return(nil).Times(1)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
@@ -1320,36 +3071,34 @@ var _ = Describe("reconcileMachineUpdates", func() {
 						Level: 2,
 						KeysAndValues: []interface{}{
 							"updateStrategy", machinev1.OnDelete,
-							"index", int32(0),
+							"index", int32(1),
 							"namespace", namespaceName,
-							"name", "machine-0",
+							"name", "machine-1",
 						},
 						Message: machineRequiresUpdate,
 					},
 						{
-							Error: fmt.Errorf("error creating new Machine for index %d: %w", 1, transientError),
+							Level: 2,
 							KeysAndValues: []interface{}{
 								"updateStrategy", machinev1.OnDelete,
-								"index", int32(1),
+								"index", int32(2),
 								"namespace", namespaceName,
-								"name", "machine-1",
+								"name", "<Unknown>",
 							},
-							Message: errorCreatingMachine,
+							Message: createdReplacement,
 						},
 					}
 				},
 			}),
-			Entry("with updates required in multiple indexes, and a machine has been deleted", onDeleteUpdateTableInput{
+			Entry("with a pending machine in an index, and other indexes need updating", onDeleteUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
-					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
-					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {pendingMachineBuilder.WithIndex(2).WithMachineName("machine-replacement-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
-					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
-					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
@@ -1357,9 +3106,9 @@ var _ = Describe("reconcileMachineUpdates", func() {
 						Level: 2,
 						KeysAndValues: []interface{}{
 							"updateStrategy", machinev1.OnDelete,
-							"index", int32(0),
+							"index", int32(1),
 							"namespace", namespaceName,
-							"name", "machine-0",
+							"name", "machine-1",
 						},
 						Message: machineRequiresUpdate,
 					},
@@ -1367,61 +3116,83 @@ var _ = Describe("reconcileMachineUpdates", func() {
 							Level: 2,
 							KeysAndValues: []interface{}{
 								"updateStrategy", machinev1.OnDelete,
-								"index", int32(1),
+								"index", int32(2),
 								"namespace", namespaceName,
-								"name", "machine-1",
+								"name", "machine-replacement-2",
 							},
-							Message: createdReplacement,
+							Message: waitingForReady,
 						},
 					}
 				},
 			}),
-			Entry("with updates required in multiple indexes, and multiple machines have been deleted", onDeleteUpdateTableInput{
+			Entry("with no updates required, but a Machine has been deleted", onDeleteUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
-					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
-					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithMachineDeletionTimestamp(metav1.Now()).Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
 					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
 					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(0)).Return(nil).Times(1)
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(2)).Times(1)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(0),
-							"namespace", namespaceName,
-							"name", "machine-0",
-						},
-						Message: createdReplacement,
-					},
+					return []testutils.LogEntry{
 						{
 							Level: 2,
 							KeysAndValues: []interface{}{
 								"updateStrategy", machinev1.OnDelete,
-								"index", int32(1),
+								"index", int32(2),
 								"namespace", namespaceName,
-								"name", "machine-1",
+								"name", "machine-2",
 							},
 							Message: createdReplacement,
 						},
 					}
 				},
 			}),
-			Entry("with updates required in multiple indexes, and a single machine has been deleted, and the replacement machine is pending", onDeleteUpdateTableInput{
+		)
+	})
+
+	Context("When the update strategy is Recreate", func() {
+		BeforeEach(func() {
+			cpmsBuilder = cpmsBuilder.WithStrategyType(machinev1.Recreate)
+		})
+
+		type recreateUpdateTableInput struct {
+			cpmsBuilder          machinev1resourcebuilder.ControlPlaneMachineSetInterface
+			machineInfos         map[int32][]machineproviders.MachineInfo
+			setupMock            func(machineInfos map[int32][]machineproviders.MachineInfo)
+			expectedErrorBuilder func() error
+			expectedResult       ctrl.Result
+			expectedLogsBuilder  func() []testutils.LogEntry
+		}
+
+		DescribeTable("should implement the update strategy based on the MachineInfo", func(in recreateUpdateTableInput) {
+			// We setup the mock machine provider on each test with the expected assertions.
+			in.setupMock(in.machineInfos)
+
+			cpms := cpmsBuilder.Build()
+			originalCPMS := cpms.DeepCopy()
+
+			result, err := reconciler.reconcileMachineUpdates(ctx, logger.Logger(), cpms, mockMachineProvider, in.machineInfos)
+			if in.expectedErrorBuilder != nil {
+				Expect(err).To(MatchError(in.expectedErrorBuilder()))
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			Expect(result).To(Equal(in.expectedResult))
+			Expect(logger.Entries()).To(ConsistOf(in.expectedLogsBuilder()))
+			Expect(cpms).To(Equal(originalCPMS), "The update functions should not modify the ControlPlaneMachineSet in any way")
+		},
+			Entry("with no updates required", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
-					1: {
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
-					},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
 					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
@@ -1429,122 +3200,82 @@ var _ = Describe("reconcileMachineUpdates", func() {
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(0),
-							"namespace", namespaceName,
-							"name", "machine-0",
-						},
-						Message: machineRequiresUpdate,
-					},
+					return []testutils.LogEntry{
 						{
-							Level: 2,
+							Level: 4,
 							KeysAndValues: []interface{}{
-								"updateStrategy", machinev1.OnDelete,
-								"index", int32(1),
-								"namespace", namespaceName,
-								"name", "machine-1",
-								"replacementName", "machine-replacement-1",
+								"updateStrategy", machinev1.Recreate,
 							},
-							Message: waitingForReplacement,
+							Message: noUpdatesRequired,
 						},
 					}
 				},
 			}),
-			Entry("with updates required in multiple indexes, and multiple machines have been deleted, and the replacement machines are pending", onDeleteUpdateTableInput{
+			Entry("with updates required in a single index", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {
-						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						pendingMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build(),
-					},
-					1: {
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build(),
-					},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
 					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
 					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					// We expect the outdated machine to be removed before any replacement is created.
+					machineInfo := updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(0),
-							"namespace", namespaceName,
-							"name", "machine-0",
-							"replacementName", "machine-replacement-0",
-						},
-						Message: waitingForReplacement,
-					},
+					return []testutils.LogEntry{
 						{
 							Level: 2,
 							KeysAndValues: []interface{}{
-								"updateStrategy", machinev1.OnDelete,
+								"updateStrategy", machinev1.Recreate,
 								"index", int32(1),
 								"namespace", namespaceName,
 								"name", "machine-1",
-								"replacementName", "machine-replacement-1",
 							},
-							Message: waitingForReplacement,
+							Message: removingOldMachine,
 						},
 					}
 				},
 			}),
-			Entry("with updates required in multiple indexes, and a single machine has been deleted, and the replacement machine is ready", onDeleteUpdateTableInput{
+			Entry("with updates required in a single index, and an error occurs", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
+				expectedErrorBuilder: func() error {
+					return fmt.Errorf("error deleting Machine %s/%s: %w", namespaceName, "machine-1", transientError)
+				},
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
-					1: {
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithNodeName("node-replacement-1").Build(),
-					},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
 					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
-				},
-				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-				},
-				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(0),
-							"namespace", namespaceName,
-							"name", "machine-0",
-						},
-						Message: machineRequiresUpdate,
-					},
+				},
+				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					machineInfo := updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(transientError).Times(1)
+				},
+				expectedLogsBuilder: func() []testutils.LogEntry {
+					return []testutils.LogEntry{
 						{
-							Level: 2,
+							Error: fmt.Errorf("error deleting Machine %s/%s: %w", namespaceName, "machine-1", transientError),
 							KeysAndValues: []interface{}{
-								"updateStrategy", machinev1.OnDelete,
+								"updateStrategy", machinev1.Recreate,
 								"index", int32(1),
 								"namespace", namespaceName,
 								"name", "machine-1",
 							},
-							Message: waitingForRemoved,
+							Message: errorDeletingMachine,
 						},
 					}
 				},
 			}),
-			Entry("with updates required in multiple indexes, and multiple machines have been deleted, and a replacement machine is ready, and a replacement machine is pending", onDeleteUpdateTableInput{
+			Entry("with updates required in a single index, and the outdated machine is being removed", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {
-						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						pendingMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build(),
-					},
-					1: {
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithNodeName("node-replacement-1").Build(),
-					},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build()},
 					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
@@ -1552,219 +3283,225 @@ var _ = Describe("reconcileMachineUpdates", func() {
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(0),
-							"namespace", namespaceName,
-							"name", "machine-0",
-							"replacementName", "machine-replacement-0",
-						},
-						Message: waitingForReplacement,
-					},
+					return []testutils.LogEntry{
 						{
 							Level: 2,
 							KeysAndValues: []interface{}{
-								"updateStrategy", machinev1.OnDelete,
+								"updateStrategy", machinev1.Recreate,
 								"index", int32(1),
 								"namespace", namespaceName,
 								"name", "machine-1",
 							},
-							Message: waitingForRemoved,
+							Message: waitingForRemovedBeforeReplacement,
 						},
 					}
 				},
 			}),
-			Entry("with updates required in multiple indexes, and multiple machines have been deleted, and all replacement machines are ready", onDeleteUpdateTableInput{
+			Entry("with updates required in a single index, and the outdated machine has been removed", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {
-						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithNodeName("node-replacement-0").Build(),
-					},
-					1: {
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).WithMachineDeletionTimestamp(metav1.Now()).Build(),
-						updatedMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").WithNodeName("node-replacement-1").Build(),
-					},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					1: {},
 					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
+					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(1)).Return(nil).Times(1)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(0),
-							"namespace", namespaceName,
-							"name", "machine-0",
-						},
-						Message: waitingForRemoved,
-					},
+					return []testutils.LogEntry{
 						{
 							Level: 2,
 							KeysAndValues: []interface{}{
-								"updateStrategy", machinev1.OnDelete,
+								"updateStrategy", machinev1.Recreate,
 								"index", int32(1),
 								"namespace", namespaceName,
-								"name", "machine-1",
+								"name", unknownMachineName,
 							},
-							Message: waitingForRemoved,
+							Message: createdReplacement,
 						},
 					}
 				},
 			}),
-			Entry("with an empty index", onDeleteUpdateTableInput{
+			Entry("with updates required in a single index, and the replacement machine is pending", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
 					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
-					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
-					2: {},
+					1: {pendingMachineBuilder.WithIndex(1).WithMachineName("machine-replacement-1").Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
-					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
-					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(2)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(2),
-							"namespace", namespaceName,
-							"name", "<Unknown>",
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-replacement-1",
+							},
+							Message: waitingForReady,
 						},
-						Message: createdReplacement,
-					},
 					}
 				},
 			}),
-			Entry("with a pending machine in an index", onDeleteUpdateTableInput{
+			Entry("with updates required in multiple indexes", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
-					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
-					2: {pendingMachineBuilder.WithIndex(2).WithMachineName("machine-replacement-2").Build()},
+					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
+					// Note, in this case it should only remove a single machine.
 					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					machineInfo := updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(2),
-							"namespace", namespaceName,
-							"name", "machine-replacement-2",
+					return []testutils.LogEntry{
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(0),
+								"namespace", namespaceName,
+								"name", "machine-0",
+							},
+							Message: removingOldMachine,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: noCapacityForRemoval,
 						},
-						Message: waitingForReady,
-					},
 					}
 				},
 			}),
-			Entry("with a missing index, and other indexes need updating", onDeleteUpdateTableInput{
+			Entry("with updates required in multiple indexes, and the first outdated machine has been removed", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					0: {},
 					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
-					2: {},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
 					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
 					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(2)).Return(nil).Times(1)
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(0)).Return(nil).Times(1)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(1),
-							"namespace", namespaceName,
-							"name", "machine-1",
-						},
-						Message: machineRequiresUpdate,
-					},
+					return []testutils.LogEntry{
 						{
 							Level: 2,
 							KeysAndValues: []interface{}{
-								"updateStrategy", machinev1.OnDelete,
-								"index", int32(2),
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(0),
 								"namespace", namespaceName,
-								"name", "<Unknown>",
+								"name", unknownMachineName,
 							},
 							Message: createdReplacement,
 						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: noCapacityForRemoval,
+						},
 					}
 				},
 			}),
-			Entry("with a pending machine in an index, and other indexes need updating", onDeleteUpdateTableInput{
+			Entry("with updates required in multiple indexes, and the first replacement machine is pending", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
+					0: {pendingMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").Build()},
 					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
-					2: {pendingMachineBuilder.WithIndex(2).WithMachineName("machine-replacement-2").Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
 					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
-					return []testutils.LogEntry{{
-						Level: 2,
-						KeysAndValues: []interface{}{
-							"updateStrategy", machinev1.OnDelete,
-							"index", int32(1),
-							"namespace", namespaceName,
-							"name", "machine-1",
-						},
-						Message: machineRequiresUpdate,
-					},
+					return []testutils.LogEntry{
 						{
 							Level: 2,
 							KeysAndValues: []interface{}{
-								"updateStrategy", machinev1.OnDelete,
-								"index", int32(2),
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(0),
 								"namespace", namespaceName,
-								"name", "machine-replacement-2",
+								"name", "machine-replacement-0",
 							},
 							Message: waitingForReady,
 						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: noCapacityForRemoval,
+						},
 					}
 				},
 			}),
-			Entry("with no updates required, but a Machine has been deleted", onDeleteUpdateTableInput{
+			Entry("with updates required in multiple indexes, and an outdated machine has a ready replacement", recreateUpdateTableInput{
 				cpmsBuilder: cpmsBuilder.WithReplicas(3),
 				machineInfos: map[int32][]machineproviders.MachineInfo{
-					0: {updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").Build()},
-					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").Build()},
-					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").WithMachineDeletionTimestamp(metav1.Now()).Build()},
+					0: {
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build(),
+						updatedMachineBuilder.WithIndex(0).WithMachineName("machine-replacement-0").WithNodeName("node-replacement-0").Build(),
+					},
+					1: {updatedMachineBuilder.WithIndex(1).WithMachineName("machine-1").WithNodeName("node-1").WithNeedsUpdate(true).Build()},
+					2: {updatedMachineBuilder.WithIndex(2).WithMachineName("machine-2").WithNodeName("node-2").Build()},
 				},
 				setupMock: func(machineInfos map[int32][]machineproviders.MachineInfo) {
-					mockMachineProvider.EXPECT().WithClient(gomock.Any()).Return(mockMachineProvider).AnyTimes()
-					mockMachineProvider.EXPECT().GetMachineInfos(gomock.Any(), gomock.Any()).Return(machineInfosMaptoSlice(machineInfos), nil).AnyTimes()
-					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), int32(2)).Times(1)
-					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					// Note, in this case, it should wait for the old Machine to go away before starting a new update.
+					mockMachineProvider.EXPECT().CreateMachine(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+					machineInfo := updatedMachineBuilder.WithIndex(0).WithMachineName("machine-0").WithNodeName("node-0").WithNeedsUpdate(true).Build()
+					mockMachineProvider.EXPECT().DeleteMachine(gomock.Any(), gomock.Any(), machineInfo.MachineRef).Return(nil).Times(1)
 				},
 				expectedLogsBuilder: func() []testutils.LogEntry {
 					return []testutils.LogEntry{
 						{
 							Level: 2,
 							KeysAndValues: []interface{}{
-								"updateStrategy", machinev1.OnDelete,
-								"index", int32(2),
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(0),
 								"namespace", namespaceName,
-								"name", "machine-2",
+								"name", "machine-0",
 							},
-							Message: createdReplacement,
+							Message: removingOldMachine,
+						},
+						{
+							Level: 2,
+							KeysAndValues: []interface{}{
+								"updateStrategy", machinev1.Recreate,
+								"index", int32(1),
+								"namespace", namespaceName,
+								"name", "machine-1",
+							},
+							Message: noCapacityForRemoval,
 						},
 					}
 				},
@@ -1772,44 +3509,6 @@ var _ = Describe("reconcileMachineUpdates", func() {
 		)
 	})
 
-	Context("When the update strategy is Recreate", func() {
-		var cpms *machinev1.ControlPlaneMachineSet
-		var result ctrl.Result
-		var err error
-
-		BeforeEach(func() {
-			cpms = cpmsBuilder.WithStrategyType(machinev1.Recreate).Build()
-
-			machineInfos := map[int32][]machineproviders.MachineInfo{}
-
-			result, err = reconciler.reconcileMachineUpdates(ctx, logger.Logger(), cpms, mockMachineProvider, machineInfos)
-		})
-
-		It("Returns an empty result", func() {
-			Expect(result).To(Equal(ctrl.Result{}))
-		})
-
-		It("Does not return an error", func() {
-			Expect(err).ToNot(HaveOccurred(), "This is a terminal error, returning an error would force a requeue which is not desired")
-		})
-
-		It("Logs that the strategy is invalid", func() {
-			Expect(logger.Entries()).To(ConsistOf(testutils.LogEntry{
-				Error:   errRecreateStrategyNotSupported,
-				Message: invalidStrategyMessage,
-			}))
-		})
-
-		It("Sets the degraded condition", func() {
-			Expect(cpms.Status.Conditions).To(ConsistOf(testutils.MatchCondition(metav1.Condition{
-				Type:    conditionDegraded,
-				Status:  metav1.ConditionTrue,
-				Reason:  reasonInvalidStrategy,
-				Message: fmt.Sprintf("%s: %s", invalidStrategyMessage, errRecreateStrategyNotSupported),
-			})))
-		})
-	})
-
 	Context("When the update strategy is invalid", func() {
 		var cpms *machinev1.ControlPlaneMachineSet
 		var result ctrl.Result
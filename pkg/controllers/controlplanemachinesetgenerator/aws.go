@@ -59,12 +59,12 @@ func generateControlPlaneMachineSetAWSSpec(machines []machinev1beta1.Machine, ma
 
 // buildAWSFailureDomains builds an AWSFailureDomain config for the ControlPlaneMachineSet from cluster's Machines and MachineSets.
 func buildAWSFailureDomains(machineSets []machinev1beta1.MachineSet, machines []machinev1beta1.Machine) (*machinev1builder.FailureDomainsApplyConfiguration, error) {
-	machineFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachines(machines)
+	machineFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachines(machines, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract failure domains from machines: %w", err)
 	}
 
-	machineSetFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachineSets(machineSets)
+	machineSetFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachineSets(machineSets, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract failure domains from machine sets: %w", err)
 	}
@@ -102,7 +102,7 @@ func buildControlPlaneMachineSetAWSMachineSpec(machines []machinev1beta1.Machine
 	// This is done so that if there are control plane machines with differing
 	// Provider Specs, we will use the most recent one. This is an attempt to try and inferr
 	// the spec that the user might want to choose among the different ones found in the cluster.
-	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machines[0].Spec)
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machines[0].Spec, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract machine's aws providerSpec: %w", err)
 	}
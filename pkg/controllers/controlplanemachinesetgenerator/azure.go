@@ -53,13 +53,13 @@ func generateControlPlaneMachineSetAzureSpec(machines []machinev1beta1.Machine,
 // buildAzureFailureDomains builds an AzureFailureDomain config for the ControlPaneMachineSet from the cluster's Machines and MachineSets.
 func buildAzureFailureDomains(machineSets []machinev1beta1.MachineSet, machines []machinev1beta1.Machine) (*machinev1builder.FailureDomainsApplyConfiguration, error) {
 	// Fetch failure domains from the machines
-	machineFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachines(machines)
+	machineFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachines(machines, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract failure domains from machines: %w", err)
 	}
 
 	// Fetch failure domains from the machineSets
-	machineSetFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachineSets(machineSets)
+	machineSetFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachineSets(machineSets, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract failure domains from machine sets: %w", err)
 	}
@@ -92,7 +92,7 @@ func buildAzureFailureDomains(machineSets []machinev1beta1.MachineSet, machines
 func buildControlPlaneMachineSetAzureMachineSpec(machines []machinev1beta1.Machine) (*machinev1beta1builder.MachineSpecApplyConfiguration, error) {
 	// The machines slice is sorted by the creation time.
 	// We want to get the provider config for the newest machine.
-	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machines[0].Spec)
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machines[0].Spec, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract machine's azure providerSpec: %w", err)
 	}
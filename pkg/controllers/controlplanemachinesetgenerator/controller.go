@@ -177,7 +177,7 @@ func (r *ControlPlaneMachineSetGeneratorReconciler) reconcile(ctx context.Contex
 	}
 
 	// generate an up to date ControlPlaneMachineSet based on the current cluster state.
-	generatedCPMS, err := r.generateControlPlaneMachineSet(logger, infrastructure.Spec.PlatformSpec.Type, machines, machineSets)
+	generatedCPMS, err := r.generateControlPlaneMachineSet(logger, infrastructure, machines, machineSets)
 	if errors.Is(err, errUnsupportedPlatform) {
 		// Do not requeue if the platform is not supported.
 		// Nothing to do in this case.
@@ -210,10 +210,14 @@ func (r *ControlPlaneMachineSetGeneratorReconciler) reconcile(ctx context.Contex
 }
 
 // generateControlPlaneMachineSet generates a control plane machine set based on the current cluster state.
+// Platforms without failure domain support use the provider spec of the newest machine as is.
 func (r *ControlPlaneMachineSetGeneratorReconciler) generateControlPlaneMachineSet(logger logr.Logger,
-	platformType configv1.PlatformType, machines []machinev1beta1.Machine, machineSets []machinev1beta1.MachineSet) (*machinev1.ControlPlaneMachineSet, error) {
+	infrastructure *configv1.Infrastructure, machines []machinev1beta1.Machine, machineSets []machinev1beta1.MachineSet) (*machinev1.ControlPlaneMachineSet, error) {
+	platformType := infrastructure.Spec.PlatformSpec.Type
+
 	var (
 		cpmsSpecApplyConfig machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration
+		cpmsAnnotations     map[string]string
 		err                 error
 	)
 
@@ -233,12 +237,35 @@ func (r *ControlPlaneMachineSetGeneratorReconciler) generateControlPlaneMachineS
 		if err != nil {
 			return nil, fmt.Errorf("unable to generate control plane machine set spec: %w", err)
 		}
-	default:
+	case configv1.OpenStackPlatformType:
+		cpmsSpecApplyConfig, cpmsAnnotations, err = generateControlPlaneMachineSetOpenStackSpec(machines, machineSets)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate control plane machine set spec: %w", err)
+		}
+	case configv1.VSpherePlatformType:
+		cpmsSpecApplyConfig, cpmsAnnotations, err = generateControlPlaneMachineSetVSphereSpec(machines, machineSets, infrastructure)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate control plane machine set spec: %w", err)
+		}
+	case configv1.NutanixPlatformType:
+		cpmsSpecApplyConfig, cpmsAnnotations, err = generateControlPlaneMachineSetNutanixSpec(machines)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate control plane machine set spec: %w", err)
+		}
+	case configv1.NonePlatformType, "":
 		logger.V(1).WithValues("platform", platformType).Info(unsupportedPlatform)
 		return nil, errUnsupportedPlatform
+	default:
+		cpmsSpecApplyConfig, err = generateControlPlaneMachineSetGenericSpec(machines)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate control plane machine set spec: %w", err)
+		}
 	}
 
 	cpmsApplyConfig := machinev1builder.ControlPlaneMachineSet(clusterControlPlaneMachineSetName, r.Namespace).WithSpec(&cpmsSpecApplyConfig)
+	if len(cpmsAnnotations) > 0 {
+		cpmsApplyConfig.WithAnnotations(cpmsAnnotations)
+	}
 
 	newCPMS := &machinev1.ControlPlaneMachineSet{}
 	if err := convertViaJSON(*cpmsApplyConfig, newCPMS); err != nil {
@@ -18,6 +18,8 @@ package controlplanemachinesetgenerator
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -31,9 +33,13 @@ import (
 	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
 	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
 	machinev1beta1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
+	testmachinev1resourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machine/v1"
+	machinev1alpha1resourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machine/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
@@ -423,10 +429,10 @@ var _ = Describe("controlplanemachinesetgenerator controller on AWS", func() {
 					Eventually(komega.Get(cpms)).Should(Succeed())
 					// In this case expect the machine Provider Spec of the youngest machine to be used here.
 					// In this case it should be `machine-2` given that's the one we created last.
-					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec)
+					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
 					Expect(err).To(BeNil())
 
-					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec)
+					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
 					Expect(err).To(BeNil())
 
 					// Remove from the machine Provider Spec the fields that won't be
@@ -478,10 +484,10 @@ var _ = Describe("controlplanemachinesetgenerator controller on AWS", func() {
 					Eventually(komega.Get(cpms)).Should(Succeed())
 					// In this case expect the machine Provider Spec of the youngest machine to be used here.
 					// In this case it should be `machine-2` given that's the one we created last.
-					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec)
+					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
 					Expect(err).To(BeNil())
 
-					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec)
+					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
 					Expect(err).To(BeNil())
 
 					// Remove from the machine Provider Spec the fields that won't be
@@ -562,8 +568,11 @@ var _ = Describe("controlplanemachinesetgenerator controller on AWS", func() {
 				By("Creating Control Plane Machines")
 				machines := create3CPMachines()
 
+				infrastructure := configv1resourcebuilder.Infrastructure().Build()
+				infrastructure.Spec.PlatformSpec.Type = configv1.NonePlatformType
+
 				logger = testutils.NewTestLogger()
-				generatedCPMS, err := reconciler.generateControlPlaneMachineSet(logger.Logger(), configv1.NonePlatformType, *machines, nil)
+				generatedCPMS, err := reconciler.generateControlPlaneMachineSet(logger.Logger(), infrastructure, *machines, nil)
 				Expect(generatedCPMS).To(BeNil())
 				Expect(err).To(MatchError(errUnsupportedPlatform))
 			})
@@ -605,7 +614,7 @@ var _ = Describe("controlplanemachinesetgenerator controller on AWS", func() {
 			It("should recreate ControlPlaneMachineSet with the provider spec matching the youngest machine provider spec", func() {
 				// In this case expect the machine Provider Spec of the youngest machine to be used here.
 				// In this case it should be `machine-1` given that's the one we created last.
-				machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec)
+				machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
 				Expect(err).To(BeNil())
 
 				// Remove from the machine Provider Spec the fields that won't be
@@ -619,7 +628,7 @@ var _ = Describe("controlplanemachinesetgenerator controller on AWS", func() {
 				Eventually(komega.Object(cpms), time.Second*30).Should(
 					HaveField("Spec.Template.OpenShiftMachineV1Beta1Machine.Spec",
 						WithTransform(func(in machinev1beta1.MachineSpec) machinev1beta1.AWSMachineProviderConfig {
-							mPS, err := providerconfig.NewProviderConfigFromMachineSpec(in)
+							mPS, err := providerconfig.NewProviderConfigFromMachineSpec(in, nil)
 							if err != nil {
 								return machinev1beta1.AWSMachineProviderConfig{}
 							}
@@ -997,10 +1006,10 @@ var _ = Describe("controlplanemachinesetgenerator controller on Azure", func() {
 					Eventually(komega.Get(cpms)).Should(Succeed())
 					// In this case expect the machine Provider Spec of the youngest machine to be used here.
 					// In this case it should be `machine-2` given that's the one we created last.
-					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec)
+					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
 					Expect(err).To(BeNil())
 
-					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec)
+					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
 					Expect(err).To(BeNil())
 
 					// Remove from the machine Provider Spec the fields that won't be
@@ -1051,10 +1060,10 @@ var _ = Describe("controlplanemachinesetgenerator controller on Azure", func() {
 					Eventually(komega.Get(cpms)).Should(Succeed())
 					// In this case expect the machine Provider Spec of the youngest machine to be used here.
 					// In this case it should be `machine-2` given that's the one we created last.
-					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec)
+					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
 					Expect(err).To(BeNil())
 
-					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec)
+					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
 					Expect(err).To(BeNil())
 
 					// Remove from the machine Provider Spec the fields that won't be
@@ -1134,8 +1143,11 @@ var _ = Describe("controlplanemachinesetgenerator controller on Azure", func() {
 				By("Creating Control Plane Machines")
 				machines := create3CPMachines()
 
+				infrastructure := configv1resourcebuilder.Infrastructure().Build()
+				infrastructure.Spec.PlatformSpec.Type = configv1.NonePlatformType
+
 				logger = testutils.NewTestLogger()
-				generatedCPMS, err := reconciler.generateControlPlaneMachineSet(logger.Logger(), configv1.NonePlatformType, *machines, nil)
+				generatedCPMS, err := reconciler.generateControlPlaneMachineSet(logger.Logger(), infrastructure, *machines, nil)
 				Expect(generatedCPMS).To(BeNil())
 				Expect(err).To(MatchError(errUnsupportedPlatform))
 			})
@@ -1177,7 +1189,7 @@ var _ = Describe("controlplanemachinesetgenerator controller on Azure", func() {
 			It("should recreate ControlPlaneMachineSet with the provider spec matching the youngest machine provider spec", func() {
 				// In this case expect the machine Provider Spec of the youngest machine to be used here.
 				// In this case it should be `machine-1` given that's the one we created last.
-				machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec)
+				machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
 				Expect(err).To(BeNil())
 
 				// Remove from the machine Provider Spec the fields that won't be
@@ -1190,7 +1202,7 @@ var _ = Describe("controlplanemachinesetgenerator controller on Azure", func() {
 				Eventually(komega.Object(cpms), time.Second*30).Should(
 					HaveField("Spec.Template.OpenShiftMachineV1Beta1Machine.Spec",
 						WithTransform(func(in machinev1beta1.MachineSpec) machinev1beta1.AzureMachineProviderSpec {
-							mPS, err := providerconfig.NewProviderConfigFromMachineSpec(in)
+							mPS, err := providerconfig.NewProviderConfigFromMachineSpec(in, nil)
 							if err != nil {
 								return machinev1beta1.AzureMachineProviderSpec{}
 							}
@@ -1568,10 +1580,10 @@ var _ = Describe("controlplanemachinesetgenerator controller on GCP", func() {
 					Eventually(komega.Get(cpms)).Should(Succeed())
 					// In this case expect the machine Provider Spec of the youngest machine to be used here.
 					// In this case it should be `machine-2` given that's the one we created last.
-					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec)
+					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
 					Expect(err).To(BeNil())
 
-					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec)
+					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
 					Expect(err).To(BeNil())
 
 					// Remove from the machine Provider Spec the fields that won't be
@@ -1622,10 +1634,10 @@ var _ = Describe("controlplanemachinesetgenerator controller on GCP", func() {
 					Eventually(komega.Get(cpms)).Should(Succeed())
 					// In this case expect the machine Provider Spec of the youngest machine to be used here.
 					// In this case it should be `machine-2` given that's the one we created last.
-					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec)
+					cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
 					Expect(err).To(BeNil())
 
-					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec)
+					machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
 					Expect(err).To(BeNil())
 
 					// Remove from the machine Provider Spec the fields that won't be
@@ -1705,8 +1717,11 @@ var _ = Describe("controlplanemachinesetgenerator controller on GCP", func() {
 				By("Creating Control Plane Machines")
 				machines := create3CPMachines()
 
+				infrastructure := configv1resourcebuilder.Infrastructure().Build()
+				infrastructure.Spec.PlatformSpec.Type = configv1.NonePlatformType
+
 				logger = testutils.NewTestLogger()
-				generatedCPMS, err := reconciler.generateControlPlaneMachineSet(logger.Logger(), configv1.NonePlatformType, *machines, nil)
+				generatedCPMS, err := reconciler.generateControlPlaneMachineSet(logger.Logger(), infrastructure, *machines, nil)
 				Expect(generatedCPMS).To(BeNil())
 				Expect(err).To(MatchError(errUnsupportedPlatform))
 			})
@@ -1748,7 +1763,7 @@ var _ = Describe("controlplanemachinesetgenerator controller on GCP", func() {
 			It("should recreate ControlPlaneMachineSet with the provider spec matching the youngest machine provider spec", func() {
 				// In this case expect the machine Provider Spec of the youngest machine to be used here.
 				// In this case it should be `machine-1` given that's the one we created last.
-				machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec)
+				machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
 				Expect(err).To(BeNil())
 
 				// Remove from the machine Provider Spec the fields that won't be
@@ -1761,7 +1776,7 @@ var _ = Describe("controlplanemachinesetgenerator controller on GCP", func() {
 				Eventually(komega.Object(cpms), time.Second*30).Should(
 					HaveField("Spec.Template.OpenShiftMachineV1Beta1Machine.Spec",
 						WithTransform(func(in machinev1beta1.MachineSpec) machinev1beta1.GCPMachineProviderSpec {
-							mPS, err := providerconfig.NewProviderConfigFromMachineSpec(in)
+							mPS, err := providerconfig.NewProviderConfigFromMachineSpec(in, nil)
 							if err != nil {
 								return machinev1beta1.GCPMachineProviderSpec{}
 							}
@@ -1870,3 +1885,760 @@ var _ = Describe("controlplanemachinesetgenerator controller on GCP", func() {
 		})
 	})
 })
+
+var _ = Describe("controlplanemachinesetgenerator controller on OpenStack", func() {
+	const (
+		openStack3FailureDomainsAnnotation = `[{"availabilityZone":"az1","rootVolume":{"availabilityZone":"volume-az1"}},` +
+			`{"availabilityZone":"az2","rootVolume":{"availabilityZone":"volume-az2"}},` +
+			`{"availabilityZone":"az3","rootVolume":{"availabilityZone":"volume-az3"}}]`
+
+		openStack4FailureDomainsAnnotation = `[{"availabilityZone":"az1","rootVolume":{"availabilityZone":"volume-az1"}},` +
+			`{"availabilityZone":"az2","rootVolume":{"availabilityZone":"volume-az2"}},` +
+			`{"availabilityZone":"az3","rootVolume":{"availabilityZone":"volume-az3"}},` +
+			`{"availabilityZone":"az4","rootVolume":{"availabilityZone":"volume-az4"}}]`
+	)
+
+	var (
+		providerSpecBuilderOpenStack = machinev1alpha1resourcebuilder.OpenStackProviderSpec().WithRootVolume()
+
+		az1ProviderSpecBuilderOpenStack = providerSpecBuilderOpenStack.WithAvailabilityZone("az1").WithRootVolumeAvailabilityZone("volume-az1")
+
+		az2ProviderSpecBuilderOpenStack = providerSpecBuilderOpenStack.WithAvailabilityZone("az2").WithRootVolumeAvailabilityZone("volume-az2")
+
+		az3ProviderSpecBuilderOpenStack = providerSpecBuilderOpenStack.WithAvailabilityZone("az3").WithRootVolumeAvailabilityZone("volume-az3")
+
+		az4ProviderSpecBuilderOpenStack = providerSpecBuilderOpenStack.WithAvailabilityZone("az4").WithRootVolumeAvailabilityZone("volume-az4")
+	)
+
+	var mgrCancel context.CancelFunc
+	var mgrDone chan struct{}
+	var mgr manager.Manager
+	var reconciler *ControlPlaneMachineSetGeneratorReconciler
+
+	var namespaceName string
+	var cpms *machinev1.ControlPlaneMachineSet
+	var machine2 *machinev1beta1.Machine
+
+	startManager := func(mgr *manager.Manager) (context.CancelFunc, chan struct{}) {
+		mgrCtx, mgrCancel := context.WithCancel(context.Background())
+		mgrDone := make(chan struct{})
+
+		go func() {
+			defer GinkgoRecover()
+			defer close(mgrDone)
+
+			Expect((*mgr).Start(mgrCtx)).To(Succeed())
+		}()
+
+		return mgrCancel, mgrDone
+	}
+
+	stopManager := func() {
+		mgrCancel()
+		// Wait for the mgrDone to be closed, which will happen once the mgr has stopped
+		<-mgrDone
+	}
+
+	create3CPMachines := func(builders ...machinev1alpha1resourcebuilder.OpenStackProviderSpecBuilder) {
+		// Create 3 control plane machines with differing Provider Specs,
+		// so then we can reliably check which machine Provider Spec is picked for the ControlPlaneMachineSet.
+		machineBuilder := machinev1beta1resourcebuilder.Machine().AsMaster().WithNamespace(namespaceName)
+		machine0 := machineBuilder.WithProviderSpecBuilder(builders[0]).WithName("master-0").Build()
+		machine1 := machineBuilder.WithProviderSpecBuilder(builders[1]).WithName("master-1").Build()
+		machine2 = machineBuilder.WithProviderSpecBuilder(builders[2].WithFlavor("m1.2xlarge")).WithName("master-2").Build()
+
+		Expect(k8sClient.Create(ctx, machine0)).To(Succeed())
+		Expect(k8sClient.Create(ctx, machine1)).To(Succeed())
+		Expect(k8sClient.Create(ctx, machine2)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		By("Setting up a namespace for the test")
+		ns := corev1resourcebuilder.Namespace().WithGenerateName("control-plane-machine-set-controller-").Build()
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		namespaceName = ns.GetName()
+
+		By("Setting up a new infrastructure for the test")
+		// Create infrastructure object.
+		infra := configv1resourcebuilder.Infrastructure().WithName(infrastructureName).AsGCP("test", "region-1").Build()
+		infra.Spec.PlatformSpec = configv1.PlatformSpec{
+			Type:      configv1.OpenStackPlatformType,
+			OpenStack: &configv1.OpenStackPlatformSpec{},
+		}
+		infra.Status.PlatformStatus = &configv1.PlatformStatus{
+			Type:      configv1.OpenStackPlatformType,
+			OpenStack: &configv1.OpenStackPlatformStatus{},
+		}
+		infraStatus := infra.Status.DeepCopy()
+		Expect(k8sClient.Create(ctx, infra)).To(Succeed())
+		// Update Infrastructure Status.
+		Eventually(komega.UpdateStatus(infra, func() {
+			infra.Status = *infraStatus
+		})).Should(Succeed())
+
+		By("Setting up a manager and controller")
+		var err error
+		mgr, err = ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:             testScheme,
+			MetricsBindAddress: "0",
+			Port:               testEnv.WebhookInstallOptions.LocalServingPort,
+			Host:               testEnv.WebhookInstallOptions.LocalServingHost,
+			CertDir:            testEnv.WebhookInstallOptions.LocalServingCertDir,
+		})
+		Expect(err).ToNot(HaveOccurred(), "Manager should be able to be created")
+		reconciler = &ControlPlaneMachineSetGeneratorReconciler{
+			Client:    mgr.GetClient(),
+			Namespace: namespaceName,
+		}
+		Expect(reconciler.SetupWithManager(mgr)).To(Succeed(), "Reconciler should be able to setup with manager")
+	})
+
+	AfterEach(func() {
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
+			&corev1.Node{},
+			&machinev1beta1.Machine{},
+			&configv1.Infrastructure{},
+			&machinev1beta1.MachineSet{},
+			&machinev1.ControlPlaneMachineSet{},
+		)
+	})
+
+	JustBeforeEach(func() {
+		By("Starting the manager")
+		mgrCancel, mgrDone = startManager(&mgr)
+	})
+
+	JustAfterEach(func() {
+		By("Stopping the manager")
+		stopManager()
+	})
+
+	Context("when a Control Plane Machine Set doesn't exist", func() {
+		BeforeEach(func() {
+			cpms = &machinev1.ControlPlaneMachineSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterControlPlaneMachineSetName,
+					Namespace: namespaceName,
+				},
+			}
+		})
+
+		Context("with 3 existing control plane machines in different availability zones", func() {
+			BeforeEach(func() {
+				By("Creating Control Plane Machines")
+				create3CPMachines(az1ProviderSpecBuilderOpenStack, az2ProviderSpecBuilderOpenStack, az3ProviderSpecBuilderOpenStack)
+			})
+
+			It("should create the ControlPlaneMachineSet with the OpenStack failure domains", func() {
+				By("Checking the Control Plane Machine Set has been created")
+				Eventually(komega.Get(cpms)).Should(Succeed())
+				Expect(cpms.Spec.State).To(Equal(machinev1.ControlPlaneMachineSetStateInactive))
+				Expect(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform).To(Equal(configv1.OpenStackPlatformType))
+				Expect(cpms.Annotations).To(HaveKeyWithValue(annotations.OpenStackFailureDomainsAnnotation, openStack3FailureDomainsAnnotation))
+			})
+
+			It("should create the ControlPlaneMachineSet with the provider spec matching the youngest machine provider spec", func() {
+				By("Checking the Control Plane Machine Set has been created")
+				Eventually(komega.Get(cpms)).Should(Succeed())
+
+				cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
+				Expect(err).To(BeNil())
+
+				machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
+				Expect(err).To(BeNil())
+
+				// Remove from the machine Provider Spec the fields that won't be
+				// present on the ControlPlaneMachineSet Provider Spec.
+				openStackMachineProviderConfig := machineProviderSpec.OpenStack().Config()
+				openStackMachineProviderConfig.AvailabilityZone = ""
+				openStackMachineProviderConfig.RootVolume.Zone = ""
+
+				Expect(cpmsProviderSpec.OpenStack().Config()).To(Equal(openStackMachineProviderConfig))
+			})
+
+			Context("With an additional MachineSet in another availability zone", func() {
+				BeforeEach(func() {
+					By("Creating an additional MachineSet")
+					machineSet := machinev1beta1resourcebuilder.MachineSet().WithNamespace(namespaceName).
+						WithProviderSpecBuilder(az4ProviderSpecBuilderOpenStack).WithGenerateName("machineset-az4-").Build()
+					Expect(k8sClient.Create(ctx, machineSet)).To(Succeed())
+				})
+
+				It("should include the failure domain of the MachineSet", func() {
+					By("Checking the Control Plane Machine Set has been created")
+					Eventually(komega.Get(cpms)).Should(Succeed())
+					Expect(cpms.Annotations).To(HaveKeyWithValue(annotations.OpenStackFailureDomainsAnnotation, openStack4FailureDomainsAnnotation))
+				})
+			})
+		})
+
+		Context("with 3 existing control plane machines without availability zones", func() {
+			BeforeEach(func() {
+				By("Creating Control Plane Machines")
+				noAZProviderSpecBuilder := machinev1alpha1resourcebuilder.OpenStackProviderSpec().WithAvailabilityZone("")
+				create3CPMachines(noAZProviderSpecBuilder, noAZProviderSpecBuilder, noAZProviderSpecBuilder)
+			})
+
+			It("should create the ControlPlaneMachineSet without failure domains", func() {
+				By("Checking the Control Plane Machine Set has been created")
+				Eventually(komega.Get(cpms)).Should(Succeed())
+				Expect(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform).To(BeEmpty())
+				Expect(cpms.Annotations).ToNot(HaveKey(annotations.OpenStackFailureDomainsAnnotation))
+			})
+		})
+	})
+
+	Context("when an Inactive Control Plane Machine Set exists with outdated failure domains", func() {
+		BeforeEach(func() {
+			By("Creating Control Plane Machines")
+			create3CPMachines(az1ProviderSpecBuilderOpenStack, az2ProviderSpecBuilderOpenStack, az3ProviderSpecBuilderOpenStack)
+
+			By("Creating an outdated and Inactive Control Plane Machine Set")
+			cpms = machinev1resourcebuilder.ControlPlaneMachineSet().
+				WithState(machinev1.ControlPlaneMachineSetStateInactive).
+				WithNamespace(namespaceName).
+				WithMachineTemplateBuilder(
+					machinev1resourcebuilder.OpenShiftMachineV1Beta1Template().
+						WithProviderSpecBuilder(providerSpecBuilderOpenStack.WithAvailabilityZone("").WithFlavor("m1.2xlarge")),
+				).Build()
+			cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform = configv1.OpenStackPlatformType
+			cpms.Annotations = map[string]string{
+				annotations.OpenStackFailureDomainsAnnotation: openStack4FailureDomainsAnnotation,
+			}
+			Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
+		})
+
+		It("should update the ControlPlaneMachineSet with the expected failure domains", func() {
+			Eventually(komega.Object(cpms), time.Second*30).Should(
+				HaveField("ObjectMeta.Annotations", HaveKeyWithValue(annotations.OpenStackFailureDomainsAnnotation, openStack3FailureDomainsAnnotation)),
+			)
+		})
+	})
+})
+
+var _ = Describe("controlplanemachinesetgenerator controller on Nutanix", func() {
+	const (
+		nutanix3FailureDomainsAnnotation = `[{"cluster":{"type":"name","name":"pe-1"},"subnets":[{"type":"name","name":"subnet-1"}]},` +
+			`{"cluster":{"type":"name","name":"pe-2"},"subnets":[{"type":"name","name":"subnet-2"}]},` +
+			`{"cluster":{"type":"name","name":"pe-3"},"subnets":[{"type":"name","name":"subnet-3"}]}]`
+	)
+
+	var (
+		pe1ProviderSpecBuilderNutanix = testmachinev1resourcebuilder.NutanixProviderSpec().WithCluster("pe-1").WithSubnets("subnet-1")
+
+		pe2ProviderSpecBuilderNutanix = testmachinev1resourcebuilder.NutanixProviderSpec().WithCluster("pe-2").WithSubnets("subnet-2")
+
+		pe3ProviderSpecBuilderNutanix = testmachinev1resourcebuilder.NutanixProviderSpec().WithCluster("pe-3").WithSubnets("subnet-3")
+	)
+
+	var mgrCancel context.CancelFunc
+	var mgrDone chan struct{}
+	var mgr manager.Manager
+	var reconciler *ControlPlaneMachineSetGeneratorReconciler
+
+	var namespaceName string
+	var cpms *machinev1.ControlPlaneMachineSet
+	var machine2 *machinev1beta1.Machine
+
+	startManager := func(mgr *manager.Manager) (context.CancelFunc, chan struct{}) {
+		mgrCtx, mgrCancel := context.WithCancel(context.Background())
+		mgrDone := make(chan struct{})
+
+		go func() {
+			defer GinkgoRecover()
+			defer close(mgrDone)
+
+			Expect((*mgr).Start(mgrCtx)).To(Succeed())
+		}()
+
+		return mgrCancel, mgrDone
+	}
+
+	stopManager := func() {
+		mgrCancel()
+		// Wait for the mgrDone to be closed, which will happen once the mgr has stopped
+		<-mgrDone
+	}
+
+	create3CPMachines := func(builders ...testmachinev1resourcebuilder.NutanixProviderSpecBuilder) {
+		// Create 3 control plane machines with differing Provider Specs,
+		// so then we can reliably check which machine Provider Spec is picked for the ControlPlaneMachineSet.
+		machineBuilder := machinev1beta1resourcebuilder.Machine().AsMaster().WithNamespace(namespaceName)
+		machine0 := machineBuilder.WithProviderSpecBuilder(builders[0]).WithName("master-0").Build()
+		machine1 := machineBuilder.WithProviderSpecBuilder(builders[1]).WithName("master-1").Build()
+		machine2 = machineBuilder.WithProviderSpecBuilder(builders[2].WithVCPUSockets(8)).WithName("master-2").Build()
+
+		Expect(k8sClient.Create(ctx, machine0)).To(Succeed())
+		Expect(k8sClient.Create(ctx, machine1)).To(Succeed())
+		Expect(k8sClient.Create(ctx, machine2)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		By("Setting up a namespace for the test")
+		ns := corev1resourcebuilder.Namespace().WithGenerateName("control-plane-machine-set-controller-").Build()
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		namespaceName = ns.GetName()
+
+		By("Setting up a new infrastructure for the test")
+		// Create infrastructure object.
+		infra := configv1resourcebuilder.Infrastructure().WithName(infrastructureName).AsGCP("test", "region-1").Build()
+		infra.Spec.PlatformSpec = configv1.PlatformSpec{
+			Type: configv1.NutanixPlatformType,
+			Nutanix: &configv1.NutanixPlatformSpec{
+				PrismCentral: configv1.NutanixPrismEndpoint{Address: "prism-central.example.com", Port: 9440},
+				PrismElements: []configv1.NutanixPrismElementEndpoint{
+					{Name: "pe-1", Endpoint: configv1.NutanixPrismEndpoint{Address: "pe-1.example.com", Port: 9440}},
+				},
+			},
+		}
+		infra.Status.PlatformStatus = &configv1.PlatformStatus{
+			Type:    configv1.NutanixPlatformType,
+			Nutanix: &configv1.NutanixPlatformStatus{},
+		}
+		infraStatus := infra.Status.DeepCopy()
+		Expect(k8sClient.Create(ctx, infra)).To(Succeed())
+		// Update Infrastructure Status.
+		Eventually(komega.UpdateStatus(infra, func() {
+			infra.Status = *infraStatus
+		})).Should(Succeed())
+
+		By("Setting up a manager and controller")
+		var err error
+		mgr, err = ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:             testScheme,
+			MetricsBindAddress: "0",
+			Port:               testEnv.WebhookInstallOptions.LocalServingPort,
+			Host:               testEnv.WebhookInstallOptions.LocalServingHost,
+			CertDir:            testEnv.WebhookInstallOptions.LocalServingCertDir,
+		})
+		Expect(err).ToNot(HaveOccurred(), "Manager should be able to be created")
+		reconciler = &ControlPlaneMachineSetGeneratorReconciler{
+			Client:    mgr.GetClient(),
+			Namespace: namespaceName,
+		}
+		Expect(reconciler.SetupWithManager(mgr)).To(Succeed(), "Reconciler should be able to setup with manager")
+	})
+
+	AfterEach(func() {
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
+			&corev1.Node{},
+			&machinev1beta1.Machine{},
+			&configv1.Infrastructure{},
+			&machinev1beta1.MachineSet{},
+			&machinev1.ControlPlaneMachineSet{},
+		)
+	})
+
+	JustBeforeEach(func() {
+		By("Starting the manager")
+		mgrCancel, mgrDone = startManager(&mgr)
+	})
+
+	JustAfterEach(func() {
+		By("Stopping the manager")
+		stopManager()
+	})
+
+	Context("when a Control Plane Machine Set doesn't exist", func() {
+		BeforeEach(func() {
+			cpms = &machinev1.ControlPlaneMachineSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterControlPlaneMachineSetName,
+					Namespace: namespaceName,
+				},
+			}
+		})
+
+		Context("with 3 existing control plane machines in different Prism Elements", func() {
+			BeforeEach(func() {
+				By("Creating Control Plane Machines")
+				create3CPMachines(pe1ProviderSpecBuilderNutanix, pe2ProviderSpecBuilderNutanix, pe3ProviderSpecBuilderNutanix)
+			})
+
+			It("should create the ControlPlaneMachineSet with the Nutanix failure domains", func() {
+				By("Checking the Control Plane Machine Set has been created")
+				Eventually(komega.Get(cpms)).Should(Succeed())
+				Expect(cpms.Spec.State).To(Equal(machinev1.ControlPlaneMachineSetStateInactive))
+				Expect(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform).To(Equal(configv1.NutanixPlatformType))
+				Expect(cpms.Annotations).To(HaveKeyWithValue(annotations.NutanixFailureDomainsAnnotation, nutanix3FailureDomainsAnnotation))
+			})
+
+			It("should create the ControlPlaneMachineSet with the provider spec matching the youngest machine provider spec", func() {
+				By("Checking the Control Plane Machine Set has been created")
+				Eventually(komega.Get(cpms)).Should(Succeed())
+
+				cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
+				Expect(err).To(BeNil())
+
+				machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, nil)
+				Expect(err).To(BeNil())
+
+				Expect(cpmsProviderSpec.Nutanix().Config()).To(Equal(machineProviderSpec.Nutanix().Config()))
+			})
+		})
+
+		Context("with 3 existing control plane machines in the same Prism Element", func() {
+			BeforeEach(func() {
+				By("Creating Control Plane Machines")
+				create3CPMachines(pe1ProviderSpecBuilderNutanix, pe1ProviderSpecBuilderNutanix, pe1ProviderSpecBuilderNutanix)
+			})
+
+			It("should create the ControlPlaneMachineSet with a single Nutanix failure domain", func() {
+				By("Checking the Control Plane Machine Set has been created")
+				Eventually(komega.Get(cpms)).Should(Succeed())
+				Expect(cpms.Annotations).To(HaveKeyWithValue(annotations.NutanixFailureDomainsAnnotation,
+					`[{"cluster":{"type":"name","name":"pe-1"},"subnets":[{"type":"name","name":"subnet-1"}]}]`,
+				))
+			})
+		})
+	})
+
+	Context("when an Inactive Control Plane Machine Set exists with outdated failure domains", func() {
+		BeforeEach(func() {
+			By("Creating Control Plane Machines")
+			create3CPMachines(pe1ProviderSpecBuilderNutanix, pe2ProviderSpecBuilderNutanix, pe3ProviderSpecBuilderNutanix)
+
+			By("Creating an outdated and Inactive Control Plane Machine Set")
+			cpms = machinev1resourcebuilder.ControlPlaneMachineSet().
+				WithState(machinev1.ControlPlaneMachineSetStateInactive).
+				WithNamespace(namespaceName).
+				WithMachineTemplateBuilder(
+					machinev1resourcebuilder.OpenShiftMachineV1Beta1Template().
+						WithProviderSpecBuilder(pe3ProviderSpecBuilderNutanix.WithVCPUSockets(8)),
+				).Build()
+			cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform = configv1.NutanixPlatformType
+			cpms.Annotations = map[string]string{
+				annotations.NutanixFailureDomainsAnnotation: `[{"cluster":{"type":"name","name":"pe-1"},"subnets":[{"type":"name","name":"subnet-1"}]}]`,
+			}
+			Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
+		})
+
+		It("should update the ControlPlaneMachineSet with the expected failure domains", func() {
+			Eventually(komega.Object(cpms), time.Second*30).Should(
+				HaveField("ObjectMeta.Annotations", HaveKeyWithValue(annotations.NutanixFailureDomainsAnnotation, nutanix3FailureDomainsAnnotation)),
+			)
+		})
+	})
+})
+
+var _ = Describe("controlplanemachinesetgenerator controller on VSphere", func() {
+	var mgrCancel context.CancelFunc
+	var mgrDone chan struct{}
+	var mgr manager.Manager
+	var reconciler *ControlPlaneMachineSetGeneratorReconciler
+
+	var namespaceName string
+	var cpms *machinev1.ControlPlaneMachineSet
+
+	startManager := func(mgr *manager.Manager) (context.CancelFunc, chan struct{}) {
+		mgrCtx, mgrCancel := context.WithCancel(context.Background())
+		mgrDone := make(chan struct{})
+
+		go func() {
+			defer GinkgoRecover()
+			defer close(mgrDone)
+
+			Expect((*mgr).Start(mgrCtx)).To(Succeed())
+		}()
+
+		return mgrCancel, mgrDone
+	}
+
+	stopManager := func() {
+		mgrCancel()
+		// Wait for the mgrDone to be closed, which will happen once the mgr has stopped
+		<-mgrDone
+	}
+
+	var infra *configv1.Infrastructure
+	var machine2 *machinev1beta1.Machine
+
+	// create3CPMachines creates 3 control plane machines, each with the workspace of the given vSphere failure domain.
+	// The newest machine uses a different template so that we can reliably check which machine
+	// Provider Spec is picked for the ControlPlaneMachineSet.
+	create3CPMachines := func(failureDomainNames ...string) {
+		machineBuilder := machinev1beta1resourcebuilder.Machine().AsMaster().WithNamespace(namespaceName)
+
+		for i, failureDomainName := range failureDomainNames {
+			providerSpec := machinev1beta1resourcebuilder.VSphereProviderSpec().Build()
+			if i == 2 {
+				providerSpec.Template = "newest-template"
+			}
+
+			for _, failureDomainSpec := range infra.Spec.PlatformSpec.VSphere.FailureDomains {
+				if failureDomainSpec.Name == failureDomainName {
+					providerSpec.Workspace = &machinev1beta1.Workspace{
+						Server:       failureDomainSpec.Server,
+						Datacenter:   failureDomainSpec.Topology.Datacenter,
+						Datastore:    failureDomainSpec.Topology.Datastore,
+						ResourcePool: failureDomainSpec.Topology.ComputeCluster + "/Resources",
+					}
+				}
+			}
+
+			rawProviderSpec, err := json.Marshal(providerSpec)
+			Expect(err).ToNot(HaveOccurred())
+
+			machine := machineBuilder.WithName(fmt.Sprintf("master-%d", i)).Build()
+			machine.Spec.ProviderSpec.Value = &runtime.RawExtension{Raw: rawProviderSpec}
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+			machine2 = machine
+		}
+	}
+
+	BeforeEach(func() {
+		By("Setting up a namespace for the test")
+		ns := corev1resourcebuilder.Namespace().WithGenerateName("control-plane-machine-set-controller-").Build()
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		namespaceName = ns.GetName()
+
+		By("Setting up a new infrastructure for the test")
+		failureDomainSpecs := []configv1.VSpherePlatformFailureDomainSpec{}
+		for _, i := range []string{"1", "2", "3"} {
+			failureDomainSpecs = append(failureDomainSpecs, configv1.VSpherePlatformFailureDomainSpec{
+				Name:   "fd-" + i,
+				Region: "region-1",
+				Zone:   "zone-" + i,
+				Server: "vcenter.example.com",
+				Topology: configv1.VSpherePlatformTopology{
+					Datacenter:     "dc-1",
+					ComputeCluster: "/dc-1/host/cluster-" + i,
+					Networks:       []string{"network-" + i},
+					Datastore:      "/dc-1/datastore/datastore-" + i,
+				},
+			})
+		}
+
+		infra = configv1resourcebuilder.Infrastructure().WithName(infrastructureName).Build()
+		infra.Spec.PlatformSpec = configv1.PlatformSpec{
+			Type: configv1.VSpherePlatformType,
+			VSphere: &configv1.VSpherePlatformSpec{
+				FailureDomains: failureDomainSpecs,
+			},
+		}
+		Expect(k8sClient.Create(ctx, infra)).To(Succeed())
+
+		By("Setting up a manager and controller")
+		var err error
+		mgr, err = ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:             testScheme,
+			MetricsBindAddress: "0",
+			Port:               testEnv.WebhookInstallOptions.LocalServingPort,
+			Host:               testEnv.WebhookInstallOptions.LocalServingHost,
+			CertDir:            testEnv.WebhookInstallOptions.LocalServingCertDir,
+		})
+		Expect(err).ToNot(HaveOccurred(), "Manager should be able to be created")
+		reconciler = &ControlPlaneMachineSetGeneratorReconciler{
+			Client:    mgr.GetClient(),
+			Namespace: namespaceName,
+		}
+		Expect(reconciler.SetupWithManager(mgr)).To(Succeed(), "Reconciler should be able to setup with manager")
+	})
+
+	AfterEach(func() {
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
+			&corev1.Node{},
+			&machinev1beta1.Machine{},
+			&configv1.Infrastructure{},
+			&machinev1beta1.MachineSet{},
+			&machinev1.ControlPlaneMachineSet{},
+		)
+	})
+
+	JustBeforeEach(func() {
+		By("Starting the manager")
+		mgrCancel, mgrDone = startManager(&mgr)
+	})
+
+	JustAfterEach(func() {
+		By("Stopping the manager")
+		stopManager()
+	})
+
+	Context("when a Control Plane Machine Set doesn't exist", func() {
+		BeforeEach(func() {
+			cpms = &machinev1.ControlPlaneMachineSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterControlPlaneMachineSetName,
+					Namespace: namespaceName,
+				},
+			}
+		})
+
+		Context("with 3 existing control plane machines in different failure domains", func() {
+			BeforeEach(func() {
+				By("Creating Control Plane Machines")
+				create3CPMachines("fd-1", "fd-2", "fd-3")
+			})
+
+			It("should create the ControlPlaneMachineSet with the VSphere failure domains", func() {
+				By("Checking the Control Plane Machine Set has been created")
+				Eventually(komega.Get(cpms)).Should(Succeed())
+				Expect(cpms.Spec.State).To(Equal(machinev1.ControlPlaneMachineSetStateInactive))
+				Expect(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform).To(Equal(configv1.VSpherePlatformType))
+				Expect(cpms.Annotations).To(HaveKeyWithValue(annotations.VSphereFailureDomainsAnnotation, `[{"name":"fd-1"},{"name":"fd-2"},{"name":"fd-3"}]`))
+			})
+
+			It("should create the ControlPlaneMachineSet with the provider spec matching the youngest machine provider spec", func() {
+				By("Checking the Control Plane Machine Set has been created")
+				Eventually(komega.Get(cpms)).Should(Succeed())
+
+				cpmsProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, infra)
+				Expect(err).To(BeNil())
+
+				machineProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(machine2.Spec, infra)
+				Expect(err).To(BeNil())
+
+				Expect(cpmsProviderSpec.VSphere().Config()).To(Equal(machineProviderSpec.VSphere().Config()))
+			})
+		})
+
+		Context("with a control plane machine outside of the failure domains", func() {
+			BeforeEach(func() {
+				By("Creating Control Plane Machines")
+				create3CPMachines("fd-1", "fd-2", "fd-4")
+			})
+
+			It("should create the ControlPlaneMachineSet without failure domains", func() {
+				By("Checking the Control Plane Machine Set has been created")
+				Eventually(komega.Get(cpms)).Should(Succeed())
+				Expect(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform).To(BeEmpty())
+				Expect(cpms.Annotations).ToNot(HaveKey(annotations.VSphereFailureDomainsAnnotation))
+			})
+		})
+	})
+})
+
+var _ = Describe("controlplanemachinesetgenerator controller on a platform without failure domains", func() {
+	const ibmCloudProviderSpecTemplate = `{"apiVersion":"machine.openshift.io/v1beta1","kind":"IBMCloudMachineProviderSpec","profile":"%s","region":"us-east"}`
+
+	var mgrCancel context.CancelFunc
+	var mgrDone chan struct{}
+	var mgr manager.Manager
+	var reconciler *ControlPlaneMachineSetGeneratorReconciler
+
+	var namespaceName string
+	var cpms *machinev1.ControlPlaneMachineSet
+
+	startManager := func(mgr *manager.Manager) (context.CancelFunc, chan struct{}) {
+		mgrCtx, mgrCancel := context.WithCancel(context.Background())
+		mgrDone := make(chan struct{})
+
+		go func() {
+			defer GinkgoRecover()
+			defer close(mgrDone)
+
+			Expect((*mgr).Start(mgrCtx)).To(Succeed())
+		}()
+
+		return mgrCancel, mgrDone
+	}
+
+	stopManager := func() {
+		mgrCancel()
+		// Wait for the mgrDone to be closed, which will happen once the mgr has stopped
+		<-mgrDone
+	}
+
+	// create3CPMachines creates 3 control plane machines with IBM Cloud provider specs, which are handled
+	// using the generic provider config. The newest machine uses a different profile so that we can reliably
+	// check which machine Provider Spec is picked for the ControlPlaneMachineSet.
+	create3CPMachines := func() {
+		machineBuilder := machinev1beta1resourcebuilder.Machine().AsMaster().WithNamespace(namespaceName)
+
+		for i, profile := range []string{"bx2-4x16", "bx2-4x16", "bx2-8x32"} {
+			machine := machineBuilder.WithName(fmt.Sprintf("master-%d", i)).Build()
+			machine.Spec.ProviderSpec.Value = &runtime.RawExtension{Raw: []byte(fmt.Sprintf(ibmCloudProviderSpecTemplate, profile))}
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		By("Setting up a namespace for the test")
+		ns := corev1resourcebuilder.Namespace().WithGenerateName("control-plane-machine-set-controller-").Build()
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		namespaceName = ns.GetName()
+
+		By("Setting up a new infrastructure for the test")
+		infra := configv1resourcebuilder.Infrastructure().WithName(infrastructureName).Build()
+		infra.Spec.PlatformSpec = configv1.PlatformSpec{
+			Type: configv1.IBMCloudPlatformType,
+		}
+		Expect(k8sClient.Create(ctx, infra)).To(Succeed())
+
+		By("Setting up a manager and controller")
+		var err error
+		mgr, err = ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:             testScheme,
+			MetricsBindAddress: "0",
+			Port:               testEnv.WebhookInstallOptions.LocalServingPort,
+			Host:               testEnv.WebhookInstallOptions.LocalServingHost,
+			CertDir:            testEnv.WebhookInstallOptions.LocalServingCertDir,
+		})
+		Expect(err).ToNot(HaveOccurred(), "Manager should be able to be created")
+		reconciler = &ControlPlaneMachineSetGeneratorReconciler{
+			Client:    mgr.GetClient(),
+			Namespace: namespaceName,
+		}
+		Expect(reconciler.SetupWithManager(mgr)).To(Succeed(), "Reconciler should be able to setup with manager")
+	})
+
+	AfterEach(func() {
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
+			&corev1.Node{},
+			&machinev1beta1.Machine{},
+			&configv1.Infrastructure{},
+			&machinev1beta1.MachineSet{},
+			&machinev1.ControlPlaneMachineSet{},
+		)
+	})
+
+	JustBeforeEach(func() {
+		By("Starting the manager")
+		mgrCancel, mgrDone = startManager(&mgr)
+	})
+
+	JustAfterEach(func() {
+		By("Stopping the manager")
+		stopManager()
+	})
+
+	Context("when a Control Plane Machine Set doesn't exist", func() {
+		BeforeEach(func() {
+			cpms = &machinev1.ControlPlaneMachineSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterControlPlaneMachineSetName,
+					Namespace: namespaceName,
+				},
+			}
+
+			By("Creating Control Plane Machines")
+			create3CPMachines()
+		})
+
+		It("should create an Inactive ControlPlaneMachineSet without failure domains", func() {
+			By("Checking the Control Plane Machine Set has been created")
+			Eventually(komega.Get(cpms)).Should(Succeed())
+			Expect(cpms.Spec.State).To(Equal(machinev1.ControlPlaneMachineSetStateInactive))
+			Expect(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform).To(BeEmpty())
+		})
+
+		It("should create the ControlPlaneMachineSet with the provider spec matching the youngest machine provider spec", func() {
+			By("Checking the Control Plane Machine Set has been created")
+			Eventually(komega.Get(cpms)).Should(Succeed())
+
+			Expect(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec.ProviderSpec.Value.Raw).To(MatchJSON(
+				fmt.Sprintf(ibmCloudProviderSpecTemplate, "bx2-8x32"),
+			))
+		})
+
+		It("should not recreate the ControlPlaneMachineSet once it is up to date", func() {
+			By("Checking the Control Plane Machine Set has been created")
+			Eventually(komega.Get(cpms)).Should(Succeed())
+			uid := cpms.GetUID()
+
+			Consistently(komega.Object(cpms), time.Second*2).Should(HaveField("ObjectMeta.UID", Equal(uid)))
+		})
+	})
+})
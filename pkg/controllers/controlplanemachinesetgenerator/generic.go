@@ -0,0 +1,67 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachinesetgenerator
+
+import (
+	"fmt"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1builder "github.com/openshift/client-go/machine/applyconfigurations/machine/v1"
+	machinev1beta1builder "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// generateControlPlaneMachineSetGenericSpec generates a ControlPlaneMachineSet Spec for platforms
+// that do not support failure domains. The provider spec of the newest Machine is used as is,
+// and no failure domains are configured.
+func generateControlPlaneMachineSetGenericSpec(machines []machinev1beta1.Machine) (machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration, error) {
+	controlPlaneMachineSetMachineSpecApplyConfig, err := buildControlPlaneMachineSetGenericMachineSpec(machines)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, fmt.Errorf("failed to build ControlPlaneMachineSet's spec: %w", err)
+	}
+
+	// We want to work with the newest machine.
+	controlPlaneMachineSetApplyConfigSpec := genericControlPlaneMachineSetSpec(replicas, machines[0].ObjectMeta.Labels[clusterIDLabelKey])
+	controlPlaneMachineSetApplyConfigSpec.Template.OpenShiftMachineV1Beta1Machine.Spec = controlPlaneMachineSetMachineSpecApplyConfig
+
+	return controlPlaneMachineSetApplyConfigSpec, nil
+}
+
+// buildControlPlaneMachineSetGenericMachineSpec builds a MachineSpec for the ControlPlaneMachineSet
+// using the generic provider config.
+func buildControlPlaneMachineSetGenericMachineSpec(machines []machinev1beta1.Machine) (*machinev1beta1builder.MachineSpecApplyConfiguration, error) {
+	// The machines slice is sorted by the creation time.
+	// We want to get the provider config for the newest machine.
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machines[0].Spec, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract machine's providerSpec: %w", err)
+	}
+
+	rawBytes, err := providerConfig.RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling providerSpec: %w", err)
+	}
+
+	re := runtime.RawExtension{
+		Raw: rawBytes,
+	}
+
+	return &machinev1beta1builder.MachineSpecApplyConfiguration{
+		ProviderSpec: &machinev1beta1builder.ProviderSpecApplyConfiguration{Value: &re},
+	}, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachinesetgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1builder "github.com/openshift/client-go/machine/applyconfigurations/machine/v1"
+	machinev1beta1builder "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// generateControlPlaneMachineSetNutanixSpec generates a Nutanix flavored ControlPlaneMachineSet Spec.
+// The ControlPlaneMachineSet API does not yet support Nutanix failure domains, so these are returned as
+// annotations to be set on the ControlPlaneMachineSet.
+// The failure domains are inferred from the Prism Elements (clusters) and subnets of the control plane Machines.
+func generateControlPlaneMachineSetNutanixSpec(machines []machinev1beta1.Machine) (machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration, map[string]string, error) {
+	nutanixFailureDomains, err := buildNutanixFailureDomains(machines)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, nil, fmt.Errorf("failed to build ControlPlaneMachineSet's Nutanix failure domains: %w", err)
+	}
+
+	controlPlaneMachineSetMachineSpecApplyConfig, err := buildControlPlaneMachineSetNutanixMachineSpec(machines)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, nil, fmt.Errorf("failed to build ControlPlaneMachineSet's Nutanix spec: %w", err)
+	}
+
+	rawFailureDomains, err := json.Marshal(nutanixFailureDomains)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, nil, fmt.Errorf("error marshalling Nutanix failure domains: %w", err)
+	}
+
+	// We want to work with the newest machine.
+	controlPlaneMachineSetApplyConfigSpec := genericControlPlaneMachineSetSpec(replicas, machines[0].ObjectMeta.Labels[clusterIDLabelKey])
+	controlPlaneMachineSetApplyConfigSpec.Template.OpenShiftMachineV1Beta1Machine.Spec = controlPlaneMachineSetMachineSpecApplyConfig
+	controlPlaneMachineSetApplyConfigSpec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains = &machinev1builder.FailureDomainsApplyConfiguration{
+		Platform: util.Ptr(configv1.NutanixPlatformType),
+	}
+
+	cpmsAnnotations := map[string]string{
+		annotations.NutanixFailureDomainsAnnotation: string(rawFailureDomains),
+	}
+
+	return controlPlaneMachineSetApplyConfigSpec, cpmsAnnotations, nil
+}
+
+// buildNutanixFailureDomains builds the Nutanix failure domains for the ControlPlaneMachineSet from the cluster's
+// control plane Machines.
+func buildNutanixFailureDomains(machines []machinev1beta1.Machine) ([]failuredomain.NutanixFailureDomain, error) {
+	// Fetch failure domains from the machines
+	machineFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachines(machines, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract failure domains from machines: %w", err)
+	}
+
+	nutanixFailureDomains := []failuredomain.NutanixFailureDomain{}
+	for _, fd := range machineFailureDomains {
+		nutanixFailureDomains = append(nutanixFailureDomains, fd.Nutanix())
+	}
+
+	return nutanixFailureDomains, nil
+}
+
+// buildControlPlaneMachineSetNutanixMachineSpec builds a Nutanix flavored MachineSpec for the ControlPlaneMachineSet.
+// The Prism Element (cluster) and subnets are retained within the provider spec, as they are required fields,
+// and are replaced by those of the failure domain when Machines are created.
+func buildControlPlaneMachineSetNutanixMachineSpec(machines []machinev1beta1.Machine) (*machinev1beta1builder.MachineSpecApplyConfiguration, error) {
+	// The machines slice is sorted by the creation time.
+	// We want to get the provider config for the newest machine.
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machines[0].Spec, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract machine's Nutanix providerSpec: %w", err)
+	}
+
+	rawBytes, err := json.Marshal(providerConfig.Nutanix().Config())
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling Nutanix providerSpec: %w", err)
+	}
+
+	re := runtime.RawExtension{
+		Raw: rawBytes,
+	}
+
+	return &machinev1beta1builder.MachineSpecApplyConfiguration{
+		ProviderSpec: &machinev1beta1builder.ProviderSpecApplyConfiguration{Value: &re},
+	}, nil
+}
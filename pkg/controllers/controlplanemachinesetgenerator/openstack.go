@@ -0,0 +1,140 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachinesetgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1builder "github.com/openshift/client-go/machine/applyconfigurations/machine/v1"
+	machinev1beta1builder "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// generateControlPlaneMachineSetOpenStackSpec generates an OpenStack flavored ControlPlaneMachineSet Spec.
+// The ControlPlaneMachineSet API does not yet support OpenStack failure domains, so these are returned as
+// annotations to be set on the ControlPlaneMachineSet.
+// When the Machines and MachineSets do not use any availability zones, no failure domains are configured.
+func generateControlPlaneMachineSetOpenStackSpec(machines []machinev1beta1.Machine, machineSets []machinev1beta1.MachineSet) (machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration, map[string]string, error) {
+	openStackFailureDomains, err := buildOpenStackFailureDomains(machineSets, machines)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, nil, fmt.Errorf("failed to build ControlPlaneMachineSet's OpenStack failure domains: %w", err)
+	}
+
+	controlPlaneMachineSetMachineSpecApplyConfig, err := buildControlPlaneMachineSetOpenStackMachineSpec(machines, len(openStackFailureDomains) > 0)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, nil, fmt.Errorf("failed to build ControlPlaneMachineSet's OpenStack spec: %w", err)
+	}
+
+	// We want to work with the newest machine.
+	controlPlaneMachineSetApplyConfigSpec := genericControlPlaneMachineSetSpec(replicas, machines[0].ObjectMeta.Labels[clusterIDLabelKey])
+	controlPlaneMachineSetApplyConfigSpec.Template.OpenShiftMachineV1Beta1Machine.Spec = controlPlaneMachineSetMachineSpecApplyConfig
+
+	if len(openStackFailureDomains) == 0 {
+		return controlPlaneMachineSetApplyConfigSpec, nil, nil
+	}
+
+	rawFailureDomains, err := json.Marshal(openStackFailureDomains)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, nil, fmt.Errorf("error marshalling OpenStack failure domains: %w", err)
+	}
+
+	controlPlaneMachineSetApplyConfigSpec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains = &machinev1builder.FailureDomainsApplyConfiguration{
+		Platform: util.Ptr(configv1.OpenStackPlatformType),
+	}
+
+	cpmsAnnotations := map[string]string{
+		annotations.OpenStackFailureDomainsAnnotation: string(rawFailureDomains),
+	}
+
+	return controlPlaneMachineSetApplyConfigSpec, cpmsAnnotations, nil
+}
+
+// buildOpenStackFailureDomains builds the OpenStack failure domains for the ControlPaneMachineSet from the cluster's
+// Machines and MachineSets. When the only failure domain found is empty, no failure domains are returned.
+func buildOpenStackFailureDomains(machineSets []machinev1beta1.MachineSet, machines []machinev1beta1.Machine) ([]failuredomain.OpenStackFailureDomain, error) {
+	// Fetch failure domains from the machines
+	machineFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachines(machines, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract failure domains from machines: %w", err)
+	}
+
+	// Fetch failure domains from the machineSets
+	machineSetFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachineSets(machineSets, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract failure domains from machine sets: %w", err)
+	}
+
+	// We have to get rid of duplicates from the failure domains.
+	// We construct a set from the failure domains, since a set can't have duplicates.
+	failureDomains := failuredomain.NewSet(machineFailureDomains...)
+	// Construction of a union of failure domains of machines and machineSets.
+	failureDomains.Insert(machineSetFailureDomains...)
+
+	openStackFailureDomains := []failuredomain.OpenStackFailureDomain{}
+	for _, fd := range failureDomains.List() {
+		openStackFailureDomains = append(openStackFailureDomains, fd.OpenStack())
+	}
+
+	if len(openStackFailureDomains) == 1 && openStackFailureDomains[0] == (failuredomain.OpenStackFailureDomain{}) {
+		// The cluster does not use availability zones.
+		return nil, nil
+	}
+
+	return openStackFailureDomains, nil
+}
+
+// buildControlPlaneMachineSetOpenStackMachineSpec builds an OpenStack flavored MachineSpec for the ControlPlaneMachineSet.
+// When failure domains are configured, the fields related to the failure domain are removed from the provider spec.
+func buildControlPlaneMachineSetOpenStackMachineSpec(machines []machinev1beta1.Machine, hasFailureDomains bool) (*machinev1beta1builder.MachineSpecApplyConfiguration, error) {
+	// The machines slice is sorted by the creation time.
+	// We want to get the provider config for the newest machine.
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machines[0].Spec, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract machine's OpenStack providerSpec: %w", err)
+	}
+
+	openStackProviderSpec := providerConfig.OpenStack().Config()
+
+	if hasFailureDomains {
+		// Remove fields related to the failure domain.
+		openStackProviderSpec.AvailabilityZone = ""
+
+		if openStackProviderSpec.RootVolume != nil {
+			openStackProviderSpec.RootVolume.Zone = ""
+		}
+	}
+
+	rawBytes, err := json.Marshal(openStackProviderSpec)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling OpenStack providerSpec: %w", err)
+	}
+
+	re := runtime.RawExtension{
+		Raw: rawBytes,
+	}
+
+	return &machinev1beta1builder.MachineSpecApplyConfiguration{
+		ProviderSpec: &machinev1beta1builder.ProviderSpecApplyConfiguration{Value: &re},
+	}, nil
+}
@@ -25,6 +25,7 @@ import (
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	machinev1builder "github.com/openshift/client-go/machine/applyconfigurations/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -91,12 +92,12 @@ func genericControlPlaneMachineSetSpec(replicas int32, clusterID string) machine
 func compareControlPlaneMachineSets(a, b *machinev1.ControlPlaneMachineSet) ([]string, error) {
 	// We need to compare the providerSpecs and the rest of the ControlPlaneMachineSets specs separately,
 	// as the formers are marshalled and need to be unmarshaled to be compared.
-	aProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(a.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec)
+	aProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(a.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
 	if err != nil {
 		return []string{}, fmt.Errorf("failed to extract providerSpec from MachineSpec: %w", err)
 	}
 
-	bProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(b.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec)
+	bProviderSpec, err := providerconfig.NewProviderConfigFromMachineSpec(b.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec, nil)
 	if err != nil {
 		return []string{}, fmt.Errorf("failed to extract providerSpec from MachineSpec: %w", err)
 	}
@@ -115,9 +116,16 @@ func compareControlPlaneMachineSets(a, b *machinev1.ControlPlaneMachineSet) ([]s
 
 	cpmsSpecDiff := deep.Equal(aCopy.Spec, bCopy.Spec)
 
-	// Combine the two diffs found.
+	// The failure domains of some platforms are configured by annotation, so these must be compared too.
+	var failureDomainsDiff []string
+	for _, annotation := range annotations.FailureDomainsAnnotations() {
+		failureDomainsDiff = append(failureDomainsDiff, deep.Equal(a.Annotations[annotation], b.Annotations[annotation])...)
+	}
+
+	// Combine the diffs found.
 	var diff []string
 	diff = append(diff, cpmsSpecDiff...)
+	diff = append(diff, failureDomainsDiff...)
 	diff = append(diff, providerSpecDiff...)
 
 	return diff, nil
@@ -0,0 +1,136 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachinesetgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1builder "github.com/openshift/client-go/machine/applyconfigurations/machine/v1"
+	machinev1beta1builder "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// generateControlPlaneMachineSetVSphereSpec generates a VSphere flavored ControlPlaneMachineSet Spec.
+// The ControlPlaneMachineSet API does not yet support vSphere failure domains, so these are returned as
+// annotations to be set on the ControlPlaneMachineSet.
+// When any of the Machines or MachineSets are not within a failure domain defined in the Infrastructure,
+// no failure domains are configured.
+func generateControlPlaneMachineSetVSphereSpec(machines []machinev1beta1.Machine, machineSets []machinev1beta1.MachineSet,
+	infrastructure *configv1.Infrastructure) (machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration, map[string]string, error) {
+	vSphereFailureDomains, err := buildVSphereFailureDomains(machineSets, machines, infrastructure)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, nil, fmt.Errorf("failed to build ControlPlaneMachineSet's VSphere failure domains: %w", err)
+	}
+
+	controlPlaneMachineSetMachineSpecApplyConfig, err := buildControlPlaneMachineSetVSphereMachineSpec(machines, infrastructure)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, nil, fmt.Errorf("failed to build ControlPlaneMachineSet's VSphere spec: %w", err)
+	}
+
+	// We want to work with the newest machine.
+	controlPlaneMachineSetApplyConfigSpec := genericControlPlaneMachineSetSpec(replicas, machines[0].ObjectMeta.Labels[clusterIDLabelKey])
+	controlPlaneMachineSetApplyConfigSpec.Template.OpenShiftMachineV1Beta1Machine.Spec = controlPlaneMachineSetMachineSpecApplyConfig
+
+	if len(vSphereFailureDomains) == 0 {
+		return controlPlaneMachineSetApplyConfigSpec, nil, nil
+	}
+
+	rawFailureDomains, err := json.Marshal(vSphereFailureDomains)
+	if err != nil {
+		return machinev1builder.ControlPlaneMachineSetSpecApplyConfiguration{}, nil, fmt.Errorf("error marshalling VSphere failure domains: %w", err)
+	}
+
+	controlPlaneMachineSetApplyConfigSpec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains = &machinev1builder.FailureDomainsApplyConfiguration{
+		Platform: util.Ptr(configv1.VSpherePlatformType),
+	}
+
+	cpmsAnnotations := map[string]string{
+		annotations.VSphereFailureDomainsAnnotation: string(rawFailureDomains),
+	}
+
+	return controlPlaneMachineSetApplyConfigSpec, cpmsAnnotations, nil
+}
+
+// buildVSphereFailureDomains builds the VSphere failure domains for the ControlPaneMachineSet from the cluster's
+// Machines and MachineSets. When any of the failure domains found is not defined in the Infrastructure,
+// no failure domains are returned.
+func buildVSphereFailureDomains(machineSets []machinev1beta1.MachineSet, machines []machinev1beta1.Machine,
+	infrastructure *configv1.Infrastructure) ([]failuredomain.VSphereFailureDomain, error) {
+	// Fetch failure domains from the machines
+	machineFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachines(machines, infrastructure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract failure domains from machines: %w", err)
+	}
+
+	// Fetch failure domains from the machineSets
+	machineSetFailureDomains, err := providerconfig.ExtractFailureDomainsFromMachineSets(machineSets, infrastructure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract failure domains from machine sets: %w", err)
+	}
+
+	// We have to get rid of duplicates from the failure domains.
+	// We construct a set from the failure domains, since a set can't have duplicates.
+	failureDomains := failuredomain.NewSet(machineFailureDomains...)
+	// Construction of a union of failure domains of machines and machineSets.
+	failureDomains.Insert(machineSetFailureDomains...)
+
+	vSphereFailureDomains := []failuredomain.VSphereFailureDomain{}
+
+	for _, fd := range failureDomains.List() {
+		if fd.VSphere().Name == "" {
+			// The cluster does not use failure domains, or not all of its Machines are within one.
+			return nil, nil
+		}
+
+		vSphereFailureDomains = append(vSphereFailureDomains, fd.VSphere())
+	}
+
+	return vSphereFailureDomains, nil
+}
+
+// buildControlPlaneMachineSetVSphereMachineSpec builds a VSphere flavored MachineSpec for the ControlPlaneMachineSet.
+// The workspace and network are retained within the provider spec, as they are required fields,
+// and are replaced by those of the failure domain when Machines are created.
+func buildControlPlaneMachineSetVSphereMachineSpec(machines []machinev1beta1.Machine,
+	infrastructure *configv1.Infrastructure) (*machinev1beta1builder.MachineSpecApplyConfiguration, error) {
+	// The machines slice is sorted by the creation time.
+	// We want to get the provider config for the newest machine.
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machines[0].Spec, infrastructure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract machine's VSphere providerSpec: %w", err)
+	}
+
+	rawBytes, err := json.Marshal(providerConfig.VSphere().Config())
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling VSphere providerSpec: %w", err)
+	}
+
+	re := runtime.RawExtension{
+		Raw: rawBytes,
+	}
+
+	return &machinev1beta1builder.MachineSpecApplyConfiguration{
+		ProviderSpec: &machinev1beta1builder.ProviderSpecApplyConfiguration{Value: &re},
+	}, nil
+}
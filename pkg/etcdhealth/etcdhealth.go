@@ -0,0 +1,216 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdhealth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// etcdNamespace is the namespace in which the etcd operator runs the etcd members.
+	etcdNamespace = "openshift-etcd"
+
+	// etcdEndpointsConfigMapName is the name of the ConfigMap in which the etcd operator publishes the voting members
+	// of the etcd cluster. Each entry maps the ID of a voting member to its IP address. Learner members are not
+	// published until they have been promoted.
+	etcdEndpointsConfigMapName = "etcd-endpoints"
+
+	// etcdPodLabelKey and etcdPodLabelValue identify the static pods that run the etcd members.
+	// The etcd pod only becomes ready once its member passes the etcd readiness check.
+	etcdPodLabelKey   = "app"
+	etcdPodLabelValue = "etcd"
+)
+
+// member is a voting member of the etcd cluster.
+type member struct {
+	// ip is the IP address of the member, as published by the etcd operator.
+	ip string
+
+	// nodeName is the name of the Node running the member. It is empty when no etcd pod runs with the IP address of
+	// the member.
+	nodeName string
+
+	// healthy is whether the etcd pod running the member is ready.
+	healthy bool
+}
+
+// MemberHealthChecker checks the health of the etcd cluster using the voting members published by the etcd operator,
+// and the readiness of the etcd pod running each member.
+type MemberHealthChecker struct {
+	client client.Reader
+
+	// namespace is the namespace in which the etcd members run.
+	namespace string
+}
+
+// NewMemberHealthChecker creates a new MemberHealthChecker that reads the etcd members using the given client.
+func NewMemberHealthChecker(client client.Reader) *MemberHealthChecker {
+	return &MemberHealthChecker{
+		client:    client,
+		namespace: etcdNamespace,
+	}
+}
+
+// CheckRemoval checks that the etcd member on the Node of the replacement Machine has joined the etcd cluster as a
+// voting member and is healthy, and that a quorum of the voting members remains healthy once the member on the
+// outdated Machine is removed.
+// It returns an empty message when the outdated Machine may be removed, or otherwise, a message explaining why it
+// must not yet be removed.
+func (c *MemberHealthChecker) CheckRemoval(ctx context.Context, outdated, replacement machineproviders.MachineInfo) (string, error) {
+	members, err := c.votingMembers(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if replacement.NodeRef == nil {
+		return "replacement Machine has no Node", nil
+	}
+
+	replacementNodeName := replacement.NodeRef.ObjectMeta.Name
+
+	replacementMember, ok := findMember(members, replacementNodeName)
+	if !ok {
+		return fmt.Sprintf("etcd member on Node %s has not yet joined the cluster as a voting member", replacementNodeName), nil
+	}
+
+	if !replacementMember.healthy {
+		return fmt.Sprintf("etcd member on Node %s is not healthy", replacementNodeName), nil
+	}
+
+	return checkQuorum(members, outdated), nil
+}
+
+// CheckRemovalWithoutReplacement checks that a quorum of the voting members remains healthy once the member on the
+// outdated Machine is removed, before any replacement for it has been created.
+// It returns an empty message when the outdated Machine may be removed, or otherwise, a message explaining why it
+// must not yet be removed.
+func (c *MemberHealthChecker) CheckRemovalWithoutReplacement(ctx context.Context, outdated machineproviders.MachineInfo) (string, error) {
+	members, err := c.votingMembers(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return checkQuorum(members, outdated), nil
+}
+
+// votingMembers returns the voting members of the etcd cluster, along with their health.
+func (c *MemberHealthChecker) votingMembers(ctx context.Context) ([]member, error) {
+	endpoints := &corev1.ConfigMap{}
+	if err := c.client.Get(ctx, client.ObjectKey{Namespace: c.namespace, Name: etcdEndpointsConfigMapName}, endpoints); err != nil {
+		return nil, fmt.Errorf("failed to get etcd voting members: %w", err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.client.List(ctx, pods, client.InNamespace(c.namespace), client.MatchingLabels{etcdPodLabelKey: etcdPodLabelValue}); err != nil {
+		return nil, fmt.Errorf("failed to list etcd pods: %w", err)
+	}
+
+	members := []member{}
+
+	for _, ip := range endpoints.Data {
+		m := member{ip: ip}
+
+		if pod := findPodByIP(pods.Items, ip); pod != nil {
+			m.nodeName = pod.Spec.NodeName
+			m.healthy = isPodReady(pod)
+		}
+
+		members = append(members, m)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ip < members[j].ip
+	})
+
+	return members, nil
+}
+
+// checkQuorum checks that a quorum of the voting members remains healthy once the member on the Node of the outdated
+// Machine is removed. The quorum is based on the current number of voting members, so that it is maintained
+// throughout the removal of the member.
+// It returns an empty message when quorum is maintained, or otherwise, a message explaining why it is not.
+func checkQuorum(members []member, outdated machineproviders.MachineInfo) string {
+	healthy := 0
+
+	for _, m := range members {
+		if m.healthy {
+			healthy++
+		}
+	}
+
+	remaining := healthy
+
+	if outdated.NodeRef != nil {
+		if m, ok := findMember(members, outdated.NodeRef.ObjectMeta.Name); ok && m.healthy {
+			remaining--
+		}
+	}
+
+	quorum := len(members)/2 + 1
+
+	if remaining < quorum {
+		return fmt.Sprintf("removal would leave %d of %d etcd voting members healthy, below the quorum of %d", remaining, len(members), quorum)
+	}
+
+	return ""
+}
+
+// findMember returns the voting member running on the given Node.
+func findMember(members []member, nodeName string) (member, bool) {
+	for _, m := range members {
+		if m.nodeName != "" && m.nodeName == nodeName {
+			return m, true
+		}
+	}
+
+	return member{}, false
+}
+
+// findPodByIP returns the pod with the given IP address, or nil if there is none.
+// The etcd pods run on the host network, so their IP address is the IP address of the Node.
+func findPodByIP(pods []corev1.Pod, ip string) *corev1.Pod {
+	for i := range pods {
+		if pods[i].Status.PodIP == ip || pods[i].Status.HostIP == ip {
+			return &pods[i]
+		}
+
+		for _, podIP := range pods[i].Status.PodIPs {
+			if podIP.IP == ip {
+				return &pods[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// isPodReady checks whether the pod reports the Ready condition.
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdhealth
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// etcdPod describes the etcd pod running a member on a Node.
+type etcdPod struct {
+	nodeName string
+	ip       string
+	ready    bool
+}
+
+var _ = Describe("MemberHealthChecker", func() {
+	var namespaceName string
+	var checker *MemberHealthChecker
+
+	outdated := machineproviders.MachineInfo{
+		NodeRef: &machineproviders.ObjectRef{ObjectMeta: metav1.ObjectMeta{Name: "node-0"}},
+		Ready:   true,
+	}
+
+	replacement := machineproviders.MachineInfo{
+		NodeRef: &machineproviders.ObjectRef{ObjectMeta: metav1.ObjectMeta{Name: "node-replacement-0"}},
+		Ready:   true,
+	}
+
+	healthyPods := []etcdPod{
+		{nodeName: "node-0", ip: "10.0.0.10", ready: true},
+		{nodeName: "node-1", ip: "10.0.0.11", ready: true},
+		{nodeName: "node-2", ip: "10.0.0.12", ready: true},
+	}
+
+	healthyReplacementPod := etcdPod{nodeName: "node-replacement-0", ip: "10.0.0.20", ready: true}
+
+	// withPod returns the given pods, with the pod on the Node of the given pod replaced.
+	withPod := func(pods []etcdPod, pod etcdPod) []etcdPod {
+		out := []etcdPod{}
+
+		for _, p := range pods {
+			if p.nodeName != pod.nodeName {
+				out = append(out, p)
+			}
+		}
+
+		return append(out, pod)
+	}
+
+	// createEtcdPods creates a ready, or not ready, etcd pod on each of the given Nodes.
+	createEtcdPods := func(pods []etcdPod) {
+		for _, p := range pods {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "etcd-" + p.nodeName,
+					Namespace: namespaceName,
+					Labels:    map[string]string{etcdPodLabelKey: etcdPodLabelValue},
+				},
+				Spec: corev1.PodSpec{
+					NodeName:    p.nodeName,
+					HostNetwork: true,
+					Containers:  []corev1.Container{{Name: "etcd", Image: "etcd"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+
+			readyStatus := corev1.ConditionFalse
+			if p.ready {
+				readyStatus = corev1.ConditionTrue
+			}
+
+			pod.Status = corev1.PodStatus{
+				HostIP:     p.ip,
+				PodIP:      p.ip,
+				PodIPs:     []corev1.PodIP{{IP: p.ip}},
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+			}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		}
+	}
+
+	// publishVotingMembers publishes the IP addresses of the voting members, as the etcd operator does.
+	publishVotingMembers := func(ips []string) {
+		endpoints := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      etcdEndpointsConfigMapName,
+				Namespace: namespaceName,
+			},
+			Data: map[string]string{},
+		}
+
+		for i, ip := range ips {
+			// The etcd operator keys each voting member by its member ID.
+			endpoints.Data[fmt.Sprintf("%016x", i+1)] = ip
+		}
+
+		Expect(k8sClient.Create(ctx, endpoints)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		By("Setting up a namespace for the test")
+		ns := corev1resourcebuilder.Namespace().WithGenerateName("etcd-health-").Build()
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		namespaceName = ns.GetName()
+
+		checker = NewMemberHealthChecker(k8sClient)
+		checker.namespace = namespaceName
+	})
+
+	AfterEach(func() {
+		// The pods are scheduled to Nodes, so they must be removed immediately as there is no kubelet to remove them.
+		Expect(k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(namespaceName), client.GracePeriodSeconds(0))).To(Succeed())
+
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
+			&corev1.Pod{},
+			&corev1.ConfigMap{},
+		)
+	})
+
+	It("should return an error when the voting members cannot be read", func() {
+		_, err := checker.CheckRemoval(ctx, outdated, replacement)
+		Expect(apierrors.IsNotFound(errors.Unwrap(err))).To(BeTrue())
+
+		_, err = checker.CheckRemovalWithoutReplacement(ctx, outdated)
+		Expect(apierrors.IsNotFound(errors.Unwrap(err))).To(BeTrue())
+	})
+
+	type checkRemovalTableInput struct {
+		pods               []etcdPod
+		votingIPs          []string
+		replacement        machineproviders.MachineInfo
+		withoutReplacement bool
+		expectedMessage    string
+	}
+
+	DescribeTable("should check the health of the etcd voting members", func(in checkRemovalTableInput) {
+		createEtcdPods(in.pods)
+		publishVotingMembers(in.votingIPs)
+
+		var message string
+		var err error
+
+		if in.withoutReplacement {
+			message, err = checker.CheckRemovalWithoutReplacement(ctx, outdated)
+		} else {
+			message, err = checker.CheckRemoval(ctx, outdated, in.replacement)
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(message).To(Equal(in.expectedMessage))
+	},
+		Entry("when the replacement has joined and all members are healthy", checkRemovalTableInput{
+			pods:            append(healthyPods, healthyReplacementPod),
+			votingIPs:       []string{"10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.20"},
+			replacement:     replacement,
+			expectedMessage: "",
+		}),
+		Entry("when the replacement has no Node", checkRemovalTableInput{
+			pods:            append(healthyPods, healthyReplacementPod),
+			votingIPs:       []string{"10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.20"},
+			replacement:     machineproviders.MachineInfo{Ready: true},
+			expectedMessage: "replacement Machine has no Node",
+		}),
+		Entry("when the replacement runs an etcd pod, but has not joined as a voting member", checkRemovalTableInput{
+			pods:            append(healthyPods, healthyReplacementPod),
+			votingIPs:       []string{"10.0.0.10", "10.0.0.11", "10.0.0.12"},
+			replacement:     replacement,
+			expectedMessage: "etcd member on Node node-replacement-0 has not yet joined the cluster as a voting member",
+		}),
+		Entry("when the replacement has joined, but is not healthy", checkRemovalTableInput{
+			pods:            append(healthyPods, etcdPod{nodeName: "node-replacement-0", ip: "10.0.0.20", ready: false}),
+			votingIPs:       []string{"10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.20"},
+			replacement:     replacement,
+			expectedMessage: "etcd member on Node node-replacement-0 is not healthy",
+		}),
+		Entry("when the replacement has joined, and another member is already unhealthy", checkRemovalTableInput{
+			pods:            append(withPod(healthyPods, etcdPod{nodeName: "node-1", ip: "10.0.0.11", ready: false}), healthyReplacementPod),
+			votingIPs:       []string{"10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.20"},
+			replacement:     replacement,
+			expectedMessage: "removal would leave 2 of 4 etcd voting members healthy, below the quorum of 3",
+		}),
+		Entry("when the replacement has joined, and a voting member has no etcd pod", checkRemovalTableInput{
+			pods:            append(healthyPods[:2:2], healthyReplacementPod),
+			votingIPs:       []string{"10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.20"},
+			replacement:     replacement,
+			expectedMessage: "removal would leave 2 of 4 etcd voting members healthy, below the quorum of 3",
+		}),
+		Entry("when the replacement has joined, and the outdated member is unhealthy", checkRemovalTableInput{
+			pods:            append(withPod(healthyPods, etcdPod{nodeName: "node-0", ip: "10.0.0.10", ready: false}), healthyReplacementPod),
+			votingIPs:       []string{"10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.20"},
+			replacement:     replacement,
+			expectedMessage: "",
+		}),
+		Entry("without a replacement, when all members are healthy", checkRemovalTableInput{
+			pods:               healthyPods,
+			votingIPs:          []string{"10.0.0.10", "10.0.0.11", "10.0.0.12"},
+			withoutReplacement: true,
+			expectedMessage:    "",
+		}),
+		Entry("without a replacement, when another member is already unhealthy", checkRemovalTableInput{
+			pods:               withPod(healthyPods, etcdPod{nodeName: "node-2", ip: "10.0.0.12", ready: false}),
+			votingIPs:          []string{"10.0.0.10", "10.0.0.11", "10.0.0.12"},
+			withoutReplacement: true,
+			expectedMessage:    "removal would leave 1 of 3 etcd voting members healthy, below the quorum of 2",
+		}),
+		Entry("without a replacement, when the outdated member is unhealthy", checkRemovalTableInput{
+			pods:               withPod(healthyPods, etcdPod{nodeName: "node-0", ip: "10.0.0.10", ready: false}),
+			votingIPs:          []string{"10.0.0.10", "10.0.0.11", "10.0.0.12"},
+			withoutReplacement: true,
+			expectedMessage:    "",
+		}),
+	)
+})
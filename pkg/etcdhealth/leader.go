@@ -0,0 +1,162 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdhealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// thanosQuerierURL is the in-cluster address of the Thanos Querier of the cluster monitoring stack.
+	thanosQuerierURL = "https://thanos-querier.openshift-monitoring.svc:9091"
+
+	// serviceCAFile is the CA bundle of the service serving certificates, such as the Thanos Querier certificate.
+	// It is mounted into every pod alongside the service account token.
+	serviceCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+
+	// etcdLeaderQuery selects the etcd member that currently reports itself as the leader.
+	etcdLeaderQuery = "etcd_server_is_leader == 1"
+
+	// podLabel is the label identifying the etcd pod that reported a metric.
+	podLabel = "pod"
+)
+
+var (
+	// errUnexpectedQueryStatus is returned when the monitoring stack does not answer the query successfully.
+	errUnexpectedQueryStatus = errors.New("unexpected query status")
+)
+
+// queryResponse is the part of the Prometheus query API response that is used to locate the etcd leader.
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// LeaderLocator locates the etcd leader using the etcd_server_is_leader metric that each etcd member reports to the
+// cluster monitoring stack. The etcd members only serve their metrics to clients with an etcd client certificate,
+// so the metric is read from the Thanos Querier, with the service account token of the operator.
+type LeaderLocator struct {
+	client     client.Reader
+	httpClient *http.Client
+
+	// namespace is the namespace in which the etcd members run.
+	namespace string
+
+	// queryURL is the address of the Prometheus query API serving the etcd metrics.
+	queryURL string
+}
+
+// NewLeaderLocator creates a new LeaderLocator that reads the etcd pods using the given client, and queries the
+// cluster monitoring stack with the credentials from the given config.
+func NewLeaderLocator(client client.Reader, config *rest.Config) (*LeaderLocator, error) {
+	httpClient, err := rest.HTTPClientFor(&rest.Config{
+		BearerToken:     config.BearerToken,
+		BearerTokenFile: config.BearerTokenFile,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile: serviceCAFile,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create monitoring client: %w", err)
+	}
+
+	return &LeaderLocator{
+		client:     client,
+		httpClient: httpClient,
+		namespace:  etcdNamespace,
+		queryURL:   thanosQuerierURL,
+	}, nil
+}
+
+// LeaderNodeName returns the name of the Node running the etcd pod of the current etcd leader.
+// An empty name is returned when no single member reports itself as the leader, for example, during a leader
+// election, or when the etcd pod of the leader cannot be found.
+func (l *LeaderLocator) LeaderNodeName(ctx context.Context) (string, error) {
+	leaderPodName, err := l.leaderPodName(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if leaderPodName == "" {
+		return "", nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := l.client.List(ctx, pods, client.InNamespace(l.namespace), client.MatchingLabels{etcdPodLabelKey: etcdPodLabelValue}); err != nil {
+		return "", fmt.Errorf("failed to list etcd pods: %w", err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Name == leaderPodName {
+			return pod.Spec.NodeName, nil
+		}
+	}
+
+	return "", nil
+}
+
+// leaderPodName queries the monitoring stack for the name of the etcd pod that reports itself as the leader.
+func (l *LeaderLocator) leaderPodName(ctx context.Context) (string, error) {
+	query := url.Values{"query": []string{etcdLeaderQuery}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.queryURL+"/api/v1/query?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("could not create etcd leader query: %w", err)
+	}
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query etcd leader: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read etcd leader query response: %w", err)
+	}
+
+	response := queryResponse{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to query etcd leader: %s: %w", resp.Status, errUnexpectedQueryStatus)
+	}
+
+	if response.Status != "success" {
+		return "", fmt.Errorf("failed to query etcd leader: %s: %w: %s", resp.Status, errUnexpectedQueryStatus, response.Error)
+	}
+
+	if len(response.Data.Result) != 1 {
+		// Either no member is the leader, or the metrics of a previous leader have not yet been updated.
+		return "", nil
+	}
+
+	return response.Data.Result[0].Metric[podLabel], nil
+}
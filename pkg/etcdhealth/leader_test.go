@@ -0,0 +1,160 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdhealth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("LeaderLocator", func() {
+	var namespaceName string
+	var locator *LeaderLocator
+	var server *httptest.Server
+
+	// responseStatus and responseBody are served by the fake query API.
+	var responseStatus int
+	var responseBody string
+
+	// receivedQuery is the query received by the fake query API.
+	var receivedQuery string
+
+	// leaderResult builds a successful query response with a result for each of the given pods.
+	leaderResult := func(podNames ...string) string {
+		results := ""
+
+		for i, podName := range podNames {
+			if i > 0 {
+				results += ","
+			}
+
+			results += fmt.Sprintf(`{"metric":{"__name__":"etcd_server_is_leader","namespace":"openshift-etcd","pod":%q},"value":[1690000000,"1"]}`, podName)
+		}
+
+		return `{"status":"success","data":{"resultType":"vector","result":[` + results + `]}}`
+	}
+
+	BeforeEach(func() {
+		By("Setting up a namespace for the test")
+		ns := corev1resourcebuilder.Namespace().WithGenerateName("etcd-leader-").Build()
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		namespaceName = ns.GetName()
+
+		By("Creating the etcd pods")
+		for _, nodeName := range []string{"node-0", "node-1", "node-2"} {
+			Expect(k8sClient.Create(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "etcd-" + nodeName,
+					Namespace: namespaceName,
+					Labels:    map[string]string{etcdPodLabelKey: etcdPodLabelValue},
+				},
+				Spec: corev1.PodSpec{
+					NodeName:    nodeName,
+					HostNetwork: true,
+					Containers:  []corev1.Container{{Name: "etcd", Image: "etcd"}},
+				},
+			})).To(Succeed())
+		}
+
+		By("Setting up the query API")
+		responseStatus = http.StatusOK
+		responseBody = leaderResult()
+		receivedQuery = ""
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			Expect(r.URL.Path).To(Equal("/api/v1/query"))
+			receivedQuery = r.URL.Query().Get("query")
+
+			w.WriteHeader(responseStatus)
+			_, _ = w.Write([]byte(responseBody))
+		}))
+
+		locator = &LeaderLocator{
+			client:     k8sClient,
+			httpClient: server.Client(),
+			namespace:  namespaceName,
+			queryURL:   server.URL,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+
+		// The pods are scheduled to Nodes, so they must be removed immediately as there is no kubelet to remove them.
+		Expect(k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(namespaceName), client.GracePeriodSeconds(0))).To(Succeed())
+
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
+			&corev1.Pod{},
+		)
+	})
+
+	It("should query the etcd leader metric", func() {
+		_, err := locator.LeaderNodeName(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receivedQuery).To(Equal(etcdLeaderQuery))
+	})
+
+	It("should return the Node of the etcd pod reporting itself as the leader", func() {
+		responseBody = leaderResult("etcd-node-1")
+
+		Expect(locator.LeaderNodeName(ctx)).To(Equal("node-1"))
+	})
+
+	It("should not return a Node when no member reports itself as the leader", func() {
+		Expect(locator.LeaderNodeName(ctx)).To(BeEmpty())
+	})
+
+	It("should not return a Node when more than one member reports itself as the leader", func() {
+		responseBody = leaderResult("etcd-node-0", "etcd-node-1")
+
+		Expect(locator.LeaderNodeName(ctx)).To(BeEmpty())
+	})
+
+	It("should not return a Node when the etcd pod of the leader does not exist", func() {
+		responseBody = leaderResult("etcd-node-other")
+
+		Expect(locator.LeaderNodeName(ctx)).To(BeEmpty())
+	})
+
+	It("should return an error when the query fails", func() {
+		responseStatus = http.StatusBadRequest
+		responseBody = `{"status":"error","errorType":"bad_data","error":"invalid query"}`
+
+		_, err := locator.LeaderNodeName(ctx)
+		Expect(err).To(MatchError(fmt.Errorf("failed to query etcd leader: 400 Bad Request: %w: invalid query", errUnexpectedQueryStatus)))
+	})
+
+	It("should return an error when the query API is unavailable", func() {
+		responseStatus = http.StatusServiceUnavailable
+		responseBody = "service unavailable"
+
+		_, err := locator.LeaderNodeName(ctx)
+		Expect(err).To(MatchError(fmt.Errorf("failed to query etcd leader: 503 Service Unavailable: %w", errUnexpectedQueryStatus)))
+	})
+})
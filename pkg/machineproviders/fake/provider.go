@@ -0,0 +1,507 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	machinev1 "github.com/openshift/api/machine/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Phase is the lifecycle phase of a simulated Machine.
+type Phase string
+
+const (
+	// PhaseProvisioning is the phase of a Machine that has been created but is not yet Ready.
+	PhaseProvisioning Phase = "Provisioning"
+
+	// PhaseRunning is the phase of a Machine that is Ready and backed by a Node.
+	PhaseRunning Phase = "Running"
+
+	// PhaseFailed is the phase of a Machine that failed to provision.
+	PhaseFailed Phase = "Failed"
+)
+
+// ActionType is the type of an action taken on the MachineProvider by its caller.
+type ActionType string
+
+const (
+	// ActionCreate is recorded when the caller creates a Machine.
+	ActionCreate ActionType = "Create"
+
+	// ActionDelete is recorded when the caller deletes a Machine.
+	ActionDelete ActionType = "Delete"
+
+	// ActionUpdateInPlace is recorded when the caller updates a Machine in place.
+	ActionUpdateInPlace ActionType = "UpdateInPlace"
+)
+
+// stepInterval is the amount of simulated time that passes each time the simulation is stepped.
+const stepInterval = time.Minute
+
+var (
+	// errMachineNotFound is returned when an operation references a Machine that does not exist.
+	errMachineNotFound = errors.New("machine not found")
+
+	// errUnknownGroupVersionResource is returned when a Machine reference has an unexpected group version resource.
+	errUnknownGroupVersionResource = errors.New("unknown group/version/resource")
+)
+
+// Machine is a simulated Machine held in memory by the MachineProvider.
+type Machine struct {
+	// Name is the name of the Machine.
+	Name string
+
+	// Index is the control plane index of the Machine.
+	Index int32
+
+	// TemplateRevision is the template revision the Machine was created from.
+	// The Machine needs an update when this differs from the current template revision of the MachineProvider.
+	TemplateRevision string
+
+	// Phase is the lifecycle phase of the Machine.
+	Phase Phase
+
+	// ErrorMessage is set when the Machine failed to provision.
+	ErrorMessage string
+
+	// Finalizers are the finalizers that prevent the Machine from being removed once it has been deleted.
+	Finalizers []string
+
+	// CreationTimestamp is the simulated time at which the Machine was created.
+	CreationTimestamp time.Time
+
+	// DeletionTimestamp is the simulated time at which the Machine was deleted.
+	DeletionTimestamp *time.Time
+
+	// provisioningSteps is the number of steps remaining before the Machine finishes provisioning.
+	provisioningSteps int
+
+	// provisioningFailure is the error message the Machine reports when it finishes provisioning.
+	// When empty, the Machine becomes Ready.
+	provisioningFailure string
+}
+
+// Action is a record of an action taken on the MachineProvider by its caller.
+type Action struct {
+	// Type is the type of the action.
+	Type ActionType
+
+	// Index is the index of the Machine the action was taken on.
+	Index int32
+
+	// Name is the name of the Machine the action was taken on.
+	Name string
+}
+
+// MachineProvider is a stateful, in-memory implementation of the machineproviders.MachineProvider interface.
+// It simulates the lifecycle of Machines, including provisioning delays, provisioning failures, Ready transitions
+// and deletion finalizers, so that the ControlPlaneMachineSet controller can be driven through full rollouts
+// without an API server.
+// The simulation only progresses when Step is called.
+type MachineProvider struct {
+	lock sync.Mutex
+
+	namespace      string
+	ownerReference metav1.OwnerReference
+
+	now               time.Time
+	machines          []*Machine
+	machineCount      int
+	templateRevision  string
+	provisioningSteps int
+	finalizers        []string
+
+	createErrors        map[int32]error
+	provisioningFailure map[int32]string
+
+	actions []Action
+}
+
+// NewMachineProvider creates a new simulated MachineProvider for the ControlPlaneMachineSet.
+// Machines created by the MachineProvider are owned by the ControlPlaneMachineSet and become Ready on the first Step
+// after they are created.
+func NewMachineProvider(cpms *machinev1.ControlPlaneMachineSet) *MachineProvider {
+	return &MachineProvider{
+		namespace: cpms.Namespace,
+		ownerReference: metav1.OwnerReference{
+			APIVersion:         machinev1.GroupVersion.String(),
+			Kind:               "ControlPlaneMachineSet",
+			Name:               cpms.Name,
+			UID:                cpms.UID,
+			Controller:         pointer.Bool(true),
+			BlockOwnerDeletion: pointer.Bool(true),
+		},
+		now:                 time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+		templateRevision:    "1",
+		provisioningSteps:   1,
+		createErrors:        make(map[int32]error),
+		provisioningFailure: make(map[int32]string),
+	}
+}
+
+// AddMachine adds a Ready Machine, created from the current template revision, in the given index.
+// It is used to set up the initial state of the simulation.
+func (m *MachineProvider) AddMachine(index int32) string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	machine := m.newMachine(index)
+	machine.Phase = PhaseRunning
+	machine.provisioningSteps = 0
+
+	return machine.Name
+}
+
+// SetTemplateRevision sets the current template revision. Any Machine created from a different revision
+// needs an update.
+func (m *MachineProvider) SetTemplateRevision(revision string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.templateRevision = revision
+}
+
+// SetProvisioningSteps sets the number of steps a newly created Machine takes to finish provisioning.
+func (m *MachineProvider) SetProvisioningSteps(steps int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.provisioningSteps = steps
+}
+
+// SetFinalizers sets the finalizers added to Machines created after the call.
+// For example, this can be used to simulate the deletion hook held by the etcd operator.
+func (m *MachineProvider) SetFinalizers(finalizers ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.finalizers = finalizers
+}
+
+// FailNextCreate causes the next call to CreateMachine for the index to return the error.
+func (m *MachineProvider) FailNextCreate(index int32, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.createErrors[index] = err
+}
+
+// FailNextProvisioning causes the next Machine created in the index to fail to provision, reporting the message.
+func (m *MachineProvider) FailNextProvisioning(index int32, message string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.provisioningFailure[index] = message
+}
+
+// DeleteMachineByName deletes the named Machine, as a user or a machine health check would.
+func (m *MachineProvider) DeleteMachineByName(name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	machine := m.getMachine(name)
+	if machine == nil {
+		return fmt.Errorf("%w: %s", errMachineNotFound, name)
+	}
+
+	m.markDeleted(machine)
+
+	return nil
+}
+
+// RemoveFinalizers removes all finalizers from the named Machine.
+// Once deleted, a Machine without finalizers is removed on the next Step.
+func (m *MachineProvider) RemoveFinalizers(name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	machine := m.getMachine(name)
+	if machine == nil {
+		return fmt.Errorf("%w: %s", errMachineNotFound, name)
+	}
+
+	machine.Finalizers = nil
+
+	return nil
+}
+
+// Step advances the simulation.
+// Deleted Machines without finalizers are removed, and Machines that are provisioning move closer to completion,
+// either becoming Ready or, when a failure was requested, failing.
+func (m *MachineProvider) Step() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.now = m.now.Add(stepInterval)
+
+	machines := []*Machine{}
+
+	for _, machine := range m.machines {
+		if machine.DeletionTimestamp != nil && len(machine.Finalizers) == 0 {
+			continue
+		}
+
+		machines = append(machines, machine)
+
+		if machine.Phase != PhaseProvisioning {
+			continue
+		}
+
+		if machine.provisioningSteps > 0 {
+			machine.provisioningSteps--
+		}
+
+		if machine.provisioningSteps > 0 {
+			continue
+		}
+
+		if machine.provisioningFailure != "" {
+			machine.Phase = PhaseFailed
+			machine.ErrorMessage = machine.provisioningFailure
+
+			continue
+		}
+
+		machine.Phase = PhaseRunning
+	}
+
+	m.machines = machines
+}
+
+// Machines returns a copy of the Machines that currently exist, in the order they were created.
+func (m *MachineProvider) Machines() []Machine {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	out := []Machine{}
+
+	for _, machine := range m.machines {
+		machineCopy := *machine
+		machineCopy.Finalizers = append([]string{}, machine.Finalizers...)
+
+		out = append(out, machineCopy)
+	}
+
+	return out
+}
+
+// Actions returns the actions taken on the MachineProvider by its caller, in the order they were taken.
+func (m *MachineProvider) Actions() []Action {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]Action{}, m.actions...)
+}
+
+// GetMachineInfos returns a MachineInfo for each of the simulated Machines.
+func (m *MachineProvider) GetMachineInfos(ctx context.Context, logger logr.Logger) ([]machineproviders.MachineInfo, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	machineInfos := []machineproviders.MachineInfo{}
+
+	for _, machine := range m.machines {
+		machineInfos = append(machineInfos, m.machineInfo(machine))
+	}
+
+	sort.SliceStable(machineInfos, func(i, j int) bool {
+		return machineInfos[i].Index < machineInfos[j].Index
+	})
+
+	return machineInfos, nil
+}
+
+// WithClient returns the MachineProvider.
+// The simulated Machines are not stored in the API, so the client is ignored and the simulation state is shared.
+func (m *MachineProvider) WithClient(client.Client) machineproviders.MachineProvider {
+	return m
+}
+
+// CreateMachine creates a new simulated Machine in the index, from the current template revision.
+func (m *MachineProvider) CreateMachine(ctx context.Context, logger logr.Logger, index int32) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err, ok := m.createErrors[index]; ok {
+		delete(m.createErrors, index)
+
+		return fmt.Errorf("cannot create machine: %w", err)
+	}
+
+	machine := m.newMachine(index)
+	machine.provisioningFailure = m.provisioningFailure[index]
+	delete(m.provisioningFailure, index)
+
+	m.actions = append(m.actions, Action{Type: ActionCreate, Index: index, Name: machine.Name})
+
+	logger.V(2).Info(
+		"Created machine",
+		"index", index,
+		"machineName", machine.Name,
+	)
+
+	return nil
+}
+
+// DeleteMachine deletes the referenced simulated Machine.
+// Deleting a Machine that does not exist is not an error.
+func (m *MachineProvider) DeleteMachine(ctx context.Context, logger logr.Logger, machineRef *machineproviders.ObjectRef) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	machinesGVR := machinev1beta1.GroupVersion.WithResource("machines")
+	if machineRef.GroupVersionResource != machinesGVR {
+		return fmt.Errorf("%w: expected %s, got %s", errUnknownGroupVersionResource, machinesGVR.String(), machineRef.GroupVersionResource.String())
+	}
+
+	machine := m.getMachine(machineRef.ObjectMeta.Name)
+	if machine == nil {
+		logger.V(2).Info("Machine not found", "namespace", machineRef.ObjectMeta.Namespace, "machineName", machineRef.ObjectMeta.Name)
+
+		return nil
+	}
+
+	m.markDeleted(machine)
+	m.actions = append(m.actions, Action{Type: ActionDelete, Index: machine.Index, Name: machine.Name})
+
+	logger.V(2).Info(
+		"Deleted machine",
+		"namespace", machineRef.ObjectMeta.Namespace,
+		"machineName", machineRef.ObjectMeta.Name,
+	)
+
+	return nil
+}
+
+// UpdateMachineInPlace records the in place update of the referenced simulated Machine.
+// The simulated Machines have no fields that can be updated in place, so the Machine is not changed.
+func (m *MachineProvider) UpdateMachineInPlace(ctx context.Context, logger logr.Logger, machineRef *machineproviders.ObjectRef) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	machinesGVR := machinev1beta1.GroupVersion.WithResource("machines")
+	if machineRef.GroupVersionResource != machinesGVR {
+		return fmt.Errorf("%w: expected %s, got %s", errUnknownGroupVersionResource, machinesGVR.String(), machineRef.GroupVersionResource.String())
+	}
+
+	machine := m.getMachine(machineRef.ObjectMeta.Name)
+	if machine == nil {
+		return fmt.Errorf("%w: %s", errMachineNotFound, machineRef.ObjectMeta.Name)
+	}
+
+	m.actions = append(m.actions, Action{Type: ActionUpdateInPlace, Index: machine.Index, Name: machine.Name})
+
+	return nil
+}
+
+// GetFailureDomainMapping returns an empty mapping, the simulated Machines are not placed in failure domains.
+func (m *MachineProvider) GetFailureDomainMapping() map[int32]machineproviders.FailureDomainMapping {
+	return map[int32]machineproviders.FailureDomainMapping{}
+}
+
+// newMachine adds a new provisioning Machine to the index.
+// The simulated time is advanced so that each Machine has a unique creation timestamp.
+func (m *MachineProvider) newMachine(index int32) *Machine {
+	m.now = m.now.Add(time.Second)
+
+	machine := &Machine{
+		Name:              fmt.Sprintf("machine-%d-%d", index, m.machineCount),
+		Index:             index,
+		TemplateRevision:  m.templateRevision,
+		Phase:             PhaseProvisioning,
+		Finalizers:        append([]string{}, m.finalizers...),
+		CreationTimestamp: m.now,
+		provisioningSteps: m.provisioningSteps,
+	}
+
+	m.machineCount++
+	m.machines = append(m.machines, machine)
+
+	return machine
+}
+
+// getMachine returns the named Machine, or nil when it does not exist.
+func (m *MachineProvider) getMachine(name string) *Machine {
+	for _, machine := range m.machines {
+		if machine.Name == name {
+			return machine
+		}
+	}
+
+	return nil
+}
+
+// markDeleted sets the deletion timestamp on the Machine, if it is not already set.
+func (m *MachineProvider) markDeleted(machine *Machine) {
+	if machine.DeletionTimestamp != nil {
+		return
+	}
+
+	now := m.now
+	machine.DeletionTimestamp = &now
+}
+
+// machineInfo builds a MachineInfo for the Machine.
+func (m *MachineProvider) machineInfo(machine *Machine) machineproviders.MachineInfo {
+	objectMeta := metav1.ObjectMeta{
+		Name:              machine.Name,
+		Namespace:         m.namespace,
+		CreationTimestamp: metav1.NewTime(machine.CreationTimestamp),
+		OwnerReferences:   []metav1.OwnerReference{m.ownerReference},
+		Finalizers:        append([]string{}, machine.Finalizers...),
+	}
+
+	if machine.DeletionTimestamp != nil {
+		deletionTimestamp := metav1.NewTime(*machine.DeletionTimestamp)
+		objectMeta.DeletionTimestamp = &deletionTimestamp
+	}
+
+	machineInfo := machineproviders.MachineInfo{
+		MachineRef: &machineproviders.ObjectRef{
+			GroupVersionResource: machinev1beta1.GroupVersion.WithResource("machines"),
+			ObjectMeta:           objectMeta,
+		},
+		Ready:        machine.Phase == PhaseRunning,
+		NeedsUpdate:  machine.TemplateRevision != m.templateRevision,
+		Index:        machine.Index,
+		ErrorMessage: machine.ErrorMessage,
+	}
+
+	if machine.Phase == PhaseRunning {
+		machineInfo.NodeRef = &machineproviders.ObjectRef{
+			GroupVersionResource: corev1.SchemeGroupVersion.WithResource("nodes"),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("%s-node", machine.Name),
+			},
+		}
+	}
+
+	return machineInfo
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+
+	"github.com/go-logr/logr"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+)
+
+var _ = Describe("MachineProvider", func() {
+	var provider *MachineProvider
+	var ctx context.Context
+	var logger logr.Logger
+
+	// getMachineInfo returns the MachineInfo for the named Machine.
+	getMachineInfo := func(name string) machineproviders.MachineInfo {
+		machineInfos, err := provider.GetMachineInfos(ctx, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, machineInfo := range machineInfos {
+			if machineInfo.MachineRef.ObjectMeta.Name == name {
+				return machineInfo
+			}
+		}
+
+		Fail(format.Message(machineInfos, "to contain a machine named", name))
+
+		return machineproviders.MachineInfo{}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		logger = logr.Discard()
+
+		cpms := machinev1resourcebuilder.ControlPlaneMachineSet().WithName("cluster").WithNamespace("openshift-machine-api").Build()
+		provider = NewMachineProvider(cpms)
+	})
+
+	Context("with an existing machine", func() {
+		var machineName string
+
+		BeforeEach(func() {
+			machineName = provider.AddMachine(1)
+		})
+
+		It("reports the machine as ready and up to date", func() {
+			machineInfo := getMachineInfo(machineName)
+
+			Expect(machineInfo.Index).To(Equal(int32(1)))
+			Expect(machineInfo.Ready).To(BeTrue())
+			Expect(machineInfo.NeedsUpdate).To(BeFalse())
+			Expect(machineInfo.NodeRef).ToNot(BeNil())
+			Expect(machineInfo.MachineRef.ObjectMeta.Namespace).To(Equal("openshift-machine-api"))
+			Expect(machineInfo.MachineRef.ObjectMeta.OwnerReferences).To(ConsistOf(
+				HaveField("Name", "cluster"),
+			))
+		})
+
+		It("reports the machine as needing an update when the template revision changes", func() {
+			provider.SetTemplateRevision("2")
+
+			Expect(getMachineInfo(machineName).NeedsUpdate).To(BeTrue())
+		})
+
+		It("removes the machine on the next step once deleted", func() {
+			Expect(provider.DeleteMachineByName(machineName)).To(Succeed())
+			Expect(getMachineInfo(machineName).MachineRef.ObjectMeta.DeletionTimestamp).ToNot(BeNil())
+
+			provider.Step()
+
+			Expect(provider.Machines()).To(BeEmpty())
+		})
+
+		It("returns an error when deleting an unknown machine by name", func() {
+			Expect(provider.DeleteMachineByName("unknown")).To(MatchError("machine not found: unknown"))
+		})
+	})
+
+	Context("when creating a machine", func() {
+		BeforeEach(func() {
+			provider.SetProvisioningSteps(2)
+			provider.SetFinalizers("etcd-quorum-operator")
+
+			Expect(provider.CreateMachine(ctx, logger, 0)).To(Succeed())
+		})
+
+		It("records the action", func() {
+			Expect(provider.Actions()).To(ConsistOf(Action{Type: ActionCreate, Index: 0, Name: "machine-0-0"}))
+		})
+
+		It("becomes ready once the provisioning steps have passed", func() {
+			Expect(getMachineInfo("machine-0-0").Ready).To(BeFalse())
+
+			provider.Step()
+			Expect(getMachineInfo("machine-0-0").Ready).To(BeFalse())
+			Expect(getMachineInfo("machine-0-0").NodeRef).To(BeNil())
+
+			provider.Step()
+			Expect(getMachineInfo("machine-0-0").Ready).To(BeTrue())
+			Expect(getMachineInfo("machine-0-0").NodeRef).ToNot(BeNil())
+		})
+
+		It("is not removed when deleted until its finalizers are removed", func() {
+			machineInfo := getMachineInfo("machine-0-0")
+			Expect(provider.DeleteMachine(ctx, logger, machineInfo.MachineRef)).To(Succeed())
+
+			provider.Step()
+			Expect(provider.Machines()).To(HaveLen(1))
+
+			Expect(provider.RemoveFinalizers("machine-0-0")).To(Succeed())
+
+			provider.Step()
+			Expect(provider.Machines()).To(BeEmpty())
+			Expect(provider.Actions()).To(Equal([]Action{
+				{Type: ActionCreate, Index: 0, Name: "machine-0-0"},
+				{Type: ActionDelete, Index: 0, Name: "machine-0-0"},
+			}))
+		})
+	})
+
+	Context("when updating a machine in place", func() {
+		BeforeEach(func() {
+			provider.AddMachine(0)
+		})
+
+		It("records the action", func() {
+			Expect(provider.UpdateMachineInPlace(ctx, logger, getMachineInfo("machine-0-0").MachineRef)).To(Succeed())
+			Expect(provider.Actions()).To(ConsistOf(Action{Type: ActionUpdateInPlace, Index: 0, Name: "machine-0-0"}))
+		})
+
+		It("returns an error when the machine does not exist", func() {
+			machineRef := getMachineInfo("machine-0-0").MachineRef
+			machineRef.ObjectMeta.Name = "unknown"
+
+			Expect(provider.UpdateMachineInPlace(ctx, logger, machineRef)).To(MatchError("machine not found: unknown"))
+		})
+	})
+
+	Context("when provisioning is set to fail", func() {
+		BeforeEach(func() {
+			provider.FailNextProvisioning(2, "insufficient capacity")
+
+			Expect(provider.CreateMachine(ctx, logger, 2)).To(Succeed())
+			Expect(provider.CreateMachine(ctx, logger, 2)).To(Succeed())
+
+			provider.Step()
+		})
+
+		It("fails the next machine created in the index", func() {
+			Expect(getMachineInfo("machine-2-0").Ready).To(BeFalse())
+			Expect(getMachineInfo("machine-2-0").ErrorMessage).To(Equal("insufficient capacity"))
+		})
+
+		It("does not fail subsequent machines", func() {
+			Expect(getMachineInfo("machine-2-1").Ready).To(BeTrue())
+			Expect(getMachineInfo("machine-2-1").ErrorMessage).To(BeEmpty())
+		})
+	})
+
+	Context("when creation is set to fail", func() {
+		var errCreate = errors.New("quota exceeded")
+
+		BeforeEach(func() {
+			provider.FailNextCreate(1, errCreate)
+		})
+
+		It("returns the error once", func() {
+			Expect(provider.CreateMachine(ctx, logger, 1)).To(MatchError(errCreate))
+			Expect(provider.Machines()).To(BeEmpty())
+
+			Expect(provider.CreateMachine(ctx, logger, 1)).To(Succeed())
+			Expect(provider.Machines()).To(HaveLen(1))
+		})
+	})
+})
@@ -65,6 +65,20 @@ func (mr *MockMachineProviderMockRecorder) DeleteMachine(arg0, arg1, arg2 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMachine", reflect.TypeOf((*MockMachineProvider)(nil).DeleteMachine), arg0, arg1, arg2)
 }
 
+// GetFailureDomainMapping mocks base method.
+func (m *MockMachineProvider) GetFailureDomainMapping() map[int32]machineproviders.FailureDomainMapping {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFailureDomainMapping")
+	ret0, _ := ret[0].(map[int32]machineproviders.FailureDomainMapping)
+	return ret0
+}
+
+// GetFailureDomainMapping indicates an expected call of GetFailureDomainMapping.
+func (mr *MockMachineProviderMockRecorder) GetFailureDomainMapping() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailureDomainMapping", reflect.TypeOf((*MockMachineProvider)(nil).GetFailureDomainMapping))
+}
+
 // GetMachineInfos mocks base method.
 func (m *MockMachineProvider) GetMachineInfos(arg0 context.Context, arg1 logr.Logger) ([]machineproviders.MachineInfo, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +94,20 @@ func (mr *MockMachineProviderMockRecorder) GetMachineInfos(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMachineInfos", reflect.TypeOf((*MockMachineProvider)(nil).GetMachineInfos), arg0, arg1)
 }
 
+// UpdateMachineInPlace mocks base method.
+func (m *MockMachineProvider) UpdateMachineInPlace(arg0 context.Context, arg1 logr.Logger, arg2 *machineproviders.ObjectRef) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMachineInPlace", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMachineInPlace indicates an expected call of UpdateMachineInPlace.
+func (mr *MockMachineProviderMockRecorder) UpdateMachineInPlace(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMachineInPlace", reflect.TypeOf((*MockMachineProvider)(nil).UpdateMachineInPlace), arg0, arg1, arg2)
+}
+
 // WithClient mocks base method.
 func (m *MockMachineProvider) WithClient(arg0 client.Client) machineproviders.MachineProvider {
 	m.ctrl.T.Helper()
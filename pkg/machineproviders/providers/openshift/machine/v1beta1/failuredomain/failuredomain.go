@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
@@ -59,6 +60,15 @@ type FailureDomain interface {
 	// GCP returns the GCPFailureDomain if the platform type is GCP.
 	GCP() machinev1.GCPFailureDomain
 
+	// OpenStack returns the OpenStackFailureDomain if the platform type is OpenStack.
+	OpenStack() OpenStackFailureDomain
+
+	// VSphere returns the VSphereFailureDomain if the platform type is VSphere.
+	VSphere() VSphereFailureDomain
+
+	// Nutanix returns the NutanixFailureDomain if the platform type is Nutanix.
+	Nutanix() NutanixFailureDomain
+
 	// Equal compares the underlying failure domain.
 	Equal(other FailureDomain) bool
 }
@@ -67,9 +77,12 @@ type FailureDomain interface {
 type failureDomain struct {
 	platformType configv1.PlatformType
 
-	aws   machinev1.AWSFailureDomain
-	azure machinev1.AzureFailureDomain
-	gcp   machinev1.GCPFailureDomain
+	aws       machinev1.AWSFailureDomain
+	azure     machinev1.AzureFailureDomain
+	gcp       machinev1.GCPFailureDomain
+	openstack OpenStackFailureDomain
+	vsphere   VSphereFailureDomain
+	nutanix   NutanixFailureDomain
 }
 
 // String returns a string representation of the failure domain.
@@ -81,6 +94,12 @@ func (f failureDomain) String() string {
 		return azureFailureDomainToString(f.azure)
 	case configv1.GCPPlatformType:
 		return gcpFailureDomainToString(f.gcp)
+	case configv1.OpenStackPlatformType:
+		return openStackFailureDomainToString(f.openstack)
+	case configv1.VSpherePlatformType:
+		return vSphereFailureDomainToString(f.vsphere)
+	case configv1.NutanixPlatformType:
+		return nutanixFailureDomainToString(f.nutanix)
 	default:
 		return fmt.Sprintf("%sFailureDomain{}", f.platformType)
 	}
@@ -106,6 +125,21 @@ func (f failureDomain) GCP() machinev1.GCPFailureDomain {
 	return f.gcp
 }
 
+// OpenStack returns the OpenStackFailureDomain if the platform type is OpenStack.
+func (f failureDomain) OpenStack() OpenStackFailureDomain {
+	return f.openstack
+}
+
+// VSphere returns the VSphereFailureDomain if the platform type is VSphere.
+func (f failureDomain) VSphere() VSphereFailureDomain {
+	return f.vsphere
+}
+
+// Nutanix returns the NutanixFailureDomain if the platform type is Nutanix.
+func (f failureDomain) Nutanix() NutanixFailureDomain {
+	return f.nutanix
+}
+
 // Equal compares the underlying failure domain.
 func (f failureDomain) Equal(other FailureDomain) bool {
 	if other == nil {
@@ -123,6 +157,12 @@ func (f failureDomain) Equal(other FailureDomain) bool {
 		return f.azure == other.Azure()
 	case configv1.GCPPlatformType:
 		return f.gcp == other.GCP()
+	case configv1.OpenStackPlatformType:
+		return reflect.DeepEqual(f.OpenStack(), other.OpenStack())
+	case configv1.VSpherePlatformType:
+		return f.vsphere == other.VSphere()
+	case configv1.NutanixPlatformType:
+		return reflect.DeepEqual(f.Nutanix(), other.Nutanix())
 	}
 
 	return true
@@ -214,6 +254,63 @@ func NewGCPFailureDomain(fd machinev1.GCPFailureDomain) FailureDomain {
 	}
 }
 
+// NewOpenStackFailureDomains creates a set of OpenStack FailureDomains from the OpenStackFailureDomains.
+func NewOpenStackFailureDomains(failureDomains []OpenStackFailureDomain) []FailureDomain {
+	foundFailureDomains := []FailureDomain{}
+
+	for _, failureDomain := range failureDomains {
+		foundFailureDomains = append(foundFailureDomains, NewOpenStackFailureDomain(failureDomain))
+	}
+
+	return foundFailureDomains
+}
+
+// NewOpenStackFailureDomain creates an OpenStack failure domain from the OpenStackFailureDomain.
+func NewOpenStackFailureDomain(fd OpenStackFailureDomain) FailureDomain {
+	return &failureDomain{
+		platformType: configv1.OpenStackPlatformType,
+		openstack:    fd,
+	}
+}
+
+// NewVSphereFailureDomains creates a set of VSphere FailureDomains from the VSphereFailureDomains.
+func NewVSphereFailureDomains(failureDomains []VSphereFailureDomain) []FailureDomain {
+	foundFailureDomains := []FailureDomain{}
+
+	for _, failureDomain := range failureDomains {
+		foundFailureDomains = append(foundFailureDomains, NewVSphereFailureDomain(failureDomain))
+	}
+
+	return foundFailureDomains
+}
+
+// NewVSphereFailureDomain creates a VSphere failure domain from the VSphereFailureDomain.
+func NewVSphereFailureDomain(fd VSphereFailureDomain) FailureDomain {
+	return &failureDomain{
+		platformType: configv1.VSpherePlatformType,
+		vsphere:      fd,
+	}
+}
+
+// NewNutanixFailureDomains creates a set of Nutanix FailureDomains from the NutanixFailureDomains.
+func NewNutanixFailureDomains(failureDomains []NutanixFailureDomain) []FailureDomain {
+	foundFailureDomains := []FailureDomain{}
+
+	for _, failureDomain := range failureDomains {
+		foundFailureDomains = append(foundFailureDomains, NewNutanixFailureDomain(failureDomain))
+	}
+
+	return foundFailureDomains
+}
+
+// NewNutanixFailureDomain creates a Nutanix failure domain from the NutanixFailureDomain.
+func NewNutanixFailureDomain(fd NutanixFailureDomain) FailureDomain {
+	return &failureDomain{
+		platformType: configv1.NutanixPlatformType,
+		nutanix:      fd,
+	}
+}
+
 // NewGenericFailureDomain creates a dummy failure domain for generic platforms that don't support failure domains.
 func NewGenericFailureDomain() FailureDomain {
 	return failureDomain{}
@@ -276,3 +373,68 @@ func gcpFailureDomainToString(fd machinev1.GCPFailureDomain) string {
 
 	return unknownFailureDomain
 }
+
+// openStackFailureDomainToString converts the OpenStackFailureDomain into a string.
+// Empty values are omitted.
+func openStackFailureDomainToString(fd OpenStackFailureDomain) string {
+	fields := []string{}
+
+	if fd.AvailabilityZone != "" {
+		fields = append(fields, fmt.Sprintf("AvailabilityZone:%s", fd.AvailabilityZone))
+	}
+
+	if fd.RootVolume != nil && fd.RootVolume.AvailabilityZone != "" {
+		fields = append(fields, fmt.Sprintf("RootVolume:{AvailabilityZone:%s}", fd.RootVolume.AvailabilityZone))
+	}
+
+	return fmt.Sprintf("OpenStackFailureDomain{%s}", strings.Join(fields, ", "))
+}
+
+// vSphereFailureDomainToString converts the VSphereFailureDomain into a string.
+// If the failure domain has no name, the failure domain is unknown.
+func vSphereFailureDomainToString(fd VSphereFailureDomain) string {
+	if fd.Name != "" {
+		return fmt.Sprintf("VSphereFailureDomain{Name:%s}", fd.Name)
+	}
+
+	return unknownFailureDomain
+}
+
+// nutanixFailureDomainToString converts the NutanixFailureDomain into a string.
+// If the failure domain has no cluster, the failure domain is unknown.
+func nutanixFailureDomainToString(fd NutanixFailureDomain) string {
+	if fd.Cluster.Type == "" {
+		return unknownFailureDomain
+	}
+
+	fields := []string{fmt.Sprintf("Cluster:%s", nutanixResourceIdentifierToString(fd.Cluster))}
+
+	if len(fd.Subnets) > 0 {
+		subnets := []string{}
+		for _, subnet := range fd.Subnets {
+			subnets = append(subnets, nutanixResourceIdentifierToString(subnet))
+		}
+
+		fields = append(fields, fmt.Sprintf("Subnets:[%s]", strings.Join(subnets, ", ")))
+	}
+
+	return fmt.Sprintf("NutanixFailureDomain{%s}", strings.Join(fields, ", "))
+}
+
+// nutanixResourceIdentifierToString converts the NutanixResourceIdentifier into a string.
+func nutanixResourceIdentifierToString(id machinev1.NutanixResourceIdentifier) string {
+	value := ""
+
+	switch id.Type {
+	case machinev1.NutanixIdentifierName:
+		if id.Name != nil {
+			value = *id.Name
+		}
+	case machinev1.NutanixIdentifierUUID:
+		if id.UUID != nil {
+			value = *id.UUID
+		}
+	}
+
+	return fmt.Sprintf("{Type:%s, Value:%s}", id.Type, value)
+}
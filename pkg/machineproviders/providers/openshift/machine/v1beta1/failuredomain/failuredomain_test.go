@@ -23,6 +23,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	"k8s.io/utils/pointer"
 )
 
 var _ = Describe("FailureDomains", func() {
@@ -296,6 +297,140 @@ var _ = Describe("FailureDomains", func() {
 		})
 	})
 
+	Context("an OpenStack failure domain", func() {
+		var fd failureDomain
+
+		BeforeEach(func() {
+			fd = failureDomain{
+				platformType: configv1.OpenStackPlatformType,
+			}
+		})
+
+		Context("with an availability zone", func() {
+			BeforeEach(func() {
+				fd.openstack = OpenStackFailureDomain{AvailabilityZone: "az1"}
+			})
+
+			It("returns the availability zone for String()", func() {
+				Expect(fd.String()).To(Equal("OpenStackFailureDomain{AvailabilityZone:az1}"))
+			})
+		})
+
+		Context("with an availability zone and a root volume availability zone", func() {
+			BeforeEach(func() {
+				fd.openstack = OpenStackFailureDomain{
+					AvailabilityZone: "az1",
+					RootVolume: &OpenStackFailureDomainRootVolume{
+						AvailabilityZone: "volume-az1",
+					},
+				}
+			})
+
+			It("returns both availability zones for String()", func() {
+				Expect(fd.String()).To(Equal("OpenStackFailureDomain{AvailabilityZone:az1, RootVolume:{AvailabilityZone:volume-az1}}"))
+			})
+		})
+	})
+
+	Context("NewOpenStackFailureDomains", func() {
+		It("should construct a list of failure domains", func() {
+			failureDomains := NewOpenStackFailureDomains([]OpenStackFailureDomain{
+				{AvailabilityZone: "az1"},
+				{AvailabilityZone: "az2"},
+			})
+
+			Expect(failureDomains).To(ConsistOf(
+				HaveField("String()", "OpenStackFailureDomain{AvailabilityZone:az1}"),
+				HaveField("String()", "OpenStackFailureDomain{AvailabilityZone:az2}"),
+			))
+		})
+	})
+
+	Context("a Nutanix failure domain", func() {
+		var fd failureDomain
+
+		BeforeEach(func() {
+			fd = failureDomain{
+				platformType: configv1.NutanixPlatformType,
+			}
+		})
+
+		Context("with a cluster and subnets", func() {
+			BeforeEach(func() {
+				fd.nutanix = NutanixFailureDomain{
+					Cluster: machinev1.NutanixResourceIdentifier{Type: machinev1.NutanixIdentifierName, Name: pointer.String("pe-1")},
+					Subnets: []machinev1.NutanixResourceIdentifier{
+						{Type: machinev1.NutanixIdentifierUUID, UUID: pointer.String("subnet-uuid-1")},
+					},
+				}
+			})
+
+			It("returns the cluster and subnets for String()", func() {
+				Expect(fd.String()).To(Equal("NutanixFailureDomain{Cluster:{Type:name, Value:pe-1}, Subnets:[{Type:uuid, Value:subnet-uuid-1}]}"))
+			})
+		})
+
+		Context("with no cluster", func() {
+			It("returns <unknown> for String()", func() {
+				Expect(fd.String()).To(Equal("<unknown>"))
+			})
+		})
+	})
+
+	Context("NewNutanixFailureDomains", func() {
+		It("should construct a list of failure domains", func() {
+			failureDomains := NewNutanixFailureDomains([]NutanixFailureDomain{
+				{Cluster: machinev1.NutanixResourceIdentifier{Type: machinev1.NutanixIdentifierName, Name: pointer.String("pe-1")}},
+				{Cluster: machinev1.NutanixResourceIdentifier{Type: machinev1.NutanixIdentifierName, Name: pointer.String("pe-2")}},
+			})
+
+			Expect(failureDomains).To(ConsistOf(
+				HaveField("String()", "NutanixFailureDomain{Cluster:{Type:name, Value:pe-1}}"),
+				HaveField("String()", "NutanixFailureDomain{Cluster:{Type:name, Value:pe-2}}"),
+			))
+		})
+	})
+
+	Context("a VSphere failure domain", func() {
+		var fd failureDomain
+
+		BeforeEach(func() {
+			fd = failureDomain{
+				platformType: configv1.VSpherePlatformType,
+			}
+		})
+
+		Context("with a name", func() {
+			BeforeEach(func() {
+				fd.vsphere = VSphereFailureDomain{Name: "fd-1"}
+			})
+
+			It("returns the name for String()", func() {
+				Expect(fd.String()).To(Equal("VSphereFailureDomain{Name:fd-1}"))
+			})
+		})
+
+		Context("without a name", func() {
+			It("returns <unknown> for String()", func() {
+				Expect(fd.String()).To(Equal("<unknown>"))
+			})
+		})
+	})
+
+	Context("NewVSphereFailureDomains", func() {
+		It("should construct a list of failure domains", func() {
+			failureDomains := NewVSphereFailureDomains([]VSphereFailureDomain{
+				{Name: "fd-1"},
+				{Name: "fd-2"},
+			})
+
+			Expect(failureDomains).To(ConsistOf(
+				HaveField("String()", "VSphereFailureDomain{Name:fd-1}"),
+				HaveField("String()", "VSphereFailureDomain{Name:fd-2}"),
+			))
+		})
+	})
+
 	Context("Equal", func() {
 		var fd1 failureDomain
 		var fd2 failureDomain
@@ -415,6 +550,117 @@ var _ = Describe("FailureDomains", func() {
 			})
 		})
 
+		Context("With two identical OpenStack failure domains", func() {
+			BeforeEach(func() {
+				fd1 = failureDomain{
+					platformType: configv1.OpenStackPlatformType,
+					openstack: OpenStackFailureDomain{
+						AvailabilityZone: "az1",
+						RootVolume:       &OpenStackFailureDomainRootVolume{AvailabilityZone: "volume-az1"},
+					},
+				}
+				fd2 = failureDomain{
+					platformType: configv1.OpenStackPlatformType,
+					openstack: OpenStackFailureDomain{
+						AvailabilityZone: "az1",
+						RootVolume:       &OpenStackFailureDomainRootVolume{AvailabilityZone: "volume-az1"},
+					},
+				}
+			})
+
+			It("returns true", func() {
+				Expect(fd1.Equal(fd2)).To(BeTrue())
+			})
+		})
+
+		Context("With two OpenStack failure domains with different root volumes", func() {
+			BeforeEach(func() {
+				fd1 = failureDomain{
+					platformType: configv1.OpenStackPlatformType,
+					openstack: OpenStackFailureDomain{
+						AvailabilityZone: "az1",
+						RootVolume:       &OpenStackFailureDomainRootVolume{AvailabilityZone: "volume-az1"},
+					},
+				}
+				fd2 = failureDomain{
+					platformType: configv1.OpenStackPlatformType,
+					openstack: OpenStackFailureDomain{
+						AvailabilityZone: "az1",
+					},
+				}
+			})
+
+			It("returns false", func() {
+				Expect(fd1.Equal(fd2)).To(BeFalse())
+			})
+		})
+
+		Context("With two identical Nutanix failure domains", func() {
+			BeforeEach(func() {
+				fd1 = failureDomain{
+					platformType: configv1.NutanixPlatformType,
+					nutanix: NutanixFailureDomain{
+						Cluster: machinev1.NutanixResourceIdentifier{Type: machinev1.NutanixIdentifierName, Name: pointer.String("pe-1")},
+						Subnets: []machinev1.NutanixResourceIdentifier{{Type: machinev1.NutanixIdentifierName, Name: pointer.String("subnet-1")}},
+					},
+				}
+				fd2 = failureDomain{
+					platformType: configv1.NutanixPlatformType,
+					nutanix: NutanixFailureDomain{
+						Cluster: machinev1.NutanixResourceIdentifier{Type: machinev1.NutanixIdentifierName, Name: pointer.String("pe-1")},
+						Subnets: []machinev1.NutanixResourceIdentifier{{Type: machinev1.NutanixIdentifierName, Name: pointer.String("subnet-1")}},
+					},
+				}
+			})
+
+			It("returns true", func() {
+				Expect(fd1.Equal(fd2)).To(BeTrue())
+			})
+		})
+
+		Context("With two Nutanix failure domains with different subnets", func() {
+			BeforeEach(func() {
+				fd1 = failureDomain{
+					platformType: configv1.NutanixPlatformType,
+					nutanix: NutanixFailureDomain{
+						Cluster: machinev1.NutanixResourceIdentifier{Type: machinev1.NutanixIdentifierName, Name: pointer.String("pe-1")},
+						Subnets: []machinev1.NutanixResourceIdentifier{{Type: machinev1.NutanixIdentifierName, Name: pointer.String("subnet-1")}},
+					},
+				}
+				fd2 = failureDomain{
+					platformType: configv1.NutanixPlatformType,
+					nutanix: NutanixFailureDomain{
+						Cluster: machinev1.NutanixResourceIdentifier{Type: machinev1.NutanixIdentifierName, Name: pointer.String("pe-1")},
+						Subnets: []machinev1.NutanixResourceIdentifier{{Type: machinev1.NutanixIdentifierName, Name: pointer.String("subnet-2")}},
+					},
+				}
+			})
+
+			It("returns false", func() {
+				Expect(fd1.Equal(fd2)).To(BeFalse())
+			})
+		})
+
+		Context("With two VSphere failure domains with different names", func() {
+			BeforeEach(func() {
+				fd1 = failureDomain{
+					platformType: configv1.VSpherePlatformType,
+					vsphere:      VSphereFailureDomain{Name: "fd-1"},
+				}
+				fd2 = failureDomain{
+					platformType: configv1.VSpherePlatformType,
+					vsphere:      VSphereFailureDomain{Name: "fd-2"},
+				}
+			})
+
+			It("returns false", func() {
+				Expect(fd1.Equal(fd2)).To(BeFalse())
+			})
+
+			It("returns true when compared with itself", func() {
+				Expect(fd1.Equal(fd1)).To(BeTrue())
+			})
+		})
 	})
 
 })
@@ -0,0 +1,33 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failuredomain
+
+import (
+	machinev1 "github.com/openshift/api/machine/v1"
+)
+
+// NutanixFailureDomain configures failure domain information for the Nutanix platform.
+// The ControlPlaneMachineSet API does not yet support Nutanix failure domains, so they are configured
+// by annotation on the ControlPlaneMachineSet. This type matches the format of that annotation.
+type NutanixFailureDomain struct {
+	// Cluster identifies the Prism Element (cluster) in which the VM is created.
+	// The Prism Element must be defined within the Nutanix platform spec of the cluster Infrastructure.
+	Cluster machinev1.NutanixResourceIdentifier `json:"cluster"`
+
+	// Subnets identifies the subnets, within the Prism Element, to which the VM is attached.
+	Subnets []machinev1.NutanixResourceIdentifier `json:"subnets,omitempty"`
+}
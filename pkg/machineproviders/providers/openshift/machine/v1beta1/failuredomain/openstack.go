@@ -0,0 +1,35 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failuredomain
+
+// OpenStackFailureDomain configures failure domain information for the OpenStack platform.
+// The ControlPlaneMachineSet API does not yet support OpenStack failure domains, so they are configured
+// by annotation on the ControlPlaneMachineSet. This type matches the format of that annotation.
+type OpenStackFailureDomain struct {
+	// AvailabilityZone is the name of the Nova availability zone in which the instance is created.
+	AvailabilityZone string `json:"availabilityZone,omitempty"`
+
+	// RootVolume configures the failure domain information for the root volume of the instance.
+	RootVolume *OpenStackFailureDomainRootVolume `json:"rootVolume,omitempty"`
+}
+
+// OpenStackFailureDomainRootVolume configures the failure domain information for the root volume of an
+// OpenStack instance.
+type OpenStackFailureDomainRootVolume struct {
+	// AvailabilityZone is the name of the Cinder availability zone in which the root volume is created.
+	AvailabilityZone string `json:"availabilityZone"`
+}
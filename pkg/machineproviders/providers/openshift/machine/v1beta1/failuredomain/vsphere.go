@@ -0,0 +1,26 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failuredomain
+
+// VSphereFailureDomain configures failure domain information for the vSphere platform.
+// The ControlPlaneMachineSet API does not yet support vSphere failure domains, so they are configured
+// by annotation on the ControlPlaneMachineSet. This type matches the format of that annotation.
+type VSphereFailureDomain struct {
+	// Name is the name of a failure domain defined within the vSphere platform spec of the cluster Infrastructure.
+	// The topology of the failure domain is read from the Infrastructure.
+	Name string `json:"name"`
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getInPlaceDiff describes each field of the Machine that differs from the Machine template and that can be
+// updated without replacing the Machine. These are the taints.
+// The labels and annotations of the Machine are kept in line with the template by the ControlPlaneMachineSet
+// controller, independently of the machine provider.
+func (m *openshiftMachineProvider) getInPlaceDiff(machine machinev1beta1.Machine) ([]machineproviders.FieldDiff, error) {
+	if taintsEqual(machine.Spec.Taints, m.machineTemplate.Spec.Taints) {
+		return nil, nil
+	}
+
+	fieldDiff := machineproviders.FieldDiff{
+		Path: field.NewPath("spec", "taints").String(),
+	}
+
+	var err error
+
+	if len(machine.Spec.Taints) > 0 {
+		if fieldDiff.Old, err = encodeInPlaceValue(machine.Spec.Taints); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(m.machineTemplate.Spec.Taints) > 0 {
+		if fieldDiff.New, err = encodeInPlaceValue(m.machineTemplate.Spec.Taints); err != nil {
+			return nil, err
+		}
+	}
+
+	return []machineproviders.FieldDiff{fieldDiff}, nil
+}
+
+// encodeInPlaceValue JSON encodes the value of a field that can be updated in place.
+func encodeInPlaceValue(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("could not encode field value: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// taintsEqual checks whether the two lists contain the same taints, in any order.
+// Taints are compared by their key, value and effect.
+func taintsEqual(a, b []corev1.Taint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, taintA := range a {
+		found := false
+
+		for _, taintB := range b {
+			if taintA.Key == taintB.Key && taintA.Value == taintB.Value && taintA.Effect == taintB.Effect {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UpdateMachineInPlace updates the taints of the Machine referenced by the machineRef provided,
+// so that they match the Machine template.
+func (m *openshiftMachineProvider) UpdateMachineInPlace(ctx context.Context, logger logr.Logger, machineRef *machineproviders.ObjectRef) error {
+	machinesGVR := machinev1beta1.GroupVersion.WithResource("machines")
+
+	if machineRef.GroupVersionResource != machinesGVR {
+		logger.Error(errUnknownGroupVersionResource,
+			"Could not update machine in place",
+			"expectedGVR", machinesGVR.String(),
+			"gotGVR", machineRef.GroupVersionResource.String(),
+		)
+
+		return fmt.Errorf("%w: expected %s, got %s", errUnknownGroupVersionResource, machinesGVR.String(), machineRef.GroupVersionResource.String())
+	}
+
+	machine := &machinev1beta1.Machine{}
+	if err := m.client.Get(ctx, client.ObjectKey{Namespace: machineRef.ObjectMeta.Namespace, Name: machineRef.ObjectMeta.Name}, machine); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.V(2).Info(
+				"Machine not found",
+				"namespace", machineRef.ObjectMeta.Namespace,
+				"machineName", machineRef.ObjectMeta.Name,
+				"group", machinev1beta1.GroupVersion.Group,
+				"version", machinev1beta1.GroupVersion.Version,
+			)
+
+			return nil
+		}
+
+		return fmt.Errorf("could not get machine %s in namespace %s: %w", machineRef.ObjectMeta.Name, machineRef.ObjectMeta.Namespace, err)
+	}
+
+	inPlaceDiff, err := m.getInPlaceDiff(*machine)
+	if err != nil {
+		return fmt.Errorf("could not compare machine with template: %w", err)
+	}
+
+	if len(inPlaceDiff) == 0 {
+		return nil
+	}
+
+	patchBase := client.MergeFrom(machine.DeepCopy())
+
+	machine.Spec.Taints = nil
+	for _, taint := range m.machineTemplate.Spec.Taints {
+		machine.Spec.Taints = append(machine.Spec.Taints, *taint.DeepCopy())
+	}
+
+	if err := m.client.Patch(ctx, machine, patchBase); err != nil {
+		logger.Error(err,
+			"Could not update machine in place",
+			"namespace", machineRef.ObjectMeta.Namespace,
+			"machineName", machineRef.ObjectMeta.Name,
+			"group", machinev1beta1.GroupVersion.Group,
+			"version", machinev1beta1.GroupVersion.Version,
+		)
+
+		return fmt.Errorf("could not update machine %s in namespace %s: %w", machineRef.ObjectMeta.Name, machineRef.ObjectMeta.Namespace, err)
+	}
+
+	logger.V(2).Info(
+		"Updated machine in place",
+		"namespace", machineRef.ObjectMeta.Namespace,
+		"machineName", machineRef.ObjectMeta.Name,
+		"group", machinev1beta1.GroupVersion.Group,
+		"version", machinev1beta1.GroupVersion.Version,
+		"diff", inPlaceDiff,
+	)
+
+	return nil
+}
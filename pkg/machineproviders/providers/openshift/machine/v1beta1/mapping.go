@@ -26,11 +26,14 @@ import (
 	"strings"
 
 	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
 
@@ -45,48 +48,186 @@ var (
 	// errNoFailureDomains is used to indicate that no failure domain mapping is required in the
 	// provider because no failure domains are configured on the ControlPlaneMachineSet.
 	errNoFailureDomains = errors.New("no failure domains configured")
+
+	// errNoAvailableFailureDomains is used to indicate that every failure domain configured on the
+	// ControlPlaneMachineSet has been marked as unavailable.
+	errNoAvailableFailureDomains = errors.New("every configured failure domain is marked as unavailable")
 )
 
 // mapMachineIndexesToFailureDomains creates a mapping of the given failure domains into an index that can be used
 // to by external code to create new Machines in the same failure domain. It should start with a basic mapping and
 // then use existing Machine information to map failure domains, if possible, so that the Machine names match the
 // index of the failure domain in which they currently reside.
-func mapMachineIndexesToFailureDomains(ctx context.Context, logger logr.Logger, cl client.Client, cpms *machinev1.ControlPlaneMachineSet, failureDomains []failuredomain.FailureDomain) (map[int32]failuredomain.FailureDomain, error) {
+// The infrastructure is used to extract failure domains from Machines on platforms that require it, and may be nil otherwise.
+// Alongside the mapping, the reason each index was mapped to its failure domain is returned.
+func mapMachineIndexesToFailureDomains(ctx context.Context, logger logr.Logger, cl client.Client, cpms *machinev1.ControlPlaneMachineSet, infrastructure *configv1.Infrastructure, failureDomains []failuredomain.FailureDomain) (map[int32]failuredomain.FailureDomain, map[int32]machineproviders.FailureDomainMappingReason, error) {
 	if len(failureDomains) == 0 {
 		logger.V(4).Info("No failure domains provided")
 
-		return nil, errNoFailureDomains
+		return nil, nil, errNoFailureDomains
 	}
 
-	machineMapping, deletingIndexes, err := createMachineMapping(ctx, logger, cl, cpms)
+	machineMapping, deletingIndexes, err := createMachineMapping(ctx, logger, cl, cpms, infrastructure)
 	if err != nil {
-		return nil, fmt.Errorf("could not construct machine mapping: %w", err)
+		return nil, nil, fmt.Errorf("could not construct machine mapping: %w", err)
 	}
 
 	failureDomainsSet := failuredomain.NewSet(failureDomains...)
 
-	baseMapping, err := createBaseFailureDomainMapping(cpms, failureDomainsSet.List(), len(machineMapping))
+	weights, err := newFailureDomainWeights(logger, cpms, failureDomainsSet.List())
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not construct failure domain weights: %w", err)
+	}
+
+	baseMapping, err := createBaseFailureDomainMapping(cpms, failureDomainsSet.List(), weights, len(machineMapping))
 	if err != nil {
-		return nil, fmt.Errorf("could not construct base failure domain mapping: %w", err)
+		return nil, nil, fmt.Errorf("could not construct base failure domain mapping: %w", err)
 	}
 
-	out := reconcileMappings(logger, baseMapping, machineMapping, deletingIndexes)
+	out := reconcileMappings(logger, baseMapping, weights, machineMapping, deletingIndexes)
+	reasons := mappingReasons(out, machineMapping)
 
 	logger.V(4).Info(
 		"Mapped provided failure domains",
 		"mapping", fmt.Sprintf("%v", out),
 	)
 
+	return out, reasons, nil
+}
+
+// mappingReasons determines why each index in the reconciled mapping was mapped to its failure domain.
+// The reconciled mapping starts from the machine mapping, and an index is only ever moved to a different
+// failure domain when no Machine exists within it, or when the failure domain of the Machine is no longer
+// configured or is over represented. Comparing the two mappings is therefore enough to determine the reason.
+func mappingReasons(mapping, machines map[int32]failuredomain.FailureDomain) map[int32]machineproviders.FailureDomainMappingReason {
+	out := make(map[int32]machineproviders.FailureDomainMappingReason, len(mapping))
+
+	for idx, failureDomain := range mapping {
+		machineFailureDomain, ok := machines[idx]
+
+		switch {
+		case !ok:
+			out[idx] = machineproviders.FailureDomainMappingReasonBaseMapping
+		case machineFailureDomain.Equal(failureDomain):
+			out[idx] = machineproviders.FailureDomainMappingReasonExistingMachine
+		default:
+			out[idx] = machineproviders.FailureDomainMappingReasonRebalanced
+		}
+	}
+
+	return out
+}
+
+// failureDomainWeights holds the weight of each failure domain, keyed by the string representation of the
+// failure domain. The weights determine the share of the indexes mapped to each failure domain.
+// Failure domains without an entry have the default weight, and failure domains with a weight of zero
+// are not mapped to any index. A nil failureDomainWeights weights every failure domain equally.
+type failureDomainWeights map[string]int
+
+// newFailureDomainWeights constructs the failure domain weights from the annotations on the ControlPlaneMachineSet.
+// Failure domains marked as unavailable always have a weight of zero, so that any index within them is evacuated.
+// Failure domains marked to be avoided have a weight of zero, unless every available failure domain is marked to be
+// avoided, in which case the avoidance is ignored so that the indexes can still be mapped.
+// Weights configured for failure domains that are not in the list of failure domains are ignored.
+func newFailureDomainWeights(logger logr.Logger, cpms *machinev1.ControlPlaneMachineSet, failureDomains []failuredomain.FailureDomain) (failureDomainWeights, error) {
+	configuredWeights, err := annotations.FailureDomainWeights(cpms)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse failure domain weights: %w", err)
+	}
+
+	configuredUnavailable, err := annotations.UnavailableFailureDomains(cpms)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse unavailable failure domains: %w", err)
+	}
+
+	out := make(failureDomainWeights)
+
+	known := sets.New[string]()
+	for _, failureDomain := range failureDomains {
+		known.Insert(failureDomain.String())
+	}
+
+	avoided := sets.New[string]()
+
+	for _, configured := range configuredWeights {
+		if !known.Has(configured.FailureDomain) {
+			logger.V(2).Info(
+				"Ignoring weight for unknown failure domain",
+				"failureDomain", configured.FailureDomain,
+			)
+
+			continue
+		}
+
+		weight := annotations.DefaultFailureDomainWeight
+		if configured.Weight > 0 {
+			weight = int(configured.Weight)
+		}
+
+		out[configured.FailureDomain] = weight
+
+		if configured.Avoid {
+			avoided.Insert(configured.FailureDomain)
+		}
+	}
+
+	unavailable := sets.New[string]()
+
+	for _, failureDomain := range configuredUnavailable {
+		if !known.Has(failureDomain) {
+			logger.V(2).Info(
+				"Ignoring unknown unavailable failure domain",
+				"failureDomain", failureDomain,
+			)
+
+			continue
+		}
+
+		unavailable.Insert(failureDomain)
+	}
+
+	if known.Len() > 0 && unavailable.Len() == known.Len() {
+		return nil, errNoAvailableFailureDomains
+	}
+
+	for _, failureDomain := range unavailable.UnsortedList() {
+		out[failureDomain] = 0
+	}
+
+	if avoided.Union(unavailable).Len() == known.Len() {
+		if avoided.Len() > 0 {
+			logger.V(2).Info("Every available failure domain is marked to be avoided, ignoring avoidance")
+		}
+
+		return out, nil
+	}
+
+	for _, failureDomain := range avoided.UnsortedList() {
+		out[failureDomain] = 0
+	}
+
 	return out, nil
 }
 
+// weight returns the weight of the failure domain.
+func (w failureDomainWeights) weight(failureDomain failuredomain.FailureDomain) int {
+	if weight, ok := w[failureDomain.String()]; ok {
+		return weight
+	}
+
+	return annotations.DefaultFailureDomainWeight
+}
+
 // createBaseFailureDomainMapping is used to create the basic failure domain mapping based on the number of failure
 // domains provided and the number of replicas within the ControlPlaneMachineSet or control plane Machine indexes.
 // To ensure consistency, we expect the function to create a stable output no matter the order of the input failure
 // domains.
 // Create the output based on the longer of the number of Machines or replicas so that when we reconcile the machine
 // mappings we always have enough candidates which are balanced between the available failure domains.
-func createBaseFailureDomainMapping(cpms *machinev1.ControlPlaneMachineSet, failureDomains []failuredomain.FailureDomain, machineIndexCount int) (map[int32]failuredomain.FailureDomain, error) {
+// The indexes are spread across the failure domains in proportion to their weights, using a smooth weighted round
+// robin so that failure domains are interleaved. When all weights are equal, this is a plain round robin over the
+// sorted failure domains.
+func createBaseFailureDomainMapping(cpms *machinev1.ControlPlaneMachineSet, failureDomains []failuredomain.FailureDomain, weights failureDomainWeights, machineIndexCount int) (map[int32]failuredomain.FailureDomain, error) {
 	out := make(map[int32]failuredomain.FailureDomain)
 
 	if cpms.Spec.Replicas == nil || *cpms.Spec.Replicas < 1 {
@@ -99,15 +240,39 @@ func createBaseFailureDomainMapping(cpms *machinev1.ControlPlaneMachineSet, fail
 		machineIndexCount = int(*cpms.Spec.Replicas)
 	}
 
-	if len(failureDomains) == 0 {
+	// Sort failure domains alphabetically
+	sort.Slice(failureDomains, func(i, j int) bool { return failureDomains[i].String() < failureDomains[j].String() })
+
+	// Failure domains with no weight are never mapped.
+	weightedFailureDomains := []failuredomain.FailureDomain{}
+	totalWeight := 0
+
+	for _, failureDomain := range failureDomains {
+		if weight := weights.weight(failureDomain); weight > 0 {
+			weightedFailureDomains = append(weightedFailureDomains, failureDomain)
+			totalWeight += weight
+		}
+	}
+
+	if len(weightedFailureDomains) == 0 {
 		return nil, errNoFailureDomains
 	}
 
-	// Sort failure domains alphabetically
-	sort.Slice(failureDomains, func(i, j int) bool { return failureDomains[i].String() < failureDomains[j].String() })
+	current := make([]int, len(weightedFailureDomains))
 
 	for i := int32(0); i < int32(machineIndexCount); i++ {
-		out[i] = failureDomains[i%int32(len(failureDomains))]
+		selected := 0
+
+		for j, failureDomain := range weightedFailureDomains {
+			current[j] += weights.weight(failureDomain)
+
+			if current[j] > current[selected] {
+				selected = j
+			}
+		}
+
+		current[selected] -= totalWeight
+		out[i] = weightedFailureDomains[selected]
 	}
 
 	return out, nil
@@ -116,7 +281,7 @@ func createBaseFailureDomainMapping(cpms *machinev1.ControlPlaneMachineSet, fail
 // createMachineMapping inspects the state of the Machines on the cluster, selected by the ControlPlaneMachineSet, and
 // creates a mapping of their indexes (if available) to their failure domain to allow the mapping to be customised
 // to the state of the cluster.
-func createMachineMapping(ctx context.Context, logger logr.Logger, cl client.Client, cpms *machinev1.ControlPlaneMachineSet) (map[int32]failuredomain.FailureDomain, sets.Set[int32], error) {
+func createMachineMapping(ctx context.Context, logger logr.Logger, cl client.Client, cpms *machinev1.ControlPlaneMachineSet, infrastructure *configv1.Infrastructure) (map[int32]failuredomain.FailureDomain, sets.Set[int32], error) {
 	selector, err := metav1.LabelSelectorAsSelector(&cpms.Spec.Selector)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not convert label selector to selector: %w", err)
@@ -127,7 +292,7 @@ func createMachineMapping(ctx context.Context, logger logr.Logger, cl client.Cli
 		return nil, nil, fmt.Errorf("failed to list machines: %w", err)
 	}
 
-	mapping, err := mapIndexesToFailureDomainsForMachines(logger, machineList)
+	mapping, err := mapIndexesToFailureDomainsForMachines(logger, machineList, infrastructure)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not map indexes to failure domains for machines: %w", err)
 	}
@@ -138,7 +303,7 @@ func createMachineMapping(ctx context.Context, logger logr.Logger, cl client.Cli
 }
 
 // mapIndexesToFailureDomainsForMachines creates an index to failure domain mapping for machine in the list.
-func mapIndexesToFailureDomainsForMachines(logger logr.Logger, machineList *machinev1beta1.MachineList) (map[int32]failuredomain.FailureDomain, error) {
+func mapIndexesToFailureDomainsForMachines(logger logr.Logger, machineList *machinev1beta1.MachineList, infrastructure *configv1.Infrastructure) (map[int32]failuredomain.FailureDomain, error) {
 	out := make(map[int32]failuredomain.FailureDomain)
 
 	// indexToMachine contains a mapping between the machine domain index in the newest machine
@@ -146,7 +311,7 @@ func mapIndexesToFailureDomainsForMachines(logger logr.Logger, machineList *mach
 	indexToMachine := make(map[int32]machinev1beta1.Machine)
 
 	for _, machine := range machineList.Items {
-		failureDomain, err := providerconfig.ExtractFailureDomainFromMachine(machine)
+		failureDomain, err := providerconfig.ExtractFailureDomainFromMachine(machine, infrastructure)
 		if err != nil {
 			return nil, fmt.Errorf("could not extract failure domain from machine %s: %w", machine.Name, err)
 		}
@@ -169,7 +334,7 @@ func mapIndexesToFailureDomainsForMachines(logger logr.Logger, machineList *mach
 				continue
 			}
 
-			oldMachineFailureDomain, err := providerconfig.ExtractFailureDomainFromMachine(oldMachine)
+			oldMachineFailureDomain, err := providerconfig.ExtractFailureDomainFromMachine(oldMachine, infrastructure)
 			if err != nil {
 				return nil, fmt.Errorf("could not extract failure domain from machine %s: %w", oldMachine.Name, err)
 			}
@@ -232,7 +397,7 @@ func listDeletingIndexes(machines []machinev1beta1.Machine) sets.Set[int32] {
 // When processing the indexes, everything must be sorted to ensure the output is stable (note iterating over a map
 // is randomised by golang).
 // The base mapping should always be at least as long as the machine mapping for this to work.
-func reconcileMappings(logger logr.Logger, base, machines map[int32]failuredomain.FailureDomain, deletingIndexes sets.Set[int32]) map[int32]failuredomain.FailureDomain {
+func reconcileMappings(logger logr.Logger, base map[int32]failuredomain.FailureDomain, weights failureDomainWeights, machines map[int32]failuredomain.FailureDomain, deletingIndexes sets.Set[int32]) map[int32]failuredomain.FailureDomain {
 	if len(base) < len(machines) {
 		// This is a programming error since user input doesn't affect this.
 		panic("base must have at least as many indexes as machines")
@@ -257,13 +422,10 @@ func reconcileMappings(logger logr.Logger, base, machines map[int32]failuredomai
 	// Run through the mappings and match these to candidates where possible.
 	matchMachinesToCandidates(out, candidates, unmatchedIndexes, deletingIndexes)
 
-	// Get the maximum number of replicas per failure domain. This is needed
-	// to ensure we balance appropriately across the available failure domains.
-	maxPerFailureDomain := maxIndexesPerFailureDomain(base)
-
 	// Handle any remaining unmatched indexes.
+	// The weights are needed to ensure we balance appropriately across the available failure domains.
 	for _, idx := range sortedIndexes(unmatchedIndexes) {
-		handleUnmatchedIndex(logger, idx, out, base, candidates, unmatchedIndexes, maxPerFailureDomain)
+		handleUnmatchedIndex(logger, idx, out, base, candidates, unmatchedIndexes, weights)
 	}
 
 	return out
@@ -315,7 +477,7 @@ func matchMachinesToCandidates(out, candidates map[int32]failuredomain.FailureDo
 // - The failure domain from the machine mapping was removed from the base.
 // - A new failure domain was added to the base mapping.
 // - The machine mapping is balanced in a different weighting to the machine mapping.
-func handleUnmatchedIndex(logger logr.Logger, idx int32, out, base, candidates map[int32]failuredomain.FailureDomain, unmatchedIndexes sets.Set[int32], maxPerFailureDomain int) {
+func handleUnmatchedIndex(logger logr.Logger, idx int32, out, base, candidates map[int32]failuredomain.FailureDomain, unmatchedIndexes sets.Set[int32], weights failureDomainWeights) {
 	switch {
 	case !indexExists(out, idx):
 		// There is no machine in this index presently,
@@ -334,7 +496,7 @@ func handleUnmatchedIndex(logger logr.Logger, idx int32, out, base, candidates m
 
 		out[idx] = candidates[idx]
 		useCandidate(candidates, unmatchedIndexes, idx)
-	case countForFailureDomain(out, out[idx]) > maxPerFailureDomain:
+	case countForFailureDomain(out, out[idx]) > maxIndexesPerFailureDomain(base, weights, out[idx]):
 		// This failure domain is over represented in the mapping.
 		// In this case, we must switch it to the candidate failure domain to rebalance
 		// the mapping.
@@ -349,7 +511,7 @@ func handleUnmatchedIndex(logger logr.Logger, idx int32, out, base, candidates m
 		useCandidate(candidates, unmatchedIndexes, idx)
 	default:
 		// The index exists, the failure domain is contained in the base,
-		// and is represented in the mapping fewer than maxPerFailureDomain times.
+		// and is represented in the mapping no more than its weighted share of the indexes.
 		// In this case, it's ok to accept the mapping even though it doesn't match
 		// a candidate.
 		// This is likely to happen if the machine mapping is balanced using a
@@ -438,26 +600,30 @@ func copyMapping(mapping map[int32]failuredomain.FailureDomain) map[int32]failur
 	return out
 }
 
-// maxIndexesPerFailureDomain is used to calculate the maximum number of allowed indexes per failure domain.
-// That is, based on how many failure domains are in the base mapping and the total number of indexes, to
-// create a balanced mapping, what is the maximum number of Machines we want to create per failure domain.
-func maxIndexesPerFailureDomain(base map[int32]failuredomain.FailureDomain) int {
-	uniqueFailureDomains := countUniqueFailureDomain(base)
+// maxIndexesPerFailureDomain is used to calculate the maximum number of allowed indexes for a failure domain.
+// That is, based on the weights of the failure domains in the base mapping and the total number of indexes, to
+// create a balanced mapping, what is the maximum number of Machines we want to create in the failure domain.
+func maxIndexesPerFailureDomain(base map[int32]failuredomain.FailureDomain, weights failureDomainWeights, target failuredomain.FailureDomain) int {
+	totalWeight := 0
+	for _, failureDomain := range uniqueFailureDomains(base) {
+		totalWeight += weights.weight(failureDomain)
+	}
 
 	// To get an accurate division we must work in floats.
-	d := float64(len(base)) / float64(uniqueFailureDomains)
+	d := float64(len(base)) * float64(weights.weight(target)) / float64(totalWeight)
 
 	return int(math.Ceil(d))
 }
 
-// countUniqueFailureDomain calculates the number of unique failure domains present within the passed mapping.
-func countUniqueFailureDomain(mapping map[int32]failuredomain.FailureDomain) int {
-	out := make(map[failuredomain.FailureDomain]struct{})
+// uniqueFailureDomains returns the unique failure domains present within the passed mapping.
+func uniqueFailureDomains(mapping map[int32]failuredomain.FailureDomain) []failuredomain.FailureDomain {
+	out := []failuredomain.FailureDomain{}
 
-	for _, failureDomain := range mapping {
+	for _, idx := range sortedIndexes(mapping) {
+		failureDomain := mapping[idx]
 		matched := false
 
-		for k := range out {
+		for _, k := range out {
 			if k.Equal(failureDomain) {
 				matched = true
 				break
@@ -466,11 +632,11 @@ func countUniqueFailureDomain(mapping map[int32]failuredomain.FailureDomain) int
 
 		if !matched {
 			// This is a new failure domain, make sure it's accounted for.
-			out[failureDomain] = struct{}{}
+			out = append(out, failureDomain)
 		}
 	}
 
-	return len(out)
+	return out
 }
 
 // countForFailureDomain counts how many times the target failure domain is present in the mapping.
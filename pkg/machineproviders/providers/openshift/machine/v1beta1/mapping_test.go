@@ -25,6 +25,8 @@ import (
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
 
 	"github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder"
@@ -170,6 +172,7 @@ var _ = Describe("Failure Domain Mapping", func() {
 			machines        []*machinev1beta1.Machine
 			expectedError   error
 			expectedMapping map[int32]failuredomain.FailureDomain
+			expectedReasons map[int32]machineproviders.FailureDomainMappingReason
 			expectedLogs    []testutils.LogEntry
 		}
 
@@ -198,7 +201,7 @@ var _ = Describe("Failure Domain Mapping", func() {
 
 			originalCPMS := cpms.DeepCopy()
 
-			mapping, err := mapMachineIndexesToFailureDomains(ctx, logger.Logger(), k8sClient, cpms, failureDomains)
+			mapping, reasons, err := mapMachineIndexesToFailureDomains(ctx, logger.Logger(), k8sClient, cpms, nil, failureDomains)
 			if in.expectedError != nil {
 				Expect(err).To(MatchError(in.expectedError))
 			} else {
@@ -206,6 +209,7 @@ var _ = Describe("Failure Domain Mapping", func() {
 			}
 
 			Expect(mapping).To(Equal(in.expectedMapping))
+			Expect(reasons).To(Equal(in.expectedReasons))
 			Expect(logger.Entries()).To(ConsistOf(in.expectedLogs))
 			Expect(cpms).To(Equal(originalCPMS), "The update functions should not modify the ControlPlaneMachineSet in any way")
 		},
@@ -243,6 +247,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -274,6 +283,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -305,6 +319,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -340,6 +359,13 @@ var _ = Describe("Failure Domain Mapping", func() {
 					3: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
 					4: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+					3: machineproviders.FailureDomainMappingReasonExistingMachine,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -377,6 +403,13 @@ var _ = Describe("Failure Domain Mapping", func() {
 					3: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 					4: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+					3: machineproviders.FailureDomainMappingReasonExistingMachine,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -414,6 +447,13 @@ var _ = Describe("Failure Domain Mapping", func() {
 					3: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 					4: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+					3: machineproviders.FailureDomainMappingReasonExistingMachine,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -446,6 +486,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()), // The extra failure domain must be the first alphabetically in this case.
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonRebalanced,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -484,6 +529,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()), // The extra failure domain must be the first alphabetically in this case.
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonRebalanced,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -521,6 +571,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonRebalanced,
+					2: machineproviders.FailureDomainMappingReasonRebalanced,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -568,6 +623,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()), // The missing failure domain fills in for the last Machine alphabetically.
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonRebalanced,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -609,6 +669,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -641,6 +706,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -673,6 +743,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -708,6 +783,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -739,6 +819,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonRebalanced,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonRebalanced,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -788,6 +873,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonBaseMapping,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -826,6 +916,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					1: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 					2: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonBaseMapping,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -868,6 +963,13 @@ var _ = Describe("Failure Domain Mapping", func() {
 					3: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
 					4: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+					3: machineproviders.FailureDomainMappingReasonRebalanced,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -910,6 +1012,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					4: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					5: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					3: machineproviders.FailureDomainMappingReasonExistingMachine,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+					5: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -941,6 +1048,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					2: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					4: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					2: machineproviders.FailureDomainMappingReasonExistingMachine,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -972,6 +1084,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					4: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					5: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					3: machineproviders.FailureDomainMappingReasonExistingMachine,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+					5: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -1005,6 +1122,12 @@ var _ = Describe("Failure Domain Mapping", func() {
 					6: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					9: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					1: machineproviders.FailureDomainMappingReasonExistingMachine,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+					6: machineproviders.FailureDomainMappingReasonExistingMachine,
+					9: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -1041,6 +1164,13 @@ var _ = Describe("Failure Domain Mapping", func() {
 					8:  failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					13: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					2:  machineproviders.FailureDomainMappingReasonExistingMachine,
+					3:  machineproviders.FailureDomainMappingReasonExistingMachine,
+					5:  machineproviders.FailureDomainMappingReasonExistingMachine,
+					8:  machineproviders.FailureDomainMappingReasonExistingMachine,
+					13: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -1076,6 +1206,11 @@ var _ = Describe("Failure Domain Mapping", func() {
 					4: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					5: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					3: machineproviders.FailureDomainMappingReasonExistingMachine,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+					5: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -1112,6 +1247,12 @@ var _ = Describe("Failure Domain Mapping", func() {
 					4: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
 					5: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
+				expectedReasons: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					3: machineproviders.FailureDomainMappingReasonExistingMachine,
+					4: machineproviders.FailureDomainMappingReasonExistingMachine,
+					5: machineproviders.FailureDomainMappingReasonExistingMachine,
+				},
 				expectedLogs: []testutils.LogEntry{
 					{
 						Level: 4,
@@ -1135,6 +1276,7 @@ var _ = Describe("Failure Domain Mapping", func() {
 			cpmsBuilder     machinev1resourcebuilder.ControlPlaneMachineSetInterface
 			machineCount    int
 			failureDomains  machinev1.FailureDomains
+			weights         failureDomainWeights
 			expectedMapping map[int32]failuredomain.FailureDomain
 			expectedError   error
 		}
@@ -1144,7 +1286,7 @@ var _ = Describe("Failure Domain Mapping", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			cpms := in.cpmsBuilder.Build()
-			mapping, err := createBaseFailureDomainMapping(cpms, failureDomains, in.machineCount)
+			mapping, err := createBaseFailureDomainMapping(cpms, failureDomains, in.weights, in.machineCount)
 			if in.expectedError != nil {
 				Expect(err).To(MatchError(in.expectedError))
 			} else {
@@ -1349,6 +1491,222 @@ var _ = Describe("Failure Domain Mapping", func() {
 					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
 				},
 			}),
+			Entry("with equal weights and three failure domains (order a,b,c)", createBaseMappingTableInput{
+				cpmsBuilder:  cpmsBuilder.WithReplicas(3),
+				machineCount: 3,
+				failureDomains: machinev1resourcebuilder.AWSFailureDomains().WithFailureDomainBuilders(
+					usEast1aFailureDomainBuilder,
+					usEast1bFailureDomainBuilder,
+					usEast1cFailureDomainBuilder,
+				).BuildFailureDomains(),
+				weights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(): 2,
+					failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()).String(): 2,
+					failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()).String(): 2,
+				},
+				expectedMapping: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+				},
+			}),
+			Entry("with five replicas and a double weighted failure domain (order a,b,c,a,a)", createBaseMappingTableInput{
+				cpmsBuilder:  cpmsBuilder.WithReplicas(5),
+				machineCount: 5,
+				failureDomains: machinev1resourcebuilder.AWSFailureDomains().WithFailureDomainBuilders(
+					usEast1bFailureDomainBuilder,
+					usEast1cFailureDomainBuilder,
+					usEast1aFailureDomainBuilder,
+				).BuildFailureDomains(),
+				weights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(): 2,
+				},
+				expectedMapping: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					3: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					4: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+				},
+			}),
+			Entry("with three replicas and an avoided failure domain (order a,b,a)", createBaseMappingTableInput{
+				cpmsBuilder:  cpmsBuilder.WithReplicas(3),
+				machineCount: 3,
+				failureDomains: machinev1resourcebuilder.AWSFailureDomains().WithFailureDomainBuilders(
+					usEast1aFailureDomainBuilder,
+					usEast1bFailureDomainBuilder,
+					usEast1cFailureDomainBuilder,
+				).BuildFailureDomains(),
+				weights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()).String(): 0,
+				},
+				expectedMapping: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+					2: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+				},
+			}),
+			Entry("with no failure domains carrying weight", createBaseMappingTableInput{
+				cpmsBuilder:  cpmsBuilder.WithReplicas(3),
+				machineCount: 3,
+				failureDomains: machinev1resourcebuilder.AWSFailureDomains().WithFailureDomainBuilders(
+					usEast1aFailureDomainBuilder,
+				).BuildFailureDomains(),
+				weights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(): 0,
+				},
+				expectedError: errNoFailureDomains,
+			}),
+		)
+	})
+
+	Context("newFailureDomainWeights", func() {
+		type failureDomainWeightsTableInput struct {
+			annotation      *string
+			unavailable     *string
+			expectedError   error
+			expectedWeights failureDomainWeights
+			expectedLogs    []testutils.LogEntry
+		}
+
+		failureDomains := []failuredomain.FailureDomain{
+			failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+			failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+			failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+		}
+
+		weightsAnnotation := func(format string) *string {
+			value := fmt.Sprintf(format, failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(), failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()).String(), failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()).String())
+
+			return &value
+		}
+
+		DescribeTable("should construct the weights from the annotation", func(in failureDomainWeightsTableInput) {
+			logger := testutils.NewTestLogger()
+
+			cpms := cpmsBuilder.Build()
+			cpms.SetAnnotations(map[string]string{})
+
+			if in.annotation != nil {
+				cpms.Annotations[annotations.FailureDomainWeightsAnnotation] = *in.annotation
+			}
+
+			if in.unavailable != nil {
+				cpms.Annotations[annotations.UnavailableFailureDomainsAnnotation] = *in.unavailable
+			}
+
+			weights, err := newFailureDomainWeights(logger.Logger(), cpms, failureDomains)
+			if in.expectedError != nil {
+				Expect(err).To(MatchError(in.expectedError))
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			Expect(weights).To(Equal(in.expectedWeights))
+			Expect(logger.Entries()).To(ConsistOf(in.expectedLogs))
+		},
+			Entry("with no annotation", failureDomainWeightsTableInput{
+				expectedWeights: failureDomainWeights{},
+			}),
+			Entry("with a weighted failure domain", failureDomainWeightsTableInput{
+				annotation: weightsAnnotation(`[{"failureDomain":%[1]q,"weight":3}]`),
+				expectedWeights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(): 3,
+				},
+			}),
+			Entry("with an omitted weight", failureDomainWeightsTableInput{
+				annotation: weightsAnnotation(`[{"failureDomain":%[2]q}]`),
+				expectedWeights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()).String(): 1,
+				},
+			}),
+			Entry("with an avoided failure domain", failureDomainWeightsTableInput{
+				annotation: weightsAnnotation(`[{"failureDomain":%[1]q,"weight":2},{"failureDomain":%[3]q,"avoid":true}]`),
+				expectedWeights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(): 2,
+					failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()).String(): 0,
+				},
+			}),
+			Entry("with every failure domain avoided", failureDomainWeightsTableInput{
+				annotation: weightsAnnotation(`[{"failureDomain":%[1]q,"avoid":true},{"failureDomain":%[2]q,"avoid":true},{"failureDomain":%[3]q,"avoid":true}]`),
+				expectedWeights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(): 1,
+					failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()).String(): 1,
+					failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()).String(): 1,
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level:   2,
+						Message: "Every available failure domain is marked to be avoided, ignoring avoidance",
+					},
+				},
+			}),
+			Entry("with an unknown failure domain", failureDomainWeightsTableInput{
+				annotation:      pointer.String(`[{"failureDomain":"AWSFailureDomain{AvailabilityZone:us-east-1d}","weight":2}]`),
+				expectedWeights: failureDomainWeights{},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"failureDomain", "AWSFailureDomain{AvailabilityZone:us-east-1d}",
+						},
+						Message: "Ignoring weight for unknown failure domain",
+					},
+				},
+			}),
+			Entry("with an invalid annotation", failureDomainWeightsTableInput{
+				annotation:    weightsAnnotation(`{"failureDomain":%[1]q}`),
+				expectedError: annotations.ErrInvalidFailureDomainWeights,
+			}),
+			Entry("with an unavailable failure domain", failureDomainWeightsTableInput{
+				unavailable: weightsAnnotation(`[%[3]q]`),
+				expectedWeights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()).String(): 0,
+				},
+			}),
+			Entry("with an unavailable failure domain that is also weighted", failureDomainWeightsTableInput{
+				annotation:  weightsAnnotation(`[{"failureDomain":%[3]q,"weight":3}]`),
+				unavailable: weightsAnnotation(`[%[3]q]`),
+				expectedWeights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()).String(): 0,
+				},
+			}),
+			Entry("with every available failure domain avoided", failureDomainWeightsTableInput{
+				annotation:  weightsAnnotation(`[{"failureDomain":%[1]q,"avoid":true},{"failureDomain":%[2]q,"avoid":true}]`),
+				unavailable: weightsAnnotation(`[%[3]q]`),
+				expectedWeights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(): 1,
+					failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()).String(): 1,
+					failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()).String(): 0,
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level:   2,
+						Message: "Every available failure domain is marked to be avoided, ignoring avoidance",
+					},
+				},
+			}),
+			Entry("with an unknown unavailable failure domain", failureDomainWeightsTableInput{
+				unavailable:     pointer.String(`["AWSFailureDomain{AvailabilityZone:us-east-1d}"]`),
+				expectedWeights: failureDomainWeights{},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 2,
+						KeysAndValues: []interface{}{
+							"failureDomain", "AWSFailureDomain{AvailabilityZone:us-east-1d}",
+						},
+						Message: "Ignoring unknown unavailable failure domain",
+					},
+				},
+			}),
+			Entry("with every failure domain unavailable", failureDomainWeightsTableInput{
+				unavailable:   weightsAnnotation(`[%[1]q,%[2]q,%[3]q]`),
+				expectedError: errNoAvailableFailureDomains,
+			}),
+			Entry("with an invalid unavailable failure domains annotation", failureDomainWeightsTableInput{
+				unavailable:   weightsAnnotation(`%[1]q`),
+				expectedError: annotations.ErrInvalidUnavailableFailureDomains,
+			}),
 		)
 	})
 
@@ -1385,7 +1743,7 @@ var _ = Describe("Failure Domain Mapping", func() {
 
 			originalCPMS := cpms.DeepCopy()
 
-			mapping, deletingIndexes, err := createMachineMapping(ctx, logger.Logger(), k8sClient, cpms)
+			mapping, deletingIndexes, err := createMachineMapping(ctx, logger.Logger(), k8sClient, cpms, nil)
 			if in.expectedError != nil {
 				Expect(err).To(MatchError(in.expectedError))
 			} else {
@@ -1607,6 +1965,7 @@ var _ = Describe("Failure Domain Mapping", func() {
 	Context("reconcileMappings", func() {
 		type reconcileMappingsTableInput struct {
 			baseMapping     map[int32]failuredomain.FailureDomain
+			weights         failureDomainWeights
 			machineMapping  map[int32]failuredomain.FailureDomain
 			deletingIndexes sets.Set[int32]
 			expectedMapping map[int32]failuredomain.FailureDomain
@@ -1618,7 +1977,7 @@ var _ = Describe("Failure Domain Mapping", func() {
 			for i := 0; i < 10; i++ {
 				logger := testutils.NewTestLogger()
 
-				mapping := reconcileMappings(logger.Logger(), in.baseMapping, in.machineMapping, in.deletingIndexes)
+				mapping := reconcileMappings(logger.Logger(), in.baseMapping, in.weights, in.machineMapping, in.deletingIndexes)
 
 				Expect(mapping).To(Equal(in.expectedMapping))
 				Expect(logger.Entries()).To(Equal(in.expectedLogs))
@@ -1917,6 +2276,72 @@ var _ = Describe("Failure Domain Mapping", func() {
 					},
 				},
 			}),
+			Entry("when a failure domain is weighted, should rebalance the failure domains to the weighted share (c,b,c,c,a)", reconcileMappingsTableInput{
+				baseMapping: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					3: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					4: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+				},
+				weights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(): 2,
+				},
+				machineMapping: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					3: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					4: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+				},
+				deletingIndexes: sets.New[int32](),
+				expectedMapping: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+					2: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					3: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					4: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"index", 2,
+							"oldFailureDomain", "AWSFailureDomain{AvailabilityZone:us-east-1c, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[subnet-us-east-1c]}]}}",
+							"newFailureDomain", "AWSFailureDomain{AvailabilityZone:us-east-1a, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[subnet-us-east-1a]}]}}",
+						},
+						Message: "Failure domain changed for index",
+					},
+				},
+			}),
+			Entry("when a failure domain is weighted, should accept machines within the weighted share (a,b,c,a,a)", reconcileMappingsTableInput{
+				baseMapping: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					1: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					3: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					4: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+				},
+				weights: failureDomainWeights{
+					failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()).String(): 2,
+				},
+				machineMapping: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					1: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					3: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					4: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+				},
+				deletingIndexes: sets.New[int32](),
+				expectedMapping: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					1: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					2: failuredomain.NewAWSFailureDomain(usEast1cFailureDomainBuilder.Build()),
+					3: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
+					4: failuredomain.NewAWSFailureDomain(usEast1bFailureDomainBuilder.Build()),
+				},
+				expectedLogs: []testutils.LogEntry{},
+			}),
 			Entry("when a machine has a an index not present in the base mapping, keeps the additional index", reconcileMappingsTableInput{
 				baseMapping: map[int32]failuredomain.FailureDomain{
 					0: failuredomain.NewAWSFailureDomain(usEast1aFailureDomainBuilder.Build()),
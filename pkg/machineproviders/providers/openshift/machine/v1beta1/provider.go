@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	corev1 "k8s.io/api/core/v1"
@@ -33,9 +34,11 @@ import (
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/utils/pointer"
 
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -99,21 +102,48 @@ func NewMachineProvider(ctx context.Context, logger logr.Logger, cl client.Clien
 		return nil, errEmptyConfig
 	}
 
-	providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*cpms.Spec.Template.OpenShiftMachineV1Beta1Machine)
+	var (
+		infrastructure *configv1.Infrastructure
+		err            error
+	)
+
+	if providerconfig.InfrastructureRequired(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform) {
+		infrastructure, err = util.GetInfrastructure(ctx, cl)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching infrastructure: %w", err)
+		}
+	}
+
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*cpms.Spec.Template.OpenShiftMachineV1Beta1Machine, infrastructure)
 	if err != nil {
 		return nil, fmt.Errorf("error constructing provider config: %w", err)
 	}
 
-	failureDomains, err := failuredomain.NewFailureDomains(cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains)
+	failureDomains, err := annotations.FailureDomains(cpms)
 	if err != nil {
 		return nil, fmt.Errorf("error constructing failure domain config: %w", err)
 	}
 
-	indexToFailureDomain, err := mapMachineIndexesToFailureDomains(ctx, logger, cl, cpms, failureDomains)
+	indexToFailureDomain, indexToFailureDomainReason, err := mapMachineIndexesToFailureDomains(ctx, logger, cl, cpms, infrastructure, failureDomains)
 	if err != nil && !errors.Is(err, errNoFailureDomains) {
 		return nil, fmt.Errorf("error mapping machine indexes: %w", err)
 	}
 
+	unavailableFailureDomains, err := getUnavailableFailureDomains(cpms, failureDomains)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing unavailable failure domains: %w", err)
+	}
+
+	ignoredProviderSpecFields, err := annotations.IgnoredProviderSpecFields(cpms, providerConfig.Type())
+	if err != nil {
+		return nil, fmt.Errorf("error constructing ignored provider spec fields: %w", err)
+	}
+
+	templateRevision, err := util.TemplateHash(cpms.Spec.Template, annotations.FailureDomainsAnnotationValues(cpms))
+	if err != nil {
+		return nil, fmt.Errorf("error calculating template revision: %w", err)
+	}
+
 	machineAPIScheme := apimachineryruntime.NewScheme()
 	if err := machinev1.Install(machineAPIScheme); err != nil {
 		return nil, fmt.Errorf("unable to add machine.openshift.io/v1 scheme: %w", err)
@@ -124,17 +154,44 @@ func NewMachineProvider(ctx context.Context, logger logr.Logger, cl client.Clien
 	}
 
 	return &openshiftMachineProvider{
-		client:               cl,
-		indexToFailureDomain: indexToFailureDomain,
-		machineSelector:      cpms.Spec.Selector,
-		machineTemplate:      *cpms.Spec.Template.OpenShiftMachineV1Beta1Machine,
-		ownerMetadata:        cpms.ObjectMeta,
-		providerConfig:       providerConfig,
-		namespace:            cpms.Namespace,
-		machineAPIScheme:     machineAPIScheme,
+		client:                     cl,
+		indexToFailureDomain:       indexToFailureDomain,
+		indexToFailureDomainReason: indexToFailureDomainReason,
+		unavailableFailureDomains:  unavailableFailureDomains,
+		ignoredProviderSpecFields:  ignoredProviderSpecFields,
+		machineSelector:            cpms.Spec.Selector,
+		machineTemplate:            *cpms.Spec.Template.OpenShiftMachineV1Beta1Machine,
+		templateRevision:           templateRevision,
+		ownerMetadata:              cpms.ObjectMeta,
+		providerConfig:             providerConfig,
+		infrastructure:             infrastructure,
+		namespace:                  cpms.Namespace,
+		machineAPIScheme:           machineAPIScheme,
 	}, nil
 }
 
+// getUnavailableFailureDomains returns the configured failure domains that are marked as unavailable
+// on the ControlPlaneMachineSet.
+func getUnavailableFailureDomains(cpms *machinev1.ControlPlaneMachineSet, failureDomains []failuredomain.FailureDomain) ([]failuredomain.FailureDomain, error) {
+	unavailable, err := annotations.UnavailableFailureDomains(cpms)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse unavailable failure domains: %w", err)
+	}
+
+	out := []failuredomain.FailureDomain{}
+
+	for _, failureDomain := range failureDomains {
+		for _, name := range unavailable {
+			if failureDomain.String() == name {
+				out = append(out, failureDomain)
+				break
+			}
+		}
+	}
+
+	return out, nil
+}
+
 // openshiftMachineProvider holds the implementation of the MachineProvider interface.
 type openshiftMachineProvider struct {
 	// client is used to make API calls to fetch Machines and Nodes.
@@ -146,6 +203,18 @@ type openshiftMachineProvider struct {
 	// We use a built in type to avoid leaking implementation specific details.
 	indexToFailureDomain map[int32]failuredomain.FailureDomain
 
+	// indexToFailureDomainReason records why each index was mapped to its failure domain.
+	// This is reported to users to help them understand the placement of Machines.
+	indexToFailureDomainReason map[int32]machineproviders.FailureDomainMappingReason
+
+	// unavailableFailureDomains are the failure domains marked as unavailable on the ControlPlaneMachineSet.
+	// Machines within these failure domains are reported as evacuating.
+	unavailableFailureDomains []failuredomain.FailureDomain
+
+	// ignoredProviderSpecFields are the paths of the provider spec fields that are ignored when determining
+	// whether a Machine needs an update.
+	ignoredProviderSpecFields []string
+
 	// machineSelector is used to identify which Machines should be considered by
 	// the machine provider when constructing machine information.
 	machineSelector metav1.LabelSelector
@@ -153,6 +222,9 @@ type openshiftMachineProvider struct {
 	// machineTemplate is used to create new Machines.
 	machineTemplate machinev1.OpenShiftMachineV1Beta1MachineTemplate
 
+	// templateRevision is the revision of the template, recorded on each new Machine.
+	templateRevision string
+
 	// ownerMetadata is used to allow newly created Machines to have an owner
 	// reference set upon creation.
 	ownerMetadata metav1.ObjectMeta
@@ -160,6 +232,11 @@ type openshiftMachineProvider struct {
 	// providerConfig stores the providerConfig for creating new Machines.
 	providerConfig providerconfig.ProviderConfig
 
+	// infrastructure is used to translate between failure domains and provider configs on platforms
+	// where the topology of the failure domains is defined within the Infrastructure.
+	// It is nil on other platforms.
+	infrastructure *configv1.Infrastructure
+
 	// namespace store the namespace where new machines will be created.
 	namespace string
 
@@ -239,7 +316,7 @@ func (m *openshiftMachineProvider) generateMachineInfo(logger logr.Logger, machi
 		return machineproviders.MachineInfo{}, fmt.Errorf("could not determine machine index: %w", err)
 	}
 
-	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machine.Spec)
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machine.Spec, m.infrastructure)
 	if err != nil {
 		return machineproviders.MachineInfo{}, fmt.Errorf("could not compare existing and desired provider configs: %w", err)
 	}
@@ -266,18 +343,86 @@ func (m *openshiftMachineProvider) generateMachineInfo(logger logr.Logger, machi
 		return machineproviders.MachineInfo{}, fmt.Errorf("cannot compare provider configs: %w", err)
 	}
 
+	var diff []machineproviders.FieldDiff
+
+	needsUpdate := !configsEqual
+
+	if !configsEqual {
+		diff, needsUpdate, err = m.getProviderConfigDiff(providerConfig, templateProviderConfig)
+		if err != nil {
+			return machineproviders.MachineInfo{}, fmt.Errorf("cannot diff provider configs: %w", err)
+		}
+	}
+
+	inPlaceDiff, err := m.getInPlaceDiff(machine)
+	if err != nil {
+		return machineproviders.MachineInfo{}, fmt.Errorf("cannot diff fields that can be updated in place: %w", err)
+	}
+
 	ready := m.isMachineReady(machine)
 
 	return machineproviders.MachineInfo{
-		MachineRef:   machineRef,
-		NodeRef:      nodeRef,
-		Ready:        ready,
-		NeedsUpdate:  !configsEqual,
-		Index:        machineIndex,
-		ErrorMessage: pointer.StringDeref(machine.Status.ErrorMessage, ""),
+		MachineRef:         machineRef,
+		NodeRef:            nodeRef,
+		Ready:              ready,
+		NeedsUpdate:        needsUpdate,
+		NeedsInPlaceUpdate: len(inPlaceDiff) > 0,
+		Evacuating:         m.isInUnavailableFailureDomain(providerConfig),
+		Index:              machineIndex,
+		ErrorMessage:       pointer.StringDeref(machine.Status.ErrorMessage, ""),
+		Diff:               diff,
+		InPlaceDiff:        inPlaceDiff,
+		TemplateRevision:   machine.Annotations[annotations.MachineTemplateRevisionAnnotation],
 	}, nil
 }
 
+// getProviderConfigDiff describes each field that differs between the provider config of an existing Machine
+// and the desired provider config, excluding the fields covered by the ignored provider spec fields.
+// When the only differences are within ignored fields, it reports that the Machine does not need an update.
+func (m *openshiftMachineProvider) getProviderConfigDiff(existing, desired providerconfig.ProviderConfig) ([]machineproviders.FieldDiff, bool, error) {
+	fieldDiffs, err := existing.StructuredDiff(desired)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not compare provider configs: %w", err)
+	}
+
+	needsUpdate := true
+
+	if len(fieldDiffs) > 0 && len(m.ignoredProviderSpecFields) > 0 {
+		fieldDiffs = providerconfig.IgnoreFieldDiffs(fieldDiffs, m.ignoredProviderSpecFields)
+		needsUpdate = len(fieldDiffs) > 0
+	}
+
+	var out []machineproviders.FieldDiff
+
+	for _, fieldDiff := range fieldDiffs {
+		out = append(out, machineproviders.FieldDiff{
+			Path: fieldDiff.Path,
+			Old:  fieldDiff.Old,
+			New:  fieldDiff.New,
+		})
+	}
+
+	return out, needsUpdate, nil
+}
+
+// isInUnavailableFailureDomain checks whether the provider config places the Machine within a failure domain
+// that has been marked as unavailable.
+func (m *openshiftMachineProvider) isInUnavailableFailureDomain(providerConfig providerconfig.ProviderConfig) bool {
+	if len(m.unavailableFailureDomains) == 0 {
+		return false
+	}
+
+	machineFailureDomain := providerConfig.ExtractFailureDomain()
+
+	for _, failureDomain := range m.unavailableFailureDomains {
+		if failureDomain.Equal(machineFailureDomain) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (m *openshiftMachineProvider) getMachineIndex(logger logr.Logger, machine machinev1beta1.Machine) (int32, error) {
 	machineNameIndex, correctFormat := getMachineNameIndex(machine)
 	if correctFormat {
@@ -287,7 +432,7 @@ func (m *openshiftMachineProvider) getMachineIndex(logger logr.Logger, machine m
 
 	// If the Machine name format doesn't match, try to fallback to matching based on the failure domain
 	// with the Machine's provider spec.
-	failureDomain, err := providerconfig.ExtractFailureDomainFromMachine(machine)
+	failureDomain, err := providerconfig.ExtractFailureDomainFromMachine(machine, m.infrastructure)
 	if err != nil {
 		return 0, fmt.Errorf("cannot extract failure domain from machine: %w", err)
 	}
@@ -386,11 +531,19 @@ func (m *openshiftMachineProvider) CreateMachine(ctx context.Context, logger log
 		ObjectMeta: m.ownerMetadata,
 	}
 
+	// Copy the template annotations so that the revision is not added to the template.
+	machineAnnotations := map[string]string{}
+	for key, value := range m.machineTemplate.ObjectMeta.Annotations {
+		machineAnnotations[key] = value
+	}
+
+	machineAnnotations[annotations.MachineTemplateRevisionAnnotation] = m.templateRevision
+
 	machine := &machinev1beta1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        machineName,
 			Namespace:   m.namespace,
-			Annotations: m.machineTemplate.ObjectMeta.Annotations,
+			Annotations: machineAnnotations,
 			Labels:      m.machineTemplate.ObjectMeta.Labels,
 		},
 		Spec: m.machineTemplate.Spec,
@@ -429,6 +582,7 @@ func (m *openshiftMachineProvider) CreateMachine(ctx context.Context, logger log
 		"index", index,
 		"machineName", machine.Name,
 		"failureDomain", providerConfig.ExtractFailureDomain().String(),
+		"templateRevision", m.templateRevision,
 	)
 
 	return nil
@@ -517,3 +671,18 @@ func (m *openshiftMachineProvider) DeleteMachine(ctx context.Context, logger log
 
 	return nil
 }
+
+// GetFailureDomainMapping returns the string representation of the failure domain mapped to each index,
+// along with the reason the index was mapped to that failure domain.
+func (m *openshiftMachineProvider) GetFailureDomainMapping() map[int32]machineproviders.FailureDomainMapping {
+	out := make(map[int32]machineproviders.FailureDomainMapping, len(m.indexToFailureDomain))
+
+	for idx, failureDomain := range m.indexToFailureDomain {
+		out[idx] = machineproviders.FailureDomainMapping{
+			FailureDomain: failureDomain.String(),
+			Reason:        m.indexToFailureDomainReason[idx],
+		}
+	}
+
+	return out
+}
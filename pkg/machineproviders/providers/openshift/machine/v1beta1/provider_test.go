@@ -29,10 +29,12 @@ import (
 	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
 	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
 	machinev1beta1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
 	machineprovidersresourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machineproviders"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -166,12 +168,35 @@ var _ = Describe("MachineProvider", func() {
 			return fmt.Sprintf("%s-master-%s", resourcebuilder.TestClusterIDValue, suffix)
 		}
 
+		fieldDiff := func(path, oldValue, newValue string) machineproviders.FieldDiff {
+			return machineproviders.FieldDiff{
+				Path: fmt.Sprintf("spec.providerSpec.value.%s", path),
+				Old:  fmt.Sprintf("%q", oldValue),
+				New:  fmt.Sprintf("%q", newValue),
+			}
+		}
+
+		availabilityZoneDiff := func(oldZone, newZone string) machineproviders.FieldDiff {
+			return fieldDiff("placement.availabilityZone", oldZone, newZone)
+		}
+
+		subnetDiff := func(oldSubnet, newSubnet string) machineproviders.FieldDiff {
+			return fieldDiff("subnet.filters[0].values[0]", oldSubnet, newSubnet)
+		}
+
+		withMachineAnnotations := func(machine *machinev1beta1.Machine, machineAnnotations map[string]string) *machinev1beta1.Machine {
+			machine.SetAnnotations(machineAnnotations)
+			return machine
+		}
+
 		type getMachineInfosTableInput struct {
-			machines             []*machinev1beta1.Machine
-			failureDomains       map[int32]failuredomain.FailureDomain
-			expectedError        error
-			expectedMachineInfos []machineproviders.MachineInfo
-			expectedLogs         []testutils.LogEntry
+			machines                  []*machinev1beta1.Machine
+			failureDomains            map[int32]failuredomain.FailureDomain
+			unavailableFailureDomains []failuredomain.FailureDomain
+			ignoredProviderSpecFields []string
+			expectedError             error
+			expectedMachineInfos      []machineproviders.MachineInfo
+			expectedLogs              []testutils.LogEntry
 		}
 
 		DescribeTable("builds machine info based on the cluster state", func(in getMachineInfosTableInput) {
@@ -206,15 +231,17 @@ var _ = Describe("MachineProvider", func() {
 				BuildTemplate().OpenShiftMachineV1Beta1Machine
 			Expect(template).ToNot(BeNil())
 
-			providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template)
+			providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			provider := &openshiftMachineProvider{
-				client:               k8sClient,
-				indexToFailureDomain: in.failureDomains,
-				machineSelector:      cpms.Spec.Selector,
-				machineTemplate:      *template,
-				providerConfig:       providerConfig,
+				client:                    k8sClient,
+				indexToFailureDomain:      in.failureDomains,
+				unavailableFailureDomains: in.unavailableFailureDomains,
+				ignoredProviderSpecFields: in.ignoredProviderSpecFields,
+				machineSelector:           cpms.Spec.Selector,
+				machineTemplate:           *template,
+				providerConfig:            providerConfig,
 			}
 
 			machineInfos, err := provider.GetMachineInfos(ctx, logger.Logger())
@@ -364,6 +391,97 @@ var _ = Describe("MachineProvider", func() {
 					},
 				},
 			}),
+			Entry("with a Machine that records the template revision it was created from", getMachineInfosTableInput{
+				machines: []*machinev1beta1.Machine{
+					withMachineAnnotations(masterMachineBuilder.WithName(masterMachineName("0")).WithProviderSpecBuilder(providerSpecBuilder.WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-0"}).Build(),
+						map[string]string{annotations.MachineTemplateRevisionAnnotation: "5d8f7c9b4"},
+					),
+				},
+				failureDomains: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnet).Build()),
+				},
+				expectedMachineInfos: []machineproviders.MachineInfo{
+					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").
+						WithMachineAnnotations(map[string]string{annotations.MachineTemplateRevisionAnnotation: "5d8f7c9b4"}).
+						WithTemplateRevision("5d8f7c9b4").Build(),
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("0"),
+							"nodeName", "node-0",
+							"index", int32(0),
+							"ready", true,
+							"needsUpdate", false,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+				},
+			}),
+			Entry("with a Machine in an unavailable failure domain", getMachineInfosTableInput{
+				machines: []*machinev1beta1.Machine{
+					masterMachineBuilder.WithName(masterMachineName("0")).WithProviderSpecBuilder(providerSpecBuilder.WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-0"}).Build(),
+					masterMachineBuilder.WithName(masterMachineName("1")).WithProviderSpecBuilder(providerSpecBuilder.WithAvailabilityZone("us-east-1b").WithSubnet(usEast1bSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-1"}).Build(),
+					masterMachineBuilder.WithName(masterMachineName("2")).WithProviderSpecBuilder(providerSpecBuilder.WithAvailabilityZone("us-east-1c").WithSubnet(usEast1cSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-2"}).Build(),
+				},
+				failureDomains: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnet).Build()),
+					1: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1b").WithSubnet(usEast1bSubnet).Build()),
+					2: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnet).Build()),
+				},
+				unavailableFailureDomains: []failuredomain.FailureDomain{
+					failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1c").WithSubnet(usEast1cSubnet).Build()),
+				},
+				expectedMachineInfos: []machineproviders.MachineInfo{
+					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").Build(),
+					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").Build(),
+					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").WithNeedsUpdate(true).WithDiff(availabilityZoneDiff("us-east-1c", "us-east-1a"), subnetDiff("subnet-us-east-1c", "subnet-us-east-1a")).WithEvacuating(true).Build(),
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("0"),
+							"nodeName", "node-0",
+							"index", int32(0),
+							"ready", true,
+							"needsUpdate", false,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("1"),
+							"nodeName", "node-1",
+							"index", int32(1),
+							"ready", true,
+							"needsUpdate", false,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("2"),
+							"nodeName", "node-2",
+							"index", int32(2),
+							"ready", true,
+							"needsUpdate", true,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+				},
+			}),
 			Entry("with ready Machine that has now been deleted", getMachineInfosTableInput{
 				machines: []*machinev1beta1.Machine{
 					masterMachineBuilder.WithName(masterMachineName("0")).WithProviderSpecBuilder(providerSpecBuilder.WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnetbeta1)).
@@ -554,7 +672,125 @@ var _ = Describe("MachineProvider", func() {
 				},
 				expectedMachineInfos: []machineproviders.MachineInfo{
 					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").Build(),
-					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").WithNeedsUpdate(true).Build(),
+					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").WithNeedsUpdate(true).WithDiff(fieldDiff("instanceType", "different", "m6i.xlarge")).Build(),
+					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").Build(),
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("0"),
+							"nodeName", "node-0",
+							"index", int32(0),
+							"ready", true,
+							"needsUpdate", false,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("1"),
+							"nodeName", "node-1",
+							"index", int32(1),
+							"ready", true,
+							"needsUpdate", true,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("2"),
+							"nodeName", "node-2",
+							"index", int32(2),
+							"ready", true,
+							"needsUpdate", false,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+				},
+			}),
+			Entry("with one Machine with a different instance type, when the instance type is ignored", getMachineInfosTableInput{
+				machines: []*machinev1beta1.Machine{
+					masterMachineBuilder.WithName(masterMachineName("0")).WithProviderSpecBuilder(providerSpecBuilder.WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-0"}).Build(),
+					masterMachineBuilder.WithName(masterMachineName("1")).WithProviderSpecBuilder(providerSpecBuilder.WithInstanceType("different").WithAvailabilityZone("us-east-1b").WithSubnet(usEast1bSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-1"}).Build(),
+					masterMachineBuilder.WithName(masterMachineName("2")).WithProviderSpecBuilder(providerSpecBuilder.WithAvailabilityZone("us-east-1c").WithSubnet(usEast1cSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-2"}).Build(),
+				},
+				failureDomains: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnet).Build()),
+					1: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1b").WithSubnet(usEast1bSubnet).Build()),
+					2: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1c").WithSubnet(usEast1cSubnet).Build()),
+				},
+				ignoredProviderSpecFields: []string{"spec.providerSpec.value.instanceType"},
+				expectedMachineInfos: []machineproviders.MachineInfo{
+					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").Build(),
+					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").Build(),
+					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").Build(),
+				},
+				expectedLogs: []testutils.LogEntry{
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("0"),
+							"nodeName", "node-0",
+							"index", int32(0),
+							"ready", true,
+							"needsUpdate", false,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("1"),
+							"nodeName", "node-1",
+							"index", int32(1),
+							"ready", true,
+							"needsUpdate", false,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+					{
+						Level: 4,
+						KeysAndValues: []interface{}{
+							"machineName", masterMachineName("2"),
+							"nodeName", "node-2",
+							"index", int32(2),
+							"ready", true,
+							"needsUpdate", false,
+							"errorMessage", "",
+						},
+						Message: "Gathered Machine Info",
+					},
+				},
+			}),
+			Entry("with one Machine with a different instance type and subnet, when the instance type is ignored", getMachineInfosTableInput{
+				machines: []*machinev1beta1.Machine{
+					masterMachineBuilder.WithName(masterMachineName("0")).WithProviderSpecBuilder(providerSpecBuilder.WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-0"}).Build(),
+					masterMachineBuilder.WithName(masterMachineName("1")).WithProviderSpecBuilder(providerSpecBuilder.WithInstanceType("different").WithAvailabilityZone("us-east-1b").WithSubnet(usEast1aSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-1"}).Build(),
+					masterMachineBuilder.WithName(masterMachineName("2")).WithProviderSpecBuilder(providerSpecBuilder.WithAvailabilityZone("us-east-1c").WithSubnet(usEast1cSubnetbeta1)).
+						WithPhase("Running").WithNodeRef(corev1.ObjectReference{Name: "node-2"}).Build(),
+				},
+				failureDomains: map[int32]failuredomain.FailureDomain{
+					0: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnet).Build()),
+					1: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1b").WithSubnet(usEast1bSubnet).Build()),
+					2: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1c").WithSubnet(usEast1cSubnet).Build()),
+				},
+				ignoredProviderSpecFields: []string{"spec.providerSpec.value.instanceType"},
+				expectedMachineInfos: []machineproviders.MachineInfo{
+					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").Build(),
+					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").WithNeedsUpdate(true).WithDiff(subnetDiff("subnet-us-east-1a", "subnet-us-east-1b")).Build(),
 					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").Build(),
 				},
 				expectedLogs: []testutils.LogEntry{
@@ -611,7 +847,7 @@ var _ = Describe("MachineProvider", func() {
 					2: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1c").WithSubnet(usEast1cSubnet).Build()),
 				},
 				expectedMachineInfos: []machineproviders.MachineInfo{
-					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").WithNeedsUpdate(true).Build(),
+					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").WithNeedsUpdate(true).WithDiff(availabilityZoneDiff("us-east-1d", "us-east-1a"), subnetDiff("aws-subnet-12345678", "subnet-us-east-1a")).Build(),
 					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").Build(),
 					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").Build(),
 				},
@@ -673,7 +909,7 @@ var _ = Describe("MachineProvider", func() {
 				expectedMachineInfos: []machineproviders.MachineInfo{
 					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").Build(),
 					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").Build(),
-					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").WithNeedsUpdate(true).Build(),
+					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").WithNeedsUpdate(true).WithDiff(fieldDiff("instanceType", "different", "m6i.xlarge"), subnetDiff("aws-subnet-12345678", "subnet-us-east-1c")).Build(),
 					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("abcde-2")).WithNodeName("node-replacement-2").Build(),
 				},
 				expectedLogs: []testutils.LogEntry{
@@ -744,9 +980,9 @@ var _ = Describe("MachineProvider", func() {
 					2: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1a").WithSubnet(usEast1aSubnet).Build()),
 				},
 				expectedMachineInfos: []machineproviders.MachineInfo{
-					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").WithNeedsUpdate(true).Build(),
-					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").WithNeedsUpdate(true).Build(),
-					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").WithNeedsUpdate(true).Build(),
+					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").WithNeedsUpdate(true).WithDiff(availabilityZoneDiff("us-east-1a", "us-east-1b"), subnetDiff("subnet-us-east-1a", "subnet-us-east-1b")).Build(),
+					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").WithNeedsUpdate(true).WithDiff(availabilityZoneDiff("us-east-1b", "us-east-1c"), subnetDiff("subnet-us-east-1b", "subnet-us-east-1c")).Build(),
+					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").WithNeedsUpdate(true).WithDiff(availabilityZoneDiff("us-east-1c", "us-east-1a"), subnetDiff("subnet-us-east-1c", "subnet-us-east-1a")).Build(),
 				},
 				expectedLogs: []testutils.LogEntry{
 					{
@@ -988,7 +1224,7 @@ var _ = Describe("MachineProvider", func() {
 				expectedMachineInfos: []machineproviders.MachineInfo{
 					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").Build(),
 					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").Build(),
-					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").WithNeedsUpdate(true).Build(),
+					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").WithNeedsUpdate(true).WithDiff(availabilityZoneDiff("us-east-1a", "us-east-1c"), subnetDiff("subnet-us-east-1a", "subnet-us-east-1c")).Build(),
 				},
 				expectedLogs: []testutils.LogEntry{
 					{
@@ -1096,7 +1332,7 @@ var _ = Describe("MachineProvider", func() {
 				expectedMachineInfos: []machineproviders.MachineInfo{
 					readyMachineInfoBuilder.WithIndex(0).WithMachineName(masterMachineName("0")).WithNodeName("node-0").Build(),
 					readyMachineInfoBuilder.WithIndex(1).WithMachineName(masterMachineName("1")).WithNodeName("node-1").Build(),
-					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").WithNeedsUpdate(true).Build(),
+					readyMachineInfoBuilder.WithIndex(2).WithMachineName(masterMachineName("2")).WithNodeName("node-2").WithNeedsUpdate(true).WithDiff(availabilityZoneDiff("us-east-1b", "us-east-1a")).Build(),
 				},
 				expectedLogs: []testutils.LogEntry{
 					{
@@ -1259,6 +1495,7 @@ var _ = Describe("MachineProvider", func() {
 	Context("CreateMachine", func() {
 		var provider machineproviders.MachineProvider
 		var template machinev1.ControlPlaneMachineSetTemplate
+		var templateRevision string
 
 		assertCreatesMachine := func(index int32, expectedProviderConfig resourcebuilder.RawExtensionBuilder, clusterID, failureDomain string) {
 			Context(fmt.Sprintf("creating a machine in index %d", index), Ordered, func() {
@@ -1305,9 +1542,13 @@ var _ = Describe("MachineProvider", func() {
 					})
 
 					It("with annotations from the Machine template", func() {
-						Expect(machine.Annotations).To(Equal(
-							template.OpenShiftMachineV1Beta1Machine.ObjectMeta.Annotations,
-						))
+						for key, value := range template.OpenShiftMachineV1Beta1Machine.ObjectMeta.Annotations {
+							Expect(machine.Annotations).To(HaveKeyWithValue(key, value))
+						}
+					})
+
+					It("with the template revision", func() {
+						Expect(machine.Annotations).To(HaveKeyWithValue(annotations.MachineTemplateRevisionAnnotation, templateRevision))
 					})
 
 					It("with the correct owner reference", func() {
@@ -1340,6 +1581,7 @@ var _ = Describe("MachineProvider", func() {
 									"index", index,
 									"machineName", machine.Name,
 									"failureDomain", fmt.Sprintf("AWSFailureDomain{AvailabilityZone:%s, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[subnet-%s]}]}}", failureDomain, failureDomain),
+									"templateRevision", templateRevision,
 								},
 								Message: "Created machine",
 							},
@@ -1358,7 +1600,10 @@ var _ = Describe("MachineProvider", func() {
 					WithLabel(machinev1beta1.MachineClusterIDLabel, "cpms-aws-cluster-id").
 					BuildTemplate()
 
-				providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template.OpenShiftMachineV1Beta1Machine)
+				providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template.OpenShiftMachineV1Beta1Machine, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				templateRevision, err = util.TemplateHash(template, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				provider = &openshiftMachineProvider{
@@ -1368,8 +1613,9 @@ var _ = Describe("MachineProvider", func() {
 						1: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1b").WithSubnet(usEast1bSubnet).Build()),
 						2: failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1c").WithSubnet(usEast1cSubnet).Build()),
 					},
-					machineSelector: machinev1resourcebuilder.ControlPlaneMachineSet().Build().Spec.Selector,
-					machineTemplate: *template.OpenShiftMachineV1Beta1Machine,
+					machineSelector:  machinev1resourcebuilder.ControlPlaneMachineSet().Build().Spec.Selector,
+					machineTemplate:  *template.OpenShiftMachineV1Beta1Machine,
+					templateRevision: templateRevision,
 					ownerMetadata: metav1.ObjectMeta{
 						Name: ownerName,
 						UID:  ownerUID,
@@ -1435,7 +1681,7 @@ var _ = Describe("MachineProvider", func() {
 						WithLabel(machinev1beta1.MachineClusterIDLabel, "cpms-aws-cluster-id").
 						BuildTemplate()
 
-					providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template.OpenShiftMachineV1Beta1Machine)
+					providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template.OpenShiftMachineV1Beta1Machine, nil)
 					Expect(err).ToNot(HaveOccurred())
 
 					openshiftProvider, ok := provider.(*openshiftMachineProvider)
@@ -1467,7 +1713,7 @@ var _ = Describe("MachineProvider", func() {
 				BuildTemplate().OpenShiftMachineV1Beta1Machine
 			Expect(template).ToNot(BeNil())
 
-			providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template)
+			providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			machineProvider = &openshiftMachineProvider{
@@ -1611,4 +1857,135 @@ var _ = Describe("MachineProvider", func() {
 			})
 		})
 	})
+
+	Context("UpdateMachineInPlace", func() {
+		var machineProvider machineproviders.MachineProvider
+		var machine *machinev1beta1.Machine
+
+		machinesGVR := machinev1beta1.GroupVersion.WithResource("machines")
+
+		templateTaint := corev1.Taint{
+			Key:    "node-role.kubernetes.io/master",
+			Effect: corev1.TaintEffectNoSchedule,
+		}
+
+		getMachineInfo := func() machineproviders.MachineInfo {
+			machineInfos, err := machineProvider.GetMachineInfos(ctx, logger.Logger())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(machineInfos).To(HaveLen(1))
+
+			return machineInfos[0]
+		}
+
+		BeforeEach(func() {
+			By("Setting up the MachineProvider")
+			cpms := machinev1resourcebuilder.ControlPlaneMachineSet().Build()
+
+			template := machinev1resourcebuilder.OpenShiftMachineV1Beta1Template().
+				WithProviderSpecBuilder(machinev1beta1resourcebuilder.AWSProviderSpec()).
+				BuildTemplate().OpenShiftMachineV1Beta1Machine
+			Expect(template).ToNot(BeNil())
+
+			template.Spec.Taints = []corev1.Taint{templateTaint}
+
+			providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			machineProvider = &openshiftMachineProvider{
+				client:               k8sClient,
+				indexToFailureDomain: map[int32]failuredomain.FailureDomain{},
+				machineSelector:      cpms.Spec.Selector,
+				machineTemplate:      *template,
+				providerConfig:       providerConfig,
+			}
+
+			By("Creating a Machine without the template taint")
+			machine = machinev1beta1resourcebuilder.Machine().AsMaster().
+				WithName(fmt.Sprintf("%s-master-0", resourcebuilder.TestClusterIDValue)).
+				WithNamespace(namespaceName).
+				WithProviderSpecBuilder(machinev1beta1resourcebuilder.AWSProviderSpec()).
+				Build()
+			machine.Labels[machinev1beta1.MachineClusterIDLabel] = resourcebuilder.TestClusterIDValue
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+		})
+
+		It("reports that the Machine can be updated in place", func() {
+			machineInfo := getMachineInfo()
+
+			Expect(machineInfo.NeedsUpdate).To(BeFalse())
+			Expect(machineInfo.NeedsInPlaceUpdate).To(BeTrue())
+			Expect(machineInfo.InPlaceDiff).To(Equal([]machineproviders.FieldDiff{
+				{Path: "spec.taints", New: `[{"key":"node-role.kubernetes.io/master","effect":"NoSchedule"}]`},
+			}))
+		})
+
+		Context("with an existing machine", func() {
+			BeforeEach(func() {
+				Expect(machineProvider.UpdateMachineInPlace(ctx, logger.Logger(), getMachineInfo().MachineRef)).To(Succeed())
+			})
+
+			It("applies the template taints", func() {
+				Eventually(komega.Object(machine)).Should(HaveField("Spec.Taints", ConsistOf(templateTaint)))
+			})
+
+			It("no longer reports that the Machine can be updated in place", func() {
+				machineInfo := getMachineInfo()
+
+				Expect(machineInfo.NeedsInPlaceUpdate).To(BeFalse())
+				Expect(machineInfo.InPlaceDiff).To(BeEmpty())
+			})
+
+			It("logs that the machine was updated", func() {
+				Expect(logger.Entries()).To(ContainElement(HaveField("Message", "Updated machine in place")))
+			})
+		})
+
+		It("does not error with a non-existent machine", func() {
+			machineRef := &machineproviders.ObjectRef{
+				GroupVersionResource: machinesGVR,
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "unknown",
+					Namespace: namespaceName,
+				},
+			}
+
+			Expect(machineProvider.UpdateMachineInPlace(ctx, logger.Logger(), machineRef)).To(Succeed())
+		})
+
+		It("returns an error with an incorrect GVR", func() {
+			machineRef := getMachineInfo().MachineRef
+			machineRef.GroupVersionResource = machinev1beta1.GroupVersion.WithResource("wrong")
+
+			Expect(machineProvider.UpdateMachineInPlace(ctx, logger.Logger(), machineRef)).To(MatchError(errUnknownGroupVersionResource))
+		})
+	})
+
+	Context("GetFailureDomainMapping", func() {
+		It("returns the failure domain and reason for each index", func() {
+			usEast1a := failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1a").Build())
+			usEast1b := failuredomain.NewAWSFailureDomain(machinev1resourcebuilder.AWSFailureDomain().WithAvailabilityZone("us-east-1b").Build())
+
+			machineProvider := &openshiftMachineProvider{
+				indexToFailureDomain: map[int32]failuredomain.FailureDomain{
+					0: usEast1a,
+					1: usEast1b,
+				},
+				indexToFailureDomainReason: map[int32]machineproviders.FailureDomainMappingReason{
+					0: machineproviders.FailureDomainMappingReasonExistingMachine,
+					1: machineproviders.FailureDomainMappingReasonRebalanced,
+				},
+			}
+
+			Expect(machineProvider.GetFailureDomainMapping()).To(Equal(map[int32]machineproviders.FailureDomainMapping{
+				0: {FailureDomain: usEast1a.String(), Reason: machineproviders.FailureDomainMappingReasonExistingMachine},
+				1: {FailureDomain: usEast1b.String(), Reason: machineproviders.FailureDomainMappingReasonRebalanced},
+			}))
+		})
+
+		It("returns an empty mapping when no failure domains are configured", func() {
+			machineProvider := &openshiftMachineProvider{}
+
+			Expect(machineProvider.GetFailureDomainMapping()).To(BeEmpty())
+		})
+	})
 })
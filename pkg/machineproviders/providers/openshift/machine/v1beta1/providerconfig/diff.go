@@ -0,0 +1,262 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providerconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// providerSpecPath is the path of the provider spec within a Machine.
+// Paths of the fields within a FieldDiff are relative to the Machine.
+const providerSpecPath = "spec.providerSpec.value"
+
+// FieldDiff describes a single field that differs between two ProviderConfigs.
+type FieldDiff struct {
+	// Path is the JSON path of the field within the Machine, eg. `spec.providerSpec.value.instanceType`.
+	Path string
+
+	// Old is the JSON encoded value of the field within the old ProviderConfig.
+	// It is empty when the field is not set.
+	Old string
+
+	// New is the JSON encoded value of the field within the new ProviderConfig.
+	// It is empty when the field is not set.
+	New string
+}
+
+// unmarshalRawConfig decodes the raw config of the ProviderConfig into generic JSON values,
+// so that it can be compared field by field.
+func unmarshalRawConfig(p ProviderConfig) (interface{}, error) {
+	rawConfig, err := p.RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not get raw config: %w", err)
+	}
+
+	var value interface{}
+
+	if len(rawConfig) > 0 {
+		if err := json.Unmarshal(rawConfig, &value); err != nil {
+			return nil, fmt.Errorf("could not unmarshal raw config: %w", err)
+		}
+	}
+
+	return value, nil
+}
+
+// diffJSONValues recursively compares two generic JSON values and returns a FieldDiff for each
+// field that differs. Objects are compared key by key and lists are compared index by index,
+// any other values are compared as a whole.
+func diffJSONValues(path string, oldValue, newValue interface{}) ([]FieldDiff, error) {
+	oldObject, oldIsObject := oldValue.(map[string]interface{})
+	newObject, newIsObject := newValue.(map[string]interface{})
+
+	if oldIsObject && newIsObject {
+		return diffJSONObjects(path, oldObject, newObject)
+	}
+
+	oldList, oldIsList := oldValue.([]interface{})
+	newList, newIsList := newValue.([]interface{})
+
+	if oldIsList && newIsList {
+		return diffJSONLists(path, oldList, newList)
+	}
+
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil, nil
+	}
+
+	oldJSON, err := encodeJSONValue(oldValue)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode old value of %s: %w", path, err)
+	}
+
+	newJSON, err := encodeJSONValue(newValue)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode new value of %s: %w", path, err)
+	}
+
+	return []FieldDiff{{Path: path, Old: oldJSON, New: newJSON}}, nil
+}
+
+// diffJSONObjects compares each key present in either of the objects, in sorted order.
+func diffJSONObjects(path string, oldObject, newObject map[string]interface{}) ([]FieldDiff, error) {
+	keys := []string{}
+
+	for key := range oldObject {
+		keys = append(keys, key)
+	}
+
+	for key := range newObject {
+		if _, ok := oldObject[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	diffs := []FieldDiff{}
+
+	for _, key := range keys {
+		keyDiffs, err := diffJSONValues(fmt.Sprintf("%s.%s", path, key), oldObject[key], newObject[key])
+		if err != nil {
+			return nil, err
+		}
+
+		diffs = append(diffs, keyDiffs...)
+	}
+
+	return nilIfEmpty(diffs), nil
+}
+
+// diffJSONLists compares the lists index by index. Any index beyond the end of one of the lists
+// is reported as unset within that list.
+func diffJSONLists(path string, oldList, newList []interface{}) ([]FieldDiff, error) {
+	length := len(oldList)
+	if len(newList) > length {
+		length = len(newList)
+	}
+
+	diffs := []FieldDiff{}
+
+	for i := 0; i < length; i++ {
+		var oldItem, newItem interface{}
+
+		if i < len(oldList) {
+			oldItem = oldList[i]
+		}
+
+		if i < len(newList) {
+			newItem = newList[i]
+		}
+
+		itemDiffs, err := diffJSONValues(fmt.Sprintf("%s[%d]", path, i), oldItem, newItem)
+		if err != nil {
+			return nil, err
+		}
+
+		diffs = append(diffs, itemDiffs...)
+	}
+
+	return nilIfEmpty(diffs), nil
+}
+
+// encodeJSONValue encodes a generic JSON value. Unset values are encoded as an empty string.
+func encodeJSONValue(value interface{}) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+
+	out, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal value: %w", err)
+	}
+
+	return string(out), nil
+}
+
+// nilIfEmpty returns nil when there are no diffs, so that equal configs consistently have no diff.
+func nilIfEmpty(diffs []FieldDiff) []FieldDiff {
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	return diffs
+}
+
+// IgnoreFieldDiffs removes each FieldDiff for a field that is covered by one of the ignored paths.
+// It returns nil when every FieldDiff is ignored.
+func IgnoreFieldDiffs(diffs []FieldDiff, ignoredPaths []string) []FieldDiff {
+	out := []FieldDiff{}
+
+	for _, diff := range diffs {
+		if !isCoveredByAny(diff.Path, ignoredPaths) {
+			out = append(out, diff)
+		}
+	}
+
+	return nilIfEmpty(out)
+}
+
+// SignificantFieldsCoveredBy returns the significant fields of the platform affected by ignoring the given path.
+// This is the case when the path is, contains, or is nested within, a significant field.
+func SignificantFieldsCoveredBy(platform configv1.PlatformType, path string) []string {
+	out := []string{}
+
+	for _, field := range significantFields(platform) {
+		if pathCovers(path, field) || pathCovers(field, path) {
+			out = append(out, field)
+		}
+	}
+
+	return out
+}
+
+// significantFields lists the provider spec fields, for each platform, that determine where the Machine runs,
+// and the compute, storage and network resources it has.
+// Ignoring changes to these fields would leave Machines running with an outdated configuration.
+func significantFields(platform configv1.PlatformType) []string {
+	var fields []string
+
+	switch platform {
+	case configv1.AWSPlatformType:
+		fields = []string{"ami", "blockDevices", "iamInstanceProfile", "instanceType", "placement", "securityGroups", "subnet"}
+	case configv1.AzurePlatformType:
+		fields = []string{"image", "osDisk", "subnet", "vmSize", "vnet", "zone"}
+	case configv1.GCPPlatformType:
+		fields = []string{"disks", "machineType", "networkInterfaces", "zone"}
+	case configv1.OpenStackPlatformType:
+		fields = []string{"availabilityZone", "flavor", "image", "networks", "ports", "rootVolume"}
+	case configv1.VSpherePlatformType:
+		fields = []string{"diskGiB", "memoryMiB", "network", "numCPUs", "template", "workspace"}
+	case configv1.NutanixPlatformType:
+		fields = []string{"cluster", "image", "memorySize", "subnets", "systemDiskSize", "vcpuSockets", "vcpusPerSocket"}
+	}
+
+	out := []string{}
+	for _, field := range fields {
+		out = append(out, fmt.Sprintf("%s.%s", providerSpecPath, field))
+	}
+
+	return out
+}
+
+// isCoveredByAny checks whether the path is covered by any of the given paths.
+func isCoveredByAny(path string, paths []string) bool {
+	for _, p := range paths {
+		if pathCovers(p, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pathCovers checks whether the field identified by the path is, or is nested within, the field identified by
+// the parent path.
+func pathCovers(parent, path string) bool {
+	if path == parent {
+		return true
+	}
+
+	return strings.HasPrefix(path, parent+".") || strings.HasPrefix(path, parent+"[")
+}
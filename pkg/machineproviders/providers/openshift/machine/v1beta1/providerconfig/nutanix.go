@@ -0,0 +1,89 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providerconfig
+
+import (
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NutanixProviderConfig holds the provider spec of a Nutanix Machine.
+// It allows external code to extract and inject failure domain information,
+// as well as gathering the stored config.
+type NutanixProviderConfig struct {
+	providerConfig machinev1.NutanixMachineProviderConfig
+}
+
+// InjectFailureDomain returns a new NutanixProviderConfig configured with the failure domain.
+// The subnets are replaced with those of the failure domain, so that the failure domain extracted
+// from the new config matches the injected failure domain.
+func (n NutanixProviderConfig) InjectFailureDomain(fd failuredomain.NutanixFailureDomain) NutanixProviderConfig {
+	newNutanixProviderConfig := n
+
+	newNutanixProviderConfig.providerConfig.Cluster = fd.Cluster
+	newNutanixProviderConfig.providerConfig.Subnets = nil
+
+	if len(fd.Subnets) > 0 {
+		newNutanixProviderConfig.providerConfig.Subnets = append([]machinev1.NutanixResourceIdentifier{}, fd.Subnets...)
+	}
+
+	return newNutanixProviderConfig
+}
+
+// ExtractFailureDomain returns a NutanixFailureDomain based on the failure domain
+// information stored within the NutanixProviderConfig.
+func (n NutanixProviderConfig) ExtractFailureDomain() failuredomain.NutanixFailureDomain {
+	fd := failuredomain.NutanixFailureDomain{
+		Cluster: n.providerConfig.Cluster,
+	}
+
+	if len(n.providerConfig.Subnets) > 0 {
+		fd.Subnets = append([]machinev1.NutanixResourceIdentifier{}, n.providerConfig.Subnets...)
+	}
+
+	return fd
+}
+
+// Config returns the stored NutanixMachineProviderConfig.
+func (n NutanixProviderConfig) Config() machinev1.NutanixMachineProviderConfig {
+	return n.providerConfig
+}
+
+// newNutanixProviderConfig creates a Nutanix type ProviderConfig from the raw extension.
+// It should return an error if the provided RawExtension does not represent a NutanixProviderConfig.
+func newNutanixProviderConfig(raw *runtime.RawExtension) (ProviderConfig, error) {
+	var nutanixMachineProviderConfig machinev1.NutanixMachineProviderConfig
+	if err := json.Unmarshal(raw.Raw, &nutanixMachineProviderConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Nutanix provider config: %w", err)
+	}
+
+	nutanixProviderConfig := NutanixProviderConfig{
+		providerConfig: nutanixMachineProviderConfig,
+	}
+
+	config := providerConfig{
+		platformType: configv1.NutanixPlatformType,
+		nutanix:      nutanixProviderConfig,
+	}
+
+	return config, nil
+}
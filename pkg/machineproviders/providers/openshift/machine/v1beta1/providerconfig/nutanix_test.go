@@ -0,0 +1,104 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providerconfig
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+	testmachinev1resourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machine/v1"
+)
+
+var _ = Describe("Nutanix Provider Config", func() {
+	var providerConfig NutanixProviderConfig
+
+	fd1 := failuredomain.NutanixFailureDomain{
+		Cluster: testmachinev1resourcebuilder.NutanixResourceName("pe-1"),
+		Subnets: []machinev1.NutanixResourceIdentifier{testmachinev1resourcebuilder.NutanixResourceName("subnet-1")},
+	}
+	fd2 := failuredomain.NutanixFailureDomain{
+		Cluster: testmachinev1resourcebuilder.NutanixResourceName("pe-2"),
+		Subnets: []machinev1.NutanixResourceIdentifier{
+			testmachinev1resourcebuilder.NutanixResourceName("subnet-2"),
+			testmachinev1resourcebuilder.NutanixResourceName("subnet-3"),
+		},
+	}
+
+	BeforeEach(func() {
+		providerConfig = NutanixProviderConfig{
+			providerConfig: *testmachinev1resourcebuilder.NutanixProviderSpec().WithCluster("pe-1").WithSubnets("subnet-1").Build(),
+		}
+	})
+
+	Context("ExtractFailureDomain", func() {
+		It("returns the configured failure domain", func() {
+			Expect(providerConfig.ExtractFailureDomain()).To(Equal(fd1))
+		})
+
+		It("does not return subnets when no subnets are configured", func() {
+			providerConfig.providerConfig.Subnets = nil
+
+			Expect(providerConfig.ExtractFailureDomain()).To(Equal(failuredomain.NutanixFailureDomain{
+				Cluster: fd1.Cluster,
+			}))
+		})
+	})
+
+	Context("when the failuredomain is changed after initialisation", func() {
+		var changedProviderConfig NutanixProviderConfig
+
+		BeforeEach(func() {
+			changedProviderConfig = providerConfig.InjectFailureDomain(fd2)
+		})
+
+		Context("ExtractFailureDomain", func() {
+			It("returns the changed failure domain from the changed config", func() {
+				Expect(changedProviderConfig.ExtractFailureDomain()).To(Equal(fd2))
+			})
+
+			It("returns the original failure domain from the original config", func() {
+				Expect(providerConfig.ExtractFailureDomain()).To(Equal(fd1))
+			})
+		})
+
+		It("retains the rest of the provider spec", func() {
+			Expect(changedProviderConfig.Config().Image).To(Equal(providerConfig.Config().Image))
+			Expect(changedProviderConfig.Config().VCPUSockets).To(Equal(providerConfig.Config().VCPUSockets))
+		})
+	})
+
+	Context("newNutanixProviderConfig", func() {
+		var providerConfig ProviderConfig
+
+		BeforeEach(func() {
+			var err error
+			providerConfig, err = newNutanixProviderConfig(testmachinev1resourcebuilder.NutanixProviderSpec().BuildRawExtension())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("sets the type to Nutanix", func() {
+			Expect(providerConfig.Type()).To(Equal(configv1.NutanixPlatformType))
+		})
+
+		It("returns the correct Nutanix config", func() {
+			Expect(providerConfig.Nutanix().Config()).To(Equal(*testmachinev1resourcebuilder.NutanixProviderSpec().Build()))
+		})
+	})
+})
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providerconfig
+
+import (
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1alpha1 "github.com/openshift/api/machine/v1alpha1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// OpenStackProviderConfig holds the provider spec of an OpenStack Machine.
+// It allows external code to extract and inject failure domain information,
+// as well as gathering the stored config.
+type OpenStackProviderConfig struct {
+	providerConfig machinev1alpha1.OpenstackProviderSpec
+}
+
+// InjectFailureDomain returns a new OpenStackProviderConfig configured with the failure domain.
+// The root volume availability zone is only injected when the provider spec configures a root volume.
+// When the failure domain does not configure a root volume, the root volume availability zone is cleared,
+// so that the failure domain extracted from the new config matches the injected failure domain.
+func (o OpenStackProviderConfig) InjectFailureDomain(fd failuredomain.OpenStackFailureDomain) OpenStackProviderConfig {
+	newOpenStackProviderConfig := o
+
+	newOpenStackProviderConfig.providerConfig.AvailabilityZone = fd.AvailabilityZone
+
+	if o.providerConfig.RootVolume != nil {
+		rootVolume := *o.providerConfig.RootVolume
+		rootVolume.Zone = ""
+
+		if fd.RootVolume != nil {
+			rootVolume.Zone = fd.RootVolume.AvailabilityZone
+		}
+
+		newOpenStackProviderConfig.providerConfig.RootVolume = &rootVolume
+	}
+
+	return newOpenStackProviderConfig
+}
+
+// ExtractFailureDomain returns an OpenStackFailureDomain based on the failure domain
+// information stored within the OpenStackProviderConfig.
+func (o OpenStackProviderConfig) ExtractFailureDomain() failuredomain.OpenStackFailureDomain {
+	fd := failuredomain.OpenStackFailureDomain{
+		AvailabilityZone: o.providerConfig.AvailabilityZone,
+	}
+
+	if o.providerConfig.RootVolume != nil && o.providerConfig.RootVolume.Zone != "" {
+		fd.RootVolume = &failuredomain.OpenStackFailureDomainRootVolume{
+			AvailabilityZone: o.providerConfig.RootVolume.Zone,
+		}
+	}
+
+	return fd
+}
+
+// Config returns the stored OpenstackProviderSpec.
+func (o OpenStackProviderConfig) Config() machinev1alpha1.OpenstackProviderSpec {
+	return o.providerConfig
+}
+
+// newOpenStackProviderConfig creates an OpenStack type ProviderConfig from the raw extension.
+// It should return an error if the provided RawExtension does not represent an OpenStackProviderConfig.
+func newOpenStackProviderConfig(raw *runtime.RawExtension) (ProviderConfig, error) {
+	var openstackProviderSpec machinev1alpha1.OpenstackProviderSpec
+	if err := json.Unmarshal(raw.Raw, &openstackProviderSpec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal OpenStack provider config: %w", err)
+	}
+
+	openstackProviderConfig := OpenStackProviderConfig{
+		providerConfig: openstackProviderSpec,
+	}
+
+	config := providerConfig{
+		platformType: configv1.OpenStackPlatformType,
+		openstack:    openstackProviderConfig,
+	}
+
+	return config, nil
+}
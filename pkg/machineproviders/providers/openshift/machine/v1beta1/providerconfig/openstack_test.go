@@ -0,0 +1,181 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providerconfig
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1alpha1 "github.com/openshift/api/machine/v1alpha1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("OpenStack Provider Config", func() {
+	var providerConfig OpenStackProviderConfig
+
+	az1 := "az1"
+	az2 := "az2"
+	volumeAZ1 := "volume-az1"
+	volumeAZ2 := "volume-az2"
+
+	BeforeEach(func() {
+		providerConfig = OpenStackProviderConfig{
+			providerConfig: machinev1alpha1.OpenstackProviderSpec{
+				AvailabilityZone: az1,
+				RootVolume: &machinev1alpha1.RootVolume{
+					Size: 100,
+					Zone: volumeAZ1,
+				},
+			},
+		}
+	})
+
+	Context("ExtractFailureDomain", func() {
+		It("returns the configured failure domain", func() {
+			expected := failuredomain.OpenStackFailureDomain{
+				AvailabilityZone: az1,
+				RootVolume: &failuredomain.OpenStackFailureDomainRootVolume{
+					AvailabilityZone: volumeAZ1,
+				},
+			}
+
+			Expect(providerConfig.ExtractFailureDomain()).To(Equal(expected))
+		})
+
+		It("does not return a root volume when the root volume has no availability zone", func() {
+			providerConfig.providerConfig.RootVolume.Zone = ""
+
+			Expect(providerConfig.ExtractFailureDomain()).To(Equal(failuredomain.OpenStackFailureDomain{
+				AvailabilityZone: az1,
+			}))
+		})
+	})
+
+	Context("when the failuredomain is changed after initialisation", func() {
+		var changedProviderConfig OpenStackProviderConfig
+
+		BeforeEach(func() {
+			changedFailureDomain := failuredomain.OpenStackFailureDomain{
+				AvailabilityZone: az2,
+				RootVolume: &failuredomain.OpenStackFailureDomainRootVolume{
+					AvailabilityZone: volumeAZ2,
+				},
+			}
+
+			changedProviderConfig = providerConfig.InjectFailureDomain(changedFailureDomain)
+		})
+
+		Context("ExtractFailureDomain", func() {
+			It("returns the changed failure domain from the changed config", func() {
+				expected := failuredomain.OpenStackFailureDomain{
+					AvailabilityZone: az2,
+					RootVolume: &failuredomain.OpenStackFailureDomainRootVolume{
+						AvailabilityZone: volumeAZ2,
+					},
+				}
+
+				Expect(changedProviderConfig.ExtractFailureDomain()).To(Equal(expected))
+			})
+
+			It("returns the original failure domain from the original config", func() {
+				expected := failuredomain.OpenStackFailureDomain{
+					AvailabilityZone: az1,
+					RootVolume: &failuredomain.OpenStackFailureDomainRootVolume{
+						AvailabilityZone: volumeAZ1,
+					},
+				}
+
+				Expect(providerConfig.ExtractFailureDomain()).To(Equal(expected))
+			})
+		})
+
+		It("retains the rest of the root volume configuration", func() {
+			Expect(changedProviderConfig.Config().RootVolume.Size).To(Equal(100))
+		})
+	})
+
+	Context("when a failure domain without a root volume is injected", func() {
+		var changedProviderConfig OpenStackProviderConfig
+
+		BeforeEach(func() {
+			changedProviderConfig = providerConfig.InjectFailureDomain(failuredomain.OpenStackFailureDomain{
+				AvailabilityZone: az2,
+			})
+		})
+
+		It("clears the root volume availability zone", func() {
+			Expect(changedProviderConfig.Config().RootVolume).ToNot(BeNil())
+			Expect(changedProviderConfig.Config().RootVolume.Zone).To(BeEmpty())
+		})
+
+		It("returns the injected failure domain", func() {
+			Expect(changedProviderConfig.ExtractFailureDomain()).To(Equal(failuredomain.OpenStackFailureDomain{
+				AvailabilityZone: az2,
+			}))
+		})
+	})
+
+	Context("when the provider spec does not configure a root volume", func() {
+		BeforeEach(func() {
+			providerConfig.providerConfig.RootVolume = nil
+		})
+
+		It("does not add a root volume when a failure domain is injected", func() {
+			changedProviderConfig := providerConfig.InjectFailureDomain(failuredomain.OpenStackFailureDomain{
+				AvailabilityZone: az2,
+				RootVolume: &failuredomain.OpenStackFailureDomainRootVolume{
+					AvailabilityZone: volumeAZ2,
+				},
+			})
+
+			Expect(changedProviderConfig.Config().RootVolume).To(BeNil())
+			Expect(changedProviderConfig.Config().AvailabilityZone).To(Equal(az2))
+		})
+	})
+
+	Context("newOpenStackProviderConfig", func() {
+		var providerConfig ProviderConfig
+		var expectedOpenStackConfig machinev1alpha1.OpenstackProviderSpec
+
+		BeforeEach(func() {
+			expectedOpenStackConfig = machinev1alpha1.OpenstackProviderSpec{
+				Flavor:           "m1.xlarge",
+				Image:            "rhcos",
+				AvailabilityZone: az1,
+			}
+
+			raw, err := json.Marshal(expectedOpenStackConfig)
+			Expect(err).ToNot(HaveOccurred())
+
+			providerConfig, err = newOpenStackProviderConfig(&runtime.RawExtension{Raw: raw})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("sets the type to OpenStack", func() {
+			Expect(providerConfig.Type()).To(Equal(configv1.OpenStackPlatformType))
+		})
+
+		It("returns the correct OpenStack config", func() {
+			Expect(providerConfig.OpenStack()).ToNot(BeNil())
+			Expect(providerConfig.OpenStack().Config()).To(Equal(expectedOpenStackConfig))
+		})
+	})
+})
@@ -64,6 +64,10 @@ type ProviderConfig interface {
 	// or nil if there are none.
 	Diff(ProviderConfig) ([]string, error)
 
+	// StructuredDiff compares the ProviderConfig to another ProviderConfig and returns
+	// each field that differs, with its value in both ProviderConfigs, or nil if there are none.
+	StructuredDiff(ProviderConfig) ([]FieldDiff, error)
+
 	// RawConfig marshalls the configuration into a JSON byte slice.
 	RawConfig() ([]byte, error)
 
@@ -79,31 +83,49 @@ type ProviderConfig interface {
 	// GCP returns the GCPProviderConfig if the platform type is GCP.
 	GCP() GCPProviderConfig
 
+	// OpenStack returns the OpenStackProviderConfig if the platform type is OpenStack.
+	OpenStack() OpenStackProviderConfig
+
+	// VSphere returns the VSphereProviderConfig if the platform type is VSphere.
+	VSphere() VSphereProviderConfig
+
+	// Nutanix returns the NutanixProviderConfig if the platform type is Nutanix.
+	Nutanix() NutanixProviderConfig
+
 	// Generic returns the GenericProviderConfig if we are on a platform that is using generic provider abstraction.
 	Generic() GenericProviderConfig
 }
 
+// InfrastructureRequired returns whether the cluster Infrastructure is required to translate between the failure
+// domains and the provider configs of the platform. This is the case when the topology of the failure domains is
+// defined within the Infrastructure.
+func InfrastructureRequired(platformType configv1.PlatformType) bool {
+	return platformType == configv1.VSpherePlatformType
+}
+
 // NewProviderConfigFromMachineTemplate creates a new ProviderConfig from the provided machine template.
-func NewProviderConfigFromMachineTemplate(tmpl machinev1.OpenShiftMachineV1Beta1MachineTemplate) (ProviderConfig, error) {
+// The infrastructure is only required on platforms for which InfrastructureRequired is true, and may otherwise be nil.
+func NewProviderConfigFromMachineTemplate(tmpl machinev1.OpenShiftMachineV1Beta1MachineTemplate, infrastructure *configv1.Infrastructure) (ProviderConfig, error) {
 	platformType, err := getPlatformTypeFromMachineTemplate(tmpl)
 	if err != nil {
 		return nil, fmt.Errorf("could not determine platform type: %w", err)
 	}
 
-	return newProviderConfigFromProviderSpec(tmpl.Spec.ProviderSpec, platformType)
+	return newProviderConfigFromProviderSpec(tmpl.Spec.ProviderSpec, platformType, infrastructure)
 }
 
 // NewProviderConfigFromMachineSpec creates a new ProviderConfig from the provided machineSpec object.
-func NewProviderConfigFromMachineSpec(machineSpec machinev1beta1.MachineSpec) (ProviderConfig, error) {
+// The infrastructure is only required on platforms for which InfrastructureRequired is true, and may otherwise be nil.
+func NewProviderConfigFromMachineSpec(machineSpec machinev1beta1.MachineSpec, infrastructure *configv1.Infrastructure) (ProviderConfig, error) {
 	platformType, err := getPlatformTypeFromProviderSpec(machineSpec.ProviderSpec)
 	if err != nil {
 		return nil, fmt.Errorf("could not determine platform type: %w", err)
 	}
 
-	return newProviderConfigFromProviderSpec(machineSpec.ProviderSpec, platformType)
+	return newProviderConfigFromProviderSpec(machineSpec.ProviderSpec, platformType, infrastructure)
 }
 
-func newProviderConfigFromProviderSpec(providerSpec machinev1beta1.ProviderSpec, platformType configv1.PlatformType) (ProviderConfig, error) {
+func newProviderConfigFromProviderSpec(providerSpec machinev1beta1.ProviderSpec, platformType configv1.PlatformType, infrastructure *configv1.Infrastructure) (ProviderConfig, error) {
 	if providerSpec.Value == nil {
 		return nil, errNilProviderSpec
 	}
@@ -115,6 +137,12 @@ func newProviderConfigFromProviderSpec(providerSpec machinev1beta1.ProviderSpec,
 		return newAzureProviderConfig(providerSpec.Value)
 	case configv1.GCPPlatformType:
 		return newGCPProviderConfig(providerSpec.Value)
+	case configv1.OpenStackPlatformType:
+		return newOpenStackProviderConfig(providerSpec.Value)
+	case configv1.VSpherePlatformType:
+		return newVSphereProviderConfig(providerSpec.Value, infrastructure)
+	case configv1.NutanixPlatformType:
+		return newNutanixProviderConfig(providerSpec.Value)
 	case configv1.NonePlatformType:
 		return nil, fmt.Errorf("%w: %s", errUnsupportedPlatformType, platformType)
 	default:
@@ -128,6 +156,9 @@ type providerConfig struct {
 	aws          AWSProviderConfig
 	azure        AzureProviderConfig
 	gcp          GCPProviderConfig
+	openstack    OpenStackProviderConfig
+	vsphere      VSphereProviderConfig
+	nutanix      NutanixProviderConfig
 	generic      GenericProviderConfig
 }
 
@@ -148,6 +179,17 @@ func (p providerConfig) InjectFailureDomain(fd failuredomain.FailureDomain) (Pro
 		newConfig.azure = p.Azure().InjectFailureDomain(fd.Azure())
 	case configv1.GCPPlatformType:
 		newConfig.gcp = p.GCP().InjectFailureDomain(fd.GCP())
+	case configv1.OpenStackPlatformType:
+		newConfig.openstack = p.OpenStack().InjectFailureDomain(fd.OpenStack())
+	case configv1.VSpherePlatformType:
+		vsphereConfig, err := p.VSphere().InjectFailureDomain(fd.VSphere())
+		if err != nil {
+			return nil, fmt.Errorf("could not inject failure domain: %w", err)
+		}
+
+		newConfig.vsphere = vsphereConfig
+	case configv1.NutanixPlatformType:
+		newConfig.nutanix = p.Nutanix().InjectFailureDomain(fd.Nutanix())
 	case configv1.NonePlatformType:
 		return nil, fmt.Errorf("%w: %s", errUnsupportedPlatformType, p.platformType)
 	}
@@ -164,6 +206,12 @@ func (p providerConfig) ExtractFailureDomain() failuredomain.FailureDomain {
 		return failuredomain.NewAzureFailureDomain(p.Azure().ExtractFailureDomain())
 	case configv1.GCPPlatformType:
 		return failuredomain.NewGCPFailureDomain(p.GCP().ExtractFailureDomain())
+	case configv1.OpenStackPlatformType:
+		return failuredomain.NewOpenStackFailureDomain(p.OpenStack().ExtractFailureDomain())
+	case configv1.VSpherePlatformType:
+		return failuredomain.NewVSphereFailureDomain(p.VSphere().ExtractFailureDomain())
+	case configv1.NutanixPlatformType:
+		return failuredomain.NewNutanixFailureDomain(p.Nutanix().ExtractFailureDomain())
 	case configv1.NonePlatformType:
 		return nil
 	default:
@@ -189,6 +237,12 @@ func (p providerConfig) Diff(other ProviderConfig) ([]string, error) {
 		return deep.Equal(p.azure.providerConfig, other.Azure().providerConfig), nil
 	case configv1.GCPPlatformType:
 		return deep.Equal(p.gcp.providerConfig, other.GCP().providerConfig), nil
+	case configv1.OpenStackPlatformType:
+		return deep.Equal(p.openstack.providerConfig, other.OpenStack().providerConfig), nil
+	case configv1.VSpherePlatformType:
+		return deep.Equal(p.vsphere.providerConfig, other.VSphere().providerConfig), nil
+	case configv1.NutanixPlatformType:
+		return deep.Equal(p.nutanix.providerConfig, other.Nutanix().providerConfig), nil
 	case configv1.NonePlatformType:
 		return nil, errUnsupportedPlatformType
 	default:
@@ -196,6 +250,37 @@ func (p providerConfig) Diff(other ProviderConfig) ([]string, error) {
 	}
 }
 
+// StructuredDiff compares the ProviderConfig to another ProviderConfig and returns each field that
+// differs, or nil if there are none. The current ProviderConfig provides the old values, and the other
+// ProviderConfig provides the new values. The fields are identified by their JSON path within the
+// provider spec, and are sorted by path.
+func (p providerConfig) StructuredDiff(other ProviderConfig) ([]FieldDiff, error) {
+	if other == nil {
+		return nil, nil
+	}
+
+	if p.platformType != other.Type() {
+		return nil, errMismatchedPlatformTypes
+	}
+
+	oldValue, err := unmarshalRawConfig(p)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode old provider config: %w", err)
+	}
+
+	newValue, err := unmarshalRawConfig(other)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode new provider config: %w", err)
+	}
+
+	diffs, err := diffJSONValues(providerSpecPath, oldValue, newValue)
+	if err != nil {
+		return nil, fmt.Errorf("could not compare provider configs: %w", err)
+	}
+
+	return diffs, nil
+}
+
 // Equal compares two ProviderConfigs to determine whether or not they are equal.
 func (p providerConfig) Equal(other ProviderConfig) (bool, error) {
 	if other == nil {
@@ -213,6 +298,12 @@ func (p providerConfig) Equal(other ProviderConfig) (bool, error) {
 		return reflect.DeepEqual(p.azure.providerConfig, other.Azure().providerConfig), nil
 	case configv1.GCPPlatformType:
 		return reflect.DeepEqual(p.gcp.providerConfig, other.GCP().providerConfig), nil
+	case configv1.OpenStackPlatformType:
+		return reflect.DeepEqual(p.openstack.providerConfig, other.OpenStack().providerConfig), nil
+	case configv1.VSpherePlatformType:
+		return reflect.DeepEqual(p.vsphere.providerConfig, other.VSphere().providerConfig), nil
+	case configv1.NutanixPlatformType:
+		return reflect.DeepEqual(p.nutanix.providerConfig, other.Nutanix().providerConfig), nil
 	case configv1.NonePlatformType:
 		return false, errUnsupportedPlatformType
 	default:
@@ -234,6 +325,12 @@ func (p providerConfig) RawConfig() ([]byte, error) {
 		rawConfig, err = json.Marshal(p.azure.providerConfig)
 	case configv1.GCPPlatformType:
 		rawConfig, err = json.Marshal(p.gcp.providerConfig)
+	case configv1.OpenStackPlatformType:
+		rawConfig, err = json.Marshal(p.openstack.providerConfig)
+	case configv1.VSpherePlatformType:
+		rawConfig, err = json.Marshal(p.vsphere.providerConfig)
+	case configv1.NutanixPlatformType:
+		rawConfig, err = json.Marshal(p.nutanix.providerConfig)
 	case configv1.NonePlatformType:
 		return nil, errUnsupportedPlatformType
 	default:
@@ -267,6 +364,21 @@ func (p providerConfig) GCP() GCPProviderConfig {
 	return p.gcp
 }
 
+// OpenStack returns the OpenStackProviderConfig if the platform type is OpenStack.
+func (p providerConfig) OpenStack() OpenStackProviderConfig {
+	return p.openstack
+}
+
+// VSphere returns the VSphereProviderConfig if the platform type is VSphere.
+func (p providerConfig) VSphere() VSphereProviderConfig {
+	return p.vsphere
+}
+
+// Nutanix returns the NutanixProviderConfig if the platform type is Nutanix.
+func (p providerConfig) Nutanix() NutanixProviderConfig {
+	return p.nutanix
+}
+
 // Generic returns the GenericProviderConfig if the platform type is generic.
 func (p providerConfig) Generic() GenericProviderConfig {
 	return p.generic
@@ -276,9 +388,12 @@ func (p providerConfig) Generic() GenericProviderConfig {
 // When platform is unknown, it returns "UnknownPlatform".
 func getPlatformTypeFromProviderSpecKind(kind string) configv1.PlatformType {
 	var providerSpecKindToPlatformType = map[string]configv1.PlatformType{
-		"AWSMachineProviderConfig": configv1.AWSPlatformType,
-		"AzureMachineProviderSpec": configv1.AzurePlatformType,
-		"GCPMachineProviderSpec":   configv1.GCPPlatformType,
+		"AWSMachineProviderConfig":     configv1.AWSPlatformType,
+		"AzureMachineProviderSpec":     configv1.AzurePlatformType,
+		"GCPMachineProviderSpec":       configv1.GCPPlatformType,
+		"OpenstackProviderSpec":        configv1.OpenStackPlatformType,
+		"VSphereMachineProviderSpec":   configv1.VSpherePlatformType,
+		"NutanixMachineProviderConfig": configv1.NutanixPlatformType,
 	}
 
 	platformType, ok := providerSpecKindToPlatformType[kind]
@@ -326,11 +441,11 @@ func getPlatformTypeFromProviderSpec(providerSpec machinev1beta1.ProviderSpec) (
 }
 
 // ExtractFailureDomainsFromMachines creates list of FailureDomains extracted from the provided list of machines.
-func ExtractFailureDomainsFromMachines(machines []machinev1beta1.Machine) ([]failuredomain.FailureDomain, error) {
+func ExtractFailureDomainsFromMachines(machines []machinev1beta1.Machine, infrastructure *configv1.Infrastructure) ([]failuredomain.FailureDomain, error) {
 	machineFailureDomains := failuredomain.NewSet()
 
 	for _, machine := range machines {
-		providerconfig, err := NewProviderConfigFromMachineSpec(machine.Spec)
+		providerconfig, err := NewProviderConfigFromMachineSpec(machine.Spec, infrastructure)
 		if err != nil {
 			return nil, fmt.Errorf("error getting failure domain from machine %s: %w", machine.Name, err)
 		}
@@ -342,8 +457,8 @@ func ExtractFailureDomainsFromMachines(machines []machinev1beta1.Machine) ([]fai
 }
 
 // ExtractFailureDomainFromMachine FailureDomain extracted from the provided machine.
-func ExtractFailureDomainFromMachine(machine machinev1beta1.Machine) (failuredomain.FailureDomain, error) {
-	providerConfig, err := NewProviderConfigFromMachineSpec(machine.Spec)
+func ExtractFailureDomainFromMachine(machine machinev1beta1.Machine, infrastructure *configv1.Infrastructure) (failuredomain.FailureDomain, error) {
+	providerConfig, err := NewProviderConfigFromMachineSpec(machine.Spec, infrastructure)
 	if err != nil {
 		return nil, fmt.Errorf("error getting failure domain from machine %s: %w", machine.Name, err)
 	}
@@ -352,11 +467,11 @@ func ExtractFailureDomainFromMachine(machine machinev1beta1.Machine) (failuredom
 }
 
 // ExtractFailureDomainsFromMachineSets creates list of FailureDomains extracted from the provided list of machineSets.
-func ExtractFailureDomainsFromMachineSets(machineSets []machinev1beta1.MachineSet) ([]failuredomain.FailureDomain, error) {
+func ExtractFailureDomainsFromMachineSets(machineSets []machinev1beta1.MachineSet, infrastructure *configv1.Infrastructure) ([]failuredomain.FailureDomain, error) {
 	machineSetFailureDomains := failuredomain.NewSet()
 
 	for _, machineSet := range machineSets {
-		providerconfig, err := NewProviderConfigFromMachineSpec(machineSet.Spec.Template.Spec)
+		providerconfig, err := NewProviderConfigFromMachineSpec(machineSet.Spec.Template.Spec, infrastructure)
 		if err != nil {
 			return nil, fmt.Errorf("error getting failure domain from machineSet %s: %w", machineSet.Name, err)
 		}
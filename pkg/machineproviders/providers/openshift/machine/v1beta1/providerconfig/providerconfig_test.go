@@ -57,7 +57,7 @@ var _ = Describe("Provider Config", func() {
 				in.modifyTemplate(&tmpl)
 			}
 
-			providerConfig, err := NewProviderConfigFromMachineTemplate(*tmpl.OpenShiftMachineV1Beta1Machine)
+			providerConfig, err := NewProviderConfigFromMachineTemplate(*tmpl.OpenShiftMachineV1Beta1Machine, nil)
 			if in.expectedError != nil {
 				Expect(err).To(MatchError(in.expectedError))
 				return
@@ -255,7 +255,7 @@ var _ = Describe("Provider Config", func() {
 				in.modifyMachine(machine)
 			}
 
-			providerConfig, err := NewProviderConfigFromMachineSpec(machine.Spec)
+			providerConfig, err := NewProviderConfigFromMachineSpec(machine.Spec, nil)
 			if in.expectedError != nil {
 				Expect(err).To(MatchError(in.expectedError))
 				return
@@ -311,7 +311,7 @@ var _ = Describe("Provider Config", func() {
 		}
 
 		DescribeTable("should correctly extract the failure domains", func(in extractFailureDomainsFromMachinesTableInput) {
-			failureDomains, err := ExtractFailureDomainsFromMachines(in.machines)
+			failureDomains, err := ExtractFailureDomainsFromMachines(in.machines, nil)
 
 			if in.expectedError != nil {
 				Expect(err).To(Equal(MatchError(in.expectedError)))
@@ -418,14 +418,14 @@ var _ = Describe("Provider Config", func() {
 					machinev1resourcebuilder.GCPFailureDomain().WithZone("us-central1-a").Build(),
 				),
 			}),
-			Entry("with a VSphere dummy failure domain", extractFailureDomainTableInput{
+			Entry("with a VSphere config without a failure domain", extractFailureDomainTableInput{
 				providerConfig: &providerConfig{
 					platformType: configv1.VSpherePlatformType,
-					generic: GenericProviderConfig{
-						providerSpec: machinev1beta1resourcebuilder.VSphereProviderSpec().BuildRawExtension(),
+					vsphere: VSphereProviderConfig{
+						providerConfig: *machinev1beta1resourcebuilder.VSphereProviderSpec().Build(),
 					},
 				},
-				expectedFailureDomain: failuredomain.NewGenericFailureDomain(),
+				expectedFailureDomain: failuredomain.NewVSphereFailureDomain(failuredomain.VSphereFailureDomain{}),
 			}),
 		)
 	})
@@ -556,32 +556,32 @@ var _ = Describe("Provider Config", func() {
 				},
 				expectedEqual: false,
 			}),
-			Entry("with matching Generic configs", equalTableInput{
+			Entry("with matching VSphere configs", equalTableInput{
 				basePC: &providerConfig{
 					platformType: configv1.VSpherePlatformType,
-					generic: GenericProviderConfig{
-						providerSpec: machinev1beta1resourcebuilder.VSphereProviderSpec().BuildRawExtension(),
+					vsphere: VSphereProviderConfig{
+						providerConfig: *machinev1beta1resourcebuilder.VSphereProviderSpec().Build(),
 					},
 				},
 				comparePC: &providerConfig{
 					platformType: configv1.VSpherePlatformType,
-					generic: GenericProviderConfig{
-						providerSpec: machinev1beta1resourcebuilder.VSphereProviderSpec().BuildRawExtension(),
+					vsphere: VSphereProviderConfig{
+						providerConfig: *machinev1beta1resourcebuilder.VSphereProviderSpec().Build(),
 					},
 				},
 				expectedEqual: true,
 			}),
-			Entry("with mis-matched spec using Generic configs", equalTableInput{
+			Entry("with mis-matched VSphere configs", equalTableInput{
 				basePC: &providerConfig{
 					platformType: configv1.VSpherePlatformType,
-					generic: GenericProviderConfig{
-						providerSpec: machinev1beta1resourcebuilder.VSphereProviderSpec().BuildRawExtension(),
+					vsphere: VSphereProviderConfig{
+						providerConfig: *machinev1beta1resourcebuilder.VSphereProviderSpec().Build(),
 					},
 				},
 				comparePC: &providerConfig{
 					platformType: configv1.VSpherePlatformType,
-					generic: GenericProviderConfig{
-						providerSpec: machinev1beta1resourcebuilder.VSphereProviderSpec().WithTemplate("different-template").BuildRawExtension(),
+					vsphere: VSphereProviderConfig{
+						providerConfig: *machinev1beta1resourcebuilder.VSphereProviderSpec().WithTemplate("different-template").Build(),
 					},
 				},
 				expectedEqual: false,
@@ -595,8 +595,8 @@ var _ = Describe("Provider Config", func() {
 				},
 				comparePC: &providerConfig{
 					platformType: configv1.VSpherePlatformType,
-					generic: GenericProviderConfig{
-						providerSpec: machinev1beta1resourcebuilder.VSphereProviderSpec().BuildRawExtension(),
+					vsphere: VSphereProviderConfig{
+						providerConfig: *machinev1beta1resourcebuilder.VSphereProviderSpec().Build(),
 					},
 				},
 				expectedEqual: false,
@@ -605,6 +605,168 @@ var _ = Describe("Provider Config", func() {
 		)
 	})
 
+	Context("StructuredDiff", func() {
+		awsConfig := func(builder machinev1beta1resourcebuilder.AWSProviderSpecBuilder) ProviderConfig {
+			return &providerConfig{
+				platformType: configv1.AWSPlatformType,
+				aws: AWSProviderConfig{
+					providerConfig: *builder.Build(),
+				},
+			}
+		}
+
+		securityGroup := func(id string) machinev1beta1.AWSResourceReference {
+			return machinev1beta1.AWSResourceReference{ID: stringPtr(id)}
+		}
+
+		type structuredDiffTableInput struct {
+			basePC        ProviderConfig
+			comparePC     ProviderConfig
+			expectedDiff  []FieldDiff
+			expectedError error
+		}
+
+		DescribeTable("should describe each differing field", func(in structuredDiffTableInput) {
+			diff, err := in.basePC.StructuredDiff(in.comparePC)
+
+			if in.expectedError != nil {
+				Expect(err).To(MatchError(in.expectedError))
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			Expect(diff).To(Equal(in.expectedDiff))
+		},
+			Entry("with nil provider config", structuredDiffTableInput{
+				basePC:       awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec()),
+				comparePC:    nil,
+				expectedDiff: nil,
+			}),
+			Entry("with different platform types", structuredDiffTableInput{
+				basePC: &providerConfig{
+					platformType: configv1.AWSPlatformType,
+				},
+				comparePC: &providerConfig{
+					platformType: configv1.AzurePlatformType,
+				},
+				expectedError: errMismatchedPlatformTypes,
+			}),
+			Entry("with matching AWS configs", structuredDiffTableInput{
+				basePC:       awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithAvailabilityZone("us-east-1a")),
+				comparePC:    awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithAvailabilityZone("us-east-1a")),
+				expectedDiff: nil,
+			}),
+			Entry("with multiple mis-matched fields, sorted by path", structuredDiffTableInput{
+				basePC:    awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithAvailabilityZone("us-east-1a").WithInstanceType("m6i.xlarge")),
+				comparePC: awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithAvailabilityZone("us-east-1b").WithInstanceType("m6i.2xlarge")),
+				expectedDiff: []FieldDiff{
+					{Path: "spec.providerSpec.value.instanceType", Old: `"m6i.xlarge"`, New: `"m6i.2xlarge"`},
+					{Path: "spec.providerSpec.value.placement.availabilityZone", Old: `"us-east-1a"`, New: `"us-east-1b"`},
+				},
+			}),
+			Entry("with a changed list item", structuredDiffTableInput{
+				basePC:    awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithSecurityGroups([]machinev1beta1.AWSResourceReference{securityGroup("sg-1")})),
+				comparePC: awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithSecurityGroups([]machinev1beta1.AWSResourceReference{securityGroup("sg-2")})),
+				expectedDiff: []FieldDiff{
+					{Path: "spec.providerSpec.value.securityGroups[0].id", Old: `"sg-1"`, New: `"sg-2"`},
+				},
+			}),
+			Entry("with an added list item", structuredDiffTableInput{
+				basePC:    awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithSecurityGroups([]machinev1beta1.AWSResourceReference{securityGroup("sg-1")})),
+				comparePC: awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithSecurityGroups([]machinev1beta1.AWSResourceReference{securityGroup("sg-1"), securityGroup("sg-2")})),
+				expectedDiff: []FieldDiff{
+					{Path: "spec.providerSpec.value.securityGroups[1]", Old: "", New: `{"id":"sg-2"}`},
+				},
+			}),
+			Entry("with a removed list item", structuredDiffTableInput{
+				basePC:    awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithSecurityGroups([]machinev1beta1.AWSResourceReference{securityGroup("sg-1"), securityGroup("sg-2")})),
+				comparePC: awsConfig(machinev1beta1resourcebuilder.AWSProviderSpec().WithSecurityGroups([]machinev1beta1.AWSResourceReference{securityGroup("sg-1")})),
+				expectedDiff: []FieldDiff{
+					{Path: "spec.providerSpec.value.securityGroups[1]", Old: `{"id":"sg-2"}`, New: ""},
+				},
+			}),
+		)
+	})
+
+	Context("IgnoreFieldDiffs", func() {
+		instanceTypeDiff := FieldDiff{Path: "spec.providerSpec.value.instanceType", Old: `"m6i.xlarge"`, New: `"m6i.2xlarge"`}
+		tagDiff := FieldDiff{Path: "spec.providerSpec.value.tags[0].value", Old: `"a"`, New: `"b"`}
+		tagsDiff := FieldDiff{Path: "spec.providerSpec.value.tagsPolicy", Old: "", New: `"Merge"`}
+
+		type ignoreFieldDiffsTableInput struct {
+			diffs        []FieldDiff
+			ignoredPaths []string
+			expectedDiff []FieldDiff
+		}
+
+		DescribeTable("should remove the ignored fields", func(in ignoreFieldDiffsTableInput) {
+			Expect(IgnoreFieldDiffs(in.diffs, in.ignoredPaths)).To(Equal(in.expectedDiff))
+		},
+			Entry("with no ignored paths", ignoreFieldDiffsTableInput{
+				diffs:        []FieldDiff{instanceTypeDiff, tagDiff},
+				expectedDiff: []FieldDiff{instanceTypeDiff, tagDiff},
+			}),
+			Entry("with an ignored field", ignoreFieldDiffsTableInput{
+				diffs:        []FieldDiff{instanceTypeDiff, tagDiff},
+				ignoredPaths: []string{"spec.providerSpec.value.instanceType"},
+				expectedDiff: []FieldDiff{tagDiff},
+			}),
+			Entry("with a field nested within an ignored list", ignoreFieldDiffsTableInput{
+				diffs:        []FieldDiff{instanceTypeDiff, tagDiff, tagsDiff},
+				ignoredPaths: []string{"spec.providerSpec.value.tags"},
+				expectedDiff: []FieldDiff{instanceTypeDiff, tagsDiff},
+			}),
+			Entry("with every field ignored", ignoreFieldDiffsTableInput{
+				diffs:        []FieldDiff{instanceTypeDiff, tagDiff},
+				ignoredPaths: []string{"spec.providerSpec.value.tags", "spec.providerSpec.value.instanceType"},
+				expectedDiff: nil,
+			}),
+		)
+	})
+
+	Context("SignificantFieldsCoveredBy", func() {
+		type significantFieldsTableInput struct {
+			platform       configv1.PlatformType
+			path           string
+			expectedFields []string
+		}
+
+		DescribeTable("should return the significant fields covered by the path", func(in significantFieldsTableInput) {
+			Expect(SignificantFieldsCoveredBy(in.platform, in.path)).To(Equal(in.expectedFields))
+		},
+			Entry("with a significant field", significantFieldsTableInput{
+				platform:       configv1.AWSPlatformType,
+				path:           "spec.providerSpec.value.instanceType",
+				expectedFields: []string{"spec.providerSpec.value.instanceType"},
+			}),
+			Entry("with a field nested within a significant field", significantFieldsTableInput{
+				platform:       configv1.AWSPlatformType,
+				path:           "spec.providerSpec.value.placement.availabilityZone",
+				expectedFields: []string{"spec.providerSpec.value.placement"},
+			}),
+			Entry("with a field that is not significant", significantFieldsTableInput{
+				platform:       configv1.AWSPlatformType,
+				path:           "spec.providerSpec.value.tags",
+				expectedFields: []string{},
+			}),
+			Entry("with a field that shares a prefix with a significant field", significantFieldsTableInput{
+				platform:       configv1.AWSPlatformType,
+				path:           "spec.providerSpec.value.amiName",
+				expectedFields: []string{},
+			}),
+			Entry("with a significant field of another platform", significantFieldsTableInput{
+				platform:       configv1.AzurePlatformType,
+				path:           "spec.providerSpec.value.instanceType",
+				expectedFields: []string{},
+			}),
+			Entry("with an unknown platform", significantFieldsTableInput{
+				platform:       configv1.NonePlatformType,
+				path:           "spec.providerSpec.value.instanceType",
+				expectedFields: []string{},
+			}),
+		)
+	})
+
 	Context("RawConfig", func() {
 		type rawConfigTableInput struct {
 			providerConfig ProviderConfig
@@ -653,8 +815,8 @@ var _ = Describe("Provider Config", func() {
 			Entry("with a VSphere config", rawConfigTableInput{
 				providerConfig: providerConfig{
 					platformType: configv1.VSpherePlatformType,
-					generic: GenericProviderConfig{
-						providerSpec: machinev1beta1resourcebuilder.VSphereProviderSpec().BuildRawExtension(),
+					vsphere: VSphereProviderConfig{
+						providerConfig: *machinev1beta1resourcebuilder.VSphereProviderSpec().Build(),
 					},
 				},
 				expectedOut: machinev1beta1resourcebuilder.VSphereProviderSpec().BuildRawExtension().Raw,
@@ -0,0 +1,170 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providerconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var (
+	// errNilInfrastructure is an error used when the Infrastructure is required to inject a failure domain,
+	// but it was not provided.
+	errNilInfrastructure = errors.New("infrastructure is required to inject vSphere failure domains")
+
+	// errVSphereFailureDomainNotFound is an error used when a vSphere failure domain is not defined within
+	// the Infrastructure.
+	errVSphereFailureDomainNotFound = errors.New("failure domain is not defined in the vSphere platform spec of the infrastructure")
+)
+
+// VSphereProviderConfig holds the provider spec of a VSphere Machine.
+// It allows external code to extract and inject failure domain information,
+// as well as gathering the stored config.
+// The topology of vSphere failure domains is defined within the cluster Infrastructure,
+// which is used to translate between the failure domain names and the provider spec.
+type VSphereProviderConfig struct {
+	providerConfig machinev1beta1.VSphereMachineProviderSpec
+	infrastructure *configv1.Infrastructure
+}
+
+// InjectFailureDomain returns a new VSphereProviderConfig configured with the workspace and network
+// of the failure domain, as defined within the Infrastructure.
+func (v VSphereProviderConfig) InjectFailureDomain(fd failuredomain.VSphereFailureDomain) (VSphereProviderConfig, error) {
+	newVSphereProviderConfig := v
+
+	failureDomainSpec, err := v.getFailureDomainSpec(fd.Name)
+	if err != nil {
+		return VSphereProviderConfig{}, err
+	}
+
+	newVSphereProviderConfig.providerConfig.Workspace = v.workspaceForFailureDomain(failureDomainSpec)
+
+	devices := make([]machinev1beta1.NetworkDeviceSpec, len(failureDomainSpec.Topology.Networks))
+	for i, network := range failureDomainSpec.Topology.Networks {
+		if i < len(v.providerConfig.Network.Devices) {
+			// Retain any other configuration of the existing network device.
+			devices[i] = v.providerConfig.Network.Devices[i]
+		}
+
+		devices[i].NetworkName = network
+	}
+
+	newVSphereProviderConfig.providerConfig.Network.Devices = devices
+
+	return newVSphereProviderConfig, nil
+}
+
+// ExtractFailureDomain returns a VSphereFailureDomain based on the failure domain information stored within
+// the VSphereProviderConfig. The failure domain is identified by matching the server, datacenter, datastore and
+// resource pool of the workspace against the failure domains defined within the Infrastructure.
+// When no failure domain matches, the failure domain returned has no name.
+func (v VSphereProviderConfig) ExtractFailureDomain() failuredomain.VSphereFailureDomain {
+	workspace := v.providerConfig.Workspace
+	if workspace == nil || v.infrastructure == nil || v.infrastructure.Spec.PlatformSpec.VSphere == nil {
+		return failuredomain.VSphereFailureDomain{}
+	}
+
+	for _, failureDomainSpec := range v.infrastructure.Spec.PlatformSpec.VSphere.FailureDomains {
+		failureDomainWorkspace := v.workspaceForFailureDomain(failureDomainSpec)
+
+		if workspace.Server == failureDomainWorkspace.Server &&
+			workspace.Datacenter == failureDomainWorkspace.Datacenter &&
+			workspace.Datastore == failureDomainWorkspace.Datastore &&
+			workspace.ResourcePool == failureDomainWorkspace.ResourcePool {
+			return failuredomain.VSphereFailureDomain{
+				Name: failureDomainSpec.Name,
+			}
+		}
+	}
+
+	return failuredomain.VSphereFailureDomain{}
+}
+
+// Config returns the stored VSphereMachineProviderSpec.
+func (v VSphereProviderConfig) Config() machinev1beta1.VSphereMachineProviderSpec {
+	return v.providerConfig
+}
+
+// getFailureDomainSpec returns the failure domain, defined within the Infrastructure, with the given name.
+func (v VSphereProviderConfig) getFailureDomainSpec(name string) (configv1.VSpherePlatformFailureDomainSpec, error) {
+	if v.infrastructure == nil {
+		return configv1.VSpherePlatformFailureDomainSpec{}, errNilInfrastructure
+	}
+
+	if v.infrastructure.Spec.PlatformSpec.VSphere != nil {
+		for _, failureDomainSpec := range v.infrastructure.Spec.PlatformSpec.VSphere.FailureDomains {
+			if failureDomainSpec.Name == name {
+				return failureDomainSpec, nil
+			}
+		}
+	}
+
+	return configv1.VSpherePlatformFailureDomainSpec{}, fmt.Errorf("%w: %q", errVSphereFailureDomainNotFound, name)
+}
+
+// workspaceForFailureDomain returns the workspace for Machines created within the failure domain.
+// When the failure domain does not specify a resource pool, the root resource pool of the compute cluster is used.
+// When the failure domain does not specify a folder, the folder named after the cluster is used,
+// matching the folder created by the installer.
+func (v VSphereProviderConfig) workspaceForFailureDomain(failureDomainSpec configv1.VSpherePlatformFailureDomainSpec) *machinev1beta1.Workspace {
+	topology := failureDomainSpec.Topology
+
+	workspace := &machinev1beta1.Workspace{
+		Server:       failureDomainSpec.Server,
+		Datacenter:   topology.Datacenter,
+		Datastore:    topology.Datastore,
+		ResourcePool: topology.ResourcePool,
+		Folder:       topology.Folder,
+	}
+
+	if workspace.ResourcePool == "" {
+		workspace.ResourcePool = fmt.Sprintf("%s/Resources", topology.ComputeCluster)
+	}
+
+	if workspace.Folder == "" && v.infrastructure != nil {
+		workspace.Folder = fmt.Sprintf("/%s/vm/%s", topology.Datacenter, v.infrastructure.Status.InfrastructureName)
+	}
+
+	return workspace
+}
+
+// newVSphereProviderConfig creates a VSphere type ProviderConfig from the raw extension.
+// It should return an error if the provided RawExtension does not represent a VSphereProviderConfig.
+func newVSphereProviderConfig(raw *runtime.RawExtension, infrastructure *configv1.Infrastructure) (ProviderConfig, error) {
+	var vsphereMachineProviderSpec machinev1beta1.VSphereMachineProviderSpec
+	if err := json.Unmarshal(raw.Raw, &vsphereMachineProviderSpec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal vSphere provider config: %w", err)
+	}
+
+	vsphereProviderConfig := VSphereProviderConfig{
+		providerConfig: vsphereMachineProviderSpec,
+		infrastructure: infrastructure,
+	}
+
+	config := providerConfig{
+		platformType: configv1.VSpherePlatformType,
+		vsphere:      vsphereProviderConfig,
+	}
+
+	return config, nil
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providerconfig
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1beta1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
+)
+
+var _ = Describe("VSphere Provider Config", func() {
+	var providerConfig VSphereProviderConfig
+	var infrastructure *configv1.Infrastructure
+
+	fd1 := failuredomain.VSphereFailureDomain{Name: "fd-1"}
+	fd2 := failuredomain.VSphereFailureDomain{Name: "fd-2"}
+
+	BeforeEach(func() {
+		infrastructure = &configv1.Infrastructure{
+			Spec: configv1.InfrastructureSpec{
+				PlatformSpec: configv1.PlatformSpec{
+					Type: configv1.VSpherePlatformType,
+					VSphere: &configv1.VSpherePlatformSpec{
+						FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{
+							{
+								Name:   fd1.Name,
+								Region: "region-1",
+								Zone:   "zone-1",
+								Server: "vcenter.example.com",
+								Topology: configv1.VSpherePlatformTopology{
+									Datacenter:     "dc-1",
+									ComputeCluster: "/dc-1/host/cluster-1",
+									Networks:       []string{"network-1"},
+									Datastore:      "/dc-1/datastore/datastore-1",
+								},
+							},
+							{
+								Name:   fd2.Name,
+								Region: "region-1",
+								Zone:   "zone-2",
+								Server: "vcenter.example.com",
+								Topology: configv1.VSpherePlatformTopology{
+									Datacenter:     "dc-1",
+									ComputeCluster: "/dc-1/host/cluster-2",
+									Networks:       []string{"network-2"},
+									Datastore:      "/dc-1/datastore/datastore-2",
+									ResourcePool:   "/dc-1/host/cluster-2/Resources/pool-2",
+									Folder:         "/dc-1/vm/folder-2",
+								},
+							},
+						},
+					},
+				},
+			},
+			Status: configv1.InfrastructureStatus{
+				InfrastructureName: "cluster-abc12",
+			},
+		}
+
+		providerConfig = VSphereProviderConfig{
+			providerConfig: *machinev1beta1resourcebuilder.VSphereProviderSpec().Build(),
+			infrastructure: infrastructure,
+		}
+
+		var err error
+		providerConfig, err = providerConfig.InjectFailureDomain(fd1)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("InjectFailureDomain", func() {
+		It("sets the workspace from the failure domain topology", func() {
+			Expect(providerConfig.Config().Workspace).To(Equal(&machinev1beta1.Workspace{
+				Server:       "vcenter.example.com",
+				Datacenter:   "dc-1",
+				Datastore:    "/dc-1/datastore/datastore-1",
+				ResourcePool: "/dc-1/host/cluster-1/Resources",
+				Folder:       "/dc-1/vm/cluster-abc12",
+			}))
+		})
+
+		It("sets the network from the failure domain topology", func() {
+			Expect(providerConfig.Config().Network.Devices).To(ConsistOf(
+				machinev1beta1.NetworkDeviceSpec{NetworkName: "network-1"},
+			))
+		})
+
+		It("uses the resource pool and folder of the failure domain when they are set", func() {
+			changedProviderConfig, err := providerConfig.InjectFailureDomain(fd2)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(changedProviderConfig.Config().Workspace.ResourcePool).To(Equal("/dc-1/host/cluster-2/Resources/pool-2"))
+			Expect(changedProviderConfig.Config().Workspace.Folder).To(Equal("/dc-1/vm/folder-2"))
+		})
+
+		It("returns an error when the failure domain is not defined in the infrastructure", func() {
+			_, err := providerConfig.InjectFailureDomain(failuredomain.VSphereFailureDomain{Name: "fd-3"})
+			Expect(err).To(MatchError(errVSphereFailureDomainNotFound))
+		})
+
+		It("returns an error when the infrastructure is not set", func() {
+			providerConfig.infrastructure = nil
+
+			_, err := providerConfig.InjectFailureDomain(fd2)
+			Expect(err).To(MatchError(errNilInfrastructure))
+		})
+	})
+
+	Context("ExtractFailureDomain", func() {
+		It("returns the configured failure domain", func() {
+			Expect(providerConfig.ExtractFailureDomain()).To(Equal(fd1))
+		})
+
+		It("returns an empty failure domain when the workspace matches no failure domain", func() {
+			providerConfig.providerConfig.Workspace.Datastore = "/dc-1/datastore/datastore-3"
+
+			Expect(providerConfig.ExtractFailureDomain()).To(Equal(failuredomain.VSphereFailureDomain{}))
+		})
+
+		It("returns an empty failure domain when the infrastructure is not set", func() {
+			providerConfig.infrastructure = nil
+
+			Expect(providerConfig.ExtractFailureDomain()).To(Equal(failuredomain.VSphereFailureDomain{}))
+		})
+	})
+
+	Context("when the failuredomain is changed after initialisation", func() {
+		var changedProviderConfig VSphereProviderConfig
+
+		BeforeEach(func() {
+			var err error
+			changedProviderConfig, err = providerConfig.InjectFailureDomain(fd2)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("ExtractFailureDomain", func() {
+			It("returns the changed failure domain from the changed config", func() {
+				Expect(changedProviderConfig.ExtractFailureDomain()).To(Equal(fd2))
+			})
+
+			It("returns the original failure domain from the original config", func() {
+				Expect(providerConfig.ExtractFailureDomain()).To(Equal(fd1))
+			})
+		})
+
+		It("retains the template of the provider spec", func() {
+			Expect(changedProviderConfig.Config().Template).To(Equal(providerConfig.Config().Template))
+		})
+	})
+
+	Context("newVSphereProviderConfig", func() {
+		var providerConfig ProviderConfig
+
+		BeforeEach(func() {
+			var err error
+			providerConfig, err = newVSphereProviderConfig(machinev1beta1resourcebuilder.VSphereProviderSpec().BuildRawExtension(), infrastructure)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("sets the type to VSphere", func() {
+			Expect(providerConfig.Type()).To(Equal(configv1.VSpherePlatformType))
+		})
+
+		It("returns the correct VSphere config", func() {
+			Expect(providerConfig.VSphere().Config()).To(Equal(*machinev1beta1resourcebuilder.VSphereProviderSpec().Build()))
+		})
+
+		It("injects failure domains using the infrastructure", func() {
+			changedProviderConfig, err := providerConfig.InjectFailureDomain(failuredomain.NewVSphereFailureDomain(fd2))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(changedProviderConfig.ExtractFailureDomain().VSphere()).To(Equal(fd2))
+		})
+	})
+})
@@ -39,10 +39,19 @@ type MachineInfo struct {
 	// Node has joined the cluster and is operating as expected.
 	Ready bool
 
-	// NeedsUpdate is set true when the existing spec of the Machine does not match the desired spec of the Machine.
+	// NeedsUpdate is set true when the existing spec of the Machine does not match the desired spec of the Machine,
+	// in a way that requires the Machine to be replaced.
 	// This is used to inform the controller about decisions related to rolling out new machines.
 	NeedsUpdate bool
 
+	// NeedsInPlaceUpdate is set true when the existing Machine differs from the desired Machine in fields that
+	// can be updated on the existing Machine, without replacing it. For example, the taints.
+	NeedsInPlaceUpdate bool
+
+	// Evacuating is set true when the Machine is within a failure domain that has been marked as unavailable.
+	// The Machine must be moved to an available failure domain, and so also needs an update.
+	Evacuating bool
+
 	// Index denotes the Control Plane Machine index. Each Control Plane Machine replica is index (typically 0-2 in a
 	// three node cluster) and the Index will be needed to generate a replacement of this replica,  if a replacement is
 	// required.
@@ -51,6 +60,33 @@ type MachineInfo struct {
 	// ErrorMessage is used to provide information about any errors that have occurred with the Machine. For example, if
 	// the Machine has an error state within its status, it should be propagated up via this error message.
 	ErrorMessage string
+
+	// Diff describes each field of the Machine that differs from the desired spec of the Machine, sorted by path.
+	// It explains why the Machine needs an update, and is empty when NeedsUpdate is false.
+	Diff []FieldDiff
+
+	// InPlaceDiff describes each field of the Machine that differs from the desired spec of the Machine, and that
+	// can be updated in place. It is empty when NeedsInPlaceUpdate is false.
+	InPlaceDiff []FieldDiff
+
+	// TemplateRevision is the revision of the template the Machine was created from.
+	// It is empty when the Machine was not created by the ControlPlaneMachineSet, or was created before
+	// the revision was recorded on the Machines.
+	TemplateRevision string
+}
+
+// FieldDiff describes a single field of a Machine that differs from the desired spec of the Machine.
+type FieldDiff struct {
+	// Path is the path of the field within the Machine, eg. `spec.providerSpec.value.instanceType`.
+	Path string `json:"path"`
+
+	// Old is the JSON encoded value of the field within the existing Machine.
+	// It is empty when the field is not set.
+	Old string `json:"old,omitempty"`
+
+	// New is the JSON encoded value of the field within the desired spec of the Machine.
+	// It is empty when the field is not set.
+	New string `json:"new,omitempty"`
 }
 
 // ObjectRef allows you to uniquely identify a resource within a cluster.
@@ -65,6 +101,33 @@ type ObjectRef struct {
 	ObjectMeta metav1.ObjectMeta
 }
 
+// FailureDomainMappingReason describes why a Control Plane Machine index was mapped to a failure domain.
+type FailureDomainMappingReason string
+
+const (
+	// FailureDomainMappingReasonExistingMachine is used when the index is mapped to the failure domain
+	// of the existing Machine within the index.
+	FailureDomainMappingReasonExistingMachine FailureDomainMappingReason = "ExistingMachine"
+
+	// FailureDomainMappingReasonBaseMapping is used when there is no existing Machine within the index,
+	// and so the index is mapped to the failure domain from the base mapping.
+	FailureDomainMappingReasonBaseMapping FailureDomainMappingReason = "BaseMapping"
+
+	// FailureDomainMappingReasonRebalanced is used when the index is moved away from the failure domain
+	// of the existing Machine within the index. This happens when the failure domain is no longer
+	// configured, or holds more than its share of the indexes.
+	FailureDomainMappingReasonRebalanced FailureDomainMappingReason = "Rebalanced"
+)
+
+// FailureDomainMapping describes the failure domain that a Control Plane Machine index is mapped to.
+type FailureDomainMapping struct {
+	// FailureDomain is the string representation of the failure domain.
+	FailureDomain string
+
+	// Reason describes why the index was mapped to the failure domain.
+	Reason FailureDomainMappingReason
+}
+
 // MachineProvider defines an interface for implementing the Machine specific
 // functions related to the ControlPlaneMachineSet controller.
 type MachineProvider interface {
@@ -86,4 +149,14 @@ type MachineProvider interface {
 	// RollingUpdate strategy of the ControlPlaneMachineSet so that it can remove old Machines once they have been
 	// replaced.
 	DeleteMachine(context.Context, logr.Logger, *ObjectRef) error
+
+	// UpdateMachineInPlace is used to instruct the Machine Provider to update the fields of a particular Machine that
+	// can be updated without replacing the Machine, so that they match the desired spec of the Machine.
+	// This is used for Machines that report NeedsInPlaceUpdate.
+	UpdateMachineInPlace(context.Context, logr.Logger, *ObjectRef) error
+
+	// GetFailureDomainMapping returns the failure domain each Control Plane Machine index is mapped to, as determined
+	// during construction of the MachineProvider, along with the reason for each assignment.
+	// When no failure domains are configured, the mapping is empty.
+	GetFailureDomainMapping() map[int32]FailureDomainMapping
 }
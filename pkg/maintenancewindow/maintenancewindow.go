@@ -0,0 +1,251 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenancewindow
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSearchYears is the number of years to search for the next match of a schedule before giving up.
+	// This allows for schedules that only match on leap days.
+	maxSearchYears = 5
+)
+
+var (
+	// ErrInvalidSchedule is returned when a schedule cannot be parsed.
+	ErrInvalidSchedule = errors.New("invalid schedule")
+
+	// ErrScheduleNeverMatches is returned when a schedule is valid but never matches, for example, the 31st of February.
+	ErrScheduleNeverMatches = errors.New("schedule never matches")
+
+	// ErrInvalidDuration is returned when the duration of a window is not a positive duration.
+	ErrInvalidDuration = errors.New("duration must be a positive duration, for example 4h")
+)
+
+// field describes the allowed range of values for a single field of a schedule.
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+// Schedule is a parsed cron schedule.
+// It supports the standard five fields, minute, hour, day of month, month and day of week, each of which may be
+// a wildcard (*), a value, a range (a-b), or a list of these (a,b-c), optionally with a step (*/n, a-b/n).
+// As with cron, when both the day of month and day of week are restricted, a day matches if either field matches.
+// All times are evaluated in UTC.
+type Schedule struct {
+	minutes     []bool
+	hours       []bool
+	daysOfMonth []bool
+	months      []bool
+	daysOfWeek  []bool
+
+	// anyDayOfMonth and anyDayOfWeek record whether the day fields were wildcards.
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+// ParseSchedule parses a five field cron schedule.
+func ParseSchedule(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidSchedule, len(fields))
+	}
+
+	var (
+		schedule Schedule
+		err      error
+	)
+
+	if schedule.minutes, err = parseField(fields[0], field{name: "minute", min: 0, max: 59}); err != nil {
+		return Schedule{}, err
+	}
+
+	if schedule.hours, err = parseField(fields[1], field{name: "hour", min: 0, max: 23}); err != nil {
+		return Schedule{}, err
+	}
+
+	if schedule.daysOfMonth, err = parseField(fields[2], field{name: "day of month", min: 1, max: 31}); err != nil {
+		return Schedule{}, err
+	}
+
+	if schedule.months, err = parseField(fields[3], field{name: "month", min: 1, max: 12}); err != nil {
+		return Schedule{}, err
+	}
+
+	if schedule.daysOfWeek, err = parseField(fields[4], field{name: "day of week", min: 0, max: 6}); err != nil {
+		return Schedule{}, err
+	}
+
+	schedule.anyDayOfMonth = strings.HasPrefix(fields[2], "*")
+	schedule.anyDayOfWeek = strings.HasPrefix(fields[4], "*")
+
+	// Any search period of the maximum search length includes a leap day, so if the schedule does not match within the
+	// search period from an arbitrary reference time, it never matches.
+	if schedule.Next(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return Schedule{}, fmt.Errorf("%w: %q", ErrScheduleNeverMatches, spec)
+	}
+
+	return schedule, nil
+}
+
+// parseField parses a single field of a schedule into a set of matching values, indexed by value.
+func parseField(spec string, f field) ([]bool, error) {
+	values := make([]bool, f.max+1)
+
+	for _, item := range strings.Split(spec, ",") {
+		rangeSpec, step := item, 1
+
+		if i := strings.Index(item, "/"); i >= 0 {
+			rangeSpec = item[:i]
+
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s < 1 {
+				return nil, fmt.Errorf("%w: invalid step in %s field: %q", ErrInvalidSchedule, f.name, item)
+			}
+
+			step = s
+		}
+
+		start, end, err := parseRange(rangeSpec, f)
+		if err != nil {
+			return nil, err
+		}
+
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// parseRange parses a wildcard, single value or range within a field of a schedule.
+func parseRange(spec string, f field) (int, int, error) {
+	if spec == "*" {
+		return f.min, f.max, nil
+	}
+
+	startSpec, endSpec, isRange := strings.Cut(spec, "-")
+
+	start, err := strconv.Atoi(startSpec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: invalid value in %s field: %q", ErrInvalidSchedule, f.name, spec)
+	}
+
+	end := start
+
+	if isRange {
+		if end, err = strconv.Atoi(endSpec); err != nil {
+			return 0, 0, fmt.Errorf("%w: invalid value in %s field: %q", ErrInvalidSchedule, f.name, spec)
+		}
+	}
+
+	if start < f.min || end > f.max || start > end {
+		return 0, 0, fmt.Errorf("%w: %s field must be within %d-%d: %q", ErrInvalidSchedule, f.name, f.min, f.max, spec)
+	}
+
+	return start, end, nil
+}
+
+// Next returns the first time, strictly after the given time, at which the schedule matches.
+// The zero time is returned if the schedule does not match within the next five years, which is only possible
+// for a Schedule that was not created by ParseSchedule.
+func (s Schedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + maxSearchYears
+
+	for t.Year() <= yearLimit {
+		switch {
+		case !s.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !s.hours[t.Hour()]:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !s.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay checks whether the day of the given time matches the day of month and day of week fields.
+func (s Schedule) matchesDay(t time.Time) bool {
+	domMatch := s.daysOfMonth[t.Day()]
+	dowMatch := s.daysOfWeek[t.Weekday()]
+
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// Window is a recurring maintenance window. The window opens each time the schedule matches, and remains open
+// for the duration.
+type Window struct {
+	Schedule Schedule
+	Duration time.Duration
+}
+
+// Parse parses a maintenance window from a five field cron schedule and a duration.
+func Parse(schedule, duration string) (Window, error) {
+	s, err := ParseSchedule(schedule)
+	if err != nil {
+		return Window{}, err
+	}
+
+	d, err := ParseDuration(duration)
+	if err != nil {
+		return Window{}, err
+	}
+
+	return Window{Schedule: s, Duration: d}, nil
+}
+
+// ParseDuration parses the duration of a maintenance window, which must be positive.
+func ParseDuration(duration string) (time.Duration, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, duration)
+	}
+
+	return d, nil
+}
+
+// IsOpen checks whether the maintenance window is open at the given time.
+func (w Window) IsOpen(now time.Time) bool {
+	// The window is open if it opened within the last duration.
+	opened := w.Schedule.Next(now.Add(-w.Duration))
+
+	return !opened.IsZero() && !opened.After(now)
+}
+
+// NextOpening returns the next time, strictly after the given time, at which the maintenance window opens.
+func (w Window) NextOpening(now time.Time) time.Time {
+	return w.Schedule.Next(now)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenancewindow
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// mustParseTime parses an RFC3339 time, failing the test if it cannot be parsed.
+func mustParseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	Expect(err).ToNot(HaveOccurred())
+
+	return t
+}
+
+var _ = Describe("Schedule", func() {
+	DescribeTable("should reject invalid schedules", func(spec string) {
+		_, err := ParseSchedule(spec)
+		Expect(err).To(MatchError(ErrInvalidSchedule))
+	},
+		Entry("with too few fields", "0 2 * *"),
+		Entry("with too many fields", "0 2 * * * *"),
+		Entry("with a non-numeric value", "a 2 * * *"),
+		Entry("with a minute out of range", "60 2 * * *"),
+		Entry("with an hour out of range", "0 24 * * *"),
+		Entry("with a day of month out of range", "0 2 0 * *"),
+		Entry("with a month out of range", "0 2 * 13 *"),
+		Entry("with a day of week out of range", "0 2 * * 7"),
+		Entry("with an inverted range", "0 4-2 * * *"),
+		Entry("with a zero step", "*/0 2 * * *"),
+		Entry("with an invalid step", "*/a 2 * * *"),
+	)
+
+	type nextTableInput struct {
+		spec     string
+		after    string
+		expected string
+	}
+
+	DescribeTable("should find the next time the schedule matches", func(in nextTableInput) {
+		schedule, err := ParseSchedule(in.spec)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(schedule.Next(mustParseTime(in.after))).To(Equal(mustParseTime(in.expected)))
+	},
+		Entry("every minute", nextTableInput{
+			spec:     "* * * * *",
+			after:    "2023-03-01T10:15:30Z",
+			expected: "2023-03-01T10:16:00Z",
+		}),
+		Entry("daily, later the same day", nextTableInput{
+			spec:     "0 22 * * *",
+			after:    "2023-03-01T10:15:00Z",
+			expected: "2023-03-01T22:00:00Z",
+		}),
+		Entry("daily, at the time the schedule matches", nextTableInput{
+			spec:     "0 22 * * *",
+			after:    "2023-03-01T22:00:00Z",
+			expected: "2023-03-02T22:00:00Z",
+		}),
+		Entry("with a step and a list", nextTableInput{
+			spec:     "*/20 1,3 * * *",
+			after:    "2023-03-01T01:45:00Z",
+			expected: "2023-03-01T03:00:00Z",
+		}),
+		Entry("on weekends", nextTableInput{
+			spec:     "30 2 * * 0,6",
+			after:    "2023-03-01T10:00:00Z", // A Wednesday.
+			expected: "2023-03-04T02:30:00Z",
+		}),
+		Entry("on week days, across a weekend", nextTableInput{
+			spec:     "0 2 * * 1-5",
+			after:    "2023-03-03T10:00:00Z", // A Friday.
+			expected: "2023-03-06T02:00:00Z",
+		}),
+		Entry("on the first of the month, across a year", nextTableInput{
+			spec:     "0 0 1 * *",
+			after:    "2023-12-15T00:00:00Z",
+			expected: "2024-01-01T00:00:00Z",
+		}),
+		Entry("with both the day of month and day of week restricted", nextTableInput{
+			spec:     "0 0 15 * 1",
+			after:    "2023-03-01T00:00:00Z", // The next Monday is the 6th.
+			expected: "2023-03-06T00:00:00Z",
+		}),
+		Entry("on a leap day", nextTableInput{
+			spec:     "0 0 29 2 *",
+			after:    "2023-03-01T00:00:00Z",
+			expected: "2024-02-29T00:00:00Z",
+		}),
+		Entry("with a non-UTC time", nextTableInput{
+			spec:     "0 22 * * *",
+			after:    "2023-03-01T20:00:00-05:00",
+			expected: "2023-03-02T22:00:00Z",
+		}),
+	)
+
+	It("should reject a schedule that never matches", func() {
+		_, err := ParseSchedule("0 0 31 2 *")
+		Expect(err).To(MatchError(ErrScheduleNeverMatches))
+	})
+})
+
+var _ = Describe("Window", func() {
+	DescribeTable("should reject invalid durations", func(duration string) {
+		_, err := Parse("0 22 * * *", duration)
+		Expect(err).To(MatchError(ErrInvalidDuration))
+	},
+		Entry("with an empty duration", ""),
+		Entry("with a non-duration", "forever"),
+		Entry("with a zero duration", "0s"),
+		Entry("with a negative duration", "-1h"),
+	)
+
+	type windowTableInput struct {
+		schedule            string
+		duration            string
+		now                 string
+		expectedOpen        bool
+		expectedNextOpening string
+	}
+
+	DescribeTable("should determine whether the window is open", func(in windowTableInput) {
+		window, err := Parse(in.schedule, in.duration)
+		Expect(err).ToNot(HaveOccurred())
+
+		now := mustParseTime(in.now)
+		Expect(window.IsOpen(now)).To(Equal(in.expectedOpen))
+		Expect(window.NextOpening(now)).To(Equal(mustParseTime(in.expectedNextOpening)))
+	},
+		Entry("before the window opens", windowTableInput{
+			schedule:            "0 22 * * *",
+			duration:            "4h",
+			now:                 "2023-03-01T21:59:00Z",
+			expectedOpen:        false,
+			expectedNextOpening: "2023-03-01T22:00:00Z",
+		}),
+		Entry("when the window opens", windowTableInput{
+			schedule:            "0 22 * * *",
+			duration:            "4h",
+			now:                 "2023-03-01T22:00:00Z",
+			expectedOpen:        true,
+			expectedNextOpening: "2023-03-02T22:00:00Z",
+		}),
+		Entry("within the window, across midnight", windowTableInput{
+			schedule:            "0 22 * * *",
+			duration:            "4h",
+			now:                 "2023-03-02T01:30:00Z",
+			expectedOpen:        true,
+			expectedNextOpening: "2023-03-02T22:00:00Z",
+		}),
+		Entry("when the window closes", windowTableInput{
+			schedule:            "0 22 * * *",
+			duration:            "4h",
+			now:                 "2023-03-02T02:00:00Z",
+			expectedOpen:        false,
+			expectedNextOpening: "2023-03-02T22:00:00Z",
+		}),
+		Entry("on a day the window does not open", windowTableInput{
+			schedule:            "0 22 * * 6",
+			duration:            "4h",
+			now:                 "2023-03-01T23:00:00Z", // A Wednesday.
+			expectedOpen:        false,
+			expectedNextOpening: "2023-03-04T22:00:00Z",
+		}),
+	)
+})
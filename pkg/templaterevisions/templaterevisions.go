@@ -0,0 +1,162 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templaterevisions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// MaxRevisions is the maximum number of template revisions kept for the ControlPlaneMachineSet.
+	// Once the limit is reached, the oldest revisions are dropped.
+	MaxRevisions = 5
+
+	// RevisionsKey is the key, within the ConfigMap data, of the template revisions.
+	// The value is a JSON list of TemplateRevisions, oldest first.
+	RevisionsKey = "revisions"
+
+	// configMapNameSuffix is appended to the name of the ControlPlaneMachineSet to name the ConfigMap
+	// holding its template revisions.
+	configMapNameSuffix = "-template-revisions"
+)
+
+var (
+	// ErrInvalidRevisions is returned when the template revisions cannot be parsed.
+	ErrInvalidRevisions = errors.New("value must be a JSON list of template revisions")
+
+	// ErrUnknownRevision is returned when the requested revision is not one of the template revisions kept.
+	ErrUnknownRevision = errors.New("unknown template revision")
+
+	// ErrNoPreviousRevision is returned when the previous revision is requested, but no revision other than
+	// the current revision is kept.
+	ErrNoPreviousRevision = errors.New("no previous template revision is available")
+)
+
+// TemplateRevision is a template that was applied to the Control Plane Machines.
+type TemplateRevision struct {
+	// Revision is the revision of the template, as recorded on the Machines created from it.
+	Revision string `json:"revision"`
+
+	// AppliedTime is the time at which every Control Plane Machine was first observed to be up to date with the template.
+	AppliedTime metav1.Time `json:"appliedTime"`
+
+	// Template is the template that was applied.
+	Template machinev1.ControlPlaneMachineSetTemplate `json:"template"`
+
+	// FailureDomainsAnnotations are the failure domains annotations that were applied alongside the template,
+	// on the platforms where the failure domains are configured by annotation.
+	FailureDomainsAnnotations map[string]string `json:"failureDomainsAnnotations,omitempty"`
+}
+
+// ConfigMapName returns the name of the ConfigMap holding the template revisions of the ControlPlaneMachineSet.
+// The ConfigMap is within the namespace of the ControlPlaneMachineSet.
+func ConfigMapName(cpms *machinev1.ControlPlaneMachineSet) string {
+	return cpms.Name + configMapNameSuffix
+}
+
+// Get fetches the template revisions kept for the ControlPlaneMachineSet, oldest first.
+// When no template has been applied yet, no revisions are returned.
+func Get(ctx context.Context, reader client.Reader, cpms *machinev1.ControlPlaneMachineSet) ([]TemplateRevision, error) {
+	configMap := &corev1.ConfigMap{}
+	configMapKey := client.ObjectKey{Namespace: cpms.Namespace, Name: ConfigMapName(cpms)}
+
+	if err := reader.Get(ctx, configMapKey, configMap); apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not fetch template revisions: %w", err)
+	}
+
+	return Parse(configMap)
+}
+
+// Parse parses the template revisions held within the ConfigMap.
+func Parse(configMap *corev1.ConfigMap) ([]TemplateRevision, error) {
+	value, ok := configMap.Data[RevisionsKey]
+	if !ok {
+		return nil, nil
+	}
+
+	revisions := []TemplateRevision{}
+
+	if err := json.Unmarshal([]byte(value), &revisions); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", RevisionsKey, ErrInvalidRevisions, err.Error())
+	}
+
+	return revisions, nil
+}
+
+// Record adds the template revision as the most recent revision.
+// Any older entry for the same revision is dropped, as are the oldest revisions beyond the MaxRevisions.
+// When the most recent revision already holds the same template, the revisions are returned unchanged,
+// and false is returned to indicate that they do not need to be stored.
+func Record(revisions []TemplateRevision, revision TemplateRevision) ([]TemplateRevision, bool) {
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+
+		if latest.Revision == revision.Revision && equality.Semantic.DeepEqual(latest.Template, revision.Template) &&
+			equality.Semantic.DeepEqual(latest.FailureDomainsAnnotations, revision.FailureDomainsAnnotations) {
+			return revisions, false
+		}
+	}
+
+	out := []TemplateRevision{}
+
+	for _, existing := range revisions {
+		if existing.Revision != revision.Revision {
+			out = append(out, existing)
+		}
+	}
+
+	out = append(out, revision)
+
+	if len(out) > MaxRevisions {
+		out = out[len(out)-MaxRevisions:]
+	}
+
+	return out, true
+}
+
+// Find returns the template revision requested by the value of the RollbackToRevisionAnnotation.
+// The value is either a revision, or the PreviousTemplateRevision, in which case the most recent revision
+// other than the current revision is returned.
+func Find(revisions []TemplateRevision, value, currentRevision string) (TemplateRevision, error) {
+	for i := len(revisions) - 1; i >= 0; i-- {
+		switch {
+		case value == annotations.PreviousTemplateRevision && revisions[i].Revision != currentRevision:
+			return revisions[i], nil
+		case revisions[i].Revision == value:
+			return revisions[i], nil
+		}
+	}
+
+	if value == annotations.PreviousTemplateRevision {
+		return TemplateRevision{}, ErrNoPreviousRevision
+	}
+
+	return TemplateRevision{}, fmt.Errorf("%w: %s", ErrUnknownRevision, value)
+}
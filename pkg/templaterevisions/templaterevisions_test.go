@@ -0,0 +1,165 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templaterevisions
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newRevision builds a template revision whose template is identified by the zone label.
+func newRevision(revision, zone string) TemplateRevision {
+	return TemplateRevision{
+		Revision:    revision,
+		AppliedTime: metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Template: machinev1.ControlPlaneMachineSetTemplate{
+			MachineType: machinev1.OpenShiftMachineV1Beta1MachineType,
+			OpenShiftMachineV1Beta1Machine: &machinev1.OpenShiftMachineV1Beta1MachineTemplate{
+				ObjectMeta: machinev1.ControlPlaneMachineSetTemplateObjectMeta{
+					Labels: map[string]string{"zone": zone},
+				},
+			},
+		},
+	}
+}
+
+// revisionNames returns the revision of each template revision, in order.
+func revisionNames(revisions []TemplateRevision) []string {
+	names := []string{}
+
+	for _, revision := range revisions {
+		names = append(names, revision.Revision)
+	}
+
+	return names
+}
+
+var _ = Describe("TemplateRevisions", func() {
+	Context("Parse", func() {
+		It("returns no revisions when the ConfigMap has none", func() {
+			revisions, err := Parse(&corev1.ConfigMap{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revisions).To(BeEmpty())
+		})
+
+		It("parses the revisions", func() {
+			configMap := &corev1.ConfigMap{
+				Data: map[string]string{
+					RevisionsKey: `[{"revision":"a","appliedTime":"2023-01-01T00:00:00Z","template":{"machineType":"machines_v1beta1_machine_openshift_io"}}]`,
+				},
+			}
+
+			revisions, err := Parse(configMap)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revisions).To(HaveLen(1))
+			Expect(revisions[0].Revision).To(Equal("a"))
+			Expect(revisions[0].Template.MachineType).To(Equal(machinev1.OpenShiftMachineV1Beta1MachineType))
+		})
+
+		It("rejects revisions that are not valid JSON", func() {
+			_, err := Parse(&corev1.ConfigMap{Data: map[string]string{RevisionsKey: "{"}})
+			Expect(err).To(MatchError(ErrInvalidRevisions))
+		})
+	})
+
+	Context("Record", func() {
+		It("records the first revision", func() {
+			revisions, changed := Record(nil, newRevision("a", "a"))
+			Expect(changed).To(BeTrue())
+			Expect(revisionNames(revisions)).To(Equal([]string{"a"}))
+		})
+
+		It("does not change the revisions when the latest revision is recorded again", func() {
+			existing := []TemplateRevision{newRevision("a", "a"), newRevision("b", "b")}
+
+			revisions, changed := Record(existing, newRevision("b", "b"))
+			Expect(changed).To(BeFalse())
+			Expect(revisions).To(Equal(existing))
+		})
+
+		It("records the latest revision again when its failure domains annotations have changed", func() {
+			existing := []TemplateRevision{newRevision("a", "a")}
+
+			revision := newRevision("a", "a")
+			revision.FailureDomainsAnnotations = map[string]string{annotations.OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1"}]`}
+
+			revisions, changed := Record(existing, revision)
+			Expect(changed).To(BeTrue())
+			Expect(revisions).To(Equal([]TemplateRevision{revision}))
+		})
+
+		It("moves a revision that is applied again to the end", func() {
+			existing := []TemplateRevision{newRevision("a", "a"), newRevision("b", "b")}
+
+			revisions, changed := Record(existing, newRevision("a", "a"))
+			Expect(changed).To(BeTrue())
+			Expect(revisionNames(revisions)).To(Equal([]string{"b", "a"}))
+		})
+
+		It("drops the oldest revisions beyond the limit", func() {
+			revisions := []TemplateRevision{}
+
+			for i := 0; i < MaxRevisions+2; i++ {
+				name := fmt.Sprintf("%d", i)
+				revisions, _ = Record(revisions, newRevision(name, name))
+			}
+
+			Expect(revisionNames(revisions)).To(Equal([]string{"2", "3", "4", "5", "6"}))
+		})
+	})
+
+	Context("Find", func() {
+		revisions := []TemplateRevision{newRevision("a", "a"), newRevision("b", "b"), newRevision("c", "c")}
+
+		It("finds a revision", func() {
+			revision, err := Find(revisions, "b", "c")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revision.Revision).To(Equal("b"))
+		})
+
+		It("finds the previous revision", func() {
+			revision, err := Find(revisions, annotations.PreviousTemplateRevision, "c")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revision.Revision).To(Equal("b"))
+		})
+
+		It("finds the latest revision as the previous revision when the template has changed since", func() {
+			revision, err := Find(revisions, annotations.PreviousTemplateRevision, "d")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revision.Revision).To(Equal("c"))
+		})
+
+		It("rejects an unknown revision", func() {
+			_, err := Find(revisions, "d", "c")
+			Expect(err).To(MatchError(ErrUnknownRevision))
+			Expect(err).To(MatchError("unknown template revision: d"))
+		})
+
+		It("rejects the previous revision when only the current revision is kept", func() {
+			_, err := Find([]TemplateRevision{newRevision("c", "c")}, annotations.PreviousTemplateRevision, "c")
+			Expect(err).To(MatchError(ErrNoPreviousRevision))
+		})
+	})
+})
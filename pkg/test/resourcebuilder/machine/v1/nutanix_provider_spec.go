@@ -0,0 +1,114 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+// NutanixProviderSpec creates a new Nutanix machine config builder.
+func NutanixProviderSpec() NutanixProviderSpecBuilder {
+	return NutanixProviderSpecBuilder{
+		cluster:     "prism-element-1",
+		subnets:     []string{"subnet-1"},
+		vcpuSockets: 4,
+	}
+}
+
+// NutanixProviderSpecBuilder is used to build a Nutanix machine config object.
+type NutanixProviderSpecBuilder struct {
+	cluster     string
+	subnets     []string
+	vcpuSockets int32
+}
+
+// Build builds a new Nutanix machine config based on the configuration provided.
+func (m NutanixProviderSpecBuilder) Build() *machinev1.NutanixMachineProviderConfig {
+	providerSpec := &machinev1.NutanixMachineProviderConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "machine.openshift.io/v1",
+			Kind:       "NutanixMachineProviderConfig",
+		},
+		Cluster: NutanixResourceName(m.cluster),
+		Image:   NutanixResourceName("rhcos-4.13"),
+		Subnets: []machinev1.NutanixResourceIdentifier{},
+		CredentialsSecret: &corev1.LocalObjectReference{
+			Name: "nutanix-credentials",
+		},
+		UserDataSecret: &corev1.LocalObjectReference{
+			Name: "master-user-data",
+		},
+		VCPUsPerSocket: 1,
+		VCPUSockets:    m.vcpuSockets,
+		MemorySize:     resource.MustParse("16Gi"),
+		SystemDiskSize: resource.MustParse("120Gi"),
+	}
+
+	for _, subnet := range m.subnets {
+		providerSpec.Subnets = append(providerSpec.Subnets, NutanixResourceName(subnet))
+	}
+
+	return providerSpec
+}
+
+// BuildRawExtension builds a new Nutanix machine config based on the configuration provided.
+func (m NutanixProviderSpecBuilder) BuildRawExtension() *runtime.RawExtension {
+	providerConfig := m.Build()
+
+	raw, err := json.Marshal(providerConfig)
+	if err != nil {
+		// As we are building the input to json.Marshal, this should never happen.
+		panic(err)
+	}
+
+	return &runtime.RawExtension{
+		Raw: raw,
+	}
+}
+
+// WithCluster sets the name of the Prism Element (cluster) for the Nutanix machine config builder.
+func (m NutanixProviderSpecBuilder) WithCluster(cluster string) NutanixProviderSpecBuilder {
+	m.cluster = cluster
+	return m
+}
+
+// WithSubnets sets the names of the subnets for the Nutanix machine config builder.
+func (m NutanixProviderSpecBuilder) WithSubnets(subnets ...string) NutanixProviderSpecBuilder {
+	m.subnets = subnets
+	return m
+}
+
+// WithVCPUSockets sets the number of vCPU sockets for the Nutanix machine config builder.
+func (m NutanixProviderSpecBuilder) WithVCPUSockets(vcpuSockets int32) NutanixProviderSpecBuilder {
+	m.vcpuSockets = vcpuSockets
+	return m
+}
+
+// NutanixResourceName returns a Nutanix resource identifier referencing the resource by name.
+func NutanixResourceName(name string) machinev1.NutanixResourceIdentifier {
+	return machinev1.NutanixResourceIdentifier{
+		Type: machinev1.NutanixIdentifierName,
+		Name: pointer.String(name),
+	}
+}
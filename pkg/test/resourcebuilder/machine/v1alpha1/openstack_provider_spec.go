@@ -0,0 +1,137 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	machinev1alpha1 "github.com/openshift/api/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// OpenStackProviderSpec creates a new OpenStack machine config builder.
+func OpenStackProviderSpec() OpenStackProviderSpecBuilder {
+	return OpenStackProviderSpecBuilder{
+		availabilityZone: "nova-az1",
+		flavor:           "m1.xlarge",
+	}
+}
+
+// OpenStackProviderSpecBuilder is used to build an OpenStack machine config object.
+type OpenStackProviderSpecBuilder struct {
+	availabilityZone           string
+	flavor                     string
+	rootVolume                 bool
+	rootVolumeAvailabilityZone string
+}
+
+// Build builds a new OpenStack machine config based on the configuration provided.
+func (m OpenStackProviderSpecBuilder) Build() *machinev1alpha1.OpenstackProviderSpec {
+	providerSpec := &machinev1alpha1.OpenstackProviderSpec{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "machine.openshift.io/v1alpha1",
+			Kind:       "OpenstackProviderSpec",
+		},
+		CloudsSecret: &corev1.SecretReference{
+			Name:      "openstack-cloud-credentials",
+			Namespace: "openshift-machine-api",
+		},
+		CloudName:        "openstack",
+		Flavor:           m.flavor,
+		Image:            "rhcos-4.13",
+		AvailabilityZone: m.availabilityZone,
+		Networks: []machinev1alpha1.NetworkParam{
+			{
+				Subnets: []machinev1alpha1.SubnetParam{
+					{
+						Filter: machinev1alpha1.SubnetFilter{
+							Name: "openstack-subnet-12345678",
+						},
+					},
+				},
+			},
+		},
+		SecurityGroups: []machinev1alpha1.SecurityGroupParam{
+			{
+				Name: "openstack-master-12345678",
+			},
+		},
+		UserDataSecret: &corev1.SecretReference{
+			Name: "master-user-data",
+		},
+		Tags: []string{
+			"openshiftClusterID=openstack-12345678",
+		},
+		ServerGroupName: "openstack-master-12345678",
+	}
+
+	if m.rootVolume {
+		providerSpec.Image = ""
+		providerSpec.RootVolume = &machinev1alpha1.RootVolume{
+			SourceUUID: "rhcos-4.13",
+			VolumeType: "tripleo",
+			Size:       100,
+			Zone:       m.rootVolumeAvailabilityZone,
+		}
+	}
+
+	return providerSpec
+}
+
+// BuildRawExtension builds a new OpenStack machine config based on the configuration provided.
+func (m OpenStackProviderSpecBuilder) BuildRawExtension() *runtime.RawExtension {
+	providerConfig := m.Build()
+
+	raw, err := json.Marshal(providerConfig)
+	if err != nil {
+		// As we are building the input to json.Marshal, this should never happen.
+		panic(err)
+	}
+
+	return &runtime.RawExtension{
+		Raw: raw,
+	}
+}
+
+// WithAvailabilityZone sets the availability zone for the OpenStack machine config builder.
+func (m OpenStackProviderSpecBuilder) WithAvailabilityZone(az string) OpenStackProviderSpecBuilder {
+	m.availabilityZone = az
+	return m
+}
+
+// WithFlavor sets the flavor for the OpenStack machine config builder.
+func (m OpenStackProviderSpecBuilder) WithFlavor(flavor string) OpenStackProviderSpecBuilder {
+	m.flavor = flavor
+	return m
+}
+
+// WithRootVolume configures a root volume for the OpenStack machine config builder.
+func (m OpenStackProviderSpecBuilder) WithRootVolume() OpenStackProviderSpecBuilder {
+	m.rootVolume = true
+	return m
+}
+
+// WithRootVolumeAvailabilityZone configures a root volume with the availability zone for the OpenStack machine
+// config builder.
+func (m OpenStackProviderSpecBuilder) WithRootVolumeAvailabilityZone(az string) OpenStackProviderSpecBuilder {
+	m.rootVolume = true
+	m.rootVolumeAvailabilityZone = az
+
+	return m
+}
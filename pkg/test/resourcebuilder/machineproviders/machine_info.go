@@ -38,24 +38,35 @@ type MachineInfoBuilder struct {
 	machineName              string
 	machineNamespace         string
 	machineLabels            map[string]string
+	machineAnnotations       map[string]string
 	machineOwnerRefs         []metav1.OwnerReference
 
 	nodeGVR  schema.GroupVersionResource
 	nodeName string
 
-	errorMessage string
-	index        int32
-	needsUpdate  bool
-	ready        bool
+	diff               []machineproviders.FieldDiff
+	errorMessage       string
+	evacuating         bool
+	inPlaceDiff        []machineproviders.FieldDiff
+	index              int32
+	needsInPlaceUpdate bool
+	needsUpdate        bool
+	ready              bool
+	templateRevision   string
 }
 
 // Build builds a new machineinfo based on the configuration provided.
 func (m MachineInfoBuilder) Build() machineproviders.MachineInfo {
 	info := machineproviders.MachineInfo{
-		ErrorMessage: m.errorMessage,
-		Index:        m.index,
-		Ready:        m.ready,
-		NeedsUpdate:  m.needsUpdate,
+		ErrorMessage:       m.errorMessage,
+		Index:              m.index,
+		Ready:              m.ready,
+		NeedsUpdate:        m.needsUpdate,
+		NeedsInPlaceUpdate: m.needsInPlaceUpdate,
+		Evacuating:         m.evacuating,
+		Diff:               m.diff,
+		InPlaceDiff:        m.inPlaceDiff,
+		TemplateRevision:   m.templateRevision,
 	}
 
 	if m.machineName != "" {
@@ -65,6 +76,7 @@ func (m MachineInfoBuilder) Build() machineproviders.MachineInfo {
 				DeletionTimestamp: m.machineDeletiontimestamp,
 				CreationTimestamp: m.machineCreationtimestamp,
 				Labels:            m.machineLabels,
+				Annotations:       m.machineAnnotations,
 				Name:              m.machineName,
 				Namespace:         m.machineNamespace,
 				OwnerReferences:   m.machineOwnerRefs,
@@ -84,6 +96,12 @@ func (m MachineInfoBuilder) Build() machineproviders.MachineInfo {
 	return info
 }
 
+// WithMachineAnnotations sets the machine annotations for the machineinfo builder.
+func (m MachineInfoBuilder) WithMachineAnnotations(annotations map[string]string) MachineInfoBuilder {
+	m.machineAnnotations = annotations
+	return m
+}
+
 // WithMachineCreationTimestamp sets the machine creation timestamp for the machineinfo builder.
 func (m MachineInfoBuilder) WithMachineCreationTimestamp(creation metav1.Time) MachineInfoBuilder {
 	m.machineCreationtimestamp = creation
@@ -144,12 +162,30 @@ func (m MachineInfoBuilder) WithNodeName(name string) MachineInfoBuilder {
 	return m
 }
 
+// WithDiff sets the diff for the machineinfo builder.
+func (m MachineInfoBuilder) WithDiff(diff ...machineproviders.FieldDiff) MachineInfoBuilder {
+	m.diff = diff
+	return m
+}
+
 // WithErrorMessage sets the error message for the machineinfo builder.
 func (m MachineInfoBuilder) WithErrorMessage(errorMsg string) MachineInfoBuilder {
 	m.errorMessage = errorMsg
 	return m
 }
 
+// WithEvacuating sets the evacuating for the machineinfo builder.
+func (m MachineInfoBuilder) WithEvacuating(evacuating bool) MachineInfoBuilder {
+	m.evacuating = evacuating
+	return m
+}
+
+// WithInPlaceDiff sets the in place diff for the machineinfo builder.
+func (m MachineInfoBuilder) WithInPlaceDiff(diff ...machineproviders.FieldDiff) MachineInfoBuilder {
+	m.inPlaceDiff = diff
+	return m
+}
+
 // WithIndex sets the index for the machineinfo builder.
 func (m MachineInfoBuilder) WithIndex(index int32) MachineInfoBuilder {
 	m.index = index
@@ -162,8 +198,20 @@ func (m MachineInfoBuilder) WithNeedsUpdate(needsUpdate bool) MachineInfoBuilder
 	return m
 }
 
+// WithNeedsInPlaceUpdate sets the needsinplaceupdate for the machineinfo builder.
+func (m MachineInfoBuilder) WithNeedsInPlaceUpdate(needsInPlaceUpdate bool) MachineInfoBuilder {
+	m.needsInPlaceUpdate = needsInPlaceUpdate
+	return m
+}
+
 // WithReady sets the ready for the machineinfo builder.
 func (m MachineInfoBuilder) WithReady(ready bool) MachineInfoBuilder {
 	m.ready = ready
 	return m
 }
+
+// WithTemplateRevision sets the template revision for the machineinfo builder.
+func (m MachineInfoBuilder) WithTemplateRevision(templateRevision string) MachineInfoBuilder {
+	m.templateRevision = templateRevision
+	return m
+}
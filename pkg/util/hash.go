@@ -0,0 +1,64 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	machinev1 "github.com/openshift/api/machine/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// TemplateHash returns a short hash of the parts of the ControlPlaneMachineSet template that require the Control Plane
+// Machines to be replaced when they change.
+// The labels and annotations of the template, and the taints within the Machine spec, are excluded from the hash as
+// they are updated in place on the existing Machines.
+// On platforms where the failure domains are configured by annotation, the failure domains annotations are part of
+// the hash, as the failure domains would otherwise be configured within the template.
+func TemplateHash(template machinev1.ControlPlaneMachineSetTemplate, failureDomainsAnnotations map[string]string) (string, error) {
+	template = *template.DeepCopy()
+
+	if template.OpenShiftMachineV1Beta1Machine != nil {
+		template.OpenShiftMachineV1Beta1Machine.ObjectMeta = machinev1.ControlPlaneMachineSetTemplateObjectMeta{}
+		template.OpenShiftMachineV1Beta1Machine.Spec.Taints = nil
+	}
+
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal template: %w", err)
+	}
+
+	hasher := fnv.New32a()
+
+	// Writes to the hasher never return an error.
+	_, _ = hasher.Write(data)
+
+	// Only hash the annotations when they are set, so that the hash of a template without them does not change.
+	if len(failureDomainsAnnotations) > 0 {
+		// Maps are marshalled with sorted keys, so the data does not depend on the iteration order.
+		annotationsData, err := json.Marshal(failureDomainsAnnotations)
+		if err != nil {
+			return "", fmt.Errorf("could not marshal failure domains annotations: %w", err)
+		}
+
+		_, _ = hasher.Write(annotationsData)
+	}
+
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())), nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InfrastructureName is the name of the cluster scoped singleton Infrastructure resource.
+const InfrastructureName = "cluster"
+
+// GetInfrastructure fetches the cluster Infrastructure resource.
+func GetInfrastructure(ctx context.Context, cl client.Reader) (*configv1.Infrastructure, error) {
+	infrastructure := &configv1.Infrastructure{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: InfrastructureName}, infrastructure); err != nil {
+		return nil, fmt.Errorf("unable to get infrastructure object: %w", err)
+	}
+
+	return infrastructure, nil
+}
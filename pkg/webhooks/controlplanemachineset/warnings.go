@@ -0,0 +1,115 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplanemachineset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1 "github.com/openshift/api/machine/v1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// warningHandler wraps the validating admission handler to add warnings to the response for
+// ControlPlaneMachineSets that are accepted, but are configured in a way that may not be intended.
+// The CustomValidator interface does not allow validators to return warnings.
+type warningHandler struct {
+	handler admission.Handler
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &warningHandler{}
+
+// InjectDecoder injects the decoder into the warningHandler and the handler it wraps.
+func (h *warningHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+
+	if _, err := admission.InjectDecoderInto(d, h.handler); err != nil {
+		return fmt.Errorf("could not inject decoder: %w", err)
+	}
+
+	return nil
+}
+
+// Handle handles admission requests, adding warnings to the response when the request is allowed.
+func (h *warningHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	resp := h.handler.Handle(ctx, req)
+
+	if !resp.Allowed || req.Operation == admissionv1.Delete {
+		return resp
+	}
+
+	cpms := &machinev1.ControlPlaneMachineSet{}
+	if err := h.decoder.DecodeRaw(req.Object, cpms); err != nil {
+		// The wrapped handler has already decoded the object, so this should never happen.
+		// Warnings are informational, so do not deny the request when they cannot be computed.
+		return resp
+	}
+
+	return resp.WithWarnings(getWarnings(cpms)...)
+}
+
+// getWarnings returns the warnings for the ControlPlaneMachineSet.
+func getWarnings(cpms *machinev1.ControlPlaneMachineSet) []string {
+	return ignoredProviderSpecFieldsWarnings(field.NewPath("metadata", "annotations"), cpms)
+}
+
+// ignoredProviderSpecFieldsWarnings warns about each ignored provider spec field that covers a field that
+// determines where a Machine runs, or the resources it has.
+// Changes to these fields would not cause outdated Machines to be replaced.
+func ignoredProviderSpecFieldsWarnings(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet) []string {
+	value, ok := cpms.Annotations[annotations.IgnoredProviderSpecFieldsAnnotation]
+	if !ok {
+		return nil
+	}
+
+	ignoredFields, err := annotations.ParseIgnoredProviderSpecFields(value)
+	if err != nil {
+		// Invalid values are rejected by the validation.
+		return nil
+	}
+
+	platforms := []string{}
+	for platform := range ignoredFields {
+		platforms = append(platforms, string(platform))
+	}
+
+	sort.Strings(platforms)
+
+	warnings := []string{}
+
+	for _, platform := range platforms {
+		for _, path := range ignoredFields[configv1.PlatformType(platform)] {
+			significantFields := providerconfig.SignificantFieldsCoveredBy(configv1.PlatformType(platform), path)
+			if len(significantFields) == 0 {
+				continue
+			}
+
+			warnings = append(warnings, fmt.Sprintf("%s: ignoring %s on %s platform means that Machines will not be replaced when %s changes",
+				parentPath.Key(annotations.IgnoredProviderSpecFieldsAnnotation), path, platform, strings.Join(significantFields, ", ")))
+		}
+	}
+
+	return warnings
+}
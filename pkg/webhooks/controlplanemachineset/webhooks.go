@@ -24,16 +24,21 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1 "github.com/openshift/api/machine/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/failuredomain"
 	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/machineproviders/providers/openshift/machine/v1beta1/providerconfig"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/templaterevisions"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 const (
@@ -52,6 +57,9 @@ const (
 	// clusterSingletonName is the OpenShift standard name, "cluster", for singleton
 	// resources. All ControlPlaneMachineSet resources must use this name.
 	clusterSingletonName = "cluster"
+
+	// validatingWebhookPath is the path at which the ControlPlaneMachineSet validating webhook is served.
+	validatingWebhookPath = "/validate-machine-openshift-io-v1-controlplanemachineset"
 )
 
 var (
@@ -71,12 +79,13 @@ type ControlPlaneMachineSetWebhook struct {
 // SetupWebhookWithManager sets up a new ControlPlaneMachineSet webhook with the manager.
 func (r *ControlPlaneMachineSetWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	r.client = mgr.GetClient()
-	if err := ctrl.NewWebhookManagedBy(mgr).
-		WithValidator(r).
-		For(&machinev1.ControlPlaneMachineSet{}).
-		Complete(); err != nil {
-		return fmt.Errorf("error constructing ControlPlaneMachineSet webhook: %w", err)
-	}
+
+	// Register the webhook directly, rather than through the webhook builder, so that the validator
+	// can be wrapped to add warnings to the admission response.
+	validatingWebhook := admission.WithCustomValidator(&machinev1.ControlPlaneMachineSet{}, r)
+	validatingWebhook.Handler = &warningHandler{handler: validatingWebhook.Handler}
+
+	mgr.GetWebhookServer().Register(validatingWebhookPath, validatingWebhook)
 
 	return nil
 }
@@ -94,9 +103,21 @@ func (r *ControlPlaneMachineSetWebhook) ValidateCreate(ctx context.Context, obj
 		return errObjNotCPMS
 	}
 
+	var infrastructure *configv1.Infrastructure
+
+	if requiresInfrastructure(cpms) {
+		var err error
+
+		infrastructure, err = util.GetInfrastructure(ctx, r.client)
+		if err != nil {
+			return fmt.Errorf("could not fetch infrastructure: %w", err)
+		}
+	}
+
 	errs = append(errs, validateMetadata(field.NewPath("metadata"), cpms.ObjectMeta)...)
+	errs = append(errs, validateAnnotations(field.NewPath("metadata", "annotations"), cpms, infrastructure)...)
 	errs = append(errs, validateSpec(field.NewPath("spec"), cpms)...)
-	errs = append(errs, r.validateSpecOnCreate(ctx, field.NewPath("spec"), cpms)...)
+	errs = append(errs, r.validateSpecOnCreate(ctx, field.NewPath("spec"), cpms, infrastructure)...)
 
 	if len(errs) > 0 {
 		return utilerrors.NewAggregate(errs)
@@ -118,7 +139,20 @@ func (r *ControlPlaneMachineSetWebhook) ValidateUpdate(ctx context.Context, oldO
 		return errObjNotCPMS
 	}
 
+	var infrastructure *configv1.Infrastructure
+
+	if requiresInfrastructure(cpms) {
+		var err error
+
+		infrastructure, err = util.GetInfrastructure(ctx, r.client)
+		if err != nil {
+			return fmt.Errorf("could not fetch infrastructure: %w", err)
+		}
+	}
+
 	errs = append(errs, validateMetadata(field.NewPath("metadata"), cpms.ObjectMeta)...)
+	errs = append(errs, validateAnnotations(field.NewPath("metadata", "annotations"), cpms, infrastructure)...)
+	errs = append(errs, r.validateRollback(ctx, field.NewPath("metadata", "annotations"), cpms)...)
 	errs = append(errs, validateSpec(field.NewPath("spec"), cpms)...)
 
 	if len(errs) > 0 {
@@ -134,7 +168,7 @@ func (r *ControlPlaneMachineSetWebhook) ValidateDelete(ctx context.Context, obj
 }
 
 // validateSpecOnCreate runs the create time validations on the ControlPlaneMachineSet spec.
-func (r *ControlPlaneMachineSetWebhook) validateSpecOnCreate(ctx context.Context, parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet) []error {
+func (r *ControlPlaneMachineSetWebhook) validateSpecOnCreate(ctx context.Context, parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet, infrastructure *configv1.Infrastructure) []error {
 	// TODO: This should be MachineInfos and should come from the MachineProvider.
 	// This is a blocker for adding Cluster API support right now.
 	controlPlaneMachines, err := r.fetchControlPlaneMachines(ctx)
@@ -153,7 +187,7 @@ func (r *ControlPlaneMachineSetWebhook) validateSpecOnCreate(ctx context.Context
 			fmt.Sprintf("control plane machine set replicas (%d) does not match the current number of control plane machines (%d)", *cpms.Spec.Replicas, len(controlPlaneMachines))))
 	}
 
-	errs = append(errs, validateTemplateOnCreate(parentPath.Child("template"), cpms.Spec.Template, controlPlaneMachines)...)
+	errs = append(errs, validateTemplateOnCreate(parentPath.Child("template"), cpms, infrastructure, controlPlaneMachines)...)
 
 	return errs
 }
@@ -169,6 +203,342 @@ func validateMetadata(parentPath *field.Path, metadata metav1.ObjectMeta) []erro
 	return errs
 }
 
+// validateAnnotations validates the annotations used to configure the behaviour of the ControlPlaneMachineSet.
+func validateAnnotations(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet, infrastructure *configv1.Infrastructure) []error {
+	errs := []error{}
+
+	if value, ok := cpms.Annotations[annotations.MaxSurgeAnnotation]; ok {
+		if _, err := annotations.ParseMaxSurge(cpms, value); err != nil {
+			errs = append(errs, field.Invalid(parentPath.Key(annotations.MaxSurgeAnnotation), value, err.Error()))
+		}
+	}
+
+	if value, ok := cpms.Annotations[annotations.PausedAnnotation]; ok {
+		if _, err := annotations.ParsePaused(value); err != nil {
+			errs = append(errs, field.Invalid(parentPath.Key(annotations.PausedAnnotation), value, err.Error()))
+		}
+	}
+
+	if value, ok := cpms.Annotations[annotations.PartitionAnnotation]; ok {
+		if _, err := annotations.ParsePartition(cpms, value); err != nil {
+			errs = append(errs, field.Invalid(parentPath.Key(annotations.PartitionAnnotation), value, err.Error()))
+		}
+	}
+
+	if value, ok := cpms.Annotations[annotations.ReplacementOrderAnnotation]; ok {
+		if _, err := annotations.ParseReplacementOrder(cpms, value); err != nil {
+			errs = append(errs, field.Invalid(parentPath.Key(annotations.ReplacementOrderAnnotation), value, err.Error()))
+		}
+	}
+
+	if value, ok := cpms.Annotations[annotations.IgnoredProviderSpecFieldsAnnotation]; ok {
+		if _, err := annotations.ParseIgnoredProviderSpecFields(value); err != nil {
+			errs = append(errs, field.Invalid(parentPath.Key(annotations.IgnoredProviderSpecFieldsAnnotation), value, err.Error()))
+		}
+	}
+
+	errs = append(errs, validateMaintenanceWindowAnnotations(parentPath, cpms)...)
+	errs = append(errs, validateOpenStackFailureDomainsAnnotation(parentPath, cpms)...)
+	errs = append(errs, validateVSphereFailureDomainsAnnotation(parentPath, cpms, infrastructure)...)
+	errs = append(errs, validateNutanixFailureDomainsAnnotation(parentPath, cpms, infrastructure)...)
+	errs = append(errs, validateFailureDomainWeightsAnnotation(parentPath, cpms)...)
+	errs = append(errs, validateUnavailableFailureDomainsAnnotation(parentPath, cpms)...)
+
+	return errs
+}
+
+// validateRollback validates that the template revision requested by the rollback annotation is kept for the
+// ControlPlaneMachineSet, and that the template would still be valid once restored.
+func (r *ControlPlaneMachineSetWebhook) validateRollback(ctx context.Context, parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet) []error {
+	annotationPath := parentPath.Key(annotations.RollbackToRevisionAnnotation)
+
+	value, ok := cpms.Annotations[annotations.RollbackToRevisionAnnotation]
+	if !ok {
+		return []error{}
+	}
+
+	currentRevision, err := util.TemplateHash(cpms.Spec.Template, annotations.FailureDomainsAnnotationValues(cpms))
+	if err != nil {
+		return []error{fmt.Errorf("could not calculate template revision: %w", err)}
+	}
+
+	revisions, err := templaterevisions.Get(ctx, r.client, cpms)
+	if err != nil {
+		return []error{fmt.Errorf("could not fetch template revisions: %w", err)}
+	}
+
+	target, err := templaterevisions.Find(revisions, value, currentRevision)
+	if err != nil {
+		return []error{field.Invalid(annotationPath, value, err.Error())}
+	}
+
+	errs := []error{}
+
+	for _, err := range validateTemplate(field.NewPath("spec", "template"), target.Template, cpms.Spec.Selector) {
+		errs = append(errs, field.Invalid(annotationPath, value, fmt.Sprintf("restored template is invalid: %s", err.Error())))
+	}
+
+	return errs
+}
+
+// validateUnavailableFailureDomainsAnnotation validates that the unavailable failure domains annotation is valid,
+// that each entry refers to a configured failure domain, and that at least one failure domain remains available.
+func validateUnavailableFailureDomainsAnnotation(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet) []error {
+	annotationPath := parentPath.Key(annotations.UnavailableFailureDomainsAnnotation)
+
+	value, ok := cpms.Annotations[annotations.UnavailableFailureDomainsAnnotation]
+	if !ok {
+		return []error{}
+	}
+
+	unavailable, err := annotations.ParseUnavailableFailureDomains(value)
+	if err != nil {
+		return []error{field.Invalid(annotationPath, value, err.Error())}
+	}
+
+	failureDomains, err := annotations.FailureDomains(cpms)
+	if err != nil {
+		// The failure domains are validated separately.
+		return []error{}
+	}
+
+	if len(failureDomains) == 0 {
+		return []error{field.Forbidden(annotationPath, annotations.ErrUnexpectedUnavailableFailureDomains.Error())}
+	}
+
+	configured := sets.New[string]()
+	for _, failureDomain := range failureDomains {
+		configured.Insert(failureDomain.String())
+	}
+
+	errs := []error{}
+
+	for _, failureDomain := range unavailable {
+		if !configured.Has(failureDomain) {
+			errs = append(errs, field.Invalid(annotationPath, value, fmt.Sprintf("%s: %s", annotations.ErrUnknownFailureDomain.Error(), failureDomain)))
+		}
+	}
+
+	if configured.Difference(sets.New(unavailable...)).Len() == 0 {
+		errs = append(errs, field.Invalid(annotationPath, value, annotations.ErrAllFailureDomainsUnavailable.Error()))
+	}
+
+	return errs
+}
+
+// validateFailureDomainWeightsAnnotation validates that the failure domain weights annotation is valid,
+// that each weight refers to a configured failure domain, and that not every failure domain is avoided.
+func validateFailureDomainWeightsAnnotation(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet) []error {
+	annotationPath := parentPath.Key(annotations.FailureDomainWeightsAnnotation)
+
+	value, ok := cpms.Annotations[annotations.FailureDomainWeightsAnnotation]
+	if !ok {
+		return []error{}
+	}
+
+	weights, err := annotations.ParseFailureDomainWeights(value)
+	if err != nil {
+		return []error{field.Invalid(annotationPath, value, err.Error())}
+	}
+
+	failureDomains, err := annotations.FailureDomains(cpms)
+	if err != nil {
+		// The failure domains are validated separately.
+		return []error{}
+	}
+
+	if len(failureDomains) == 0 {
+		return []error{field.Forbidden(annotationPath, annotations.ErrUnexpectedFailureDomainWeights.Error())}
+	}
+
+	configured := sets.New[string]()
+	for _, failureDomain := range failureDomains {
+		configured.Insert(failureDomain.String())
+	}
+
+	errs := []error{}
+	avoided := sets.New[string]()
+
+	for _, weight := range weights {
+		if !configured.Has(weight.FailureDomain) {
+			errs = append(errs, field.Invalid(annotationPath, value, fmt.Sprintf("%s: %s", annotations.ErrUnknownFailureDomain.Error(), weight.FailureDomain)))
+
+			continue
+		}
+
+		if weight.Avoid {
+			avoided.Insert(weight.FailureDomain)
+		}
+	}
+
+	if avoided.Len() == configured.Len() {
+		errs = append(errs, field.Invalid(annotationPath, value, annotations.ErrAllFailureDomainsAvoided.Error()))
+	}
+
+	return errs
+}
+
+// validateMaintenanceWindowAnnotations validates that the maintenance window annotations are valid
+// and are set together.
+func validateMaintenanceWindowAnnotations(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet) []error {
+	errs := []error{}
+
+	schedule, hasSchedule := cpms.Annotations[annotations.MaintenanceWindowScheduleAnnotation]
+	duration, hasDuration := cpms.Annotations[annotations.MaintenanceWindowDurationAnnotation]
+
+	if hasSchedule {
+		if _, err := annotations.ParseMaintenanceWindowSchedule(schedule); err != nil {
+			errs = append(errs, field.Invalid(parentPath.Key(annotations.MaintenanceWindowScheduleAnnotation), schedule, err.Error()))
+		}
+	} else if hasDuration {
+		errs = append(errs, field.Required(parentPath.Key(annotations.MaintenanceWindowScheduleAnnotation), annotations.ErrIncompleteMaintenanceWindow.Error()))
+	}
+
+	if hasDuration {
+		if _, err := annotations.ParseMaintenanceWindowDuration(duration); err != nil {
+			errs = append(errs, field.Invalid(parentPath.Key(annotations.MaintenanceWindowDurationAnnotation), duration, err.Error()))
+		}
+	} else if hasSchedule {
+		errs = append(errs, field.Required(parentPath.Key(annotations.MaintenanceWindowDurationAnnotation), annotations.ErrIncompleteMaintenanceWindow.Error()))
+	}
+
+	return errs
+}
+
+// validateOpenStackFailureDomainsAnnotation validates that the OpenStack failure domains annotation is valid,
+// and is set when, and only when, the platform of the template failure domains is OpenStack.
+func validateOpenStackFailureDomainsAnnotation(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet) []error {
+	annotationPath := parentPath.Key(annotations.OpenStackFailureDomainsAnnotation)
+	value, hasAnnotation := cpms.Annotations[annotations.OpenStackFailureDomainsAnnotation]
+
+	template := cpms.Spec.Template.OpenShiftMachineV1Beta1Machine
+	isOpenStack := template != nil && template.FailureDomains.Platform == configv1.OpenStackPlatformType
+
+	switch {
+	case !hasAnnotation && isOpenStack:
+		return []error{field.Required(annotationPath, annotations.ErrMissingFailureDomains.Error())}
+	case !hasAnnotation:
+		return []error{}
+	case !isOpenStack:
+		return []error{field.Forbidden(annotationPath, annotations.ErrUnexpectedFailureDomains.Error())}
+	}
+
+	failureDomains, err := annotations.ParseOpenStackFailureDomains(value)
+	if err != nil {
+		return []error{field.Invalid(annotationPath, value, err.Error())}
+	}
+
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(*template, nil)
+	if err != nil || providerConfig.Type() != configv1.OpenStackPlatformType {
+		// Errors in the provider config are reported by the template validation.
+		return []error{}
+	}
+
+	if providerConfig.OpenStack().Config().RootVolume != nil {
+		return []error{}
+	}
+
+	for _, failureDomain := range failureDomains {
+		if failureDomain.RootVolume != nil {
+			return []error{field.Invalid(annotationPath, value, "a root volume availability zone may only be configured when the provider spec configures a root volume")}
+		}
+	}
+
+	return []error{}
+}
+
+// validateVSphereFailureDomainsAnnotation validates that the vSphere failure domains annotation is valid,
+// and is set when, and only when, the platform of the template failure domains is VSphere.
+// Each failure domain must be defined within the vSphere platform spec of the cluster Infrastructure.
+func validateVSphereFailureDomainsAnnotation(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet, infrastructure *configv1.Infrastructure) []error {
+	annotationPath := parentPath.Key(annotations.VSphereFailureDomainsAnnotation)
+	value, hasAnnotation := cpms.Annotations[annotations.VSphereFailureDomainsAnnotation]
+
+	template := cpms.Spec.Template.OpenShiftMachineV1Beta1Machine
+	isVSphere := template != nil && template.FailureDomains.Platform == configv1.VSpherePlatformType
+
+	switch {
+	case !hasAnnotation && isVSphere:
+		return []error{field.Required(annotationPath, annotations.ErrMissingFailureDomains.Error())}
+	case !hasAnnotation:
+		return []error{}
+	case !isVSphere:
+		return []error{field.Forbidden(annotationPath, annotations.ErrUnexpectedFailureDomains.Error())}
+	}
+
+	failureDomains, err := annotations.ParseVSphereFailureDomains(value)
+	if err != nil {
+		return []error{field.Invalid(annotationPath, value, err.Error())}
+	}
+
+	definedFailureDomains := sets.New[string]()
+
+	if infrastructure != nil && infrastructure.Spec.PlatformSpec.VSphere != nil {
+		for _, failureDomain := range infrastructure.Spec.PlatformSpec.VSphere.FailureDomains {
+			definedFailureDomains.Insert(failureDomain.Name)
+		}
+	}
+
+	errs := []error{}
+
+	for _, failureDomain := range failureDomains {
+		if !definedFailureDomains.Has(failureDomain.Name) {
+			errs = append(errs, field.Invalid(annotationPath, value, fmt.Sprintf("failure domain %q is not defined in the vSphere platform spec of the infrastructure", failureDomain.Name)))
+		}
+	}
+
+	return errs
+}
+
+// validateNutanixFailureDomainsAnnotation validates that the Nutanix failure domains annotation is valid,
+// and is set when, and only when, the platform of the template failure domains is Nutanix.
+// Each Prism Element (cluster) referenced by name must be defined within the Nutanix platform spec of the
+// cluster Infrastructure. The Infrastructure does not record the UUIDs of the Prism Elements, so Prism Elements
+// referenced by UUID are not checked.
+func validateNutanixFailureDomainsAnnotation(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet, infrastructure *configv1.Infrastructure) []error {
+	annotationPath := parentPath.Key(annotations.NutanixFailureDomainsAnnotation)
+	value, hasAnnotation := cpms.Annotations[annotations.NutanixFailureDomainsAnnotation]
+
+	template := cpms.Spec.Template.OpenShiftMachineV1Beta1Machine
+	isNutanix := template != nil && template.FailureDomains.Platform == configv1.NutanixPlatformType
+
+	switch {
+	case !hasAnnotation && isNutanix:
+		return []error{field.Required(annotationPath, annotations.ErrMissingFailureDomains.Error())}
+	case !hasAnnotation:
+		return []error{}
+	case !isNutanix:
+		return []error{field.Forbidden(annotationPath, annotations.ErrUnexpectedFailureDomains.Error())}
+	}
+
+	failureDomains, err := annotations.ParseNutanixFailureDomains(value)
+	if err != nil {
+		return []error{field.Invalid(annotationPath, value, err.Error())}
+	}
+
+	definedPrismElements := sets.New[string]()
+
+	if infrastructure != nil && infrastructure.Spec.PlatformSpec.Nutanix != nil {
+		for _, prismElement := range infrastructure.Spec.PlatformSpec.Nutanix.PrismElements {
+			definedPrismElements.Insert(prismElement.Name)
+		}
+	}
+
+	errs := []error{}
+
+	for _, failureDomain := range failureDomains {
+		if failureDomain.Cluster.Type != machinev1.NutanixIdentifierName {
+			continue
+		}
+
+		if !definedPrismElements.Has(*failureDomain.Cluster.Name) {
+			errs = append(errs, field.Invalid(annotationPath, value, fmt.Sprintf("prism element %q is not defined in the Nutanix platform spec of the infrastructure", *failureDomain.Cluster.Name)))
+		}
+	}
+
+	return errs
+}
+
 // validateSpec validates that the spec of the ControlPlaneMachineSet resource is valid.
 func validateSpec(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet) []error {
 	errs := []error{}
@@ -198,7 +568,9 @@ func validateTemplate(parentPath *field.Path, template machinev1.ControlPlaneMac
 
 // validateTemplateOnCreate validates the failure domains defined in the template match up with the Machines
 // that already exist within the cluster. This check is only performed on create.
-func validateTemplateOnCreate(parentPath *field.Path, template machinev1.ControlPlaneMachineSetTemplate, machines []machinev1beta1.Machine) []error {
+func validateTemplateOnCreate(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet, infrastructure *configv1.Infrastructure, machines []machinev1beta1.Machine) []error {
+	template := cpms.Spec.Template
+
 	switch template.MachineType {
 	case machinev1.OpenShiftMachineV1Beta1MachineType:
 		openshiftMachineTemplatePath := parentPath.Child(string(machinev1.OpenShiftMachineV1Beta1MachineType))
@@ -209,7 +581,7 @@ func validateTemplateOnCreate(parentPath *field.Path, template machinev1.Control
 			return []error{field.Required(openshiftMachineTemplatePath, fmt.Sprintf("%s is required when machine type is %s", machinev1.OpenShiftMachineV1Beta1MachineType, machinev1.OpenShiftMachineV1Beta1MachineType))}
 		}
 
-		return validateOpenShiftMachineV1BetaTemplateOnCreate(openshiftMachineTemplatePath, *template.OpenShiftMachineV1Beta1Machine, machines)
+		return validateOpenShiftMachineV1BetaTemplateOnCreate(openshiftMachineTemplatePath, cpms, infrastructure, machines)
 	default:
 		return []error{field.NotSupported(parentPath.Child("machineType"), template.MachineType, []string{string(machinev1.OpenShiftMachineV1Beta1MachineType)})}
 	}
@@ -227,13 +599,14 @@ func validateOpenShiftMachineV1BetaTemplate(parentPath *field.Path, template mac
 
 // validateOpenShiftMachineV1BetaTemplateOnCreate validates the failure domains in the provided template match up with those
 // present in the Machines provided.
-func validateOpenShiftMachineV1BetaTemplateOnCreate(parentPath *field.Path, template machinev1.OpenShiftMachineV1Beta1MachineTemplate, machines []machinev1beta1.Machine) []error {
+func validateOpenShiftMachineV1BetaTemplateOnCreate(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet, infrastructure *configv1.Infrastructure, machines []machinev1beta1.Machine) []error {
 	errs := []error{}
+	template := *cpms.Spec.Template.OpenShiftMachineV1Beta1Machine
 
 	if template.FailureDomains.Platform == "" {
 		errs = append(errs, checkOpenShiftProviderSpecFailureDomainMatchesMachines(parentPath.Child("spec", "providerSpec"), template, machines)...)
 	} else {
-		errs = append(errs, checkOpenShiftFailureDomainsMatchMachines(parentPath.Child("failureDomains"), template.FailureDomains, machines)...)
+		errs = append(errs, checkOpenShiftFailureDomainsMatchMachines(parentPath.Child("failureDomains"), cpms, infrastructure, machines)...)
 	}
 
 	return errs
@@ -262,7 +635,7 @@ func validateTemplateLabels(labelsPath *field.Path, templateLabels map[string]st
 func validateOpenShiftProviderConfig(parentPath *field.Path, template machinev1.OpenShiftMachineV1Beta1MachineTemplate) []error {
 	providerSpecPath := parentPath.Child("spec", "providerSpec")
 
-	providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(template)
+	providerConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(template, nil)
 	if err != nil {
 		return []error{field.Invalid(providerSpecPath, template.Spec.ProviderSpec, fmt.Sprintf("error determining provider configuration: %s", err))}
 	}
@@ -297,6 +670,18 @@ func validateOpenShiftGCPProviderConfig(parentPath *field.Path, providerConfig p
 	return []error{}
 }
 
+// requiresInfrastructure returns whether the cluster Infrastructure is required to validate the ControlPlaneMachineSet.
+// This is the case when the Infrastructure is required to translate between the failure domains and the provider
+// config of the template, or when the failure domains reference resources defined within the Infrastructure.
+func requiresInfrastructure(cpms *machinev1.ControlPlaneMachineSet) bool {
+	template := cpms.Spec.Template.OpenShiftMachineV1Beta1Machine
+	if template == nil {
+		return false
+	}
+
+	return providerconfig.InfrastructureRequired(template.FailureDomains.Platform) || template.FailureDomains.Platform == configv1.NutanixPlatformType
+}
+
 // fetchControlPlaneMachines returns all control plane machines in the cluster.
 func (r *ControlPlaneMachineSetWebhook) fetchControlPlaneMachines(ctx context.Context) ([]machinev1beta1.Machine, error) {
 	machineList := machinev1beta1.MachineList{}
@@ -322,14 +707,14 @@ func (r *ControlPlaneMachineSetWebhook) fetchControlPlaneMachines(ctx context.Co
 func checkOpenShiftProviderSpecFailureDomainMatchesMachines(parentPath *field.Path, template machinev1.OpenShiftMachineV1Beta1MachineTemplate, machines []machinev1beta1.Machine) []error {
 	errs := []error{}
 
-	templateProviderConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(template)
+	templateProviderConfig, err := providerconfig.NewProviderConfigFromMachineTemplate(template, nil)
 	if err != nil {
 		return []error{field.Invalid(parentPath, template, fmt.Sprintf("error parsing provider config from machine template: %v", err))}
 	}
 
 	templateProviderSpecFailureDomain := templateProviderConfig.ExtractFailureDomain()
 
-	failureDomains, err := providerconfig.ExtractFailureDomainsFromMachines(machines)
+	failureDomains, err := providerconfig.ExtractFailureDomainsFromMachines(machines, nil)
 	if err != nil {
 		return append(errs, field.InternalError(parentPath, fmt.Errorf("could not get failure domains from cluster machines: %w", err)))
 	}
@@ -345,15 +730,16 @@ func checkOpenShiftProviderSpecFailureDomainMatchesMachines(parentPath *field.Pa
 
 // checkOpenShiftFailureDomainsMatchMachines ensures that failure domains of the Control Plane Machines match the
 // failure domains defined on the OpenShift Machine template on the ControlPlaneMachineSet.
-func checkOpenShiftFailureDomainsMatchMachines(parentPath *field.Path, failureDomains machinev1.FailureDomains, machines []machinev1beta1.Machine) []error {
+func checkOpenShiftFailureDomainsMatchMachines(parentPath *field.Path, cpms *machinev1.ControlPlaneMachineSet, infrastructure *configv1.Infrastructure, machines []machinev1beta1.Machine) []error {
 	errs := []error{}
+	failureDomains := cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains
 
-	machineFailureDomains, err := getMachineFailureDomains(machines)
+	machineFailureDomains, err := getMachineFailureDomains(machines, infrastructure)
 	if err != nil {
 		return append(errs, field.InternalError(parentPath.Child("platform"), fmt.Errorf("could not get failure domains from cluster machines on platform %s: %w", failureDomains.Platform, err)))
 	}
 
-	specifiedFailureDomains, err := failuredomain.NewFailureDomains(failureDomains)
+	specifiedFailureDomains, err := annotations.FailureDomains(cpms)
 	if err != nil {
 		return append(errs, field.Invalid(parentPath, failureDomains, fmt.Sprintf("error getting failure domains from control plane machine set machine template: %v", err)))
 	}
@@ -376,11 +762,11 @@ func checkOpenShiftFailureDomainsMatchMachines(parentPath *field.Path, failureDo
 // getMachineFailureDomains returns a list of failure domains used by the control plane machines.
 // We use this instead of providerconfig.ExtractFailureDomainsFromMachines because we want to
 // keep all machines and the providerconfig util deduplicates the failure domains.
-func getMachineFailureDomains(machines []machinev1beta1.Machine) ([]failuredomain.FailureDomain, error) {
+func getMachineFailureDomains(machines []machinev1beta1.Machine, infrastructure *configv1.Infrastructure) ([]failuredomain.FailureDomain, error) {
 	machineFailureDomains := []failuredomain.FailureDomain{}
 
 	for _, machine := range machines {
-		failureDomain, err := providerconfig.ExtractFailureDomainFromMachine(machine)
+		failureDomain, err := providerconfig.ExtractFailureDomainFromMachine(machine, infrastructure)
 		if err != nil {
 			return nil, fmt.Errorf("could not extract failure domain from machine: %w", err)
 		}
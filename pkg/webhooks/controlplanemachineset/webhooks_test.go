@@ -18,6 +18,7 @@ package controlplanemachineset
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -31,10 +32,19 @@ import (
 	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
 	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
 	machinev1beta1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/annotations"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/templaterevisions"
+	testmachinev1resourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machine/v1"
+	machinev1alpha1resourcebuilder "github.com/openshift/cluster-control-plane-machine-set-operator/pkg/test/resourcebuilder/machine/v1alpha1"
+	"github.com/openshift/cluster-control-plane-machine-set-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
 )
 
@@ -43,6 +53,16 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+// warningCollector collects the warnings returned by the API server.
+type warningCollector struct {
+	warnings []string
+}
+
+// HandleWarningHeader implements rest.WarningHandler to record each warning.
+func (w *warningCollector) HandleWarningHeader(code int, agent string, text string) {
+	w.warnings = append(w.warnings, text)
+}
+
 var _ = Describe("Webhooks", func() {
 	var mgrCancel context.CancelFunc
 	var mgrDone chan struct{}
@@ -94,6 +114,8 @@ var _ = Describe("Webhooks", func() {
 		testutils.CleanupResources(Default, ctx, cfg, k8sClient, namespaceName,
 			&machinev1beta1.Machine{},
 			&machinev1.ControlPlaneMachineSet{},
+			&configv1.Infrastructure{},
+			&corev1.ConfigMap{},
 		)
 	})
 
@@ -127,6 +149,23 @@ var _ = Describe("Webhooks", func() {
 				Expect(apierrors.ReasonForError(k8sClient.Create(ctx, cpms))).To(BeEquivalentTo("metadata.name: Invalid value: \"disallowed\": control plane machine set name must be cluster"))
 			})
 
+			It("with a valid max surge annotation", func() {
+				cpms := builder.Build()
+				cpms.Annotations = map[string]string{annotations.MaxSurgeAnnotation: "1"}
+
+				Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
+			})
+
+			It("with a max surge annotation that could break etcd quorum", func() {
+				cpms := builder.Build()
+				cpms.Annotations = map[string]string{annotations.MaxSurgeAnnotation: "2"}
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/max-surge]: Invalid value: \"2\": " +
+						"value must be between 1 and the number of etcd members that may be lost while maintaining quorum: got 2, allowed range is 1-1",
+				)))
+			})
+
 			It("with 4 replicas", func() {
 				// This is an openapi validation but it makes sense to include it here as well
 				cpms := builder.WithReplicas(4).Build()
@@ -453,6 +492,138 @@ var _ = Describe("Webhooks", func() {
 						ContainSubstring("AWSFailureDomain{AvailabilityZone:us-east-1c, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}"),
 					)))
 				})
+
+				Context("with failure domain weights", func() {
+					var cpms *machinev1.ControlPlaneMachineSet
+
+					BeforeEach(func() {
+						cpms = builder.WithMachineTemplateBuilder(machineTemplate.WithFailureDomainsBuilder(
+							machinev1resourcebuilder.AWSFailureDomains().WithFailureDomainBuilders(
+								usEast1aBuilder,
+								usEast1bBuilder,
+								usEast1cBuilder,
+							),
+						)).Build()
+					})
+
+					It("with valid weights", func() {
+						cpms.Annotations = map[string]string{
+							annotations.FailureDomainWeightsAnnotation: `[{"failureDomain":"AWSFailureDomain{AvailabilityZone:us-east-1a, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","weight":2},{"failureDomain":"AWSFailureDomain{AvailabilityZone:us-east-1c, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","avoid":true}]`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
+					})
+
+					It("with an invalid value", func() {
+						cpms.Annotations = map[string]string{
+							annotations.FailureDomainWeightsAnnotation: `[{"failureDomain":"AWSFailureDomain{AvailabilityZone:us-east-1a, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","weight":-1}]`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(MatchError(SatisfyAll(
+							ContainSubstring("metadata.annotations[controlplanemachineset.machine.openshift.io/failure-domain-weights]: Invalid value"),
+							ContainSubstring("weight must not be negative: got -1 for AWSFailureDomain{AvailabilityZone:us-east-1a, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}"),
+						)))
+					})
+
+					It("with a weight for a failure domain that is not configured", func() {
+						cpms.Annotations = map[string]string{
+							annotations.FailureDomainWeightsAnnotation: `[{"failureDomain":"AWSFailureDomain{AvailabilityZone:us-east-1d, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","weight":2}]`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(MatchError(SatisfyAll(
+							ContainSubstring("metadata.annotations[controlplanemachineset.machine.openshift.io/failure-domain-weights]: Invalid value"),
+							ContainSubstring("failure domain is not configured on the control plane machine set: AWSFailureDomain{AvailabilityZone:us-east-1d, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}"),
+						)))
+					})
+
+					It("with every failure domain avoided", func() {
+						cpms.Annotations = map[string]string{
+							annotations.FailureDomainWeightsAnnotation: `[{"failureDomain":"AWSFailureDomain{AvailabilityZone:us-east-1a, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","avoid":true},{"failureDomain":"AWSFailureDomain{AvailabilityZone:us-east-1b, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","avoid":true},{"failureDomain":"AWSFailureDomain{AvailabilityZone:us-east-1c, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","avoid":true}]`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(MatchError(SatisfyAll(
+							ContainSubstring("metadata.annotations[controlplanemachineset.machine.openshift.io/failure-domain-weights]: Invalid value"),
+							ContainSubstring("at least one failure domain must not be avoided"),
+						)))
+					})
+
+					It("without failure domains", func() {
+						cpms = builder.Build()
+						cpms.Annotations = map[string]string{
+							annotations.FailureDomainWeightsAnnotation: `[{"failureDomain":"AWSFailureDomain{AvailabilityZone:us-east-1a, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","weight":2}]`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+							"metadata.annotations[controlplanemachineset.machine.openshift.io/failure-domain-weights]: Forbidden: failure domain weights may only be configured when failure domains are configured",
+						)))
+					})
+				})
+
+				Context("with unavailable failure domains", func() {
+					var cpms *machinev1.ControlPlaneMachineSet
+
+					BeforeEach(func() {
+						cpms = builder.WithMachineTemplateBuilder(machineTemplate.WithFailureDomainsBuilder(
+							machinev1resourcebuilder.AWSFailureDomains().WithFailureDomainBuilders(
+								usEast1aBuilder,
+								usEast1bBuilder,
+								usEast1cBuilder,
+							),
+						)).Build()
+					})
+
+					It("with a valid unavailable failure domain", func() {
+						cpms.Annotations = map[string]string{
+							annotations.UnavailableFailureDomainsAnnotation: `["AWSFailureDomain{AvailabilityZone:us-east-1c, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}"]`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
+					})
+
+					It("with an invalid value", func() {
+						cpms.Annotations = map[string]string{
+							annotations.UnavailableFailureDomainsAnnotation: `AWSFailureDomain{AvailabilityZone:us-east-1c, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(MatchError(SatisfyAll(
+							ContainSubstring("metadata.annotations[controlplanemachineset.machine.openshift.io/unavailable-failure-domains]: Invalid value"),
+							ContainSubstring("value must be a JSON list of failure domains identified by their string representation"),
+						)))
+					})
+
+					It("with a failure domain that is not configured", func() {
+						cpms.Annotations = map[string]string{
+							annotations.UnavailableFailureDomainsAnnotation: `["AWSFailureDomain{AvailabilityZone:us-east-1d, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}"]`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(MatchError(SatisfyAll(
+							ContainSubstring("metadata.annotations[controlplanemachineset.machine.openshift.io/unavailable-failure-domains]: Invalid value"),
+							ContainSubstring("failure domain is not configured on the control plane machine set: AWSFailureDomain{AvailabilityZone:us-east-1d, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}"),
+						)))
+					})
+
+					It("with every failure domain unavailable", func() {
+						cpms.Annotations = map[string]string{
+							annotations.UnavailableFailureDomainsAnnotation: `["AWSFailureDomain{AvailabilityZone:us-east-1a, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","AWSFailureDomain{AvailabilityZone:us-east-1b, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}","AWSFailureDomain{AvailabilityZone:us-east-1c, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}"]`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(MatchError(SatisfyAll(
+							ContainSubstring("metadata.annotations[controlplanemachineset.machine.openshift.io/unavailable-failure-domains]: Invalid value"),
+							ContainSubstring("at least one failure domain must remain available"),
+						)))
+					})
+
+					It("without failure domains", func() {
+						cpms = builder.Build()
+						cpms.Annotations = map[string]string{
+							annotations.UnavailableFailureDomainsAnnotation: `["AWSFailureDomain{AvailabilityZone:us-east-1a, Subnet:{Type:Filters, Value:&[{Name:tag:Name Values:[aws-subnet-12345678]}]}}"]`,
+						}
+
+						Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+							"metadata.annotations[controlplanemachineset.machine.openshift.io/unavailable-failure-domains]: Forbidden: failure domains may only be marked unavailable when failure domains are configured",
+						)))
+					})
+				})
 			})
 
 			Context("with machines spread unevenly across failure domains", func() {
@@ -699,6 +870,273 @@ var _ = Describe("Webhooks", func() {
 				Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
 			})
 		})
+
+		Context("on OpenStack", func() {
+			var cpms *machinev1.ControlPlaneMachineSet
+
+			BeforeEach(func() {
+				providerSpec := machinev1alpha1resourcebuilder.OpenStackProviderSpec().WithRootVolume()
+				machineTemplate = machinev1resourcebuilder.OpenShiftMachineV1Beta1Template().WithProviderSpecBuilder(providerSpec)
+				// Default CPMS should be valid, individual tests will override to make it invalid
+				cpms = machinev1resourcebuilder.ControlPlaneMachineSet().WithNamespace(namespaceName).WithMachineTemplateBuilder(machineTemplate).Build()
+				cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform = configv1.OpenStackPlatformType
+				cpms.Annotations = map[string]string{
+					annotations.OpenStackFailureDomainsAnnotation: `[{"availabilityZone":"az1","rootVolume":{"availabilityZone":"volume-az1"}},` +
+						`{"availabilityZone":"az2","rootVolume":{"availabilityZone":"volume-az2"}},` +
+						`{"availabilityZone":"az3","rootVolume":{"availabilityZone":"volume-az3"}}]`,
+				}
+
+				machineBuilder := machinev1beta1resourcebuilder.Machine().WithNamespace(namespaceName)
+
+				By("Creating a selection of Machines")
+				for _, az := range []string{"1", "2", "3"} {
+					machineProviderSpec := providerSpec.WithAvailabilityZone("az" + az).WithRootVolumeAvailabilityZone("volume-az" + az)
+					controlPlaneMachine := machineBuilder.WithGenerateName("control-plane-machine-").AsMaster().WithProviderSpecBuilder(machineProviderSpec).Build()
+					Expect(k8sClient.Create(ctx, controlPlaneMachine)).To(Succeed())
+				}
+			})
+
+			It("with a valid failure domains annotation", func() {
+				Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
+			})
+
+			It("with a mismatched failure domains annotation", func() {
+				cpms.Annotations[annotations.OpenStackFailureDomainsAnnotation] = `[{"availabilityZone":"az1","rootVolume":{"availabilityZone":"volume-az1"}},` +
+					`{"availabilityZone":"az2","rootVolume":{"availabilityZone":"volume-az2"}},` +
+					`{"availabilityZone":"az3"}]`
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(
+					ContainSubstring("spec.template.machines_v1beta1_machine_openshift_io.failureDomains: Forbidden: control plane machines are using unspecified failure domain(s) [OpenStackFailureDomain{AvailabilityZone:az3, RootVolume:{AvailabilityZone:volume-az3}}]"),
+				))
+			})
+
+			It("without the failure domains annotation", func() {
+				delete(cpms.Annotations, annotations.OpenStackFailureDomainsAnnotation)
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/openstack-failure-domains]: Required value: failure domains must be configured when the failure domains platform is set",
+				)))
+			})
+
+			It("with an invalid failure domains annotation", func() {
+				cpms.Annotations[annotations.OpenStackFailureDomainsAnnotation] = `[{"zone":"az1"}]`
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(SatisfyAll(
+					ContainSubstring("metadata.annotations[controlplanemachineset.machine.openshift.io/openstack-failure-domains]: Invalid value"),
+					ContainSubstring("value must be a JSON list of failure domains: json: unknown field"),
+				)))
+			})
+
+			It("with a root volume failure domain when the provider spec has no root volume", func() {
+				cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.Spec.ProviderSpec.Value = machinev1alpha1resourcebuilder.OpenStackProviderSpec().BuildRawExtension()
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+					"a root volume availability zone may only be configured when the provider spec configures a root volume",
+				)))
+			})
+
+			It("with the failure domains annotation on another platform", func() {
+				cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform = ""
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/openstack-failure-domains]: Forbidden: failure domains may only be configured when the failure domains platform matches",
+				)))
+			})
+		})
+
+		Context("on VSphere", func() {
+			var cpms *machinev1.ControlPlaneMachineSet
+
+			BeforeEach(func() {
+				failureDomainSpecs := []configv1.VSpherePlatformFailureDomainSpec{}
+				for _, i := range []string{"1", "2", "3"} {
+					failureDomainSpecs = append(failureDomainSpecs, configv1.VSpherePlatformFailureDomainSpec{
+						Name:   "fd-" + i,
+						Region: "region-1",
+						Zone:   "zone-" + i,
+						Server: "vcenter.example.com",
+						Topology: configv1.VSpherePlatformTopology{
+							Datacenter:     "dc-1",
+							ComputeCluster: "/dc-1/host/cluster-" + i,
+							Networks:       []string{"network-" + i},
+							Datastore:      "/dc-1/datastore/datastore-" + i,
+						},
+					})
+				}
+
+				By("Creating an Infrastructure with vSphere failure domains")
+				infrastructure := &configv1.Infrastructure{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "cluster",
+					},
+					Spec: configv1.InfrastructureSpec{
+						PlatformSpec: configv1.PlatformSpec{
+							Type: configv1.VSpherePlatformType,
+							VSphere: &configv1.VSpherePlatformSpec{
+								FailureDomains: failureDomainSpecs,
+							},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, infrastructure)).To(Succeed())
+
+				providerSpec := machinev1beta1resourcebuilder.VSphereProviderSpec()
+				machineTemplate = machinev1resourcebuilder.OpenShiftMachineV1Beta1Template().WithProviderSpecBuilder(providerSpec)
+				// Default CPMS should be valid, individual tests will override to make it invalid
+				cpms = machinev1resourcebuilder.ControlPlaneMachineSet().WithNamespace(namespaceName).WithMachineTemplateBuilder(machineTemplate).Build()
+				cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform = configv1.VSpherePlatformType
+				cpms.Annotations = map[string]string{
+					annotations.VSphereFailureDomainsAnnotation: `[{"name":"fd-1"},{"name":"fd-2"},{"name":"fd-3"}]`,
+				}
+
+				machineBuilder := machinev1beta1resourcebuilder.Machine().WithNamespace(namespaceName)
+
+				By("Creating a selection of Machines")
+				for _, failureDomainSpec := range failureDomainSpecs {
+					machineProviderSpec := providerSpec.Build()
+					machineProviderSpec.Workspace = &machinev1beta1.Workspace{
+						Server:       failureDomainSpec.Server,
+						Datacenter:   failureDomainSpec.Topology.Datacenter,
+						Datastore:    failureDomainSpec.Topology.Datastore,
+						ResourcePool: failureDomainSpec.Topology.ComputeCluster + "/Resources",
+					}
+
+					rawProviderSpec, err := json.Marshal(machineProviderSpec)
+					Expect(err).ToNot(HaveOccurred())
+
+					controlPlaneMachine := machineBuilder.WithGenerateName("control-plane-machine-").AsMaster().Build()
+					controlPlaneMachine.Spec.ProviderSpec.Value = &runtime.RawExtension{Raw: rawProviderSpec}
+					Expect(k8sClient.Create(ctx, controlPlaneMachine)).To(Succeed())
+				}
+			})
+
+			It("with a valid failure domains annotation", func() {
+				Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
+			})
+
+			It("with a failure domain that is not defined in the infrastructure", func() {
+				cpms.Annotations[annotations.VSphereFailureDomainsAnnotation] = `[{"name":"fd-1"},{"name":"fd-2"},{"name":"fd-3"},{"name":"fd-4"}]`
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+					"failure domain \"fd-4\" is not defined in the vSphere platform spec of the infrastructure",
+				)))
+			})
+
+			It("with a mismatched failure domains annotation", func() {
+				cpms.Annotations[annotations.VSphereFailureDomainsAnnotation] = `[{"name":"fd-1"},{"name":"fd-2"}]`
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(
+					ContainSubstring("spec.template.machines_v1beta1_machine_openshift_io.failureDomains: Forbidden: control plane machines are using unspecified failure domain(s) [VSphereFailureDomain{Name:fd-3}]"),
+				))
+			})
+
+			It("without the failure domains annotation", func() {
+				delete(cpms.Annotations, annotations.VSphereFailureDomainsAnnotation)
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/vsphere-failure-domains]: Required value: failure domains must be configured when the failure domains platform is set",
+				)))
+			})
+
+			It("with a failure domain without a name", func() {
+				cpms.Annotations[annotations.VSphereFailureDomainsAnnotation] = `[{"name":""}]`
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+					"each failure domain must have a name",
+				)))
+			})
+		})
+
+		Context("on Nutanix", func() {
+			var cpms *machinev1.ControlPlaneMachineSet
+
+			BeforeEach(func() {
+				prismElements := []configv1.NutanixPrismElementEndpoint{}
+				for _, name := range []string{"pe-1", "pe-2", "pe-3"} {
+					prismElements = append(prismElements, configv1.NutanixPrismElementEndpoint{
+						Name: name,
+						Endpoint: configv1.NutanixPrismEndpoint{
+							Address: name + ".example.com",
+							Port:    9440,
+						},
+					})
+				}
+
+				By("Creating an Infrastructure with Nutanix Prism Elements")
+				infrastructure := &configv1.Infrastructure{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "cluster",
+					},
+					Spec: configv1.InfrastructureSpec{
+						PlatformSpec: configv1.PlatformSpec{
+							Type: configv1.NutanixPlatformType,
+							Nutanix: &configv1.NutanixPlatformSpec{
+								PrismCentral: configv1.NutanixPrismEndpoint{
+									Address: "prism-central.example.com",
+									Port:    9440,
+								},
+								PrismElements: prismElements,
+							},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, infrastructure)).To(Succeed())
+
+				providerSpec := testmachinev1resourcebuilder.NutanixProviderSpec()
+				machineTemplate = machinev1resourcebuilder.OpenShiftMachineV1Beta1Template().WithProviderSpecBuilder(providerSpec)
+				// Default CPMS should be valid, individual tests will override to make it invalid
+				cpms = machinev1resourcebuilder.ControlPlaneMachineSet().WithNamespace(namespaceName).WithMachineTemplateBuilder(machineTemplate).Build()
+				cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.FailureDomains.Platform = configv1.NutanixPlatformType
+				cpms.Annotations = map[string]string{
+					annotations.NutanixFailureDomainsAnnotation: `[{"cluster":{"type":"name","name":"pe-1"},"subnets":[{"type":"name","name":"subnet-1"}]},` +
+						`{"cluster":{"type":"name","name":"pe-2"},"subnets":[{"type":"name","name":"subnet-2"}]},` +
+						`{"cluster":{"type":"name","name":"pe-3"},"subnets":[{"type":"name","name":"subnet-3"}]}]`,
+				}
+
+				machineBuilder := machinev1beta1resourcebuilder.Machine().WithNamespace(namespaceName)
+
+				By("Creating a selection of Machines")
+				for _, i := range []string{"1", "2", "3"} {
+					machineProviderSpec := providerSpec.WithCluster("pe-" + i).WithSubnets("subnet-" + i)
+
+					controlPlaneMachine := machineBuilder.WithGenerateName("control-plane-machine-").AsMaster().WithProviderSpecBuilder(machineProviderSpec).Build()
+					Expect(k8sClient.Create(ctx, controlPlaneMachine)).To(Succeed())
+				}
+			})
+
+			It("with a valid failure domains annotation", func() {
+				Expect(k8sClient.Create(ctx, cpms)).To(Succeed())
+			})
+
+			It("with a Prism Element that is not defined in the infrastructure", func() {
+				cpms.Annotations[annotations.NutanixFailureDomainsAnnotation] = `[{"cluster":{"type":"name","name":"pe-1"},"subnets":[{"type":"name","name":"subnet-1"}]},` +
+					`{"cluster":{"type":"name","name":"pe-2"},"subnets":[{"type":"name","name":"subnet-2"}]},` +
+					`{"cluster":{"type":"name","name":"pe-3"},"subnets":[{"type":"name","name":"subnet-3"}]},` +
+					`{"cluster":{"type":"name","name":"pe-4"},"subnets":[{"type":"name","name":"subnet-4"}]}]`
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+					"prism element \"pe-4\" is not defined in the Nutanix platform spec of the infrastructure",
+				)))
+			})
+
+			It("with a mismatched failure domains annotation", func() {
+				cpms.Annotations[annotations.NutanixFailureDomainsAnnotation] = `[{"cluster":{"type":"name","name":"pe-1"},"subnets":[{"type":"name","name":"subnet-1"}]},` +
+					`{"cluster":{"type":"name","name":"pe-2"},"subnets":[{"type":"name","name":"subnet-2"}]}]`
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(
+					ContainSubstring("spec.template.machines_v1beta1_machine_openshift_io.failureDomains: Forbidden: control plane machines are using unspecified failure domain(s) " +
+						"[NutanixFailureDomain{Cluster:{Type:name, Value:pe-3}, Subnets:[{Type:name, Value:subnet-3}]}]"),
+				))
+			})
+
+			It("without the failure domains annotation", func() {
+				delete(cpms.Annotations, annotations.NutanixFailureDomainsAnnotation)
+
+				Expect(k8sClient.Create(ctx, cpms)).To(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/nutanix-failure-domains]: Required value: failure domains must be configured when the failure domains platform is set",
+				)))
+			})
+		})
 	})
 
 	Context("on update", func() {
@@ -754,6 +1192,217 @@ var _ = Describe("Webhooks", func() {
 				})()).Should(Succeed(), "Machine label updates are allowed provided the selector still matches")
 			})
 
+			It("when pausing the rollout", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.PausedAnnotation: "true"}
+				})()).Should(Succeed())
+			})
+
+			It("when adding an invalid paused annotation", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.PausedAnnotation: "yes"}
+				})()).Should(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/paused]: Invalid value: \"yes\": value must be either true or false: \"yes\"",
+				)))
+			})
+
+			It("when partitioning the rollout", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.PartitionAnnotation: "1"}
+				})()).Should(Succeed())
+			})
+
+			Context("when ignoring provider spec fields", func() {
+				var collector *warningCollector
+				var warningClient client.Client
+
+				BeforeEach(func() {
+					collector = &warningCollector{}
+
+					warningConfig := rest.CopyConfig(cfg)
+					warningConfig.WarningHandler = collector
+
+					// The client replaces the configured warning handler unless its own handling of warnings is suppressed.
+					var err error
+					warningClient, err = client.New(warningConfig, client.Options{Scheme: testScheme, Opts: client.WarningHandlerOptions{SuppressWarnings: true}})
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				ignoreFields := func(value string) error {
+					patchBase := client.MergeFrom(cpms.DeepCopy())
+					cpms.Annotations = map[string]string{annotations.IgnoredProviderSpecFieldsAnnotation: value}
+
+					return warningClient.Patch(ctx, cpms, patchBase)
+				}
+
+				It("with fields that do not affect the Machine resources", func() {
+					Expect(ignoreFields(`{"AWS":["spec.providerSpec.value.tags"]}`)).To(Succeed())
+					Expect(collector.warnings).To(BeEmpty())
+				})
+
+				It("with fields that affect the Machine resources", func() {
+					Expect(ignoreFields(`{"AWS":["spec.providerSpec.value.tags","spec.providerSpec.value.instanceType"],"Azure":["spec.providerSpec.value.image.sku"]}`)).To(Succeed())
+					Expect(collector.warnings).To(ConsistOf(
+						"metadata.annotations[controlplanemachineset.machine.openshift.io/ignored-provider-spec-fields]: ignoring spec.providerSpec.value.instanceType on AWS platform means that Machines will not be replaced when spec.providerSpec.value.instanceType changes",
+						"metadata.annotations[controlplanemachineset.machine.openshift.io/ignored-provider-spec-fields]: ignoring spec.providerSpec.value.image.sku on Azure platform means that Machines will not be replaced when spec.providerSpec.value.image changes",
+					))
+				})
+
+				It("with an invalid value", func() {
+					Expect(ignoreFields(`{"AWS":["tags"]}`)).To(MatchError(ContainSubstring(
+						"metadata.annotations[controlplanemachineset.machine.openshift.io/ignored-provider-spec-fields]: Invalid value: \"{\\\"AWS\\\":[\\\"tags\\\"]}\": field path must identify a field within spec.providerSpec.value: tags",
+					)))
+					Expect(collector.warnings).To(BeEmpty())
+				})
+			})
+
+			Context("when rolling back the template", func() {
+				// storeRevisions records the templates as the applied template revisions, and returns their revisions.
+				storeRevisions := func(templates ...machinev1.ControlPlaneMachineSetTemplate) []string {
+					revisions := []templaterevisions.TemplateRevision{}
+					out := []string{}
+
+					for _, template := range templates {
+						revision, err := util.TemplateHash(template, nil)
+						Expect(err).ToNot(HaveOccurred())
+
+						revisions = append(revisions, templaterevisions.TemplateRevision{Revision: revision, AppliedTime: metav1.Now(), Template: template})
+						out = append(out, revision)
+					}
+
+					value, err := json.Marshal(revisions)
+					Expect(err).ToNot(HaveOccurred())
+
+					configMap := &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      templaterevisions.ConfigMapName(cpms),
+							Namespace: namespaceName,
+						},
+						Data: map[string]string{templaterevisions.RevisionsKey: string(value)},
+					}
+					Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+
+					return out
+				}
+
+				rollback := func(value string) func() error {
+					return komega.Update(cpms, func() {
+						cpms.Annotations = map[string]string{annotations.RollbackToRevisionAnnotation: value}
+					})
+				}
+
+				previousTemplate := func() machinev1.ControlPlaneMachineSetTemplate {
+					template := *cpms.Spec.Template.DeepCopy()
+					template.OpenShiftMachineV1Beta1Machine.Spec.ProviderSpec.Value = machinev1beta1resourcebuilder.AWSProviderSpec().WithAvailabilityZone("us-east-2").BuildRawExtension()
+
+					return template
+				}
+
+				It("to a kept revision", func() {
+					revisions := storeRevisions(previousTemplate(), cpms.Spec.Template)
+
+					// The webhook reads the revisions from its cache, so allow time for the cache to observe them.
+					Eventually(rollback(revisions[0])).Should(Succeed())
+				})
+
+				It("to the previous revision", func() {
+					storeRevisions(previousTemplate(), cpms.Spec.Template)
+
+					Eventually(rollback(annotations.PreviousTemplateRevision)).Should(Succeed())
+				})
+
+				It("to an unknown revision", func() {
+					Expect(rollback("abc")()).To(MatchError(ContainSubstring(
+						"metadata.annotations[controlplanemachineset.machine.openshift.io/rollback-to-revision]: Invalid value: \"abc\": unknown template revision: abc",
+					)))
+				})
+
+				It("to the previous revision when only the current revision is kept", func() {
+					storeRevisions(cpms.Spec.Template)
+
+					Eventually(rollback(annotations.PreviousTemplateRevision)).Should(MatchError(ContainSubstring(
+						"metadata.annotations[controlplanemachineset.machine.openshift.io/rollback-to-revision]: Invalid value: \"previous\": no previous template revision is available",
+					)))
+				})
+
+				It("to a revision that is no longer valid", func() {
+					template := previousTemplate()
+					delete(template.OpenShiftMachineV1Beta1Machine.ObjectMeta.Labels, machinev1beta1.MachineClusterIDLabel)
+
+					revisions := storeRevisions(template, cpms.Spec.Template)
+
+					Eventually(rollback(revisions[0])).Should(MatchError(ContainSubstring(
+						fmt.Sprintf("metadata.annotations[controlplanemachineset.machine.openshift.io/rollback-to-revision]: Invalid value: \"%s\": restored template is invalid: ", revisions[0]),
+					)))
+				})
+			})
+
+			It("when adding a partition annotation greater than the replicas", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.PartitionAnnotation: "4"}
+				})()).Should(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/partition]: Invalid value: \"4\": value must be between 0 and the number of replicas: got 4, allowed range is 0-3",
+				)))
+			})
+
+			It("when setting an explicit replacement order", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.ReplacementOrderAnnotation: "2,0,1"}
+				})()).Should(Succeed())
+			})
+
+			It("when setting the NonLeaderFirst replacement order", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.ReplacementOrderAnnotation: "NonLeaderFirst"}
+				})()).Should(Succeed())
+			})
+
+			It("when setting a replacement order with an index out of range", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.ReplacementOrderAnnotation: "3,0"}
+				})()).Should(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/replacement-order]: Invalid value: \"3,0\": index must be between 0 and the number of replicas minus 1: got 3, allowed range is 0-2",
+				)))
+			})
+
+			It("when configuring a maintenance window", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{
+						annotations.MaintenanceWindowScheduleAnnotation: "0 22 * * 1-5",
+						annotations.MaintenanceWindowDurationAnnotation: "4h",
+					}
+				})()).Should(Succeed())
+			})
+
+			It("when configuring a maintenance window with an invalid schedule", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{
+						annotations.MaintenanceWindowScheduleAnnotation: "0 25 * * *",
+						annotations.MaintenanceWindowDurationAnnotation: "4h",
+					}
+				})()).Should(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/maintenance-window-schedule]: Invalid value: \"0 25 * * *\": could not parse schedule: invalid schedule: hour field must be within 0-23: \"25\"",
+				)))
+			})
+
+			It("when configuring a maintenance window without a duration", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{
+						annotations.MaintenanceWindowScheduleAnnotation: "0 22 * * 1-5",
+					}
+				})()).Should(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/maintenance-window-duration]: Required value: the maintenance window schedule and duration must be set together",
+				)))
+			})
+
+			It("when adding a non-integer max surge annotation", func() {
+				Expect(komega.Update(cpms, func() {
+					cpms.Annotations = map[string]string{annotations.MaxSurgeAnnotation: "two"}
+				})()).Should(MatchError(ContainSubstring(
+					"metadata.annotations[controlplanemachineset.machine.openshift.io/max-surge]: Invalid value: \"two\": value must be an integer: \"two\"",
+				)))
+			})
+
 			It("when modifying the machine labels so that the selector no longer matches", func() {
 				Expect(komega.Update(cpms, func() {
 					cpms.Spec.Template.OpenShiftMachineV1Beta1Machine.ObjectMeta.Labels = map[string]string{
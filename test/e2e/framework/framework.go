@@ -207,7 +207,7 @@ func (f *framework) IncreaseProviderSpecInstanceSize(rawProviderSpec *runtime.Ra
 		ProviderSpec: machinev1beta1.ProviderSpec{
 			Value: rawProviderSpec,
 		},
-	})
+	}, nil)
 	if err != nil {
 		return fmt.Errorf("failed to get provider config: %w", err)
 	}
@@ -229,7 +229,7 @@ func (f *framework) IncreaseProviderSpecInstanceSize(rawProviderSpec *runtime.Ra
 func (f *framework) ConvertToControlPlaneMachineSetProviderSpec(providerSpec machinev1beta1.ProviderSpec) (*runtime.RawExtension, error) {
 	providerConfig, err := providerconfig.NewProviderConfigFromMachineSpec(machinev1beta1.MachineSpec{
 		ProviderSpec: providerSpec,
-	})
+	}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get provider config: %w", err)
 	}